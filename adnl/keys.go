@@ -0,0 +1,82 @@
+package adnl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/oasisprotocol/curve25519-voi/curve"
+	ed25519crv "github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/oasisprotocol/curve25519-voi/primitives/x25519"
+)
+
+// SharedKey computes an x25519 shared secret of two ed25519 keys.
+func SharedKey(ourKey ed25519.PrivateKey, peerKey ed25519.PublicKey) ([]byte, error) {
+	comp, err := curve.NewCompressedEdwardsYFromBytes(peerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := curve.NewEdwardsPoint().SetCompressedY(comp)
+	if err != nil {
+		return nil, err
+	}
+
+	mp := curve.NewMontgomeryPoint().SetEdwards(ep)
+	bb := x25519.EdPrivateKeyToX25519(ed25519crv.PrivateKey(ourKey))
+
+	key, err := x25519.X25519(bb, mp[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// newCipher returns AES-CTR stream derived from a shared secret and a sha256 checksum of data.
+// It is the same scheme that is used by the ADNL TCP handshake.
+func newCipher(secret []byte, checksum []byte) (cipher.Stream, error) {
+	key := append([]byte{}, secret[:16]...)
+	key = append(key, checksum[16:32]...)
+	nonce := append([]byte{}, checksum[0:4]...)
+	nonce = append(nonce, secret[20:32]...)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCTR(block, nonce), nil
+}
+
+// encrypt returns checksum || ciphertext.
+func encrypt(secret []byte, data []byte) ([]byte, error) {
+	checksum := sha256.Sum256(data)
+	stream, err := newCipher(secret, checksum[:])
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, 32+len(data))
+	copy(res, checksum[:])
+	stream.XORKeyStream(res[32:], data)
+	return res, nil
+}
+
+// decrypt decrypts checksum || ciphertext and validates the checksum.
+func decrypt(secret []byte, data []byte) ([]byte, error) {
+	if len(data) < 32 {
+		return nil, fmt.Errorf("too short encrypted data")
+	}
+	stream, err := newCipher(secret, data[:32])
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, len(data)-32)
+	stream.XORKeyStream(res, data[32:])
+	checksum := sha256.Sum256(res)
+	if !bytes.Equal(checksum[:], data[:32]) {
+		return nil, fmt.Errorf("invalid checksum")
+	}
+	return res, nil
+}
@@ -0,0 +1,352 @@
+package adnl
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/caigou-xyz/tongo/tl"
+)
+
+const (
+	magicPacketContents = 0xd142cd89 // adnl.packetContents
+)
+
+// PublicKey is a TL PublicKey type from ton_api.tl.
+type PublicKey struct {
+	tl.SumType
+	PubUnenc struct {
+		Data []byte
+	} `tlSumType:"b61f450a"`
+	PubEd25519 struct {
+		Key tl.Int256
+	} `tlSumType:"4813b4c6"`
+	PubAes struct {
+		Key tl.Int256
+	} `tlSumType:"2dbcadd4"`
+	PubOverlay struct {
+		Name []byte
+	} `tlSumType:"34ba45cb"`
+}
+
+// NewPublicKey returns pub.ed25519 key.
+func NewPublicKey(key ed25519.PublicKey) PublicKey {
+	var k PublicKey
+	k.SumType = "PubEd25519"
+	copy(k.PubEd25519.Key[:], key)
+	return k
+}
+
+// ID returns a short ID of the key: sha256 hash of its TL representation.
+// ADNL addresses, DHT node IDs and DHT key IDs are short IDs of public keys.
+func (k PublicKey) ID() (tl.Int256, error) {
+	b, err := tl.Marshal(k)
+	if err != nil {
+		return tl.Int256{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+// Ed25519 returns an ed25519 key if k is pub.ed25519.
+func (k PublicKey) Ed25519() (ed25519.PublicKey, bool) {
+	if k.SumType != "PubEd25519" {
+		return nil, false
+	}
+	return ed25519.PublicKey(append([]byte{}, k.PubEd25519.Key[:]...)), true
+}
+
+// KeyID returns a short ID of an ed25519 public key.
+func KeyID(key ed25519.PublicKey) tl.Int256 {
+	id, _ := NewPublicKey(key).ID() // pub.ed25519 can always be serialized
+	return id
+}
+
+// Int128 is a TL int128.
+type Int128 [16]byte
+
+func (i Int128) MarshalTL() ([]byte, error) {
+	return i[:], nil
+}
+
+func (i *Int128) UnmarshalTL(r io.Reader) error {
+	_, err := io.ReadFull(r, i[:])
+	return err
+}
+
+// Address is a TL adnl.Address type.
+type Address struct {
+	tl.SumType
+	AddressUdp struct {
+		Ip   uint32
+		Port uint32
+	} `tlSumType:"670da6e7"`
+	AddressUdp6 struct {
+		Ip   Int128
+		Port uint32
+	} `tlSumType:"e31d63fa"`
+}
+
+// UDPAddr converts an address to *net.UDPAddr.
+func (a Address) UDPAddr() (*net.UDPAddr, error) {
+	switch a.SumType {
+	case "AddressUdp":
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, a.AddressUdp.Ip)
+		return &net.UDPAddr{IP: ip, Port: int(a.AddressUdp.Port)}, nil
+	case "AddressUdp6":
+		ip := make(net.IP, 16)
+		copy(ip, a.AddressUdp6.Ip[:])
+		return &net.UDPAddr{IP: ip, Port: int(a.AddressUdp6.Port)}, nil
+	}
+	return nil, fmt.Errorf("unsupported address type %v", a.SumType)
+}
+
+// AddressList is a TL adnl.addressList type.
+type AddressList struct {
+	Addrs      []Address
+	Version    uint32
+	ReinitDate uint32
+	Priority   uint32
+	ExpireAt   uint32
+}
+
+// NewAddressList returns an address list with the given UDP addresses.
+func NewAddressList(version uint32, addrs ...*net.UDPAddr) AddressList {
+	list := AddressList{Addrs: []Address{}, Version: version, ReinitDate: version}
+	for _, addr := range addrs {
+		var a Address
+		if ip := addr.IP.To4(); ip != nil {
+			a.SumType = "AddressUdp"
+			a.AddressUdp.Ip = binary.BigEndian.Uint32(ip)
+			a.AddressUdp.Port = uint32(addr.Port)
+		} else {
+			a.SumType = "AddressUdp6"
+			copy(a.AddressUdp6.Ip[:], addr.IP.To16())
+			a.AddressUdp6.Port = uint32(addr.Port)
+		}
+		list.Addrs = append(list.Addrs, a)
+	}
+	return list
+}
+
+// UDPAddrs returns all addresses of the list that can be reached over UDP.
+func (l AddressList) UDPAddrs() []*net.UDPAddr {
+	var addrs []*net.UDPAddr
+	for _, a := range l.Addrs {
+		addr, err := a.UDPAddr()
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// Message is a TL adnl.Message type.
+type Message struct {
+	tl.SumType
+	MessageCreateChannel struct {
+		Key  tl.Int256
+		Date uint32
+	} `tlSumType:"e673c3bb"`
+	MessageConfirmChannel struct {
+		Key     tl.Int256
+		PeerKey tl.Int256
+		Date    uint32
+	} `tlSumType:"60dd1d69"`
+	MessageCustom struct {
+		Data []byte
+	} `tlSumType:"204818f5"`
+	MessageNop    struct{} `tlSumType:"17f8dfda"`
+	MessageReinit struct {
+		Date uint32
+	} `tlSumType:"10c20520"`
+	MessageQuery struct {
+		QueryId tl.Int256
+		Query   []byte
+	} `tlSumType:"b48bf97a"`
+	MessageAnswer struct {
+		QueryId tl.Int256
+		Answer  []byte
+	} `tlSumType:"0fac8416"`
+	MessagePart struct {
+		Hash      tl.Int256
+		TotalSize uint32
+		Offset    uint32
+		Data      []byte
+	} `tlSumType:"fd452d39"`
+}
+
+const (
+	flagFrom = 1 << iota
+	flagFromShort
+	flagMessage
+	flagMessages
+	flagAddress
+	flagPriorityAddress
+	flagSeqno
+	flagConfirmSeqno
+	flagRecvAddrListVersion
+	flagRecvPriorityAddrListVersion
+	flagReinitDate
+	flagSignature
+)
+
+// packetContents is a TL adnl.packetContents type.
+// The type has a lot of optional fields, so it is encoded manually.
+type packetContents struct {
+	Rand1                       []byte
+	Flags                       uint32
+	From                        PublicKey
+	FromShort                   tl.Int256
+	Message                     Message
+	Messages                    []Message
+	Address                     AddressList
+	PriorityAddress             AddressList
+	Seqno                       uint64
+	ConfirmSeqno                uint64
+	RecvAddrListVersion         uint32
+	RecvPriorityAddrListVersion uint32
+	ReinitDate                  uint32
+	DstReinitDate               uint32
+	Signature                   []byte
+	Rand2                       []byte
+}
+
+// marshal returns a boxed TL representation of the packet.
+func (p packetContents) marshal() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	write := func(v any) error {
+		b, err := tl.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+	fields := []struct {
+		flag  uint32
+		value any
+	}{
+		{0, uint32(magicPacketContents)},
+		{0, p.Rand1},
+		{0, p.Flags},
+		{flagFrom, p.From},
+		{flagFromShort, p.FromShort},
+		{flagMessage, p.Message},
+		{flagMessages, p.Messages},
+		{flagAddress, p.Address},
+		{flagPriorityAddress, p.PriorityAddress},
+		{flagSeqno, p.Seqno},
+		{flagConfirmSeqno, p.ConfirmSeqno},
+		{flagRecvAddrListVersion, p.RecvAddrListVersion},
+		{flagRecvPriorityAddrListVersion, p.RecvPriorityAddrListVersion},
+		{flagReinitDate, p.ReinitDate},
+		{flagReinitDate, p.DstReinitDate},
+		{flagSignature, p.Signature},
+		{0, p.Rand2},
+	}
+	for _, f := range fields {
+		if f.flag != 0 && p.Flags&f.flag == 0 {
+			continue
+		}
+		if err := write(f.value); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshal decodes a boxed TL representation of the packet.
+func (p *packetContents) unmarshal(data []byte) error {
+	r := bytes.NewReader(data)
+	var tag uint32
+	if err := tl.Unmarshal(r, &tag); err != nil {
+		return err
+	}
+	if tag != magicPacketContents {
+		return fmt.Errorf("invalid packet tag %x", tag)
+	}
+	if err := tl.Unmarshal(r, &p.Rand1); err != nil {
+		return err
+	}
+	if err := tl.Unmarshal(r, &p.Flags); err != nil {
+		return err
+	}
+	fields := []struct {
+		flag  uint32
+		value any
+	}{
+		{flagFrom, &p.From},
+		{flagFromShort, &p.FromShort},
+		{flagMessage, &p.Message},
+		{flagMessages, &p.Messages},
+		{flagAddress, &p.Address},
+		{flagPriorityAddress, &p.PriorityAddress},
+		{flagSeqno, &p.Seqno},
+		{flagConfirmSeqno, &p.ConfirmSeqno},
+		{flagRecvAddrListVersion, &p.RecvAddrListVersion},
+		{flagRecvPriorityAddrListVersion, &p.RecvPriorityAddrListVersion},
+		{flagReinitDate, &p.ReinitDate},
+		{flagReinitDate, &p.DstReinitDate},
+		{flagSignature, &p.Signature},
+	}
+	for _, f := range fields {
+		if p.Flags&f.flag == 0 {
+			continue
+		}
+		if err := tl.Unmarshal(r, f.value); err != nil {
+			return err
+		}
+	}
+	return tl.Unmarshal(r, &p.Rand2)
+}
+
+// messages returns all messages of the packet.
+func (p packetContents) messages() []Message {
+	var msgs []Message
+	if p.Flags&flagMessage != 0 {
+		msgs = append(msgs, p.Message)
+	}
+	if p.Flags&flagMessages != 0 {
+		msgs = append(msgs, p.Messages...)
+	}
+	return msgs
+}
+
+// sign signs the packet with the key and sets flagFrom and flagSignature.
+func (p *packetContents) sign(key ed25519.PrivateKey) error {
+	p.From = NewPublicKey(key.Public().(ed25519.PublicKey))
+	p.Flags |= flagFrom
+	p.Flags &^= flagSignature
+	p.Signature = nil
+	b, err := p.marshal()
+	if err != nil {
+		return err
+	}
+	p.Signature = ed25519.Sign(key, b)
+	p.Flags |= flagSignature
+	return nil
+}
+
+// verify checks the signature of the packet.
+func (p packetContents) verify(key ed25519.PublicKey) error {
+	if p.Flags&flagSignature == 0 {
+		return fmt.Errorf("packet is not signed")
+	}
+	signature := p.Signature
+	p.Flags &^= flagSignature
+	p.Signature = nil
+	b, err := p.marshal()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, b, signature) {
+		return fmt.Errorf("invalid packet signature")
+	}
+	return nil
+}
@@ -0,0 +1,686 @@
+package adnl
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/caigou-xyz/tongo/tl"
+)
+
+const (
+	// maxMessageSize is a size of a serialized message
+	// after which the message is split into adnl.message.part messages.
+	maxMessageSize = 1024
+	// maxPartialMessageSize limits a size of a message assembled from adnl.message.part messages.
+	maxPartialMessageSize = 1 << 20
+	// maxPartialMessages limits a number of messages being assembled for one peer.
+	maxPartialMessages = 16
+	maxDatagramSize    = 1 << 16
+)
+
+var (
+	ErrClosed = errors.New("adnl: transport is closed")
+)
+
+// QueryHandler answers adnl.message.query messages received by a Transport.
+// If QueryHandler returns an error, no answer is sent.
+type QueryHandler func(ctx context.Context, peer *Peer, query []byte) ([]byte, error)
+
+// Transport implements ADNL over UDP.
+// It owns a UDP socket and an ed25519 identity and multiplexes communication with many peers.
+// Once a peer is contacted, the transport establishes an ADNL channel with it,
+// so only the first packets are signed and encrypted with the identity key.
+type Transport struct {
+	key        ed25519.PrivateKey
+	id         tl.Int256
+	conn       net.PacketConn
+	reinitDate uint32
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	// mu protects all fields below.
+	mu       sync.Mutex
+	handler  QueryHandler
+	addrList AddressList
+	peers    map[tl.Int256]*Peer
+	channels map[tl.Int256]*Peer
+}
+
+// Listen announces on the local UDP address addr and returns a Transport serving it.
+func Listen(addr string, key ed25519.PrivateKey) (*Transport, error) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransport(conn, key), nil
+}
+
+// NewTransport returns a Transport that reads and writes datagrams over conn.
+// The transport takes ownership of conn and closes it on Close.
+func NewTransport(conn net.PacketConn, key ed25519.PrivateKey) *Transport {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Transport{
+		key:        key,
+		id:         KeyID(key.Public().(ed25519.PublicKey)),
+		conn:       conn,
+		reinitDate: uint32(time.Now().Unix()),
+		ctx:        ctx,
+		cancel:     cancel,
+		addrList:   AddressList{Addrs: []Address{}},
+		peers:      map[tl.Int256]*Peer{},
+		channels:   map[tl.Int256]*Peer{},
+	}
+	go t.reader()
+	return t
+}
+
+// PublicKey returns a public key of the transport's identity.
+func (t *Transport) PublicKey() ed25519.PublicKey {
+	return t.key.Public().(ed25519.PublicKey)
+}
+
+// ID returns an ADNL address of the transport.
+func (t *Transport) ID() tl.Int256 {
+	return t.id
+}
+
+// LocalAddr returns the local network address.
+func (t *Transport) LocalAddr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+// Sign signs data with the transport's identity key.
+func (t *Transport) Sign(data []byte) []byte {
+	return ed25519.Sign(t.key, data)
+}
+
+// SetQueryHandler sets a handler for incoming queries.
+// Queries received without a handler are dropped.
+func (t *Transport) SetQueryHandler(handler QueryHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = handler
+}
+
+// SetAddressList sets a list of public addresses of the transport.
+// The list is sent to peers, so they know where to reach us.
+func (t *Transport) SetAddressList(list AddressList) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.addrList = list
+}
+
+// AddressList returns a list of public addresses of the transport.
+func (t *Transport) AddressList() AddressList {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.addrList
+}
+
+// Peer returns a peer with the given identity.
+// If addr is not nil, it replaces the address that is used to reach the peer.
+func (t *Transport) Peer(key ed25519.PublicKey, addr net.Addr) (*Peer, error) {
+	id := KeyID(key)
+	t.mu.Lock()
+	peer, ok := t.peers[id]
+	if !ok {
+		_, channelKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.mu.Unlock()
+			return nil, err
+		}
+		peer = &Peer{
+			t:          t,
+			publicKey:  append(ed25519.PublicKey{}, key...),
+			id:         id,
+			channelKey: channelKey,
+			queries:    map[tl.Int256]chan []byte{},
+			parts:      map[tl.Int256]*partialMessage{},
+		}
+		t.peers[id] = peer
+	}
+	t.mu.Unlock()
+	if addr != nil {
+		peer.setAddr(addr)
+	}
+	return peer, nil
+}
+
+// Close closes the underlying connection and fails all pending queries.
+func (t *Transport) Close() error {
+	t.cancel()
+	return t.conn.Close()
+}
+
+func (t *Transport) queryHandler() QueryHandler {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.handler
+}
+
+func (t *Transport) registerChannel(peer *Peer, inID tl.Int256, oldInID *tl.Int256) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if oldInID != nil {
+		delete(t.channels, *oldInID)
+	}
+	t.channels[inID] = peer
+}
+
+func (t *Transport) forgetChannel(inID tl.Int256) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.channels, inID)
+}
+
+func (t *Transport) write(datagram []byte, addr net.Addr) error {
+	if addr == nil {
+		return fmt.Errorf("peer address is unknown")
+	}
+	if t.ctx.Err() != nil {
+		return ErrClosed
+	}
+	_, err := t.conn.WriteTo(datagram, addr)
+	return err
+}
+
+func (t *Transport) reader() {
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			if t.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Debug("adnl: read failed", "err", err)
+			continue
+		}
+		datagram := append([]byte{}, buf[:n]...)
+		if err := t.handleDatagram(datagram, addr); err != nil {
+			slog.Debug("adnl: failed to process datagram", "remote", addr.String(), "err", err)
+		}
+	}
+}
+
+func (t *Transport) handleDatagram(data []byte, addr net.Addr) error {
+	if len(data) < 64 {
+		return fmt.Errorf("too short datagram")
+	}
+	var dst tl.Int256
+	copy(dst[:], data[:32])
+	if dst != t.id {
+		t.mu.Lock()
+		peer, ok := t.channels[dst]
+		t.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("unknown destination %x", dst)
+		}
+		return peer.handleChannelDatagram(dst, data[32:], addr)
+	}
+	// a packet encrypted with our identity key
+	if len(data) < 96 {
+		return fmt.Errorf("too short datagram")
+	}
+	shared, err := SharedKey(t.key, ed25519.PublicKey(data[32:64]))
+	if err != nil {
+		return err
+	}
+	plain, err := decrypt(shared, data[64:])
+	if err != nil {
+		return err
+	}
+	var pkt packetContents
+	if err := pkt.unmarshal(plain); err != nil {
+		return err
+	}
+	var key ed25519.PublicKey
+	switch {
+	case pkt.Flags&flagFrom != 0:
+		var ok bool
+		key, ok = pkt.From.Ed25519()
+		if !ok {
+			return fmt.Errorf("unsupported sender key %v", pkt.From.SumType)
+		}
+	case pkt.Flags&flagFromShort != 0:
+		t.mu.Lock()
+		peer, ok := t.peers[pkt.FromShort]
+		t.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("unknown sender %x", pkt.FromShort)
+		}
+		key = peer.publicKey
+	default:
+		return fmt.Errorf("packet without sender")
+	}
+	if err := pkt.verify(key); err != nil {
+		return err
+	}
+	peer, err := t.Peer(key, addr)
+	if err != nil {
+		return err
+	}
+	peer.handlePacket(pkt, false)
+	return nil
+}
+
+type channel struct {
+	peerKey tl.Int256
+	inID    tl.Int256
+	outID   tl.Int256
+	encKey  []byte
+	decKey  []byte
+	// ready is set once the peer proves it knows the channel,
+	// after that outgoing packets are sent over the channel.
+	ready bool
+}
+
+type partialMessage struct {
+	data     []byte
+	received map[uint32]struct{}
+	size     int
+}
+
+// Peer is a remote ADNL node.
+type Peer struct {
+	t          *Transport
+	publicKey  ed25519.PublicKey
+	id         tl.Int256
+	channelKey ed25519.PrivateKey
+
+	// mu protects all fields below.
+	mu             sync.Mutex
+	addr           net.Addr
+	seqno          uint64
+	confirmSeqno   uint64
+	peerReinitDate uint32
+	channel        *channel
+	queries        map[tl.Int256]chan []byte
+	parts          map[tl.Int256]*partialMessage
+}
+
+// PublicKey returns a public key of the peer's identity.
+func (p *Peer) PublicKey() ed25519.PublicKey {
+	return p.publicKey
+}
+
+// ID returns an ADNL address of the peer.
+func (p *Peer) ID() tl.Int256 {
+	return p.id
+}
+
+// RemoteAddr returns an address the peer is reachable at.
+func (p *Peer) RemoteAddr() net.Addr {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.addr
+}
+
+func (p *Peer) setAddr(addr net.Addr) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.addr = addr
+}
+
+// Query sends adnl.message.query to the peer and waits for an answer.
+// ADNL doesn't retransmit lost packets, so ctx should have a deadline.
+func (p *Peer) Query(ctx context.Context, query []byte) ([]byte, error) {
+	var msg Message
+	msg.SumType = "MessageQuery"
+	msg.MessageQuery.Query = query
+	if _, err := rand.Read(msg.MessageQuery.QueryId[:]); err != nil {
+		return nil, err
+	}
+	id := msg.MessageQuery.QueryId
+	ch := make(chan []byte, 1)
+	p.mu.Lock()
+	p.queries[id] = ch
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.queries, id)
+		p.mu.Unlock()
+	}()
+	if err := p.send(msg); err != nil {
+		return nil, err
+	}
+	select {
+	case answer := <-ch:
+		return answer, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.t.ctx.Done():
+		return nil, ErrClosed
+	}
+}
+
+// SendCustom sends adnl.message.custom to the peer.
+func (p *Peer) SendCustom(data []byte) error {
+	var msg Message
+	msg.SumType = "MessageCustom"
+	msg.MessageCustom.Data = data
+	return p.send(msg)
+}
+
+func (p *Peer) send(msg Message) error {
+	msgs, err := splitMessage(msg)
+	if err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		if err := p.sendPacket(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitMessage(msg Message) ([]Message, error) {
+	b, err := tl.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) <= maxMessageSize {
+		return []Message{msg}, nil
+	}
+	hash := sha256.Sum256(b)
+	var parts []Message
+	for offset := 0; offset < len(b); offset += maxMessageSize {
+		var part Message
+		part.SumType = "MessagePart"
+		part.MessagePart.Hash = hash
+		part.MessagePart.TotalSize = uint32(len(b))
+		part.MessagePart.Offset = uint32(offset)
+		part.MessagePart.Data = b[offset:min(offset+maxMessageSize, len(b))]
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+func randomBytes() []byte {
+	b := make([]byte, 15)
+	rand.Read(b)
+	return b
+}
+
+func (p *Peer) sendPacket(msg Message) error {
+	addrList := p.t.AddressList()
+
+	p.mu.Lock()
+	p.seqno++
+	pkt := packetContents{
+		Rand1:        randomBytes(),
+		Flags:        flagSeqno | flagConfirmSeqno,
+		Seqno:        p.seqno,
+		ConfirmSeqno: p.confirmSeqno,
+		Rand2:        randomBytes(),
+	}
+	addr := p.addr
+	ch := p.channel
+	if ch != nil && ch.ready {
+		outID, encKey := ch.outID, ch.encKey
+		p.mu.Unlock()
+		pkt.Flags |= flagMessage
+		pkt.Message = msg
+		b, err := pkt.marshal()
+		if err != nil {
+			return err
+		}
+		enc, err := encrypt(encKey, b)
+		if err != nil {
+			return err
+		}
+		return p.t.write(append(outID[:], enc...), addr)
+	}
+	// until the channel is ready, every packet carries a channel-related message
+	// and is signed and encrypted with identity keys.
+	var service Message
+	channelPub := p.channelKey.Public().(ed25519.PublicKey)
+	if ch == nil {
+		service.SumType = "MessageCreateChannel"
+		copy(service.MessageCreateChannel.Key[:], channelPub)
+		service.MessageCreateChannel.Date = uint32(time.Now().Unix())
+	} else {
+		service.SumType = "MessageConfirmChannel"
+		copy(service.MessageConfirmChannel.Key[:], channelPub)
+		service.MessageConfirmChannel.PeerKey = ch.peerKey
+		service.MessageConfirmChannel.Date = uint32(time.Now().Unix())
+	}
+	pkt.Flags |= flagMessages | flagReinitDate
+	pkt.Messages = []Message{service, msg}
+	pkt.ReinitDate = p.t.reinitDate
+	pkt.DstReinitDate = p.peerReinitDate
+	p.mu.Unlock()
+
+	if len(addrList.Addrs) > 0 {
+		pkt.Flags |= flagAddress
+		pkt.Address = addrList
+	}
+	if err := pkt.sign(p.t.key); err != nil {
+		return err
+	}
+	b, err := pkt.marshal()
+	if err != nil {
+		return err
+	}
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	shared, err := SharedKey(private, p.publicKey)
+	if err != nil {
+		return err
+	}
+	enc, err := encrypt(shared, b)
+	if err != nil {
+		return err
+	}
+	datagram := make([]byte, 0, 64+len(enc))
+	datagram = append(datagram, p.id[:]...)
+	datagram = append(datagram, public...)
+	datagram = append(datagram, enc...)
+	return p.t.write(datagram, addr)
+}
+
+func (p *Peer) handleChannelDatagram(inID tl.Int256, data []byte, addr net.Addr) error {
+	p.mu.Lock()
+	ch := p.channel
+	p.mu.Unlock()
+	if ch == nil || ch.inID != inID {
+		return fmt.Errorf("unknown channel")
+	}
+	plain, err := decrypt(ch.decKey, data)
+	if err != nil {
+		return err
+	}
+	var pkt packetContents
+	if err := pkt.unmarshal(plain); err != nil {
+		return err
+	}
+	p.setAddr(addr)
+	p.handlePacket(pkt, true)
+	return nil
+}
+
+func (p *Peer) handlePacket(pkt packetContents, viaChannel bool) {
+	p.mu.Lock()
+	if pkt.Flags&flagReinitDate != 0 && pkt.ReinitDate > p.peerReinitDate {
+		if p.peerReinitDate != 0 && p.channel != nil {
+			// the peer has restarted and forgotten the channel and seqno.
+			p.t.forgetChannel(p.channel.inID)
+			p.channel = nil
+			p.confirmSeqno = 0
+		}
+		p.peerReinitDate = pkt.ReinitDate
+	}
+	if pkt.Flags&flagSeqno != 0 && pkt.Seqno > p.confirmSeqno {
+		p.confirmSeqno = pkt.Seqno
+	}
+	if viaChannel && p.channel != nil {
+		p.channel.ready = true
+	}
+	p.mu.Unlock()
+	for _, msg := range pkt.messages() {
+		if err := p.handleMessage(msg); err != nil {
+			slog.Debug("adnl: failed to process message", "type", msg.SumType, "err", err)
+		}
+	}
+}
+
+func (p *Peer) handleMessage(msg Message) error {
+	switch msg.SumType {
+	case "MessageCreateChannel":
+		if _, err := p.setupChannel(msg.MessageCreateChannel.Key); err != nil {
+			return err
+		}
+		// confirm the channel right away, the initiator can't use it before.
+		var nop Message
+		nop.SumType = "MessageNop"
+		return p.send(nop)
+	case "MessageConfirmChannel":
+		channelPub := p.channelKey.Public().(ed25519.PublicKey)
+		if !bytes.Equal(msg.MessageConfirmChannel.PeerKey[:], channelPub) {
+			return fmt.Errorf("channel confirmation for unknown key")
+		}
+		ch, err := p.setupChannel(msg.MessageConfirmChannel.Key)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		ch.ready = true
+		p.mu.Unlock()
+	case "MessageQuery":
+		go p.handleQuery(msg.MessageQuery.QueryId, msg.MessageQuery.Query)
+	case "MessageAnswer":
+		p.mu.Lock()
+		ch, ok := p.queries[msg.MessageAnswer.QueryId]
+		p.mu.Unlock()
+		if ok {
+			select {
+			case ch <- msg.MessageAnswer.Answer:
+			default:
+			}
+		}
+	case "MessagePart":
+		full, err := p.assemble(msg)
+		if err != nil || full == nil {
+			return err
+		}
+		return p.handleMessage(*full)
+	}
+	return nil
+}
+
+func (p *Peer) setupChannel(peerKey tl.Int256) (*channel, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.channel != nil && p.channel.peerKey == peerKey {
+		return p.channel, nil
+	}
+	secret, err := SharedKey(p.channelKey, ed25519.PublicKey(peerKey[:]))
+	if err != nil {
+		return nil, err
+	}
+	reversed := make([]byte, len(secret))
+	for i := range secret {
+		reversed[i] = secret[len(secret)-1-i]
+	}
+	// both sides derive the same pair of keys,
+	// the node with the lower ID decrypts with the secret itself.
+	dec, enc := secret, reversed
+	switch bytes.Compare(p.t.id[:], p.id[:]) {
+	case 0:
+		enc = secret
+	case 1:
+		dec, enc = reversed, secret
+	}
+	ch := &channel{peerKey: peerKey, encKey: enc, decKey: dec}
+	for _, k := range []struct {
+		key []byte
+		id  *tl.Int256
+	}{{dec, &ch.inID}, {enc, &ch.outID}} {
+		var pub PublicKey
+		pub.SumType = "PubAes"
+		copy(pub.PubAes.Key[:], k.key)
+		if *k.id, err = pub.ID(); err != nil {
+			return nil, err
+		}
+	}
+	var oldInID *tl.Int256
+	if p.channel != nil {
+		oldInID = &p.channel.inID
+	}
+	p.channel = ch
+	p.t.registerChannel(p, ch.inID, oldInID)
+	return ch, nil
+}
+
+func (p *Peer) assemble(msg Message) (*Message, error) {
+	part := msg.MessagePart
+	size := int(part.TotalSize)
+	if size > maxPartialMessageSize || int(part.Offset)+len(part.Data) > size {
+		return nil, fmt.Errorf("invalid message part")
+	}
+	p.mu.Lock()
+	partial, ok := p.parts[part.Hash]
+	if !ok {
+		if len(p.parts) >= maxPartialMessages {
+			p.parts = map[tl.Int256]*partialMessage{}
+		}
+		partial = &partialMessage{data: make([]byte, size), received: map[uint32]struct{}{}}
+		p.parts[part.Hash] = partial
+	}
+	if len(partial.data) != size {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("message part size mismatch")
+	}
+	if _, ok := partial.received[part.Offset]; !ok {
+		partial.received[part.Offset] = struct{}{}
+		partial.size += copy(partial.data[part.Offset:], part.Data)
+	}
+	if partial.size < size {
+		p.mu.Unlock()
+		return nil, nil
+	}
+	delete(p.parts, part.Hash)
+	p.mu.Unlock()
+
+	if sha256.Sum256(partial.data) != part.Hash {
+		return nil, fmt.Errorf("invalid message hash")
+	}
+	var full Message
+	if err := tl.Unmarshal(bytes.NewReader(partial.data), &full); err != nil {
+		return nil, err
+	}
+	if full.SumType == "MessagePart" {
+		return nil, fmt.Errorf("nested message part")
+	}
+	return &full, nil
+}
+
+func (p *Peer) handleQuery(queryID tl.Int256, query []byte) {
+	handler := p.t.queryHandler()
+	if handler == nil {
+		return
+	}
+	answer, err := handler(p.t.ctx, p, query)
+	if err != nil {
+		slog.Debug("adnl: query handler failed", "peer", fmt.Sprintf("%x", p.id), "err", err)
+		return
+	}
+	var msg Message
+	msg.SumType = "MessageAnswer"
+	msg.MessageAnswer.QueryId = queryID
+	msg.MessageAnswer.Answer = answer
+	if err := p.send(msg); err != nil {
+		slog.Debug("adnl: failed to send answer", "peer", fmt.Sprintf("%x", p.id), "err", err)
+	}
+}
@@ -0,0 +1,96 @@
+package adnl
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestTransport(t *testing.T) *Transport {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	transport, err := Listen("127.0.0.1:0", key)
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	return transport
+}
+
+func TestTransport_Query(t *testing.T) {
+	server := newTestTransport(t)
+	client := newTestTransport(t)
+	server.SetQueryHandler(func(ctx context.Context, peer *Peer, query []byte) ([]byte, error) {
+		if peer.ID() != client.ID() {
+			return nil, errors.New("unexpected peer")
+		}
+		return append([]byte("echo:"), query...), nil
+	})
+
+	peer, err := client.Peer(server.PublicKey(), server.LocalAddr())
+	if err != nil {
+		t.Fatalf("Peer() failed: %v", err)
+	}
+	tests := []struct {
+		name  string
+		query []byte
+	}{
+		{name: "first query establishes a channel", query: []byte("hello")},
+		{name: "query over channel", query: []byte("world")},
+		{name: "query split into parts", query: bytes.Repeat([]byte{0xab}, 5000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			answer, err := peer.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Query() failed: %v", err)
+			}
+			if want := append([]byte("echo:"), tt.query...); !bytes.Equal(answer, want) {
+				t.Fatalf("want %d bytes answer, got: %d", len(want), len(answer))
+			}
+		})
+	}
+	peer.mu.Lock()
+	ready := peer.channel != nil && peer.channel.ready
+	peer.mu.Unlock()
+	if !ready {
+		t.Fatalf("channel must be established")
+	}
+}
+
+func TestTransport_Close(t *testing.T) {
+	server := newTestTransport(t)
+	client := newTestTransport(t)
+	server.SetQueryHandler(func(ctx context.Context, peer *Peer, query []byte) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	peer, err := client.Peer(server.PublicKey(), server.LocalAddr())
+	if err != nil {
+		t.Fatalf("Peer() failed: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := peer.Query(context.Background(), []byte("ping"))
+		errCh <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	client.Close()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("want ErrClosed, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Query() didn't return after Close()")
+	}
+}
@@ -63,35 +63,9 @@ func parseBocHeader(boc []byte) (*bocHeader, error) {
 	var prefix = boc[0:4]
 	boc = boc[4:]
 
-	var (
-		hasIdx       bool
-		hashCrc32    bool
-		hasCacheBits bool
-		flags        int
-		sizeBytes    int
-	)
-
-	if bytes.Equal(prefix, reachBocMagicPrefix) {
-		var flagsByte = boc[0]
-		hasIdx = (flagsByte & 128) > 0
-		hashCrc32 = (flagsByte & 64) > 0
-		hasCacheBits = (flagsByte & 32) > 0
-		flags = int((flagsByte&16)*2 + (flagsByte & 8))
-		sizeBytes = int(flagsByte % 8)
-	} else if bytes.Equal(prefix, leanBocMagicPrefix) {
-		hasIdx = true
-		hashCrc32 = false
-		hasCacheBits = false
-		flags = 0
-		sizeBytes = int(boc[0])
-	} else if bytes.Equal(prefix, leanBocMagicPrefixCRC) {
-		hasIdx = true
-		hashCrc32 = true
-		hasCacheBits = false
-		flags = 0
-		sizeBytes = int(boc[0])
-	} else {
-		return nil, errors.New("unknown magic prefix")
+	hasIdx, hashCrc32, hasCacheBits, flags, sizeBytes, err := parseBocFlags(prefix, boc[0])
+	if err != nil {
+		return nil, err
 	}
 
 	boc = boc[1:]
@@ -175,6 +149,28 @@ func parseBocHeader(boc []byte) (*bocHeader, error) {
 	}, nil
 }
 
+// parseBocFlags parses a flags byte that follows the given magic prefix of a boc.
+func parseBocFlags(prefix []byte, flagsByte byte) (hasIdx, hasCrc32, hasCacheBits bool, flags, sizeBytes int, err error) {
+	switch {
+	case bytes.Equal(prefix, reachBocMagicPrefix):
+		hasIdx = (flagsByte & 128) > 0
+		hasCrc32 = (flagsByte & 64) > 0
+		hasCacheBits = (flagsByte & 32) > 0
+		flags = int((flagsByte&16)*2 + (flagsByte & 8))
+		sizeBytes = int(flagsByte % 8)
+	case bytes.Equal(prefix, leanBocMagicPrefix):
+		hasIdx = true
+		sizeBytes = int(flagsByte)
+	case bytes.Equal(prefix, leanBocMagicPrefixCRC):
+		hasIdx = true
+		hasCrc32 = true
+		sizeBytes = int(flagsByte)
+	default:
+		err = errors.New("unknown magic prefix")
+	}
+	return
+}
+
 func deserializeCellData(cellData []byte, referenceIndexSize int) (*Cell, []int, []byte, error) {
 	if len(cellData) < 2 {
 		return nil, nil, nil, errors.New("not enough bytes to encode cell descriptors")
@@ -272,6 +268,37 @@ func SerializeBoc(cell *Cell, idx bool, hasCrc32 bool, cacheBits bool, flags uin
 	return bag.serializeBoc([]*Cell{cell}, idx, hasCrc32, cacheBits, flags)
 }
 
+// SerializeMode configures SerializeBocWithMode.
+// Its values match modes of std_boc_serialize of the reference implementation,
+// so for the same cells and mode the result is identical to the node's output.
+type SerializeMode uint
+
+const (
+	// WithIndex adds an index with offsets of cells.
+	WithIndex SerializeMode = 1
+	// WithCRC32C appends a crc32c checksum.
+	WithCRC32C SerializeMode = 2
+	// WithTopHash is accepted for compatibility.
+	// The reference implementation never marks cells as roots when it serializes them,
+	// so hashes of root cells aren't stored and the result is the same as without this mode.
+	WithTopHash SerializeMode = 4
+	// WithIntHashes stores hashes and depths of cells with many descendants.
+	WithIntHashes SerializeMode = 8
+	// WithCacheBits marks cells referred to several times, it requires WithIndex.
+	WithCacheBits SerializeMode = 16
+	// WithDepthFirstOrder places cells in the order they are met walking the roots depth-first
+	// instead of the order used by the reference implementation.
+	// The order depends only on the roots, so it is easy to reproduce with other tools.
+	// It isn't a mode of the reference implementation.
+	WithDepthFirstOrder SerializeMode = 256
+)
+
+// SerializeBocWithMode serializes the given root cells into one bag of cells.
+func SerializeBocWithMode(roots []*Cell, mode SerializeMode) ([]byte, error) {
+	bag := newBagOfCells()
+	return bag.serialize(roots, mode, 0)
+}
+
 // bagOfCells serializes cells to a boc.
 //
 // the serialization algorithms is a golang version of
@@ -286,7 +313,21 @@ func newBagOfCells() *bagOfCells {
 	}
 }
 
-// serializeBoc converts the given list of root cells to a byte representation.
+func (boc *bagOfCells) serializeBoc(rootCells []*Cell, idx bool, hasCrc32 bool, cacheBits bool, flags uint) ([]byte, error) {
+	var mode SerializeMode
+	if idx {
+		mode |= WithIndex
+	}
+	if hasCrc32 {
+		mode |= WithCRC32C
+	}
+	if cacheBits {
+		mode |= WithCacheBits
+	}
+	return boc.serialize(rootCells, mode, flags)
+}
+
+// serialize converts the given list of root cells to a byte representation.
 //
 //	serialized_boc#672fb0ac has_idx:(## 1) has_crc32c:(## 1)
 //	has_cache_bits:(## 1) flags:(## 2) { flags = 0 }
@@ -299,8 +340,14 @@ func newBagOfCells() *bagOfCells {
 //	index:(cells * ##(off_bytes * 8))
 //	cell_data:(tot_cells_size * [ uint8 ])
 //	= BagOfCells;
-func (boc *bagOfCells) serializeBoc(rootCells []*Cell, idx bool, hasCrc32 bool, cacheBits bool, flags uint) ([]byte, error) {
-	roots, cellInfos, err := boc.importRoots(rootCells)
+func (boc *bagOfCells) serialize(rootCells []*Cell, mode SerializeMode, flags uint) ([]byte, error) {
+	idx := mode&WithIndex != 0
+	hasCrc32 := mode&WithCRC32C != 0
+	cacheBits := mode&WithCacheBits != 0
+	if cacheBits && !idx {
+		return nil, errors.New("cache bits require an index")
+	}
+	roots, cellInfos, err := boc.importRoots(rootCells, mode&WithDepthFirstOrder != 0)
 	if err != nil {
 		return nil, err
 	}
@@ -312,7 +359,10 @@ func (boc *bagOfCells) serializeBoc(rootCells []*Cell, idx bool, hasCrc32 bool,
 	reps := make([][]byte, cellCount)
 	for i := cellCount - 1; i >= 0; i-- {
 		ci := cellInfos[i]
-		repr := ci.cell.bocReprWithoutRefs(ci.cell.mask)
+		repr, err := boc.cellRepr(ci.cell, mode&WithIntHashes != 0 && ci.isSpecial())
+		if err != nil {
+			return nil, err
+		}
 		for j := 0; j < ci.refsNumber; j++ {
 			var b [8]byte
 			k := cellCount - 1 - ci.refsIndex[j]
@@ -331,7 +381,11 @@ func (boc *bagOfCells) serializeBoc(rootCells []*Cell, idx bool, hasCrc32 bool,
 		offsets[i] = fixedOffset
 	}
 
-	offsetBitSize := bits.Len(offset)
+	maxOffset := offset
+	if cacheBits {
+		maxOffset = offset * 2
+	}
+	offsetBitSize := bits.Len(maxOffset)
 	offsetByteSize := int(math.Max(math.Ceil(float64(offsetBitSize)/8), 1))
 
 	bitString := NewBitString((CellBits + 32*4 + 32*3) * int(cellCount))
@@ -408,7 +462,34 @@ func (boc *bagOfCells) serializeBoc(rootCells []*Cell, idx bool, hasCrc32 bool,
 	return resBytes, nil
 }
 
-func (boc *bagOfCells) importRoots(rootCells []*Cell) ([]*rootInfo, []*cellInfo, error) {
+// cellRepr returns a serialized cell without refs.
+// If withHashes is set, hashes and depths of all the cell's levels go before the cell's data.
+func (boc *bagOfCells) cellRepr(cell *Cell, withHashes bool) ([]byte, error) {
+	repr := cell.bocReprWithoutRefs(cell.mask)
+	if !withHashes {
+		return repr, nil
+	}
+	imm, err := newImmutableCell(cell, boc.hasher.cache)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, 0, len(repr)+cell.mask.HashesCount()*(hashSize+depthSize))
+	res = append(res, repr[0]|16, repr[1])
+	level := cell.mask.Level()
+	for i := 0; i <= level; i++ {
+		if cell.mask.IsSignificant(uint32(i)) {
+			res = append(res, imm.Hash(i)...)
+		}
+	}
+	for i := 0; i <= level; i++ {
+		if cell.mask.IsSignificant(uint32(i)) {
+			res = binary.BigEndian.AppendUint16(res, uint16(imm.Depth(i)))
+		}
+	}
+	return append(res, repr[2:]...), nil
+}
+
+func (boc *bagOfCells) importRoots(rootCells []*Cell, depthFirstOrder bool) ([]*rootInfo, []*cellInfo, error) {
 	roots := make([]*rootInfo, 0, len(rootCells))
 	state := &orderState{
 		cells: map[string]int{},
@@ -424,8 +505,11 @@ func (boc *bagOfCells) importRoots(rootCells []*Cell) ([]*rootInfo, []*cellInfo,
 		}
 		roots = append(roots, &info)
 	}
-	cellInfos := boc.reorderCells(roots, state)
-	return roots, cellInfos, nil
+	boc.computeWeights(roots, state)
+	if depthFirstOrder {
+		return roots, boc.depthFirstOrder(roots, state), nil
+	}
+	return roots, boc.reorderCells(roots, state), nil
 }
 
 func (boc *bagOfCells) importCell(state *orderState, cell *Cell, depth int) (int, error) {
@@ -446,7 +530,11 @@ func (boc *bagOfCells) importCell(state *orderState, cell *Cell, depth int) (int
 	var refs [4]int
 	sumChildWt := 1
 	refsNumber := 0
-	for i, ref := range cell.refs {
+	cellRefs, err := cell.refList()
+	if err != nil {
+		return 0, err
+	}
+	for i, ref := range cellRefs {
 		if ref == nil {
 			break
 		}
@@ -560,7 +648,9 @@ func (boc *bagOfCells) revisit(state, newState *orderState, cellIndex int, force
 	return dci.newIndex
 
 }
-func (boc *bagOfCells) reorderCells(roots []*rootInfo, state *orderState) []*cellInfo {
+
+// computeWeights decides which cells are special, hashes of special cells can be stored in a boc.
+func (boc *bagOfCells) computeWeights(roots []*rootInfo, state *orderState) {
 	for i := len(state.cellList) - 1; i >= 0; i-- {
 		dci := state.cellList[i]
 		c := dci.refsNumber
@@ -579,8 +669,8 @@ func (boc *bagOfCells) reorderCells(roots []*rootInfo, state *orderState) []*cel
 			for j := 0; j < dci.refsNumber; j++ {
 				if mask&(1<<j) == 0 {
 					dcj := state.cellList[dci.refsIndex[j]]
-					sum += 1
 					limit := sum / c
+					sum += 1
 					if dcj.wt > limit {
 						dcj.wt = limit
 					}
@@ -613,7 +703,10 @@ func (boc *bagOfCells) reorderCells(roots []*rootInfo, state *orderState) []*cel
 			topHashes += ci.hashCount
 		}
 	}
+}
 
+// reorderCells orders cells the same way as the reference implementation does.
+func (boc *bagOfCells) reorderCells(roots []*rootInfo, state *orderState) []*cellInfo {
 	newState := &orderState{}
 	if len(state.cellList) > 0 {
 		for _, root := range roots {
@@ -629,3 +722,36 @@ func (boc *bagOfCells) reorderCells(roots []*rootInfo, state *orderState) []*cel
 	}
 	return newState.cellList
 }
+
+// depthFirstOrder orders cells so that a serialized boc contains them in the order of a depth-first walk:
+// the first root goes first, followed by its first child and so on.
+// A cell referred to several times goes after all cells referring to it.
+func (boc *bagOfCells) depthFirstOrder(roots []*rootInfo, state *orderState) []*cellInfo {
+	// the serializer writes cells in the reverse order, so cells are collected in post-order
+	// walking roots and refs backwards.
+	cellList := make([]*cellInfo, 0, len(state.cellList))
+	var walk func(index int)
+	walk = func(index int) {
+		dci := state.cellList[index]
+		if dci.newIndex >= 0 {
+			return
+		}
+		for j := dci.refsNumber - 1; j >= 0; j-- {
+			walk(dci.refsIndex[j])
+		}
+		dci.newIndex = len(cellList)
+		cellList = append(cellList, dci)
+	}
+	for i := len(roots) - 1; i >= 0; i-- {
+		walk(roots[i].index)
+	}
+	for _, dci := range cellList {
+		for j := 0; j < dci.refsNumber; j++ {
+			dci.refsIndex[j] = state.cellList[dci.refsIndex[j]].newIndex
+		}
+	}
+	for _, root := range roots {
+		root.index = state.cellList[root.index].newIndex
+	}
+	return cellList
+}
@@ -390,3 +390,115 @@ func Test_bagOfCells_serializeBoc(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeBocWithMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		mode     SerializeMode
+	}{
+		{
+			// blocks are serialized by a collator with mode 31.
+			name:     "masterchain block",
+			filename: "../tlb/testdata/block-1/block.bin",
+			mode:     WithIndex | WithCRC32C | WithTopHash | WithIntHashes | WithCacheBits,
+		},
+		{
+			name:     "small block",
+			filename: "../tlb/testdata/block-4/block.bin",
+			mode:     WithIndex | WithCRC32C | WithTopHash | WithIntHashes | WithCacheBits,
+		},
+		{
+			name:     "shard block",
+			filename: "../tlb/testdata/block-5/block.bin",
+			mode:     WithIndex | WithCRC32C | WithTopHash | WithIntHashes | WithCacheBits,
+		},
+		{
+			name:     "config proof",
+			filename: "../ton/testdata/config_proof_4324374.boc",
+			mode:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(tt.filename)
+			if err != nil {
+				t.Fatalf("ReadFile() failed: %v", err)
+			}
+			cells, err := DeserializeBoc(data)
+			if err != nil {
+				t.Fatalf("DeserializeBoc() failed: %v", err)
+			}
+			bs, err := SerializeBocWithMode(cells, tt.mode)
+			if err != nil {
+				t.Fatalf("SerializeBocWithMode() failed: %v", err)
+			}
+			if !reflect.DeepEqual(bs, data) {
+				t.Fatalf("serialized boc differs from the original one")
+			}
+		})
+	}
+}
+
+func TestSerializeBocWithMode_multipleRoots(t *testing.T) {
+	newCell := func(value uint64, refs ...*Cell) *Cell {
+		c := NewCell()
+		if err := c.WriteUint(value, 32); err != nil {
+			t.Fatalf("WriteUint() failed: %v", err)
+		}
+		for _, ref := range refs {
+			if err := c.AddRef(ref); err != nil {
+				t.Fatalf("AddRef() failed: %v", err)
+			}
+		}
+		return c
+	}
+	shared := newCell(3)
+	first := newCell(1, newCell(11, shared), newCell(12))
+	second := newCell(2, shared)
+
+	for _, mode := range []SerializeMode{0, WithIndex | WithCRC32C, WithIndex | WithCacheBits | WithIntHashes, WithDepthFirstOrder} {
+		bs, err := SerializeBocWithMode([]*Cell{first, second}, mode)
+		if err != nil {
+			t.Fatalf("SerializeBocWithMode() failed: %v", err)
+		}
+		roots, err := DeserializeBoc(bs)
+		if err != nil {
+			t.Fatalf("DeserializeBoc() failed: %v", err)
+		}
+		if len(roots) != 2 {
+			t.Fatalf("want 2 roots, got: %v", len(roots))
+		}
+		for i, root := range []*Cell{first, second} {
+			want, _ := root.HashString()
+			got, _ := roots[i].HashString()
+			if want != got {
+				t.Fatalf("mode %v: root %v has hash %v instead of %v", mode, i, got, want)
+			}
+		}
+	}
+
+	bs, err := SerializeBocWithMode([]*Cell{first, second}, WithDepthFirstOrder)
+	if err != nil {
+		t.Fatalf("SerializeBocWithMode() failed: %v", err)
+	}
+	// a shared cell goes after both roots referring to it.
+	var values []int
+	data := bs[4+1+1+3+1+2:]
+	for len(data) > 0 {
+		cell, _, residue, err := deserializeCellData(data, 1)
+		if err != nil {
+			t.Fatalf("deserializeCellData() failed: %v", err)
+		}
+		value, _ := cell.ReadUint(32)
+		values = append(values, int(value))
+		data = residue
+	}
+	if want := []int{1, 11, 12, 2, 3}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("want cells in order %v, got: %v", want, values)
+	}
+
+	if _, err := SerializeBocWithMode([]*Cell{first}, WithCacheBits); err == nil {
+		t.Fatalf("want error for cache bits without an index")
+	}
+}
@@ -0,0 +1,103 @@
+package boc
+
+import (
+	"math/big"
+)
+
+// Builder builds a new cell.
+// A cell returned by EndCell must not be modified,
+// so it can be shared and read by any number of slices without copying.
+type Builder struct {
+	cell *Cell
+}
+
+// NewBuilder returns a builder of a new ordinary cell.
+func NewBuilder() *Builder {
+	return &Builder{cell: NewCell()}
+}
+
+// WriteBuilder calls f with a builder appending to the cell.
+// It lets code written for the Cell API use code written for Builder.
+func (c *Cell) WriteBuilder(f func(b *Builder) error) error {
+	return f(&Builder{cell: c})
+}
+
+// WriteCell calls f with the cell being built.
+// It lets code written for the Cell API write to a builder.
+func (b *Builder) WriteCell(f func(c *Cell) error) error {
+	return f(b.cell)
+}
+
+// EndCell returns the built cell. The builder must not be used after that.
+func (b *Builder) EndCell() *Cell {
+	c := b.cell
+	b.cell = nil
+	c.ResetCounters()
+	return c
+}
+
+func (b *Builder) BitsAvailableForWrite() int {
+	return b.cell.BitsAvailableForWrite()
+}
+
+func (b *Builder) RefsSize() int {
+	return b.cell.RefsSize()
+}
+
+func (b *Builder) AddRef(c *Cell) error {
+	return b.cell.AddRef(c)
+}
+
+// WriteSlice appends the remaining bits and refs of the slice without copying the refs.
+func (b *Builder) WriteSlice(s *Slice) error {
+	rest := *s
+	if err := b.cell.WriteBitString(rest.ReadRemainingBits()); err != nil {
+		return err
+	}
+	refs, err := s.cell.refList()
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs[s.refPos:s.cell.RefsSize()] {
+		if err := b.cell.AddRef(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Builder) WriteBit(val bool) error {
+	return b.cell.WriteBit(val)
+}
+
+func (b *Builder) WriteUint(val uint64, bitLen int) error {
+	return b.cell.WriteUint(val, bitLen)
+}
+
+func (b *Builder) WriteInt(val int64, bitLen int) error {
+	return b.cell.WriteInt(val, bitLen)
+}
+
+func (b *Builder) WriteBigUint(val *big.Int, bitLen int) error {
+	return b.cell.WriteBigUint(val, bitLen)
+}
+
+func (b *Builder) WriteBigInt(val *big.Int, bitLen int) error {
+	return b.cell.WriteBigInt(val, bitLen)
+}
+
+func (b *Builder) WriteBytes(data []byte) error {
+	return b.cell.WriteBytes(data)
+}
+
+func (b *Builder) WriteBitString(s BitString) error {
+	return b.cell.WriteBitString(s)
+}
+
+func (b *Builder) WriteUnary(n uint) error {
+	return b.cell.WriteUnary(n)
+}
+
+func (b *Builder) WriteLimUint(val, n int) error {
+	return b.cell.WriteLimUint(val, n)
+}
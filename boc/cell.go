@@ -35,6 +35,17 @@ type Cell struct {
 	refCursor int
 	cellType  CellType
 	mask      levelMask
+	// lazy is set for a cell read by LazyBoc, its refs are loaded from the bag of cells on demand.
+	lazy *lazyRefs
+	// virtual is set for cells of a tree returned by VerifyMerkleProof,
+	// NextRef of such a cell returns ErrPrunedBranchAccess instead of a pruned branch cell.
+	virtual bool
+	// view is set for cells passed by Slice.ReadCell,
+	// NextRef of such a cell returns a new view of the ref instead of resetting counters of the ref.
+	view bool
+	// origin is the cell a view shares bits and refs with,
+	// a view has the same hash as its origin, so hashes are cached by the origin.
+	origin *Cell
 	// TODO: add capacity checking
 }
 
@@ -55,6 +66,9 @@ func NewCellExotic(cellType CellType) *Cell {
 }
 
 func (c *Cell) RefsSize() int {
+	if c.lazy != nil {
+		return len(c.lazy.indexes)
+	}
 	var count int
 	for i := range c.refs {
 		if c.refs[i] != nil {
@@ -64,14 +78,53 @@ func (c *Cell) RefsSize() int {
 	return count
 }
 
+// Refs returns the refs of the cell.
+// It returns nil if the refs of a cell read by LazyBoc can't be loaded, use LoadRefs to get the error.
 func (c *Cell) Refs() []*Cell {
+	refs, _ := c.LoadRefs()
+	return refs
+}
+
+// LoadRefs returns the refs of the cell
+// or an error if they can't be loaded from the bag of cells the cell was read from by LazyBoc.
+func (c *Cell) LoadRefs() ([]*Cell, error) {
+	refs, err := c.refList()
+	if err != nil {
+		return nil, err
+	}
 	res := make([]*Cell, 0, 4)
-	for _, ref := range c.refs {
+	for _, ref := range refs {
 		if ref != nil {
 			res = append(res, ref)
 		}
 	}
-	return res
+	return res, nil
+}
+
+// LazyErr returns the first error that happened while reading the bag of cells
+// the cell was read from by LazyBoc, see LazyBoc.Err.
+// It returns nil for other cells.
+func (c *Cell) LazyErr() error {
+	if c.lazy == nil {
+		return nil
+	}
+	return c.lazy.bag.Err()
+}
+
+// refList returns the refs of the cell loading them from a lazy bag of cells if needed.
+func (c *Cell) refList() ([4]*Cell, error) {
+	if c.lazy == nil {
+		return c.refs, nil
+	}
+	return c.lazy.load()
+}
+
+// hashKey returns the cell whose hash is cached for this cell.
+func (c *Cell) hashKey() *Cell {
+	if c.origin != nil {
+		return c.origin
+	}
+	return c
 }
 
 func (c *Cell) IsExotic() bool {
@@ -117,6 +170,17 @@ func (c *Cell) Hash256() ([32]byte, error) {
 	return h, err
 }
 
+// HashAtLevel returns a hash of this cell at the given level.
+// A hash at level 0 is a hash of the original cell tree,
+// so for a cell of a merkle proof it doesn't depend on which branches of the tree are pruned.
+func (c *Cell) HashAtLevel(level int) ([]byte, error) {
+	imc, err := newImmutableCell(c, map[*Cell]*immutableCell{})
+	if err != nil {
+		return nil, err
+	}
+	return imc.Hash(level), nil
+}
+
 func (c *Cell) HashString() (string, error) {
 	h, err := c.hash(map[*Cell]*immutableCell{})
 	if err != nil {
@@ -180,6 +244,13 @@ func (c *Cell) NewRef() (*Cell, error) {
 }
 
 func (c *Cell) AddRef(c2 *Cell) error {
+	if c.lazy != nil {
+		refs, err := c.refList()
+		if err != nil {
+			return err
+		}
+		c.refs, c.lazy = refs, nil
+	}
 	for i := range c.refs {
 		if c.refs[i] == nil {
 			c.refs[i] = c2
@@ -193,15 +264,37 @@ func (c *Cell) NextRef() (*Cell, error) {
 	if c.refCursor > 3 {
 		return nil, ErrNotEnoughRefs
 	}
-	ref := c.refs[c.refCursor]
+	refs, err := c.refList()
+	if err != nil {
+		return nil, err
+	}
+	ref := refs[c.refCursor]
 	if ref != nil {
+		if c.virtual && ref.cellType == PrunedBranchCell {
+			return nil, ErrPrunedBranchAccess
+		}
 		c.refCursor++
+		if c.view {
+			bits := ref.bits
+			bits.rCursor = 0
+			return newCellView(ref, bits, 0), nil
+		}
 		ref.ResetCounters()
 		return ref, nil
 	}
 	return nil, ErrNotEnoughRefs
 }
 
+// SkipRef moves the refs read cursor past the next ref without reading it.
+// Unlike NextRef, it accepts a pruned branch of a tree returned by VerifyMerkleProof.
+func (c *Cell) SkipRef() error {
+	if c.refCursor >= c.RefsSize() {
+		return ErrNotEnoughRefs
+	}
+	c.refCursor++
+	return nil
+}
+
 func (c *Cell) toStringImpl(ident string, iterationsLimit *int) string {
 	var s string
 	if c.IsExotic() {
@@ -324,20 +417,19 @@ func (c *Cell) CopyRemaining() *Cell {
 	rCursor := c.bits.rCursor
 	c2 := NewCellWithBits(c.bits.ReadRemainingBits())
 	c.bits.rCursor = rCursor
-	refCursor := c.refCursor
-	refsNums := c.RefsAvailableForRead()
-	for i := 0; i < refsNums; i++ {
-		ref, err := c.NextRef()
-		if err != nil {
-			// this should never happen but anyway
-			panic(err)
-		}
+	c2.virtual = c.virtual
+	if c.lazy != nil {
+		// refs of a lazy cell stay lazy, an error reading them is reported by the copy's LazyErr.
+		c2.lazy = &lazyRefs{bag: c.lazy.bag, indexes: c.lazy.indexes[c.refCursor:]}
+		return c2
+	}
+	for _, ref := range c.refs[c.refCursor:c.RefsSize()] {
+		ref.ResetCounters()
 		if err := c2.AddRef(ref); err != nil {
 			// this should never happen but anyway
 			panic(err)
 		}
 	}
-	c.refCursor = refCursor
 	return c2
 }
 
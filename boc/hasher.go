@@ -25,7 +25,7 @@ func (h *Hasher) Hash(c *Cell) ([]byte, error) {
 }
 
 func (h *Hasher) HashString(c *Cell) (string, error) {
-	if s, ok := h.cacheHex[c]; ok {
+	if s, ok := h.cacheHex[c.hashKey()]; ok {
 		return s, nil
 	}
 	hash, err := h.Hash(c)
@@ -33,6 +33,6 @@ func (h *Hasher) HashString(c *Cell) (string, error) {
 		return "", err
 	}
 	s := hex.EncodeToString(hash)
-	h.cacheHex[c] = s
+	h.cacheHex[c.hashKey()] = s
 	return s, nil
 }
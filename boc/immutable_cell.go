@@ -25,7 +25,7 @@ type immutableCell struct {
 // newImmutableCell returns a new instance of immutable cell.
 // cache can't be nil because it helps to avoid an endless loop in case of the given cell contains a fork bomb.
 func newImmutableCell(c *Cell, cache map[*Cell]*immutableCell) (*immutableCell, error) {
-	if imm, ok := cache[c]; ok {
+	if imm, ok := cache[c.hashKey()]; ok {
 		return imm, nil
 	}
 	imm := &immutableCell{
@@ -37,7 +37,11 @@ func newImmutableCell(c *Cell, cache map[*Cell]*immutableCell) (*immutableCell,
 		bitsBuf:  c.bits.buf,
 		bitsLen:  c.bits.len,
 	}
-	for _, ref := range c.refs {
+	refs, err := c.refList()
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
 		if ref == nil {
 			break
 		}
@@ -129,7 +133,7 @@ func newImmutableCell(c *Cell, cache map[*Cell]*immutableCell) (*immutableCell,
 
 		imm.hashes = append(imm.hashes, x.Sum(nil))
 	}
-	cache[c] = imm
+	cache[c.hashKey()] = imm
 	return imm, nil
 }
 
@@ -0,0 +1,380 @@
+package boc
+
+import (
+	"bufio"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// DefaultLazyBocCacheSize is a default number of cells kept in memory by LazyBoc.
+const DefaultLazyBocCacheSize = 8192
+
+// lazyBocCheckpointInterval is a number of cells between two remembered cell offsets
+// in a bag of cells without an index.
+const lazyBocCheckpointInterval = 64
+
+// maxCellReprSize is the largest size of a serialized cell:
+// two descriptor bytes, hashes and depths of all levels, data and four refs of 4 bytes each.
+const maxCellReprSize = 2 + (maxLevel+1)*(hashSize+depthSize) + 128 + 4*4
+
+// LazyBocOptions holds parameters to configure a LazyBoc.
+type LazyBocOptions struct {
+	// CacheSize is a maximum number of cells kept in memory besides root cells.
+	CacheSize int
+}
+
+type LazyBocOption func(o *LazyBocOptions)
+
+// WithCacheSize sets a maximum number of cells kept in memory by LazyBoc.
+func WithCacheSize(size int) LazyBocOption {
+	return func(o *LazyBocOptions) {
+		o.CacheSize = size
+	}
+}
+
+// LazyBoc is a bag of cells that reads cells from an underlying io.ReaderAt on demand.
+//
+// Only the header of the bag is read when LazyBoc is created.
+// A cell is read when a parent's ref pointing to it is accessed,
+// recently used cells are kept in an LRU cache and the others are read again when needed.
+// So the memory taken by LazyBoc is bounded by the cache size,
+// and a cell tree can be walked with tlb.Unmarshal or the Cell's methods as usual.
+// A memory-mapped file can be read with LazyBoc by wrapping it with bytes.NewReader.
+//
+// Cells are identified by pointers only while they are in the cache,
+// so hashing of a subtree with many shared cells is faster with a bigger cache.
+// A crc32c checksum of the bag isn't checked because it requires reading all the bag.
+type LazyBoc struct {
+	r           io.ReaderAt
+	closer      io.Closer
+	sizeBytes   int
+	offsetBytes int
+	cellCount   int
+	hasIdx      bool
+	cacheBits   bool
+	// indexOffset is a position of the index in the underlying reader.
+	indexOffset int64
+	// dataOffset and dataSize describe a position of cells' data in the underlying reader.
+	dataOffset int64
+	dataSize   int64
+	// checkpoints contains offsets of every lazyBocCheckpointInterval-th cell in a bag without an index.
+	checkpoints []int64
+	roots       []*Cell
+
+	mu        sync.Mutex
+	cacheSize int
+	cache     map[int]*list.Element
+	lru       *list.List
+	err       error
+}
+
+type lazyCacheEntry struct {
+	index int
+	cell  *Cell
+}
+
+// lazyRefs describes refs of a cell read by LazyBoc.
+type lazyRefs struct {
+	bag     *LazyBoc
+	indexes []int
+}
+
+func (l *lazyRefs) load() ([4]*Cell, error) {
+	var refs [4]*Cell
+	for i, index := range l.indexes {
+		cell, err := l.bag.cell(index)
+		if err != nil {
+			return [4]*Cell{}, err
+		}
+		refs[i] = cell
+	}
+	return refs, nil
+}
+
+// NewLazyBoc reads a header of a bag of cells of the given size from the reader.
+func NewLazyBoc(r io.ReaderAt, size int64, opts ...LazyBocOption) (*LazyBoc, error) {
+	options := LazyBocOptions{CacheSize: DefaultLazyBocCacheSize}
+	for _, o := range opts {
+		o(&options)
+	}
+	b := &LazyBoc{
+		r:         r,
+		cacheSize: max(options.CacheSize, 1),
+		cache:     map[int]*list.Element{},
+		lru:       list.New(),
+	}
+	if err := b.readHeader(size); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// OpenLazyBocFile opens a file with a bag of cells.
+// The file is kept open until Close is called.
+func OpenLazyBocFile(path string, opts ...LazyBocOption) (*LazyBoc, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	b, err := NewLazyBoc(f, stat.Size(), opts...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	b.closer = f
+	return b, nil
+}
+
+// Close closes the underlying file if the bag was opened with OpenLazyBocFile.
+func (b *LazyBoc) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// Roots returns root cells of the bag.
+func (b *LazyBoc) Roots() []*Cell {
+	return b.roots
+}
+
+// Err returns the first error that happened while reading cells.
+// Methods of Cell that can't return an error, like Refs(), return an empty result instead,
+// and the error can be checked here.
+func (b *LazyBoc) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+func (b *LazyBoc) readAt(offset int64, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	read, err := b.r.ReadAt(buf, offset)
+	if read == n {
+		return buf, nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
+
+func (b *LazyBoc) readHeader(size int64) error {
+	if size < 4+1 {
+		return errors.New("not enough bytes for magic prefix")
+	}
+	head, err := b.readAt(0, 4+1+1)
+	if err != nil {
+		return err
+	}
+	hasIdx, hasCrc32, hasCacheBits, _, sizeBytes, err := parseBocFlags(head[:4], head[4])
+	if err != nil {
+		return err
+	}
+	if sizeBytes < 1 || sizeBytes > 4 {
+		return fmt.Errorf("invalid size of cell references: %v", sizeBytes)
+	}
+	offsetBytes := int(head[5])
+	if offsetBytes < 1 || offsetBytes > 8 {
+		return fmt.Errorf("invalid size of offsets: %v", offsetBytes)
+	}
+	offset := int64(len(head))
+	counters, err := b.readAt(offset, 3*sizeBytes+offsetBytes)
+	if err != nil {
+		return fmt.Errorf("not enough bytes for encoding cells counters: %w", err)
+	}
+	offset += int64(len(counters))
+	cellCount := int(readNBytesUIntFromArray(sizeBytes, counters))
+	rootCount := int(readNBytesUIntFromArray(sizeBytes, counters[sizeBytes:]))
+	dataSize := int64(readNBytesUIntFromArray(offsetBytes, counters[3*sizeBytes:]))
+	if int64(cellCount)*2 > dataSize {
+		// every cell takes two bytes at least.
+		return fmt.Errorf("invalid number of cells: %v", cellCount)
+	}
+	if rootCount == 0 || rootCount > cellCount {
+		return fmt.Errorf("invalid number of roots: %v", rootCount)
+	}
+	rootList, err := b.readAt(offset, rootCount*sizeBytes)
+	if err != nil {
+		return fmt.Errorf("not enough bytes for encoding root cells: %w", err)
+	}
+	offset += int64(len(rootList))
+
+	b.sizeBytes = sizeBytes
+	b.offsetBytes = offsetBytes
+	b.cellCount = cellCount
+	b.hasIdx = hasIdx
+	b.cacheBits = hasCacheBits
+	b.indexOffset = offset
+	if hasIdx {
+		offset += int64(cellCount) * int64(offsetBytes)
+	}
+	b.dataOffset = offset
+	b.dataSize = dataSize
+	expectedSize := offset + dataSize
+	if hasCrc32 {
+		expectedSize += 4
+	}
+	if expectedSize != size {
+		return fmt.Errorf("boc size is %v bytes instead of %v", size, expectedSize)
+	}
+	if !hasIdx {
+		if err := b.readCheckpoints(); err != nil {
+			return err
+		}
+	}
+	b.roots = make([]*Cell, 0, rootCount)
+	for i := 0; i < rootCount; i++ {
+		index := int(readNBytesUIntFromArray(sizeBytes, rootList[i*sizeBytes:]))
+		if index >= cellCount {
+			return errors.New("index out of range for boc deserialization")
+		}
+		root, err := b.readCell(index)
+		if err != nil {
+			return err
+		}
+		b.roots = append(b.roots, root)
+	}
+	return nil
+}
+
+// cellReprSize returns a size of a serialized cell by its two descriptor bytes.
+func (b *LazyBoc) cellReprSize(d1, d2 byte) int64 {
+	size := 2 + int(d2>>1) + int(d2%2) + int(d1%8)*b.sizeBytes
+	if d1&0b10000 != 0 {
+		size += levelMask(d1>>5).HashesCount() * (hashSize + depthSize)
+	}
+	return int64(size)
+}
+
+// readCheckpoints scans cells' data once to remember offsets of cells in a bag without an index.
+func (b *LazyBoc) readCheckpoints() error {
+	b.checkpoints = make([]int64, 0, b.cellCount/lazyBocCheckpointInterval+1)
+	r := bufio.NewReaderSize(io.NewSectionReader(b.r, b.dataOffset, b.dataSize), 64*1024)
+	var offset int64
+	for i := 0; i < b.cellCount; i++ {
+		if i%lazyBocCheckpointInterval == 0 {
+			b.checkpoints = append(b.checkpoints, offset)
+		}
+		var descr [2]byte
+		if _, err := io.ReadFull(r, descr[:]); err != nil {
+			return errors.New("not enough bytes to encode cell descriptors")
+		}
+		size := b.cellReprSize(descr[0], descr[1])
+		if _, err := r.Discard(int(size - 2)); err != nil {
+			return errors.New("not enough bytes to encode cell data")
+		}
+		offset += size
+	}
+	return nil
+}
+
+// cellData returns the serialized cell with the given index.
+func (b *LazyBoc) cellData(index int) ([]byte, error) {
+	if b.hasIdx {
+		// the index contains an end offset of each cell.
+		first := max(index-1, 0)
+		entries, err := b.readAt(b.indexOffset+int64(first*b.offsetBytes), (index-first+1)*b.offsetBytes)
+		if err != nil {
+			return nil, err
+		}
+		var start int64
+		if index > 0 {
+			start = b.indexEntry(entries)
+			entries = entries[b.offsetBytes:]
+		}
+		end := b.indexEntry(entries)
+		if start >= end || end > b.dataSize {
+			return nil, fmt.Errorf("invalid index entry of cell %v", index)
+		}
+		return b.readAt(b.dataOffset+start, int(end-start))
+	}
+	// the cell is somewhere after the closest checkpoint,
+	// so all cells in between are read at once and skipped.
+	first := index / lazyBocCheckpointInterval * lazyBocCheckpointInterval
+	start := b.checkpoints[index/lazyBocCheckpointInterval]
+	data, err := b.readAt(b.dataOffset+start, int(min(int64(index-first+1)*maxCellReprSize, b.dataSize-start)))
+	if err != nil {
+		return nil, err
+	}
+	for i := first; i < index; i++ {
+		if len(data) < 2 {
+			return nil, errors.New("not enough bytes to encode cell descriptors")
+		}
+		size := b.cellReprSize(data[0], data[1])
+		if int64(len(data)) < size {
+			return nil, errors.New("not enough bytes to encode cell data")
+		}
+		data = data[size:]
+	}
+	return data, nil
+}
+
+func (b *LazyBoc) indexEntry(entry []byte) int64 {
+	value := int64(readNBytesUIntFromArray(b.offsetBytes, entry))
+	if b.cacheBits {
+		value /= 2
+	}
+	return value
+}
+
+// readCell reads the cell with the given index from the underlying reader.
+func (b *LazyBoc) readCell(index int) (*Cell, error) {
+	data, err := b.cellData(index)
+	if err != nil {
+		return nil, err
+	}
+	cell, refs, _, err := deserializeCellData(data, b.sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) > 4 {
+		return nil, fmt.Errorf("too long refs array")
+	}
+	for _, r := range refs {
+		if r <= index {
+			return nil, errors.New("topological order is broken")
+		}
+		if r >= b.cellCount {
+			return nil, errors.New("index out of range for boc deserialization")
+		}
+	}
+	if len(refs) > 0 {
+		cell.lazy = &lazyRefs{bag: b, indexes: refs}
+	}
+	return cell, nil
+}
+
+// cell returns the cell with the given index either from the cache or from the underlying reader.
+func (b *LazyBoc) cell(index int) (*Cell, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if elem, ok := b.cache[index]; ok {
+		b.lru.MoveToFront(elem)
+		return elem.Value.(*lazyCacheEntry).cell, nil
+	}
+	cell, err := b.readCell(index)
+	if err != nil {
+		err = fmt.Errorf("failed to read cell %v: %w", index, err)
+		if b.err == nil {
+			b.err = err
+		}
+		return nil, err
+	}
+	b.cache[index] = b.lru.PushFront(&lazyCacheEntry{index: index, cell: cell})
+	if b.lru.Len() > b.cacheSize {
+		last := b.lru.Back()
+		b.lru.Remove(last)
+		delete(b.cache, last.Value.(*lazyCacheEntry).index)
+	}
+	return cell, nil
+}
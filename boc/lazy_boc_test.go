@@ -0,0 +1,194 @@
+package boc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readTestBlockBoc(t *testing.T) []byte {
+	content, err := os.ReadFile("testdata/deserialize-block-2.json")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	var testFile file
+	if err := json.Unmarshal(content, &testFile); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+	bocBytes, err := hex.DecodeString(testFile.Boc)
+	if err != nil {
+		t.Fatalf("hex.DecodeString() failed: %v", err)
+	}
+	return bocBytes
+}
+
+// walkCells returns hashes of all cells of the tree in the depth-first order.
+func walkCells(t *testing.T, c *Cell) []string {
+	hash, err := c.HashString()
+	if err != nil {
+		t.Fatalf("HashString() failed: %v", err)
+	}
+	hashes := []string{hash}
+	for i := 0; i < c.RefsSize(); i++ {
+		ref, err := c.NextRef()
+		if err != nil {
+			t.Fatalf("NextRef() failed: %v", err)
+		}
+		hashes = append(hashes, walkCells(t, ref)...)
+	}
+	return hashes
+}
+
+func TestLazyBoc(t *testing.T) {
+	cells, err := DeserializeBoc(readTestBlockBoc(t))
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	root := cells[0]
+	wantHashes := walkCells(t, root)
+
+	tests := []struct {
+		name      string
+		idx       bool
+		hasCrc32  bool
+		cacheBits bool
+		cacheSize int
+	}{
+		{name: "without index", cacheSize: DefaultLazyBocCacheSize},
+		{name: "with index", idx: true, hasCrc32: true, cacheSize: DefaultLazyBocCacheSize},
+		{name: "with index and cache bits", idx: true, cacheBits: true, cacheSize: DefaultLazyBocCacheSize},
+		{name: "without index, tiny cache", cacheSize: 2},
+		{name: "with index, tiny cache", idx: true, cacheSize: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := SerializeBoc(root, tt.idx, tt.hasCrc32, tt.cacheBits, 0)
+			if err != nil {
+				t.Fatalf("SerializeBoc() failed: %v", err)
+			}
+			bag, err := NewLazyBoc(bytes.NewReader(data), int64(len(data)), WithCacheSize(tt.cacheSize))
+			if err != nil {
+				t.Fatalf("NewLazyBoc() failed: %v", err)
+			}
+			if len(bag.Roots()) != 1 {
+				t.Fatalf("want 1 root, got: %v", len(bag.Roots()))
+			}
+			hashes := walkCells(t, bag.Roots()[0])
+			if !reflect.DeepEqual(hashes, wantHashes) {
+				t.Fatalf("lazy cells differ from deserialized ones")
+			}
+			if bag.lru.Len() > tt.cacheSize {
+				t.Fatalf("want at most %v cached cells, got: %v", tt.cacheSize, bag.lru.Len())
+			}
+			reserialized, err := bag.Roots()[0].ToBoc()
+			if err != nil {
+				t.Fatalf("ToBoc() failed: %v", err)
+			}
+			want, err := root.ToBoc()
+			if err != nil {
+				t.Fatalf("ToBoc() failed: %v", err)
+			}
+			if !bytes.Equal(reserialized, want) {
+				t.Fatalf("reserialized boc differs")
+			}
+			if err := bag.Err(); err != nil {
+				t.Fatalf("Err() returned: %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenLazyBocFile(t *testing.T) {
+	data := readTestBlockBoc(t)
+	path := filepath.Join(t.TempDir(), "block.boc")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	bag, err := OpenLazyBocFile(path, WithCacheSize(16))
+	if err != nil {
+		t.Fatalf("OpenLazyBocFile() failed: %v", err)
+	}
+	defer bag.Close()
+	cells, err := DeserializeBoc(data)
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	want, err := cells[0].Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	got, err := bag.Roots()[0].Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("want hash %x, got: %x", want, got)
+	}
+}
+
+func TestNewLazyBoc_corrupted(t *testing.T) {
+	cells, err := DeserializeBoc(readTestBlockBoc(t))
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	data, err := SerializeBoc(cells[0], true, false, false, 0)
+	if err != nil {
+		t.Fatalf("SerializeBoc() failed: %v", err)
+	}
+	if _, err := NewLazyBoc(bytes.NewReader(data[:len(data)-1]), int64(len(data)-1)); err == nil {
+		t.Fatalf("want error for a truncated boc")
+	}
+
+	root := NewCell()
+	if err := root.WriteUint(1, 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if _, err := root.NewRef(); err != nil {
+		t.Fatalf("NewRef() failed: %v", err)
+	}
+	data, err = SerializeBoc(root, true, false, false, 0)
+	if err != nil {
+		t.Fatalf("SerializeBoc() failed: %v", err)
+	}
+	// the header takes 11 bytes, then the index goes with an end offset of the child cell at position 12.
+	data[12] = 0xff
+	bag, err := NewLazyBoc(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewLazyBoc() failed: %v", err)
+	}
+	if refs := bag.Roots()[0].Refs(); len(refs) != 0 {
+		t.Fatalf("want no refs, got: %v", len(refs))
+	}
+	if bag.Err() == nil {
+		t.Fatalf("want error")
+	}
+	if _, err := bag.Roots()[0].LoadRefs(); err == nil {
+		t.Fatalf("want error")
+	}
+	if err := bag.Roots()[0].LazyErr(); err != bag.Err() {
+		t.Fatalf("want %v, got: %v", bag.Err(), err)
+	}
+	if _, err := bag.Roots()[0].NextRef(); err == nil {
+		t.Fatalf("want error")
+	}
+	copied := bag.Roots()[0].CopyRemaining()
+	if _, err := copied.NextRef(); err == nil {
+		t.Fatalf("want error")
+	}
+	if err := copied.LazyErr(); err != bag.Err() {
+		t.Fatalf("want %v, got: %v", bag.Err(), err)
+	}
+	err = bag.Roots()[0].ReadSlice(func(s *Slice) error {
+		return s.CopyRemaining().LazyErr()
+	})
+	if err != bag.Err() {
+		t.Fatalf("want %v, got: %v", bag.Err(), err)
+	}
+	if _, err := bag.Roots()[0].Hash(); err == nil {
+		t.Fatalf("want error")
+	}
+}
@@ -1,5 +1,12 @@
 package boc
 
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
 type MerkleProver struct {
 	root *immutableCell
 }
@@ -51,3 +58,124 @@ func (c *Cursor) Prune() {
 func (c *Cursor) Ref(ref int) *Cursor {
 	return &Cursor{cell: c.cell.refs[ref], pruned: c.pruned}
 }
+
+// ErrInvalidMerkleProof is returned when a merkle proof cell doesn't prove the expected cell tree.
+var ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+
+// ErrPrunedBranchAccess is returned when a cell tree returned by VerifyMerkleProof
+// is read at a branch that was pruned from the proof.
+var ErrPrunedBranchAccess = errors.New("access to a pruned branch of a merkle proof")
+
+// VerifyMerkleProof checks that the given merkle proof cell proves a cell tree with the given hash
+// and returns the root of the proven tree.
+// It validates level masks of all cells of the proof.
+// NextRef of any cell of the returned tree returns ErrPrunedBranchAccess instead of a pruned branch cell,
+// so decoding a branch that is missing in the proof fails instead of producing zero values.
+// Pruned branch cells are still available through Refs to read their hashes,
+// SkipRef steps over a pruned branch which a caller doesn't need.
+func VerifyMerkleProof(proof *Cell, hash [32]byte) (*Cell, error) {
+	// !merkle_proof#03 {X:Type} virtual_hash:bits256 depth:uint16 virtual_root:^X = MERKLE_PROOF X;
+	if proof.CellType() != MerkleProofCell || proof.RefsSize() != 1 || proof.BitSize() != 8+256+16 {
+		return nil, ErrInvalidMerkleProof
+	}
+	buf := proof.bits.buf
+	if buf[0] != 3 || !bytes.Equal(buf[1:33], hash[:]) {
+		return nil, ErrInvalidMerkleProof
+	}
+	refs, err := proof.refList()
+	if err != nil {
+		return nil, err
+	}
+	root := refs[0]
+	if err := checkLevelMasks(root, make(map[*Cell]struct{})); err != nil {
+		return nil, err
+	}
+	// the proof cell decreases the level of its child by one,
+	// so only pruned branches of level 1 may be cut by the proof itself.
+	if root.mask > 1 {
+		return nil, fmt.Errorf("%w: virtual root has level %v", ErrInvalidMerkleProof, root.mask.Level())
+	}
+	imm, err := newImmutableCell(root, make(map[*Cell]*immutableCell))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(imm.Hash(0), hash[:]) || imm.Depth(0) != int(binary.BigEndian.Uint16(buf[33:35])) {
+		return nil, ErrInvalidMerkleProof
+	}
+	return virtualCopy(root, make(map[*Cell]*Cell))
+}
+
+// checkLevelMasks checks that level masks of the cell tree are consistent with cell contents and refs.
+func checkLevelMasks(c *Cell, checked map[*Cell]struct{}) error {
+	if _, ok := checked[c]; ok {
+		return nil
+	}
+	checked[c] = struct{}{}
+	refs, err := c.refList()
+	if err != nil {
+		return err
+	}
+	var mask levelMask
+	for _, ref := range refs[:c.RefsSize()] {
+		if err := checkLevelMasks(ref, checked); err != nil {
+			return err
+		}
+		mask |= ref.mask
+	}
+	buf := c.bits.buf
+	switch c.cellType {
+	case OrdinaryCell:
+		if c.mask != mask {
+			return fmt.Errorf("%w: level mask %v of ordinary cell, want %v", ErrInvalidMerkleProof, c.mask, mask)
+		}
+	case PrunedBranchCell:
+		if c.RefsSize() != 0 || c.mask == 0 || c.mask > 7 ||
+			c.BitSize() != 16+c.mask.HashIndex()*(hashSize+depthSize)*8 ||
+			buf[0] != byte(PrunedBranchCell) || levelMask(buf[1]) != c.mask {
+			return fmt.Errorf("%w: invalid pruned branch cell", ErrInvalidMerkleProof)
+		}
+	case LibraryCell:
+		if c.RefsSize() != 0 || c.mask != 0 || c.BitSize() != 8+256 {
+			return fmt.Errorf("%w: invalid library cell", ErrInvalidMerkleProof)
+		}
+	case MerkleProofCell, MerkleUpdateCell:
+		if c.mask != mask>>1 {
+			return fmt.Errorf("%w: level mask %v of merkle cell, want %v", ErrInvalidMerkleProof, c.mask, mask>>1)
+		}
+	default:
+		return fmt.Errorf("%w: unknown cell type %v", ErrInvalidMerkleProof, c.cellType)
+	}
+	return nil
+}
+
+// virtualCopy returns a copy of the cell tree marked as virtual.
+// Pruned branch cells are shared with the original tree.
+func virtualCopy(c *Cell, copies map[*Cell]*Cell) (*Cell, error) {
+	if c.cellType == PrunedBranchCell {
+		return c, nil
+	}
+	if cp, ok := copies[c]; ok {
+		return cp, nil
+	}
+	refs, err := c.refList()
+	if err != nil {
+		return nil, err
+	}
+	cp := &Cell{
+		bits: BitString{
+			buf: bytes.Clone(c.bits.buf),
+			cap: c.bits.cap,
+			len: c.bits.len,
+		},
+		cellType: c.cellType,
+		mask:     c.mask,
+		virtual:  true,
+	}
+	for i, ref := range refs[:c.RefsSize()] {
+		if cp.refs[i], err = virtualCopy(ref, copies); err != nil {
+			return nil, err
+		}
+	}
+	copies[c] = cp
+	return cp, nil
+}
@@ -0,0 +1,87 @@
+package boc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVerifyMerkleProof(t *testing.T) {
+	tree := buildTestTree(t, 3, 1, func(prefix uint64) uint64 { return prefix })
+	prover, err := NewMerkleProver(tree)
+	if err != nil {
+		t.Fatalf("NewMerkleProver() failed: %v", err)
+	}
+	cursor := prover.Cursor()
+	cursor.Ref(1).Prune()
+	data, err := prover.CreateProof(cursor)
+	if err != nil {
+		t.Fatalf("CreateProof() failed: %v", err)
+	}
+	proof, err := DeserializeSingleRootBoc(data)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	var hash [32]byte
+	copy(hash[:], mustHash(t, tree))
+
+	root, err := VerifyMerkleProof(proof, hash)
+	if err != nil {
+		t.Fatalf("VerifyMerkleProof() failed: %v", err)
+	}
+	if prefix, err := root.ReadUint(16); err != nil || prefix != 1 {
+		t.Fatalf("want prefix 1, got: %v, %v", prefix, err)
+	}
+	left, err := root.NextRef()
+	if err != nil {
+		t.Fatalf("NextRef() failed: %v", err)
+	}
+	if len(walkCells(t, left)) != 7 {
+		t.Fatalf("the left subtree must be available")
+	}
+	if _, err := root.NextRef(); !errors.Is(err, ErrPrunedBranchAccess) {
+		t.Fatalf("want ErrPrunedBranchAccess, got: %v", err)
+	}
+	// a pruned branch is still available to read its hash.
+	pruned := root.Refs()[1]
+	if pruned.CellType() != PrunedBranchCell {
+		t.Fatalf("want pruned branch cell")
+	}
+	want, err := tree.Refs()[1].HashAtLevel(0)
+	if err != nil {
+		t.Fatalf("HashAtLevel() failed: %v", err)
+	}
+	got, err := pruned.HashAtLevel(0)
+	if err != nil {
+		t.Fatalf("HashAtLevel() failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("want hash %x, got: %x", want, got)
+	}
+	root.ResetCounters()
+	copied := root.CopyRemaining()
+	if _, err := copied.NextRef(); err != nil {
+		t.Fatalf("NextRef() failed: %v", err)
+	}
+	if _, err := copied.NextRef(); !errors.Is(err, ErrPrunedBranchAccess) {
+		t.Fatalf("want ErrPrunedBranchAccess, got: %v", err)
+	}
+	if err := copied.SkipRef(); err != nil {
+		t.Fatalf("SkipRef() failed: %v", err)
+	}
+	if err := copied.SkipRef(); !errors.Is(err, ErrNotEnoughRefs) {
+		t.Fatalf("want ErrNotEnoughRefs, got: %v", err)
+	}
+
+	if _, err := VerifyMerkleProof(proof, [32]byte{1}); !errors.Is(err, ErrInvalidMerkleProof) {
+		t.Fatalf("want ErrInvalidMerkleProof for another hash, got: %v", err)
+	}
+	if _, err := VerifyMerkleProof(tree, hash); !errors.Is(err, ErrInvalidMerkleProof) {
+		t.Fatalf("want ErrInvalidMerkleProof for an ordinary cell, got: %v", err)
+	}
+	// the level mask of the virtual root doesn't mention the pruned branch.
+	proof.refs[0].mask = 0
+	if _, err := VerifyMerkleProof(proof, hash); !errors.Is(err, ErrInvalidMerkleProof) {
+		t.Fatalf("want ErrInvalidMerkleProof for an invalid level mask, got: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package boc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidMerkleUpdate is returned when a merkle update cell can't be applied to a cell tree.
+var ErrInvalidMerkleUpdate = errors.New("invalid merkle update")
+
+// CreateMerkleUpdate returns a merkle update cell that turns the "from" cell tree into the "to" cell tree.
+//
+//	!merkle_update#04 {X:Type} from_hash:bits256 to_hash:bits256
+//	from_depth:uint16 to_depth:uint16 from_proof:^X to_proof:^X = MERKLE_UPDATE X;
+//
+// Subtrees of the new tree that already exist in the old one are replaced with pruned branch cells.
+// The old tree keeps only paths to such subtrees, the subtrees and the rest of the old tree are pruned too.
+func CreateMerkleUpdate(from, to *Cell) (*Cell, error) {
+	cache := make(map[*Cell]*immutableCell)
+	immFrom, err := newImmutableCell(from, cache)
+	if err != nil {
+		return nil, err
+	}
+	immTo, err := newImmutableCell(to, cache)
+	if err != nil {
+		return nil, err
+	}
+	fromCells := make(map[string]*immutableCell)
+	collectImmutableCells(immFrom, fromCells)
+
+	// the new tree is pruned at the topmost cells that can be taken from the old tree.
+	prunedTo := make(map[*immutableCell]struct{})
+	reused := make(map[string]struct{})
+	visited := make(map[*immutableCell]struct{})
+	var walkTo func(ic *immutableCell)
+	walkTo = func(ic *immutableCell) {
+		if _, ok := visited[ic]; ok {
+			return
+		}
+		visited[ic] = struct{}{}
+		hash := string(ic.Hash(0))
+		if _, ok := fromCells[hash]; ok {
+			prunedTo[ic] = struct{}{}
+			reused[hash] = struct{}{}
+			return
+		}
+		for _, ref := range ic.refs {
+			walkTo(ref)
+		}
+	}
+	walkTo(immTo)
+
+	// the old tree keeps only paths to the reused cells, and the reused cells are pruned in both trees.
+	kept := make(map[*immutableCell]bool)
+	var walkFrom func(ic *immutableCell) bool
+	walkFrom = func(ic *immutableCell) bool {
+		if result, ok := kept[ic]; ok {
+			return result
+		}
+		kept[ic] = false
+		if _, ok := reused[string(ic.Hash(0))]; ok {
+			return false
+		}
+		result := false
+		for _, ref := range ic.refs {
+			keptRef := walkFrom(ref)
+			if _, ok := reused[string(ref.Hash(0))]; ok || keptRef {
+				result = true
+			}
+		}
+		kept[ic] = result
+		return result
+	}
+	walkFrom(immFrom)
+	prunedFrom := make(map[*immutableCell]struct{})
+	for ic, ok := range kept {
+		if !ok {
+			prunedFrom[ic] = struct{}{}
+		}
+	}
+
+	fromProof, err := immFrom.pruneCells(prunedFrom)
+	if err != nil {
+		return nil, err
+	}
+	toProof, err := immTo.pruneCells(prunedTo)
+	if err != nil {
+		return nil, err
+	}
+	update := NewCellExotic(MerkleUpdateCell)
+	update.mask = (fromProof.mask | toProof.mask) >> 1
+	if err := update.WriteUint(4, 8); err != nil {
+		return nil, err
+	}
+	if err := update.WriteBytes(immFrom.Hash(0)); err != nil {
+		return nil, err
+	}
+	if err := update.WriteBytes(immTo.Hash(0)); err != nil {
+		return nil, err
+	}
+	if err := update.WriteUint(uint64(immFrom.Depth(0)), 16); err != nil {
+		return nil, err
+	}
+	if err := update.WriteUint(uint64(immTo.Depth(0)), 16); err != nil {
+		return nil, err
+	}
+	if err := update.AddRef(fromProof); err != nil {
+		return nil, err
+	}
+	if err := update.AddRef(toProof); err != nil {
+		return nil, err
+	}
+	update.ResetCounters()
+	return update, nil
+}
+
+// collectImmutableCells puts all cells of the tree to the map by their level 0 hashes.
+func collectImmutableCells(ic *immutableCell, cells map[string]*immutableCell) {
+	hash := string(ic.Hash(0))
+	if _, ok := cells[hash]; ok {
+		return
+	}
+	cells[hash] = ic
+	for _, ref := range ic.refs {
+		collectImmutableCells(ref, cells)
+	}
+}
+
+// ApplyMerkleUpdate applies the merkle update cell to the "from" cell tree and returns the new cell tree.
+// It checks that the update is made for a tree with the hash of "from"
+// and that the result has the hash declared by the update.
+func ApplyMerkleUpdate(update, from *Cell) (*Cell, error) {
+	if update.CellType() != MerkleUpdateCell || update.RefsSize() != 2 || update.BitSize() != 8+256+256+16+16 {
+		return nil, ErrInvalidMerkleUpdate
+	}
+	buf := update.bits.buf
+	if buf[0] != 4 {
+		return nil, ErrInvalidMerkleUpdate
+	}
+	fromHash, toHash := buf[1:33], buf[33:65]
+	fromDepth, toDepth := int(binary.BigEndian.Uint16(buf[65:67])), int(binary.BigEndian.Uint16(buf[67:69]))
+	refs, err := update.refList()
+	if err != nil {
+		return nil, err
+	}
+	cache := make(map[*Cell]*immutableCell)
+	fromProof, err := newImmutableCell(refs[0], cache)
+	if err != nil {
+		return nil, err
+	}
+	toProof, err := newImmutableCell(refs[1], cache)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fromProof.Hash(0), fromHash) || fromProof.Depth(0) != fromDepth {
+		return nil, fmt.Errorf("%w: from_hash mismatch", ErrInvalidMerkleUpdate)
+	}
+	if !bytes.Equal(toProof.Hash(0), toHash) || toProof.Depth(0) != toDepth {
+		return nil, fmt.Errorf("%w: to_hash mismatch", ErrInvalidMerkleUpdate)
+	}
+	immFrom, err := newImmutableCell(from, cache)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(immFrom.Hash(0), fromHash) {
+		return nil, fmt.Errorf("%w: the update is made for another cell tree", ErrInvalidMerkleUpdate)
+	}
+
+	// cells of the old tree that are visible through from_proof can be used to build the new tree.
+	known := make(map[string]*Cell)
+	if err := collectVisibleCells(fromProof, from, known, make(map[*immutableCell]struct{})); err != nil {
+		return nil, err
+	}
+	to, err := buildUpdatedCell(toProof, known, make(map[*immutableCell]*Cell))
+	if err != nil {
+		return nil, err
+	}
+	hash, err := to.HashAtLevel(0)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hash, toHash) {
+		return nil, fmt.Errorf("%w: the result has unexpected hash", ErrInvalidMerkleUpdate)
+	}
+	to.ResetCounters()
+	return to, nil
+}
+
+// isUpdatePruned returns true if the cell is a pruned branch cell created by a merkle update.
+func isUpdatePruned(ic *immutableCell) bool {
+	return ic.cellType == PrunedBranchCell && ic.mask&1 != 0
+}
+
+// collectVisibleCells walks a proof and the original cell tree simultaneously
+// and puts the original cells present in the proof, either as is or pruned, to the map by their level 0 hashes.
+func collectVisibleCells(proof *immutableCell, cell *Cell, known map[string]*Cell, visited map[*immutableCell]struct{}) error {
+	if _, ok := visited[proof]; ok {
+		return nil
+	}
+	visited[proof] = struct{}{}
+	known[string(proof.Hash(0))] = cell
+	if isUpdatePruned(proof) {
+		return nil
+	}
+	refs, err := cell.refList()
+	if err != nil {
+		return err
+	}
+	if len(proof.refs) != cell.RefsSize() {
+		return fmt.Errorf("%w: from_proof doesn't match the cell tree", ErrInvalidMerkleUpdate)
+	}
+	for i, ref := range proof.refs {
+		if err := collectVisibleCells(ref, refs[i], known, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildUpdatedCell converts a proof cell to a regular cell
+// replacing pruned branch cells with the known cells of the old tree.
+func buildUpdatedCell(proof *immutableCell, known map[string]*Cell, built map[*immutableCell]*Cell) (*Cell, error) {
+	if cell, ok := built[proof]; ok {
+		return cell, nil
+	}
+	if isUpdatePruned(proof) {
+		cell, ok := known[string(proof.Hash(0))]
+		if !ok {
+			return nil, fmt.Errorf("%w: pruned cell %x isn't found in from_proof", ErrInvalidMerkleUpdate, proof.Hash(0))
+		}
+		return cell, nil
+	}
+	cell := &Cell{
+		bits: BitString{
+			buf: bytes.Clone(proof.bitsBuf),
+			cap: proof.bitsLen,
+			len: proof.bitsLen,
+		},
+		cellType: proof.cellType,
+		mask:     proof.mask,
+	}
+	var mask levelMask
+	for i, ref := range proof.refs {
+		refCell, err := buildUpdatedCell(ref, known, built)
+		if err != nil {
+			return nil, err
+		}
+		cell.refs[i] = refCell
+		mask |= refCell.mask
+	}
+	switch cell.cellType {
+	case OrdinaryCell:
+		cell.mask = mask
+	case MerkleProofCell, MerkleUpdateCell:
+		cell.mask = mask >> 1
+	}
+	built[proof] = cell
+	return cell, nil
+}
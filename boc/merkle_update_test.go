@@ -0,0 +1,143 @@
+package boc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// buildTestTree returns a binary tree of the given depth with leaves storing values returned by leaf.
+func buildTestTree(t *testing.T, depth int, prefix uint64, leaf func(prefix uint64) uint64) *Cell {
+	c := NewCell()
+	if depth == 0 {
+		if err := c.WriteUint(leaf(prefix), 64); err != nil {
+			t.Fatalf("WriteUint() failed: %v", err)
+		}
+		return c
+	}
+	if err := c.WriteUint(prefix, 16); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	for i := uint64(0); i < 2; i++ {
+		if err := c.AddRef(buildTestTree(t, depth-1, prefix*2+i, leaf)); err != nil {
+			t.Fatalf("AddRef() failed: %v", err)
+		}
+	}
+	return c
+}
+
+func mustHash(t *testing.T, c *Cell) []byte {
+	hash, err := c.Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	return hash
+}
+
+func TestMerkleUpdate(t *testing.T) {
+	from := buildTestTree(t, 6, 1, func(prefix uint64) uint64 { return prefix })
+	to := buildTestTree(t, 6, 1, func(prefix uint64) uint64 {
+		if prefix == 77 {
+			return 1000
+		}
+		return prefix
+	})
+	unrelated := buildTestTree(t, 3, 1, func(prefix uint64) uint64 { return prefix + 100 })
+
+	update, err := CreateMerkleUpdate(from, to)
+	if err != nil {
+		t.Fatalf("CreateMerkleUpdate() failed: %v", err)
+	}
+	if update.CellType() != MerkleUpdateCell || update.Level() != 0 {
+		t.Fatalf("invalid merkle update cell")
+	}
+	// the update contains paths to the changed leaf only.
+	data, err := update.ToBoc()
+	if err != nil {
+		t.Fatalf("ToBoc() failed: %v", err)
+	}
+	cells, err := DeserializeBoc(data)
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	if count := countCells(cells[0], map[*Cell]struct{}{}); count > 1+2*(1+6+6) {
+		t.Fatalf("the update is too big: %v cells", count)
+	}
+
+	got, err := ApplyMerkleUpdate(cells[0], from)
+	if err != nil {
+		t.Fatalf("ApplyMerkleUpdate() failed: %v", err)
+	}
+	if !bytes.Equal(mustHash(t, got), mustHash(t, to)) {
+		t.Fatalf("the result differs from the new tree")
+	}
+
+	if _, err := ApplyMerkleUpdate(cells[0], to); !errors.Is(err, ErrInvalidMerkleUpdate) {
+		t.Fatalf("want ErrInvalidMerkleUpdate applying to another tree, got: %v", err)
+	}
+	if _, err := ApplyMerkleUpdate(from, from); !errors.Is(err, ErrInvalidMerkleUpdate) {
+		t.Fatalf("want ErrInvalidMerkleUpdate for an ordinary cell, got: %v", err)
+	}
+
+	// a new tree without common cells is stored completely.
+	update, err = CreateMerkleUpdate(from, unrelated)
+	if err != nil {
+		t.Fatalf("CreateMerkleUpdate() failed: %v", err)
+	}
+	got, err = ApplyMerkleUpdate(update, from)
+	if err != nil {
+		t.Fatalf("ApplyMerkleUpdate() failed: %v", err)
+	}
+	if !bytes.Equal(mustHash(t, got), mustHash(t, unrelated)) {
+		t.Fatalf("the result differs from the new tree")
+	}
+
+	// nothing changes.
+	update, err = CreateMerkleUpdate(from, from)
+	if err != nil {
+		t.Fatalf("CreateMerkleUpdate() failed: %v", err)
+	}
+	got, err = ApplyMerkleUpdate(update, from)
+	if err != nil {
+		t.Fatalf("ApplyMerkleUpdate() failed: %v", err)
+	}
+	if !bytes.Equal(mustHash(t, got), mustHash(t, from)) {
+		t.Fatalf("the result differs from the original tree")
+	}
+}
+
+func TestApplyMerkleUpdate_forged(t *testing.T) {
+	from := buildTestTree(t, 4, 1, func(prefix uint64) uint64 { return prefix })
+	to := buildTestTree(t, 4, 1, func(prefix uint64) uint64 { return prefix * 3 })
+	update, err := CreateMerkleUpdate(from, to)
+	if err != nil {
+		t.Fatalf("CreateMerkleUpdate() failed: %v", err)
+	}
+	// to_hash is changed.
+	forged := NewCellExotic(MerkleUpdateCell)
+	buf := bytes.Clone(update.bits.buf)
+	buf[40] ^= 1
+	if err := forged.WriteBytes(buf[:69]); err != nil {
+		t.Fatalf("WriteBytes() failed: %v", err)
+	}
+	for _, ref := range update.Refs() {
+		if err := forged.AddRef(ref); err != nil {
+			t.Fatalf("AddRef() failed: %v", err)
+		}
+	}
+	if _, err := ApplyMerkleUpdate(forged, from); !errors.Is(err, ErrInvalidMerkleUpdate) {
+		t.Fatalf("want ErrInvalidMerkleUpdate, got: %v", err)
+	}
+}
+
+func countCells(c *Cell, visited map[*Cell]struct{}) int {
+	if _, ok := visited[c]; ok {
+		return 0
+	}
+	visited[c] = struct{}{}
+	count := 1
+	for _, ref := range c.Refs() {
+		count += countCells(ref, visited)
+	}
+	return count
+}
@@ -0,0 +1,225 @@
+package boc
+
+import (
+	"math/big"
+)
+
+// Slice is a read cursor over a cell.
+// Unlike reading methods of Cell, reading a slice never changes the cell,
+// so the same cell can be read by any number of slices, concurrently as well,
+// without resetting its counters or copying it.
+type Slice struct {
+	cell   *Cell
+	bits   BitString
+	refPos int
+}
+
+// BeginParse returns a slice reading the cell from the beginning.
+func (c *Cell) BeginParse() *Slice {
+	s := &Slice{cell: c, bits: c.bits}
+	s.bits.rCursor = 0
+	return s
+}
+
+// ReadSlice calls f with a slice positioned at the cell's read cursors
+// and moves the cursors past everything f has read from the slice.
+// It lets code written for the Cell API use code written for Slice.
+func (c *Cell) ReadSlice(f func(s *Slice) error) error {
+	s := Slice{cell: c, bits: c.bits, refPos: c.refCursor}
+	if err := f(&s); err != nil {
+		return err
+	}
+	c.bits.rCursor = s.bits.rCursor
+	c.refCursor = s.refPos
+	return nil
+}
+
+// Cell returns the cell read by the slice.
+func (s *Slice) Cell() *Cell {
+	return s.cell
+}
+
+// Copy returns an independent slice with the same cursors.
+func (s *Slice) Copy() *Slice {
+	cp := *s
+	return &cp
+}
+
+// ToCell returns a copy of the slice's cell with read cursors set to the slice's cursors.
+// The copy shares bits and refs with the original cell, so it must not be modified.
+// Like a cell passed by ReadCell, NextRef of the copy never changes the original cells.
+func (s *Slice) ToCell() *Cell {
+	c := newCellView(s.cell, s.bits, s.refPos)
+	if c.lazy != nil {
+		// refs of a lazy cell are loaded on demand, so NextRef of the view creates views of them.
+		return c
+	}
+	// the copy is a regular cell, but its refs are views,
+	// so NextRef resets counters of the views instead of the shared refs.
+	c.view, c.origin = false, nil
+	for i, ref := range c.refs {
+		if ref != nil {
+			bits := ref.bits
+			bits.rCursor = 0
+			c.refs[i] = newCellView(ref, bits, 0)
+		}
+	}
+	return c
+}
+
+// newCellView returns a cell sharing bits and refs with the given cell.
+// NextRef of the returned cell and of cells obtained through it never changes the original cells.
+func newCellView(c *Cell, bits BitString, refPos int) *Cell {
+	return &Cell{
+		bits:      bits,
+		refs:      c.refs,
+		refCursor: refPos,
+		cellType:  c.cellType,
+		mask:      c.mask,
+		lazy:      c.lazy,
+		virtual:   c.virtual,
+		view:      true,
+		origin:    c.hashKey(),
+	}
+}
+
+// ReadCell calls f with a cell positioned at the slice's cursors
+// and moves the cursors past everything f has read from the cell.
+// It lets code written for the Cell API read a slice.
+// Reading the cell and cells obtained through its NextRef never changes the original cells,
+// but f must not modify them.
+func (s *Slice) ReadCell(f func(c *Cell) error) error {
+	c := newCellView(s.cell, s.bits, s.refPos)
+	if err := f(c); err != nil {
+		return err
+	}
+	s.bits.rCursor = c.bits.rCursor
+	s.refPos = c.refCursor
+	return nil
+}
+
+func (s *Slice) CellType() CellType {
+	return s.cell.cellType
+}
+
+func (s *Slice) IsLibrary() bool {
+	return s.cell.IsLibrary()
+}
+
+// LazyErr returns the first error that happened while reading the bag of cells
+// the slice's cell was read from by LazyBoc, see Cell.LazyErr.
+func (s *Slice) LazyErr() error {
+	return s.cell.LazyErr()
+}
+
+func (s *Slice) BitsAvailableForRead() int {
+	return s.bits.BitsAvailableForRead()
+}
+
+func (s *Slice) RefsAvailableForRead() int {
+	return s.cell.RefsSize() - s.refPos
+}
+
+// NextRef returns a slice reading the next ref of the cell from the beginning.
+func (s *Slice) NextRef() (*Slice, error) {
+	ref, err := s.PickRef()
+	if err != nil {
+		return nil, err
+	}
+	s.refPos++
+	return ref.BeginParse(), nil
+}
+
+// PickRef returns the next ref of the cell without moving the cursor.
+func (s *Slice) PickRef() (*Cell, error) {
+	if s.refPos > 3 {
+		return nil, ErrNotEnoughRefs
+	}
+	refs, err := s.cell.refList()
+	if err != nil {
+		return nil, err
+	}
+	ref := refs[s.refPos]
+	if ref == nil {
+		return nil, ErrNotEnoughRefs
+	}
+	if s.cell.virtual && ref.cellType == PrunedBranchCell {
+		return nil, ErrPrunedBranchAccess
+	}
+	return ref, nil
+}
+
+func (s *Slice) SkipRefs(n int) error {
+	if s.RefsAvailableForRead() < n {
+		return ErrNotEnoughRefs
+	}
+	s.refPos += n
+	return nil
+}
+
+func (s *Slice) Skip(n int) error {
+	return s.bits.Skip(n)
+}
+
+func (s *Slice) ReadBit() (bool, error) {
+	return s.bits.ReadBit()
+}
+
+func (s *Slice) ReadUint(bitLen int) (uint64, error) {
+	return s.bits.ReadUint(bitLen)
+}
+
+func (s *Slice) PickUint(bitLen int) (uint64, error) {
+	return s.bits.PickUint(bitLen)
+}
+
+func (s *Slice) ReadInt(bitLen int) (int64, error) {
+	return s.bits.ReadInt(bitLen)
+}
+
+func (s *Slice) ReadBigUint(bitLen int) (*big.Int, error) {
+	return s.bits.ReadBigUint(bitLen)
+}
+
+func (s *Slice) ReadBigInt(bitLen int) (*big.Int, error) {
+	return s.bits.ReadBigInt(bitLen)
+}
+
+func (s *Slice) ReadBytes(n int) ([]byte, error) {
+	return s.bits.ReadBytes(n)
+}
+
+func (s *Slice) ReadBits(n int) (BitString, error) {
+	return s.bits.ReadBits(n)
+}
+
+func (s *Slice) ReadUnary() (uint, error) {
+	return s.bits.ReadUnary()
+}
+
+func (s *Slice) ReadLimUint(n int) (uint, error) {
+	return s.bits.ReadLimUint(n)
+}
+
+func (s *Slice) ReadRemainingBits() BitString {
+	return s.bits.ReadRemainingBits()
+}
+
+// CopyRemaining returns a new cell with the remaining bits and refs of the slice.
+// Unlike Cell.CopyRemaining, it doesn't reset counters of the refs, they are shared as is.
+func (s *Slice) CopyRemaining() *Cell {
+	rest := *s
+	c := NewCellWithBits(rest.ReadRemainingBits())
+	c.virtual = s.cell.virtual
+	if lazy := s.cell.lazy; lazy != nil {
+		c.lazy = &lazyRefs{bag: lazy.bag, indexes: lazy.indexes[s.refPos:]}
+		return c
+	}
+	for _, ref := range s.cell.refs[s.refPos:s.cell.RefsSize()] {
+		if err := c.AddRef(ref); err != nil {
+			// this should never happen but anyway
+			panic(err)
+		}
+	}
+	return c
+}
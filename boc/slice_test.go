@@ -0,0 +1,196 @@
+package boc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	tree := buildTestTree(t, 2, 1, func(prefix uint64) uint64 { return prefix })
+
+	first, second := tree.BeginParse(), tree.BeginParse()
+	if prefix, err := first.ReadUint(16); err != nil || prefix != 1 {
+		t.Fatalf("want prefix 1, got: %v, %v", prefix, err)
+	}
+	if prefix, err := second.ReadUint(16); err != nil || prefix != 1 {
+		t.Fatalf("slices must be independent, got: %v, %v", prefix, err)
+	}
+	for i := uint64(0); i < 2; i++ {
+		ref, err := first.NextRef()
+		if err != nil {
+			t.Fatalf("NextRef() failed: %v", err)
+		}
+		if prefix, err := ref.ReadUint(16); err != nil || prefix != 2+i {
+			t.Fatalf("want prefix %v, got: %v, %v", 2+i, prefix, err)
+		}
+	}
+	if _, err := first.NextRef(); !errors.Is(err, ErrNotEnoughRefs) {
+		t.Fatalf("want ErrNotEnoughRefs, got: %v", err)
+	}
+	// reading slices doesn't change the cells.
+	if tree.BitsAvailableForRead() != 16 || tree.RefsAvailableForRead() != 2 {
+		t.Fatalf("the cell cursors must not be changed")
+	}
+	if tree.Refs()[0].BitsAvailableForRead() != 16 {
+		t.Fatalf("the ref cursors must not be changed")
+	}
+
+	// ReadCell and ReadSlice move the cursors past everything that has been read.
+	s := tree.BeginParse()
+	err := s.ReadCell(func(c *Cell) error {
+		if _, err := c.ReadUint(8); err != nil {
+			return err
+		}
+		ref, err := c.NextRef()
+		if err != nil {
+			return err
+		}
+		_, err = ref.ReadUint(16)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ReadCell() failed: %v", err)
+	}
+	if s.BitsAvailableForRead() != 8 || s.RefsAvailableForRead() != 1 {
+		t.Fatalf("want 8 bits and 1 ref left, got: %v, %v", s.BitsAvailableForRead(), s.RefsAvailableForRead())
+	}
+	if tree.Refs()[0].BitsAvailableForRead() != 16 {
+		t.Fatalf("the ref cursors must not be changed")
+	}
+	err = tree.ReadSlice(func(s *Slice) error {
+		_, err := s.ReadUint(4)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ReadSlice() failed: %v", err)
+	}
+	if tree.BitsAvailableForRead() != 12 {
+		t.Fatalf("want 12 bits left, got: %v", tree.BitsAvailableForRead())
+	}
+}
+
+func TestSlice_prunedBranch(t *testing.T) {
+	tree := buildTestTree(t, 2, 1, func(prefix uint64) uint64 { return prefix })
+	prover, err := NewMerkleProver(tree)
+	if err != nil {
+		t.Fatalf("NewMerkleProver() failed: %v", err)
+	}
+	cursor := prover.Cursor()
+	cursor.Ref(1).Prune()
+	data, err := prover.CreateProof(cursor)
+	if err != nil {
+		t.Fatalf("CreateProof() failed: %v", err)
+	}
+	proof, err := DeserializeSingleRootBoc(data)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	var hash [32]byte
+	copy(hash[:], mustHash(t, tree))
+	root, err := VerifyMerkleProof(proof, hash)
+	if err != nil {
+		t.Fatalf("VerifyMerkleProof() failed: %v", err)
+	}
+	s := root.BeginParse()
+	if _, err := s.NextRef(); err != nil {
+		t.Fatalf("NextRef() failed: %v", err)
+	}
+	if _, err := s.NextRef(); !errors.Is(err, ErrPrunedBranchAccess) {
+		t.Fatalf("want ErrPrunedBranchAccess, got: %v", err)
+	}
+}
+
+func TestSlice_ToCell(t *testing.T) {
+	tree := buildTestTree(t, 3, 1, func(prefix uint64) uint64 { return prefix })
+	ref := tree.Refs()[0]
+	grandchild := ref.Refs()[0]
+	if _, err := ref.ReadUint(8); err != nil {
+		t.Fatalf("ReadUint() failed: %v", err)
+	}
+	if _, err := grandchild.ReadUint(8); err != nil {
+		t.Fatalf("ReadUint() failed: %v", err)
+	}
+
+	c := tree.BeginParse().ToCell()
+	for _, want := range []uint64{1, 2, 4} {
+		if prefix, err := c.ReadUint(16); err != nil || prefix != want {
+			t.Fatalf("want prefix %v, got: %v, %v", want, prefix, err)
+		}
+		next, err := c.NextRef()
+		if err != nil {
+			t.Fatalf("NextRef() failed: %v", err)
+		}
+		c = next
+	}
+	// NextRef of the copy doesn't reset the cursors of the shared cells.
+	if ref.BitsAvailableForRead() != 8 || grandchild.BitsAvailableForRead() != 8 {
+		t.Fatalf("the ref cursors must not be changed")
+	}
+}
+
+func TestHasher_views(t *testing.T) {
+	tree := buildTestTree(t, 2, 1, func(prefix uint64) uint64 { return prefix })
+	hasher := NewHasher()
+	want, err := hasher.Hash(tree)
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	err = tree.BeginParse().ReadCell(func(c *Cell) error {
+		hash, err := hasher.Hash(c)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash, want) {
+			t.Fatalf("want hash %x, got: %x", want, hash)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadCell() failed: %v", err)
+	}
+	// views are hashed once, by the cells they share bits and refs with.
+	if len(hasher.cache) != 7 {
+		t.Fatalf("want 7 cached cells, got: %v", len(hasher.cache))
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	tree := buildTestTree(t, 2, 1, func(prefix uint64) uint64 { return prefix })
+
+	s := tree.BeginParse()
+	if _, err := s.ReadUint(8); err != nil {
+		t.Fatalf("ReadUint() failed: %v", err)
+	}
+	if err := s.SkipRefs(1); err != nil {
+		t.Fatalf("SkipRefs() failed: %v", err)
+	}
+	b := NewBuilder()
+	if err := b.WriteUint(5, 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := b.WriteSlice(s); err != nil {
+		t.Fatalf("WriteSlice() failed: %v", err)
+	}
+	if s.BitsAvailableForRead() != 8 {
+		t.Fatalf("WriteSlice() must not move the slice cursors")
+	}
+	got := b.EndCell()
+
+	want := NewCell()
+	if err := want.WriteUint(5, 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := want.WriteUint(1, 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := want.AddRef(tree.Refs()[1]); err != nil {
+		t.Fatalf("AddRef() failed: %v", err)
+	}
+	if !bytes.Equal(mustHash(t, got), mustHash(t, want)) {
+		t.Fatalf("want cell %v, got: %v", want.ToString(), got.ToString())
+	}
+	if got.Refs()[0] != tree.Refs()[1] {
+		t.Fatalf("WriteSlice() must not copy refs")
+	}
+}
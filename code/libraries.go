@@ -38,8 +38,12 @@ func findLibraries(cell *boc.Cell) (map[ton.Bits256]struct{}, error) {
 		}
 		return nil, nil
 	}
+	refs, err := cell.LoadRefs()
+	if err != nil {
+		return nil, err
+	}
 	var libs map[ton.Bits256]struct{}
-	for _, ref := range cell.Refs() {
+	for _, ref := range refs {
 		ref.ResetCounters()
 		hashes, err := findLibraries(ref)
 		if err != nil {
@@ -1,11 +1,14 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/caigou-xyz/tongo/ton"
 )
 
 type liteServerConfig struct {
@@ -19,15 +22,84 @@ type liteServerId struct {
 	Key  string `json:"key"`
 }
 
+type dhtAddressConfig struct {
+	Type string `json:"@type"`
+	Ip   int64  `json:"ip"`
+	Port int64  `json:"port"`
+}
+
+type dhtNodeConfig struct {
+	ID       liteServerId `json:"id"`
+	AddrList struct {
+		Addrs      []dhtAddressConfig `json:"addrs"`
+		Version    int32              `json:"version"`
+		ReinitDate int32              `json:"reinit_date"`
+		Priority   int32              `json:"priority"`
+		ExpireAt   int32              `json:"expire_at"`
+	} `json:"addr_list"`
+	Version   int32  `json:"version"`
+	Signature string `json:"signature"`
+}
+
+type dhtConfig struct {
+	StaticNodes struct {
+		Nodes []dhtNodeConfig `json:"nodes"`
+	} `json:"static_nodes"`
+}
+
+type blockIDConfig struct {
+	Workchain int32  `json:"workchain"`
+	Shard     int64  `json:"shard"`
+	Seqno     uint32 `json:"seqno"`
+	RootHash  string `json:"root_hash"`
+	FileHash  string `json:"file_hash"`
+}
+
+type validatorConfig struct {
+	ZeroState *blockIDConfig `json:"zero_state"`
+	InitBlock *blockIDConfig `json:"init_block"`
+}
+
 type configGlobal struct {
 	LiteServers []liteServerConfig `json:"liteservers"`
-	//Validator   ValidatorConfig  `json:"validator"`
+	DHT         dhtConfig          `json:"dht"`
+	Validator   validatorConfig    `json:"validator"`
 }
 
 // GlobalConfigurationFile contains global configuration of the TON Blockchain.
 // It is shared by all nodes and includes information about network, init block, hardforks, etc.
 type GlobalConfigurationFile struct {
 	LiteServers []LiteServer
+	DHTNodes    []DHTNode
+	Validator   ValidatorConfig
+}
+
+// ValidatorConfig contains masterchain blocks that nodes of the network trust without proofs.
+type ValidatorConfig struct {
+	// ZeroState is the first block of the masterchain.
+	ZeroState *ton.BlockIDExt
+	// InitBlock is a recent key block of the masterchain.
+	// Proofs of newer blocks can be checked starting from it.
+	InitBlock *ton.BlockIDExt
+}
+
+// DHTNode is a static DHT node that is used to bootstrap a DHT client.
+// All fields are kept as is, because the node is signed by its key.
+type DHTNode struct {
+	Key      string // base64-encoded ed25519 public key
+	AddrList DHTAddressList
+	Version  int32
+	// Signature is a base64-encoded signature of the node.
+	Signature string
+}
+
+// DHTAddressList is a list of UDP addresses of a DHT node.
+type DHTAddressList struct {
+	Addrs      []string // "ip:port"
+	Version    int32
+	ReinitDate int32
+	Priority   int32
+	ExpireAt   int32
 }
 
 // LiteServer TODO: clarify struct
@@ -60,6 +132,57 @@ func convertToLiteServerOptions(server liteServerConfig) (LiteServer, error) {
 	}, nil
 }
 
+func convertToDHTNode(node dhtNodeConfig) (DHTNode, error) {
+	if node.ID.Type != "pub.ed25519" {
+		return DHTNode{}, fmt.Errorf("not pub.ed25519 dht node ID. Other types not supported")
+	}
+	res := DHTNode{
+		Key: node.ID.Key,
+		AddrList: DHTAddressList{
+			Addrs:      []string{},
+			Version:    node.AddrList.Version,
+			ReinitDate: node.AddrList.ReinitDate,
+			Priority:   node.AddrList.Priority,
+			ExpireAt:   node.AddrList.ExpireAt,
+		},
+		Version:   node.Version,
+		Signature: node.Signature,
+	}
+	for _, addr := range node.AddrList.Addrs {
+		if addr.Type != "adnl.address.udp" {
+			return DHTNode{}, fmt.Errorf("only adnl.address.udp supported")
+		}
+		ipBytes := make([]byte, 4)
+		binary.BigEndian.PutUint32(ipBytes, uint32(addr.Ip))
+		res.AddrList.Addrs = append(res.AddrList.Addrs, fmt.Sprintf("%v.%v.%v.%v:%d", ipBytes[0], ipBytes[1], ipBytes[2], ipBytes[3], addr.Port))
+	}
+	return res, nil
+}
+
+func convertToBlockID(block *blockIDConfig) (*ton.BlockIDExt, error) {
+	if block == nil {
+		return nil, nil
+	}
+	rootHash, err := base64.StdEncoding.DecodeString(block.RootHash)
+	if err != nil || len(rootHash) != 32 {
+		return nil, fmt.Errorf("invalid root hash of block %v", block.Seqno)
+	}
+	fileHash, err := base64.StdEncoding.DecodeString(block.FileHash)
+	if err != nil || len(fileHash) != 32 {
+		return nil, fmt.Errorf("invalid file hash of block %v", block.Seqno)
+	}
+	id := ton.BlockIDExt{
+		BlockID: ton.BlockID{
+			Workchain: block.Workchain,
+			Shard:     uint64(block.Shard),
+			Seqno:     block.Seqno,
+		},
+	}
+	copy(id.RootHash[:], rootHash)
+	copy(id.FileHash[:], fileHash)
+	return &id, nil
+}
+
 func ParseConfig(data io.Reader) (*GlobalConfigurationFile, error) {
 	var conf configGlobal
 	err := json.NewDecoder(data).Decode(&conf)
@@ -74,6 +197,19 @@ func ParseConfig(data io.Reader) (*GlobalConfigurationFile, error) {
 		}
 		options.LiteServers = append(options.LiteServers, ls)
 	}
+	for _, node := range conf.DHT.StaticNodes.Nodes {
+		n, err := convertToDHTNode(node)
+		if err != nil {
+			continue
+		}
+		options.DHTNodes = append(options.DHTNodes, n)
+	}
+	if options.Validator.ZeroState, err = convertToBlockID(conf.Validator.ZeroState); err != nil {
+		return nil, err
+	}
+	if options.Validator.InitBlock, err = convertToBlockID(conf.Validator.InitBlock); err != nil {
+		return nil, err
+	}
 	if len(options.LiteServers) == 0 {
 		return nil, fmt.Errorf("no one supported liteservers")
 	}
@@ -0,0 +1,616 @@
+package dht
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caigou-xyz/tongo/adnl"
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+)
+
+const (
+	// k is a number of the closest nodes a lookup collects and a value is stored at.
+	k = 6
+	// alpha is a number of parallel queries during a lookup.
+	alpha = 3
+	// bucketSize is a maximum number of nodes in a bucket of the routing table.
+	bucketSize = 10
+
+	queryTimeout = 3 * time.Second
+)
+
+var (
+	ErrNotFound = errors.New("dht: value not found")
+)
+
+type knownNode struct {
+	id   tl.Int256
+	node Node
+}
+
+// Client is a DHT node.
+// It looks up and stores values in the TON DHT and answers DHT queries of other nodes
+// using the ADNL identity of the transport as its node ID.
+type Client struct {
+	transport *adnl.Transport
+	id        tl.Int256
+
+	// mu protects all fields below.
+	mu      sync.Mutex
+	buckets [256][]knownNode
+	values  map[tl.Int256]Value
+}
+
+// NewClient returns a DHT client that uses the transport to reach other nodes.
+// Nodes are used to bootstrap the routing table, usually they come from the global config.
+// The client starts answering DHT queries received by the transport.
+func NewClient(transport *adnl.Transport, nodes []Node) (*Client, error) {
+	c := &Client{
+		transport: transport,
+		id:        transport.ID(),
+		values:    map[tl.Int256]Value{},
+	}
+	for _, n := range nodes {
+		if err := c.addNode(n); err != nil {
+			return nil, err
+		}
+	}
+	transport.SetQueryHandler(c.handleQuery)
+	return c, nil
+}
+
+// NodeFromConfig converts a static DHT node of the global config to Node.
+func NodeFromConfig(node config.DHTNode) (Node, error) {
+	key, err := base64.StdEncoding.DecodeString(node.Key)
+	if err != nil {
+		return Node{}, err
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return Node{}, fmt.Errorf("invalid public key length %v", len(key))
+	}
+	signature, err := base64.StdEncoding.DecodeString(node.Signature)
+	if err != nil {
+		return Node{}, err
+	}
+	var addrs []*net.UDPAddr
+	for _, a := range node.AddrList.Addrs {
+		addr, err := net.ResolveUDPAddr("udp", a)
+		if err != nil {
+			return Node{}, err
+		}
+		addrs = append(addrs, addr)
+	}
+	list := adnl.NewAddressList(uint32(node.AddrList.Version), addrs...)
+	list.ReinitDate = uint32(node.AddrList.ReinitDate)
+	list.Priority = uint32(node.AddrList.Priority)
+	list.ExpireAt = uint32(node.AddrList.ExpireAt)
+	return Node{
+		Id:        adnl.NewPublicKey(key),
+		AddrList:  list,
+		Version:   uint32(node.Version),
+		Signature: signature,
+	}, nil
+}
+
+// SignedNode returns this node signed with the transport's identity key.
+func (c *Client) SignedNode() (Node, error) {
+	n := Node{
+		Id:       adnl.NewPublicKey(c.transport.PublicKey()),
+		AddrList: c.transport.AddressList(),
+		Version:  uint32(time.Now().Unix()),
+	}
+	msg, err := n.signingMessage()
+	if err != nil {
+		return Node{}, err
+	}
+	n.Signature = c.transport.Sign(msg)
+	return n, nil
+}
+
+// FindValue looks up a value stored under the key.
+// The returned value is verified according to its update rule.
+func (c *Client) FindValue(ctx context.Context, key Key) (*Value, error) {
+	id, err := key.ID()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := c.localValue(id); ok {
+		return &v, nil
+	}
+	value, _, err := c.lookup(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// FindNodes returns up to k nodes that are the closest to the id.
+func (c *Client) FindNodes(ctx context.Context, id tl.Int256) ([]Node, error) {
+	_, nodes, err := c.lookup(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		res = append(res, n.node)
+	}
+	return res, nil
+}
+
+// Store stores the value at the nodes that are the closest to its key.
+// It returns a number of nodes that accepted the value.
+func (c *Client) Store(ctx context.Context, value Value) (int, error) {
+	if err := value.Verify(); err != nil {
+		return 0, err
+	}
+	id, err := value.Key.Key.ID()
+	if err != nil {
+		return 0, err
+	}
+	// we answer DHT queries, so keep a copy as well
+	if err := c.storeLocal(value); err != nil {
+		return 0, err
+	}
+	_, nodes, err := c.lookup(ctx, id, false)
+	if err != nil {
+		return 0, err
+	}
+	query, err := marshalBoxed(magicStore, value)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stored := 0
+	for _, n := range nodes {
+		wg.Add(1)
+		go func(n knownNode) {
+			defer wg.Done()
+			answer, err := c.query(ctx, n, query)
+			if err != nil || len(answer) < 4 || binary.LittleEndian.Uint32(answer) != magicStored {
+				return
+			}
+			mu.Lock()
+			stored++
+			mu.Unlock()
+		}(n)
+	}
+	wg.Wait()
+	if stored == 0 {
+		return 0, fmt.Errorf("dht: no node accepted the value")
+	}
+	return stored, nil
+}
+
+// ResolveAddress finds a public key and addresses of an ADNL node.
+// The adnl address can be taken from tlb.DNSRecord: tl.Int256(record.DNSAdnlAddress.Address).
+func (c *Client) ResolveAddress(ctx context.Context, adnlAddress tl.Int256) (ed25519.PublicKey, adnl.AddressList, error) {
+	value, err := c.FindValue(ctx, Key{Id: adnlAddress, Name: []byte(addressKeyName)})
+	if err != nil {
+		return nil, adnl.AddressList{}, err
+	}
+	pub, ok := value.Key.Id.Ed25519()
+	if !ok {
+		return nil, adnl.AddressList{}, fmt.Errorf("unsupported key type %v", value.Key.Id.SumType)
+	}
+	var list adnl.AddressList
+	if err := unmarshalBoxed(bytes.NewReader(value.Value), magicAddressList, &list); err != nil {
+		return nil, adnl.AddressList{}, err
+	}
+	return pub, list, nil
+}
+
+// ResolveDNSRecord finds a public key and addresses of an ADNL node referenced by a dns_adnl_address record.
+func (c *Client) ResolveDNSRecord(ctx context.Context, record tlb.DNSRecord) (ed25519.PublicKey, adnl.AddressList, error) {
+	if record.SumType != "DNSAdnlAddress" {
+		return nil, adnl.AddressList{}, fmt.Errorf("not an adnl address record: %v", record.SumType)
+	}
+	return c.ResolveAddress(ctx, tl.Int256(record.DNSAdnlAddress.Address))
+}
+
+// StoreAddress publishes the address list of the transport, so other nodes can resolve it by the ADNL address.
+func (c *Client) StoreAddress(ctx context.Context, ttl time.Duration) error {
+	list, err := marshalBoxed(magicAddressList, c.transport.AddressList())
+	if err != nil {
+		return err
+	}
+	value, err := c.signedValue(addressKeyName, list, ttl)
+	if err != nil {
+		return err
+	}
+	_, err = c.Store(ctx, value)
+	return err
+}
+
+func (c *Client) signedValue(name string, data []byte, ttl time.Duration) (Value, error) {
+	id := adnl.NewPublicKey(c.transport.PublicKey())
+	keyID, err := id.ID()
+	if err != nil {
+		return Value{}, err
+	}
+	v := Value{
+		Key: KeyDescription{
+			Key: Key{Id: keyID, Name: []byte(name)},
+			Id:  id,
+		},
+		Value: data,
+		Ttl:   uint32(time.Now().Add(ttl).Unix()),
+	}
+	v.Key.UpdateRule.SumType = "UpdateRuleSignature"
+	msg, err := v.Key.signingMessage()
+	if err != nil {
+		return Value{}, err
+	}
+	v.Key.Signature = c.transport.Sign(msg)
+	msg, err = v.signingMessage()
+	if err != nil {
+		return Value{}, err
+	}
+	v.Signature = c.transport.Sign(msg)
+	return v, nil
+}
+
+// FindOverlayNodes returns nodes of the overlay with the given full ID,
+// the full ID is a name of the overlay's pub.overlay key.
+// Shard overlays can be found with FindShardOverlayNodes.
+func (c *Client) FindOverlayNodes(ctx context.Context, overlayFullID []byte) ([]OverlayNode, error) {
+	key, err := overlayKey(overlayFullID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.FindValue(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []OverlayNode
+	if err := unmarshalBoxed(bytes.NewReader(value.Value), magicOverlayNodes, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// FindShardOverlayNodes returns nodes of the public overlay of a shard.
+func (c *Client) FindShardOverlayNodes(ctx context.Context, overlay liteclient.OverlayID) ([]OverlayNode, error) {
+	fullID, err := overlay.FullID()
+	if err != nil {
+		return nil, err
+	}
+	return c.FindOverlayNodes(ctx, fullID)
+}
+
+// StoreOverlayNode announces this node as a member of the overlay with the given full ID.
+func (c *Client) StoreOverlayNode(ctx context.Context, overlayFullID []byte, ttl time.Duration) error {
+	key, err := overlayKey(overlayFullID)
+	if err != nil {
+		return err
+	}
+	node := OverlayNode{
+		Id:      adnl.NewPublicKey(c.transport.PublicKey()),
+		Overlay: key.Id,
+		Version: uint32(time.Now().Unix()),
+	}
+	msg, err := node.signingMessage()
+	if err != nil {
+		return err
+	}
+	node.Signature = c.transport.Sign(msg)
+	nodes := []OverlayNode{node}
+	// keep nodes that are already announced, so we don't replace them.
+	if current, err := c.FindOverlayNodes(ctx, overlayFullID); err == nil {
+		for _, n := range current {
+			if len(nodes) >= maxOverlayNodesInDhtRecord {
+				break
+			}
+			if n.Id.PubEd25519 != node.Id.PubEd25519 {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	data, err := marshalBoxed(magicOverlayNodes, nodes)
+	if err != nil {
+		return err
+	}
+	value := Value{
+		Key:   KeyDescription{Key: key},
+		Value: data,
+		Ttl:   uint32(time.Now().Add(ttl).Unix()),
+	}
+	value.Key.Id.SumType = "PubOverlay"
+	value.Key.Id.PubOverlay.Name = overlayFullID
+	value.Key.UpdateRule.SumType = "UpdateRuleOverlayNodes"
+	_, err = c.Store(ctx, value)
+	return err
+}
+
+func overlayKey(overlayFullID []byte) (Key, error) {
+	var pub adnl.PublicKey
+	pub.SumType = "PubOverlay"
+	pub.PubOverlay.Name = overlayFullID
+	id, err := pub.ID()
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Id: id, Name: []byte(overlayNodesKeyName)}, nil
+}
+
+func distance(a, b tl.Int256) tl.Int256 {
+	var d tl.Int256
+	for i := range d {
+		d[i] = a[i] ^ b[i]
+	}
+	return d
+}
+
+func (c *Client) bucketIndex(id tl.Int256) int {
+	d := distance(c.id, id)
+	for i, b := range d {
+		if b != 0 {
+			return i*8 + bits.LeadingZeros8(b)
+		}
+	}
+	return -1 // our own id
+}
+
+// addNode verifies the node and adds it to the routing table.
+func (c *Client) addNode(n Node) error {
+	id, err := n.Verify()
+	if err != nil {
+		return err
+	}
+	if len(n.AddrList.UDPAddrs()) == 0 {
+		return fmt.Errorf("node without udp address")
+	}
+	idx := c.bucketIndex(id)
+	if idx < 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bucket := c.buckets[idx]
+	for i, known := range bucket {
+		if known.id == id {
+			if n.Version >= known.node.Version {
+				bucket[i].node = n
+			}
+			return nil
+		}
+	}
+	if len(bucket) < bucketSize {
+		c.buckets[idx] = append(bucket, knownNode{id: id, node: n})
+	}
+	return nil
+}
+
+func (c *Client) removeNode(id tl.Int256) {
+	idx := c.bucketIndex(id)
+	if idx < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	bucket := c.buckets[idx]
+	for i, known := range bucket {
+		if known.id == id {
+			c.buckets[idx] = append(bucket[:i:i], bucket[i+1:]...)
+			return
+		}
+	}
+}
+
+// closestNodes returns up to count known nodes that are the closest to the id.
+func (c *Client) closestNodes(id tl.Int256, count int) []knownNode {
+	c.mu.Lock()
+	var nodes []knownNode
+	for _, bucket := range c.buckets {
+		nodes = append(nodes, bucket...)
+	}
+	c.mu.Unlock()
+	sortByDistance(nodes, id)
+	if len(nodes) > count {
+		nodes = nodes[:count]
+	}
+	return nodes
+}
+
+func sortByDistance(nodes []knownNode, id tl.Int256) {
+	sort.Slice(nodes, func(i, j int) bool {
+		di, dj := distance(nodes[i].id, id), distance(nodes[j].id, id)
+		return bytes.Compare(di[:], dj[:]) < 0
+	})
+}
+
+func (c *Client) localValue(id tl.Int256) (Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.values[id]
+	if !ok {
+		return Value{}, false
+	}
+	if time.Now().Unix() >= int64(v.Ttl) {
+		delete(c.values, id)
+		return Value{}, false
+	}
+	return v, true
+}
+
+func (c *Client) query(ctx context.Context, n knownNode, query []byte) ([]byte, error) {
+	addrs := n.node.AddrList.UDPAddrs()
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("node without udp address")
+	}
+	pub, _ := n.node.Id.Ed25519() // verified by addNode
+	peer, err := c.transport.Peer(pub, addrs[0])
+	if err != nil {
+		return nil, err
+	}
+	// announce ourselves, so the node can add us to its routing table
+	if len(c.transport.AddressList().Addrs) > 0 {
+		self, err := c.SignedNode()
+		if err != nil {
+			return nil, err
+		}
+		prefix, err := marshalBoxed(magicQuery, self)
+		if err != nil {
+			return nil, err
+		}
+		query = append(prefix, query...)
+	}
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	answer, err := peer.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+type lookupResponse struct {
+	node  knownNode
+	value *Value
+	nodes []Node
+	err   error
+}
+
+// lookup iteratively queries nodes closer and closer to the id.
+// If findValue is set, it stops as soon as a node returns a valid value stored under the id.
+func (c *Client) lookup(ctx context.Context, id tl.Int256, findValue bool) (*Value, []knownNode, error) {
+	candidates := c.closestNodes(id, k)
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("dht: routing table is empty")
+	}
+	queried := map[tl.Int256]bool{}
+	var answered []knownNode
+	tag := uint32(magicFindNode)
+	if findValue {
+		tag = magicFindValue
+	}
+	query, err := marshalBoxed(tag, struct {
+		Key tl.Int256
+		K   uint32
+	}{id, k})
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		var batch []knownNode
+		for _, n := range candidates {
+			if len(batch) == alpha {
+				break
+			}
+			if !queried[n.id] {
+				queried[n.id] = true
+				batch = append(batch, n)
+			}
+		}
+		if len(batch) == 0 {
+			break
+		}
+		responses := make(chan lookupResponse, len(batch))
+		for _, n := range batch {
+			go func(n knownNode) {
+				responses <- c.lookupStep(ctx, n, id, query, findValue)
+			}(n)
+		}
+		for range batch {
+			r := <-responses
+			if r.err != nil {
+				// a query fails because of the caller's context too, it says nothing about the node.
+				if ctx.Err() == nil {
+					c.removeNode(r.node.id)
+				}
+				continue
+			}
+			if r.value != nil {
+				return r.value, nil, nil
+			}
+			answered = append(answered, r.node)
+			for _, n := range r.nodes {
+				nodeID, err := n.Verify()
+				if err != nil || nodeID == c.id || queried[nodeID] {
+					continue
+				}
+				if err := c.addNode(n); err != nil {
+					continue
+				}
+				if !containsNode(candidates, nodeID) {
+					candidates = append(candidates, knownNode{id: nodeID, node: n})
+				}
+			}
+		}
+		sortByDistance(candidates, id)
+		if len(candidates) > k*2 {
+			candidates = candidates[:k*2]
+		}
+	}
+	sortByDistance(answered, id)
+	if len(answered) > k {
+		answered = answered[:k]
+	}
+	if len(answered) == 0 {
+		return nil, nil, fmt.Errorf("dht: no node answered")
+	}
+	return nil, answered, nil
+}
+
+func containsNode(nodes []knownNode, id tl.Int256) bool {
+	for _, n := range nodes {
+		if n.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) lookupStep(ctx context.Context, n knownNode, id tl.Int256, query []byte, findValue bool) lookupResponse {
+	res := lookupResponse{node: n}
+	answer, err := c.query(ctx, n, query)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	r := bytes.NewReader(answer)
+	if !findValue {
+		res.err = unmarshalBoxed(r, magicNodes, &res.nodes)
+		return res
+	}
+	var result valueResult
+	if err := tl.Unmarshal(r, &result); err != nil {
+		res.err = err
+		return res
+	}
+	if !result.found {
+		res.nodes = result.nodes
+		return res
+	}
+	// a node returning a forged value is not trusted anymore
+	valueID, err := result.value.Key.Key.ID()
+	if err != nil || valueID != id {
+		res.err = fmt.Errorf("value for another key")
+		return res
+	}
+	if err := result.value.Verify(); err != nil {
+		res.err = err
+		return res
+	}
+	res.value = &result.value
+	return res
+}
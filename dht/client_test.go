@@ -0,0 +1,180 @@
+package dht
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/adnl"
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+)
+
+func newTestClient(t *testing.T, bootstrap ...*Client) *Client {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	transport, err := adnl.Listen("127.0.0.1:0", key)
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	t.Cleanup(func() { transport.Close() })
+	transport.SetAddressList(adnl.NewAddressList(uint32(time.Now().Unix()), transport.LocalAddr().(*net.UDPAddr)))
+	var nodes []Node
+	for _, c := range bootstrap {
+		node, err := c.SignedNode()
+		if err != nil {
+			t.Fatalf("SignedNode() failed: %v", err)
+		}
+		nodes = append(nodes, node)
+	}
+	client, err := NewClient(transport, nodes)
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	return client
+}
+
+func TestClient(t *testing.T) {
+	bootstrap := newTestClient(t)
+	alice := newTestClient(t, bootstrap)
+	bob := newTestClient(t, bootstrap)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := alice.StoreAddress(ctx, time.Hour); err != nil {
+		t.Fatalf("StoreAddress() failed: %v", err)
+	}
+
+	var record tlb.DNSRecord
+	record.SumType = "DNSAdnlAddress"
+	record.DNSAdnlAddress.Address = alice.transport.ID()
+	pub, list, err := bob.ResolveDNSRecord(ctx, record)
+	if err != nil {
+		t.Fatalf("ResolveDNSRecord() failed: %v", err)
+	}
+	if !bytes.Equal(pub, alice.transport.PublicKey()) {
+		t.Fatalf("resolved public key mismatch")
+	}
+	addrs := list.UDPAddrs()
+	if len(addrs) != 1 || addrs[0].String() != alice.transport.LocalAddr().String() {
+		t.Fatalf("want %v, got: %v", alice.transport.LocalAddr(), addrs)
+	}
+
+	// the bootstrap node has learned about alice from the dht.query prefix.
+	nodes, err := bob.FindNodes(ctx, alice.transport.ID())
+	if err != nil {
+		t.Fatalf("FindNodes() failed: %v", err)
+	}
+	if len(nodes) == 0 || !bytes.Equal(nodes[0].Id.PubEd25519.Key[:], alice.transport.PublicKey()) {
+		t.Fatalf("alice must be the closest node to herself")
+	}
+
+	overlay := liteclient.OverlayID{Workchain: -1, Shard: -0x8000000000000000}
+	fullID, err := overlay.FullID()
+	if err != nil {
+		t.Fatalf("FullID() failed: %v", err)
+	}
+	if err := alice.StoreOverlayNode(ctx, fullID, time.Hour); err != nil {
+		t.Fatalf("StoreOverlayNode() failed: %v", err)
+	}
+	overlayNodes, err := bob.FindShardOverlayNodes(ctx, overlay)
+	if err != nil {
+		t.Fatalf("FindShardOverlayNodes() failed: %v", err)
+	}
+	shortID, err := overlay.ComputeShortID()
+	if err != nil {
+		t.Fatalf("ComputeShortID() failed: %v", err)
+	}
+	if len(overlayNodes) != 1 || overlayNodes[0].Overlay != shortID {
+		t.Fatalf("unexpected overlay nodes: %v", overlayNodes)
+	}
+
+	_, _, err = bob.ResolveAddress(ctx, tl.Int256{1, 2, 3})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("want ErrNotFound, got: %v", err)
+	}
+}
+
+func TestClient_lookupCanceled(t *testing.T) {
+	silent := newTestClient(t)
+	c := newTestClient(t, silent)
+	// the node doesn't answer anymore.
+	silent.transport.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.FindNodes(ctx, silent.transport.ID()); err == nil {
+		t.Fatalf("FindNodes() must fail")
+	}
+	if nodes := c.closestNodes(silent.transport.ID(), k); len(nodes) != 1 {
+		t.Fatalf("the node must stay in the routing table after the caller's timeout")
+	}
+}
+
+func TestValue_Verify(t *testing.T) {
+	c := newTestClient(t)
+	value, err := c.signedValue(addressKeyName, []byte("data"), time.Hour)
+	if err != nil {
+		t.Fatalf("signedValue() failed: %v", err)
+	}
+	if err := value.Verify(); err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	tests := []struct {
+		name    string
+		modify  func(v *Value)
+		wantErr string
+	}{
+		{name: "forged value", modify: func(v *Value) { v.Value = []byte("forged") }, wantErr: "invalid value signature"},
+		{name: "expired", modify: func(v *Value) { v.Ttl = 1 }, wantErr: "value is expired"},
+		{name: "key of another node", modify: func(v *Value) { v.Key.Key.Id[0] ^= 1 }, wantErr: "key id doesn't match"},
+		{name: "anybody with signature", modify: func(v *Value) { v.Key.UpdateRule.SumType = "UpdateRuleAnybody" }, wantErr: "must not be signed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := value
+			tt.modify(&v)
+			err := v.Verify()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("want %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNodeFromConfig(t *testing.T) {
+	c := newTestClient(t)
+	node, err := c.SignedNode()
+	if err != nil {
+		t.Fatalf("SignedNode() failed: %v", err)
+	}
+	cfg := config.DHTNode{
+		Key: base64.StdEncoding.EncodeToString(node.Id.PubEd25519.Key[:]),
+		AddrList: config.DHTAddressList{
+			Addrs:      []string{c.transport.LocalAddr().String()},
+			Version:    int32(node.AddrList.Version),
+			ReinitDate: int32(node.AddrList.ReinitDate),
+		},
+		Version:   int32(node.Version),
+		Signature: base64.StdEncoding.EncodeToString(node.Signature),
+	}
+	converted, err := NodeFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("NodeFromConfig() failed: %v", err)
+	}
+	if _, err := converted.Verify(); err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+}
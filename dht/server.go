@@ -0,0 +1,108 @@
+package dht
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/adnl"
+	"github.com/caigou-xyz/tongo/tl"
+)
+
+// handleQuery answers DHT queries of other nodes.
+func (c *Client) handleQuery(ctx context.Context, peer *adnl.Peer, query []byte) ([]byte, error) {
+	r := bytes.NewReader(query)
+	var tag uint32
+	if err := tl.Unmarshal(r, &tag); err != nil {
+		return nil, err
+	}
+	if tag == magicQuery {
+		// dht.query node:dht.node prefix announces the sender.
+		var node Node
+		if err := tl.Unmarshal(r, &node); err != nil {
+			return nil, err
+		}
+		if id, err := node.Verify(); err == nil && id == peer.ID() {
+			c.addNode(node)
+		}
+		if err := tl.Unmarshal(r, &tag); err != nil {
+			return nil, err
+		}
+	}
+	switch tag {
+	case magicPing:
+		var randomID uint64
+		if err := tl.Unmarshal(r, &randomID); err != nil {
+			return nil, err
+		}
+		return marshalBoxed(magicPong, randomID)
+	case magicFindNode:
+		var req struct {
+			Key tl.Int256
+			K   uint32
+		}
+		if err := tl.Unmarshal(r, &req); err != nil {
+			return nil, err
+		}
+		return marshalBoxed(magicNodes, c.closestNodesForAnswer(req.Key, req.K))
+	case magicFindValue:
+		var req struct {
+			Key tl.Int256
+			K   uint32
+		}
+		if err := tl.Unmarshal(r, &req); err != nil {
+			return nil, err
+		}
+		if v, ok := c.localValue(req.Key); ok {
+			return tl.Marshal(valueResult{found: true, value: v})
+		}
+		return tl.Marshal(valueResult{nodes: c.closestNodesForAnswer(req.Key, req.K)})
+	case magicStore:
+		var value Value
+		if err := tl.Unmarshal(r, &value); err != nil {
+			return nil, err
+		}
+		if err := c.storeLocal(value); err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint32(nil, magicStored), nil
+	case magicGetSignedAddressList:
+		node, err := c.SignedNode()
+		if err != nil {
+			return nil, err
+		}
+		return marshalBoxed(magicNode, node)
+	}
+	return nil, fmt.Errorf("unsupported dht query %x", tag)
+}
+
+func (c *Client) closestNodesForAnswer(id tl.Int256, count uint32) []Node {
+	if count > k {
+		count = k
+	}
+	known := c.closestNodes(id, int(count))
+	nodes := make([]Node, 0, len(known))
+	for _, n := range known {
+		nodes = append(nodes, n.node)
+	}
+	return nodes
+}
+
+// storeLocal verifies the value and saves it if it is newer than the one we have.
+func (c *Client) storeLocal(value Value) error {
+	if err := value.Verify(); err != nil {
+		return err
+	}
+	id, err := value.Key.Key.ID()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.values[id]; ok && current.Ttl > value.Ttl {
+		return nil
+	}
+	c.values[id] = value
+	return nil
+}
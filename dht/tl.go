@@ -0,0 +1,278 @@
+package dht
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/caigou-xyz/tongo/adnl"
+	"github.com/caigou-xyz/tongo/tl"
+)
+
+const (
+	magicNode                  = 0x84533248 // dht.node
+	magicNodes                 = 0x7974a0be // dht.nodes
+	magicKey                   = 0xf667de8f // dht.key
+	magicKeyDescription        = 0x281d4e05 // dht.keyDescription
+	magicValue                 = 0x90ad27cb // dht.value
+	magicPong                  = 0x5a8aef81 // dht.pong
+	magicValueNotFound         = 0xa2620568 // dht.valueNotFound
+	magicValueFound            = 0xe40cf774 // dht.valueFound
+	magicStored                = 0x7026fb08 // dht.stored
+	magicPing                  = 0xcbeb3f18 // dht.ping
+	magicStore                 = 0x34934212 // dht.store
+	magicFindNode              = 0x6ce2ce6b // dht.findNode
+	magicFindValue             = 0xae4b6011 // dht.findValue
+	magicGetSignedAddressList  = 0xa97948ed // dht.getSignedAddressList
+	magicQuery                 = 0x7d530769 // dht.query
+	magicAddressList           = 0x2227e658 // adnl.addressList
+	magicOverlayNode           = 0xb86b8a83 // overlay.node
+	magicOverlayNodes          = 0xe487290e // overlay.nodes
+	magicOverlayNodeToSign     = 0x03d8a8e1 // overlay.node.toSign
+	maxKeyNameLength           = 127
+	maxKeyIdx                  = 15
+	maxValueSize               = 768
+	overlayNodesKeyName        = "nodes"
+	addressKeyName             = "address"
+	maxOverlayNodesInDhtRecord = 20
+)
+
+// marshalBoxed returns a TL representation of v prefixed with the tag.
+func marshalBoxed(tag uint32, v any) ([]byte, error) {
+	b, err := tl.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(b)), tag), b...), nil
+}
+
+// unmarshalBoxed decodes a TL representation of v prefixed with the tag.
+func unmarshalBoxed(r io.Reader, tag uint32, v any) error {
+	var t uint32
+	if err := tl.Unmarshal(r, &t); err != nil {
+		return err
+	}
+	if t != tag {
+		return fmt.Errorf("invalid tag %x, want %x", t, tag)
+	}
+	return tl.Unmarshal(r, v)
+}
+
+// Key is a TL dht.key type.
+type Key struct {
+	Id   tl.Int256
+	Name []byte
+	Idx  uint32
+}
+
+// ID returns an ID of the key in the DHT.
+func (k Key) ID() (tl.Int256, error) {
+	b, err := marshalBoxed(magicKey, k)
+	if err != nil {
+		return tl.Int256{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+// UpdateRule is a TL dht.UpdateRule type.
+type UpdateRule struct {
+	tl.SumType
+	UpdateRuleSignature    struct{} `tlSumType:"cc9f31f7"`
+	UpdateRuleAnybody      struct{} `tlSumType:"61578e14"`
+	UpdateRuleOverlayNodes struct{} `tlSumType:"26779383"`
+}
+
+// KeyDescription is a TL dht.keyDescription type.
+type KeyDescription struct {
+	Key        Key
+	Id         adnl.PublicKey
+	UpdateRule UpdateRule
+	Signature  []byte
+}
+
+func (d KeyDescription) signingMessage() ([]byte, error) {
+	d.Signature = nil
+	return marshalBoxed(magicKeyDescription, d)
+}
+
+// Value is a TL dht.value type.
+type Value struct {
+	Key       KeyDescription
+	Value     []byte
+	Ttl       uint32
+	Signature []byte
+}
+
+func (v Value) signingMessage() ([]byte, error) {
+	v.Signature = nil
+	return marshalBoxed(magicValue, v)
+}
+
+// Verify checks that the value is not expired and follows its update rule.
+func (v Value) Verify() error {
+	if time.Now().Unix() >= int64(v.Ttl) {
+		return fmt.Errorf("value is expired")
+	}
+	if len(v.Key.Key.Name) == 0 || len(v.Key.Key.Name) > maxKeyNameLength || v.Key.Key.Idx > maxKeyIdx {
+		return fmt.Errorf("invalid key")
+	}
+	if len(v.Value) > maxValueSize {
+		return fmt.Errorf("too big value")
+	}
+	id, err := v.Key.Id.ID()
+	if err != nil {
+		return err
+	}
+	if id != v.Key.Key.Id {
+		return fmt.Errorf("key id doesn't match the public key")
+	}
+	switch v.Key.UpdateRule.SumType {
+	case "UpdateRuleSignature":
+		pub, ok := v.Key.Id.Ed25519()
+		if !ok {
+			return fmt.Errorf("unsupported key type %v", v.Key.Id.SumType)
+		}
+		msg, err := v.Key.signingMessage()
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(pub, msg, v.Key.Signature) {
+			return fmt.Errorf("invalid key description signature")
+		}
+		msg, err = v.signingMessage()
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(pub, msg, v.Signature) {
+			return fmt.Errorf("invalid value signature")
+		}
+	case "UpdateRuleAnybody":
+		if len(v.Signature) != 0 {
+			return fmt.Errorf("value must not be signed")
+		}
+	case "UpdateRuleOverlayNodes":
+		if v.Key.Id.SumType != "PubOverlay" {
+			return fmt.Errorf("overlay nodes must be stored under pub.overlay key")
+		}
+		if len(v.Key.Signature) != 0 || len(v.Signature) != 0 {
+			return fmt.Errorf("overlay nodes value must not be signed")
+		}
+		var nodes []OverlayNode
+		if err := unmarshalBoxed(bytes.NewReader(v.Value), magicOverlayNodes, &nodes); err != nil {
+			return err
+		}
+		for _, n := range nodes {
+			if n.Overlay != id {
+				return fmt.Errorf("overlay node of another overlay")
+			}
+			if err := n.Verify(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown update rule %v", v.Key.UpdateRule.SumType)
+	}
+	return nil
+}
+
+// Node is a TL dht.node type.
+type Node struct {
+	Id        adnl.PublicKey
+	AddrList  adnl.AddressList
+	Version   uint32
+	Signature []byte
+}
+
+func (n Node) signingMessage() ([]byte, error) {
+	n.Signature = nil
+	return marshalBoxed(magicNode, n)
+}
+
+// Verify checks the signature of the node and returns its ID.
+func (n Node) Verify() (tl.Int256, error) {
+	pub, ok := n.Id.Ed25519()
+	if !ok {
+		return tl.Int256{}, fmt.Errorf("unsupported key type %v", n.Id.SumType)
+	}
+	msg, err := n.signingMessage()
+	if err != nil {
+		return tl.Int256{}, err
+	}
+	if !ed25519.Verify(pub, msg, n.Signature) {
+		return tl.Int256{}, fmt.Errorf("invalid node signature")
+	}
+	return adnl.KeyID(pub), nil
+}
+
+// OverlayNode is a TL overlay.node type.
+type OverlayNode struct {
+	Id        adnl.PublicKey
+	Overlay   tl.Int256
+	Version   uint32
+	Signature []byte
+}
+
+func (n OverlayNode) signingMessage() ([]byte, error) {
+	id, err := n.Id.ID()
+	if err != nil {
+		return nil, err
+	}
+	return marshalBoxed(magicOverlayNodeToSign, struct {
+		Id      tl.Int256
+		Overlay tl.Int256
+		Version uint32
+	}{id, n.Overlay, n.Version})
+}
+
+// Verify checks the signature of the node.
+func (n OverlayNode) Verify() error {
+	pub, ok := n.Id.Ed25519()
+	if !ok {
+		return fmt.Errorf("unsupported key type %v", n.Id.SumType)
+	}
+	msg, err := n.signingMessage()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, n.Signature) {
+		return fmt.Errorf("invalid overlay node signature")
+	}
+	return nil
+}
+
+// valueResult is a TL dht.ValueResult type.
+type valueResult struct {
+	found bool
+	value Value
+	nodes []Node
+}
+
+func (r valueResult) MarshalTL() ([]byte, error) {
+	if r.found {
+		b, err := marshalBoxed(magicValue, r.value)
+		if err != nil {
+			return nil, err
+		}
+		return append(binary.LittleEndian.AppendUint32(nil, magicValueFound), b...), nil
+	}
+	return marshalBoxed(magicValueNotFound, r.nodes)
+}
+
+func (r *valueResult) UnmarshalTL(reader io.Reader) error {
+	var tag uint32
+	if err := tl.Unmarshal(reader, &tag); err != nil {
+		return err
+	}
+	switch tag {
+	case magicValueFound:
+		r.found = true
+		return unmarshalBoxed(reader, magicValue, &r.value)
+	case magicValueNotFound:
+		return tl.Unmarshal(reader, &r.nodes)
+	}
+	return fmt.Errorf("invalid dht.ValueResult tag %x", tag)
+}
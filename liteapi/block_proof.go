@@ -0,0 +1,631 @@
+package liteapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math/bits"
+	"sort"
+	"sync"
+
+	"github.com/caigou-xyz/tongo/adnl"
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+const (
+	// magicTonBlockID is a tag of ton.blockId TL type whose serialization is signed by validators.
+	magicTonBlockID = 0xc50b6e70 // crc32("ton.blockId root_cell_hash:int256 file_hash:int256 = ton.BlockId")
+	// magicValidatorSet is a tag of test0.validatorSet TL type used to calculate a short hash of a validator set.
+	magicValidatorSet = 0xb7be4167 // crc32("test0.validatorSet catchain_seqno:int validators:vector test0.validatorSetItem = test0.ValidatorSet")
+
+	masterchainShard  = 0x8000000000000000
+	masterchainID     = -1
+	catchainConfigIdx = 28
+)
+
+// VerifyBlockProof checks a chain of masterchain blocks returned by liteServer.getBlockProof
+// and returns the last block of the chain.
+// The first block of the chain must be trusted, for example,
+// it can be the init block of the global configuration file.
+//
+// A forward link of the chain is checked with signatures of validators
+// taken from the configuration of a key block that has already been proven.
+// A backward link is checked with a list of previous blocks stored in the state of a proven block.
+func VerifyBlockProof(trusted ton.BlockIDExt, proof liteclient.LiteServerPartialBlockProofC) (ton.BlockIDExt, error) {
+	last, _, err := verifyBlockProof(trusted, proof)
+	return last, err
+}
+
+// verifyBlockProof checks a chain of masterchain blocks
+// and returns the last block of the chain and the latest key block proven by the chain.
+// The trusted block is returned as a key block if the chain contains no key blocks.
+func verifyBlockProof(trusted ton.BlockIDExt, proof liteclient.LiteServerPartialBlockProofC) (ton.BlockIDExt, ton.BlockIDExt, error) {
+	if proof.From.ToBlockIdExt() != trusted {
+		return ton.BlockIDExt{}, ton.BlockIDExt{}, fmt.Errorf("%w: proof starts from block %v instead of %v", ErrInvalidProof, proof.From.ToBlockIdExt(), trusted)
+	}
+	current, keyBlock := trusted, trusted
+	for i, step := range proof.Steps {
+		var (
+			from, to   ton.BlockIDExt
+			toKeyBlock bool
+			err        error
+		)
+		switch step.SumType {
+		case "LiteServerBlockLinkBack":
+			link := step.LiteServerBlockLinkBack
+			from, to, toKeyBlock = link.From.ToBlockIdExt(), link.To.ToBlockIdExt(), link.ToKeyBlock
+			if from == current {
+				err = checkBlockLinkBack(from, to, link.ToKeyBlock, link.DestProof, link.Proof, link.StateProof)
+			}
+		case "LiteServerBlockLinkForward":
+			link := step.LiteServerBlockLinkForward
+			from, to, toKeyBlock = link.From.ToBlockIdExt(), link.To.ToBlockIdExt(), link.ToKeyBlock
+			if from == current {
+				err = checkBlockLinkForward(from, to, link.ToKeyBlock, link.DestProof, link.ConfigProof, liteclient.LiteServerSignatureSetC(link.Signatures))
+			}
+		default:
+			err = fmt.Errorf("unknown link type %v", step.SumType)
+		}
+		if from != current {
+			err = fmt.Errorf("link starts from block %v instead of %v", from, current)
+		}
+		if err != nil {
+			return ton.BlockIDExt{}, ton.BlockIDExt{}, fmt.Errorf("%w: step %v: %v", ErrInvalidProof, i, err)
+		}
+		current = to
+		if toKeyBlock {
+			keyBlock = to
+		}
+	}
+	if proof.To.ToBlockIdExt() != current {
+		return ton.BlockIDExt{}, ton.BlockIDExt{}, fmt.Errorf("%w: proof ends with block %v instead of %v", ErrInvalidProof, current, proof.To.ToBlockIdExt())
+	}
+	return current, keyBlock, nil
+}
+
+// checkBlockLinkBack checks liteServer.blockLinkBack:
+// the state of the "from" block must contain the "to" block in its list of previous blocks.
+func checkBlockLinkBack(from, to ton.BlockIDExt, toKeyBlock bool, destProof, proof, stateProof []byte) error {
+	if from.Workchain != masterchainID || to.Workchain != masterchainID {
+		return fmt.Errorf("both blocks of a link must be masterchain blocks")
+	}
+	if to.Seqno >= from.Seqno {
+		return fmt.Errorf("backward link from block %v to block %v", from.Seqno, to.Seqno)
+	}
+	blockProof, err := boc.DeserializeSingleRootBoc(proof)
+	if err != nil {
+		return err
+	}
+	stateHash, err := blockStateHash(blockProof, from)
+	if err != nil {
+		return err
+	}
+	stateProofCell, err := boc.DeserializeSingleRootBoc(stateProof)
+	if err != nil {
+		return err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProofCell, stateHash)
+	if err != nil {
+		return fmt.Errorf("masterchain state: %w", err)
+	}
+	ref, err := findPrevBlock(stateRoot, to.Seqno)
+	if err != nil {
+		return err
+	}
+	if ref.BlkRef.SeqNo != to.Seqno || ton.Bits256(ref.BlkRef.RootHash) != to.RootHash || ton.Bits256(ref.BlkRef.FileHash) != to.FileHash {
+		return fmt.Errorf("block %v is not a previous block of %v", to, from)
+	}
+	if toKeyBlock && !ref.Key {
+		return fmt.Errorf("block %v is not a key block", to)
+	}
+	if len(destProof) == 0 {
+		return nil
+	}
+	destProofCell, err := boc.DeserializeSingleRootBoc(destProof)
+	if err != nil {
+		return err
+	}
+	info, err := blockInfo(destProofCell, to)
+	if err != nil {
+		return err
+	}
+	if info.KeyBlock != toKeyBlock {
+		return fmt.Errorf("key block flag mismatch for block %v", to)
+	}
+	return nil
+}
+
+// checkBlockLinkForward checks liteServer.blockLinkForward:
+// the "to" block must be signed by validators from the configuration of the "from" key block.
+func checkBlockLinkForward(from, to ton.BlockIDExt, toKeyBlock bool, destProof, configProof []byte, signatures liteclient.LiteServerSignatureSetC) error {
+	if from.Workchain != masterchainID || to.Workchain != masterchainID {
+		return fmt.Errorf("both blocks of a link must be masterchain blocks")
+	}
+	if to.Seqno <= from.Seqno {
+		return fmt.Errorf("forward link from block %v to block %v", from.Seqno, to.Seqno)
+	}
+	destProofCell, err := boc.DeserializeSingleRootBoc(destProof)
+	if err != nil {
+		return err
+	}
+	info, err := blockInfo(destProofCell, to)
+	if err != nil {
+		return err
+	}
+	if info.KeyBlock != toKeyBlock {
+		return fmt.Errorf("key block flag mismatch for block %v", to)
+	}
+	configProofCell, err := boc.DeserializeSingleRootBoc(configProof)
+	if err != nil {
+		return err
+	}
+	config, err := keyBlockConfig(configProofCell, from)
+	if err != nil {
+		return err
+	}
+	validators, err := blockValidators(config, info)
+	if err != nil {
+		return err
+	}
+	return checkBlockSignatures(validators, signatures, to, info)
+}
+
+// blockInfo checks a merkle proof of a block's header and returns information about the block.
+func blockInfo(proof *boc.Cell, blockID ton.BlockIDExt) (tlb.BlockInfoPart, error) {
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		return tlb.BlockInfoPart{}, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	var header struct {
+		Magic tlb.Magic `tlb:"block#11ef55aa"`
+		Info  struct {
+			Magic tlb.Magic `tlb:"block_info#9bc7a987"`
+			Info  tlb.BlockInfoPart
+		} `tlb:"^"`
+	}
+	if root.RefsSize() == 0 || root.Refs()[0].CellType() == boc.PrunedBranchCell {
+		return tlb.BlockInfoPart{}, fmt.Errorf("block %v: info is pruned", blockID)
+	}
+	if err := tlb.Unmarshal(root, &header); err != nil {
+		return tlb.BlockInfoPart{}, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	info := header.Info.Info
+	if blockIDFromInfo(info) != blockID.BlockID {
+		return tlb.BlockInfoPart{}, fmt.Errorf("block %v: block info doesn't match block id", blockID)
+	}
+	return info, nil
+}
+
+// blockIDFromInfo returns an id of a block described by the given info.
+func blockIDFromInfo(info tlb.BlockInfoPart) ton.BlockID {
+	return ton.BlockID{
+		Workchain: info.Shard.WorkchainID,
+		Shard:     uint64(info.Shard.ShardPrefix) | (1 << (63 - uint64(info.Shard.ShardPfxBits))),
+		Seqno:     info.SeqNo,
+	}
+}
+
+// keyBlockConfig checks a merkle proof of a key block and returns the root cell of the blockchain configuration
+// stored in the block: a hashmap with config params.
+func keyBlockConfig(proof *boc.Cell, blockID ton.BlockIDExt) (*boc.Cell, error) {
+	custom, err := mcBlockExtra(proof, blockID)
+	if err != nil {
+		return nil, err
+	}
+	// masterchain_block_extra#cca5 key_block:(## 1) shard_hashes:ShardHashes shard_fees:ShardFees
+	// ^[ prev_blk_signatures:(HashmapE 16 CryptoSignaturePair) recover_create_msg:(Maybe ^InMsg) mint_msg:(Maybe ^InMsg) ]
+	// config:key_block?ConfigParams = McBlockExtra;
+	if tag, err := custom.ReadUint(16); err != nil || tag != mcBlockExtraTag {
+		return nil, fmt.Errorf("block %v: invalid McBlockExtra", blockID)
+	}
+	keyBlock, err := custom.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !keyBlock {
+		return nil, fmt.Errorf("block %v is not a key block", blockID)
+	}
+	// _ (HashmapE 32 ^(BinTree ShardDescr)) = ShardHashes;
+	if err := skipMaybeRef(custom); err != nil {
+		return nil, err
+	}
+	// _ (HashmapAugE 96 ShardFeeCreated ShardFeeCreated) = ShardFees;
+	// fees$_ fees:CurrencyCollection create:CurrencyCollection = ShardFeeCreated;
+	if err := skipMaybeRef(custom); err != nil {
+		return nil, err
+	}
+	for i := 0; i < 2; i++ {
+		if err := skipCurrencyCollection(custom); err != nil {
+			return nil, err
+		}
+	}
+	if err := custom.SkipRef(); err != nil {
+		return nil, err
+	}
+	// _ config_addr:bits256 config:^(Hashmap 32 ^Cell) = ConfigParams;
+	if err := custom.Skip(256); err != nil {
+		return nil, err
+	}
+	config, err := custom.NextRef()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// mcBlockExtra checks a merkle proof of a masterchain block and returns a cell with McBlockExtra of the block.
+func mcBlockExtra(proof *boc.Cell, blockID ton.BlockIDExt) (*boc.Cell, error) {
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		return nil, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	refs := root.Refs()
+	if len(refs) != 4 || refs[3].CellType() == boc.PrunedBranchCell {
+		return nil, fmt.Errorf("block %v: block extra is pruned", blockID)
+	}
+	// block_extra in_msg_descr:^InMsgDescr out_msg_descr:^OutMsgDescr account_blocks:^ShardAccountBlocks
+	// rand_seed:bits256 created_by:bits256 custom:(Maybe ^McBlockExtra) = BlockExtra;
+	extraCell := refs[3]
+	extraCell.ResetCounters()
+	if magic, err := extraCell.ReadUint(32); err != nil || magic != blockExtraMagic {
+		return nil, fmt.Errorf("block %v: invalid block extra", blockID)
+	}
+	for i := 0; i < 3; i++ {
+		if err := extraCell.SkipRef(); err != nil {
+			return nil, err
+		}
+	}
+	if err := extraCell.Skip(256 + 256); err != nil {
+		return nil, err
+	}
+	hasCustom, err := extraCell.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !hasCustom {
+		return nil, fmt.Errorf("block %v is not a masterchain block", blockID)
+	}
+	custom, err := extraCell.NextRef()
+	if err != nil {
+		return nil, fmt.Errorf("block %v: McBlockExtra: %w", blockID, err)
+	}
+	return custom, nil
+}
+
+// configParam decodes a config param with the given index.
+// It returns false if the configuration doesn't contain the param.
+func configParam(config *boc.Cell, index uint32, param any) (bool, error) {
+	value, err := findUint32Key(config, index)
+	if err != nil || value == nil {
+		return false, err
+	}
+	cell, err := value.NextRef()
+	if err != nil {
+		return false, err
+	}
+	if cell.CellType() == boc.PrunedBranchCell {
+		return false, fmt.Errorf("config param %v is pruned", index)
+	}
+	if err := tlb.Unmarshal(cell, param); err != nil {
+		return false, fmt.Errorf("failed to decode config param %v: %w", index, err)
+	}
+	return true, nil
+}
+
+// blockValidator is a validator that signs masterchain blocks.
+type blockValidator struct {
+	publicKey ed25519.PublicKey
+	weight    uint64
+}
+
+// blockValidators returns validators that must have signed a masterchain block with the given info.
+// Validators are selected from the current and the next validator sets of the configuration (params 34 and 36)
+// and the set is confirmed by the validator list hash stored in the block.
+func blockValidators(config *boc.Cell, info tlb.BlockInfoPart) ([]blockValidator, error) {
+	var catchainConfig tlb.ConfigParam28
+	found, err := configParam(config, catchainConfigIdx, &catchainConfig)
+	if err != nil {
+		return nil, err
+	}
+	shuffle := found && catchainConfig.CatchainConfig.SumType == "CatchainConfigNew" &&
+		catchainConfig.CatchainConfig.CatchainConfigNew.ShuffleMcValidators
+
+	var current tlb.ConfigParam34
+	var next tlb.ConfigParam36
+	sets := []struct {
+		index uint32
+		param any
+		set   *tlb.ValidatorSet
+	}{
+		{index: 34, param: &current, set: &current.CurValidators},
+		{index: 36, param: &next, set: &next.NextValidators},
+	}
+	for _, s := range sets {
+		found, err := configParam(config, s.index, s.param)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		validators, err := masterchainValidators(*s.set, shuffle, info.GenCatchainSeqno)
+		if err != nil {
+			return nil, err
+		}
+		if validatorSetHash(info.GenCatchainSeqno, validators) == info.GenValidatorListHashShort {
+			return validators, nil
+		}
+	}
+	return nil, fmt.Errorf("no validator set with hash %x in the config", info.GenValidatorListHashShort)
+}
+
+// masterchainValidators returns validators of the masterchain selected from the validator set.
+// The algorithm follows Config::do_compute_validator_set of the reference implementation.
+func masterchainValidators(set tlb.ValidatorSet, shuffle bool, catchainSeqno uint32) ([]blockValidator, error) {
+	var (
+		total, main uint16
+		items       []tlb.HashmapItem[tlb.Uint16, tlb.ValidatorDescr]
+	)
+	switch set.SumType {
+	case "Validators":
+		total, main, items = set.Validators.Total, set.Validators.Main, set.Validators.List.Items()
+	case "ValidatorsExt":
+		total, main, items = set.ValidatorsExt.Total, set.ValidatorsExt.Main, set.ValidatorsExt.List.Items()
+	default:
+		return nil, fmt.Errorf("unknown validator set type %v", set.SumType)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	count := int(min(total, main))
+	if count == 0 || count > len(items) {
+		return nil, fmt.Errorf("invalid validator set")
+	}
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	if shuffle {
+		prng := newValidatorSetPRNG(masterchainID, masterchainShard, catchainSeqno)
+		for i := 0; i < count; i++ {
+			j := prng.nextRanged(uint64(i + 1))
+			indexes[i] = indexes[j]
+			indexes[j] = i
+		}
+	}
+	validators := make([]blockValidator, 0, count)
+	for _, idx := range indexes {
+		descr := items[idx].Value
+		var weight uint64
+		switch descr.SumType {
+		case "Validator":
+			weight = descr.Validator.Weight
+		case "ValidatorAddr":
+			weight = descr.ValidatorAddr.Weight
+		default:
+			return nil, fmt.Errorf("unknown validator description type %v", descr.SumType)
+		}
+		pubKey := descr.PubKey()
+		validators = append(validators, blockValidator{
+			publicKey: ed25519.PublicKey(pubKey[:]),
+			weight:    weight,
+		})
+	}
+	return validators, nil
+}
+
+// validatorSetPRNG is a pseudo-random generator used to shuffle validators.
+// It follows ValidatorSetPRNG of the reference implementation.
+type validatorSetPRNG struct {
+	// data is seed:bits256 shard:int64 workchain:int32 cc_seqno:uint32, all big-endian.
+	data [48]byte
+	hash [64]byte
+	pos  int
+}
+
+func newValidatorSetPRNG(workchain int32, shard uint64, catchainSeqno uint32) *validatorSetPRNG {
+	prng := &validatorSetPRNG{pos: 8}
+	binary.BigEndian.PutUint64(prng.data[32:], shard)
+	binary.BigEndian.PutUint32(prng.data[40:], uint32(workchain))
+	binary.BigEndian.PutUint32(prng.data[44:], catchainSeqno)
+	return prng
+}
+
+func (p *validatorSetPRNG) next() uint64 {
+	if p.pos == 8 {
+		p.hash = sha512.Sum512(p.data[:])
+		p.pos = 0
+		// increment the seed as a big-endian number.
+		for i := 31; i >= 0; i-- {
+			p.data[i]++
+			if p.data[i] != 0 {
+				break
+			}
+		}
+	}
+	value := binary.BigEndian.Uint64(p.hash[p.pos*8:])
+	p.pos++
+	return value
+}
+
+// nextRanged returns a pseudo-random number in [0, n).
+func (p *validatorSetPRNG) nextRanged(n uint64) uint64 {
+	hi, _ := bits.Mul64(n, p.next())
+	return hi
+}
+
+// validatorSetHash returns a short hash of validators as it is stored in a header of a block signed by them.
+func validatorSetHash(catchainSeqno uint32, validators []blockValidator) uint32 {
+	buf := make([]byte, 0, 12+len(validators)*40)
+	buf = binary.LittleEndian.AppendUint32(buf, magicValidatorSet)
+	buf = binary.LittleEndian.AppendUint32(buf, catchainSeqno)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(validators)))
+	for _, v := range validators {
+		id := adnl.KeyID(v.publicKey)
+		buf = append(buf, id[:]...)
+		buf = binary.LittleEndian.AppendUint64(buf, v.weight)
+	}
+	return crc32.Checksum(buf, crc32.MakeTable(crc32.Castagnoli))
+}
+
+// checkBlockSignatures checks that validators with more than 2/3 of the total weight signed the block.
+func checkBlockSignatures(validators []blockValidator, signatures liteclient.LiteServerSignatureSetC, blockID ton.BlockIDExt, info tlb.BlockInfoPart) error {
+	if signatures.CatchainSeqno != info.GenCatchainSeqno {
+		return fmt.Errorf("signatures of catchain %v for block of catchain %v", signatures.CatchainSeqno, info.GenCatchainSeqno)
+	}
+	if signatures.ValidatorSetHash != info.GenValidatorListHashShort {
+		return fmt.Errorf("signatures of validator set %x for block of validator set %x", signatures.ValidatorSetHash, info.GenValidatorListHashShort)
+	}
+	message := make([]byte, 0, 68)
+	message = binary.LittleEndian.AppendUint32(message, magicTonBlockID)
+	message = append(message, blockID.RootHash[:]...)
+	message = append(message, blockID.FileHash[:]...)
+
+	byID := make(map[tl.Int256]blockValidator, len(validators))
+	var totalWeight uint64
+	for _, v := range validators {
+		byID[adnl.KeyID(v.publicKey)] = v
+		totalWeight += v.weight
+	}
+	var signedWeight uint64
+	for _, sig := range signatures.Signatures {
+		v, ok := byID[sig.NodeIdShort]
+		if !ok {
+			return fmt.Errorf("signature of unknown validator %x", sig.NodeIdShort[:])
+		}
+		delete(byID, sig.NodeIdShort)
+		if !ed25519.Verify(v.publicKey, message, sig.Signature) {
+			return fmt.Errorf("invalid signature of validator %x", sig.NodeIdShort[:])
+		}
+		signedWeight += v.weight
+	}
+	// signedWeight * 3 > totalWeight * 2 without overflows.
+	signedHi, signedLo := bits.Mul64(signedWeight, 3)
+	totalHi, totalLo := bits.Mul64(totalWeight, 2)
+	if signedHi < totalHi || (signedHi == totalHi && signedLo <= totalLo) {
+		return fmt.Errorf("block is signed by validators with weight %v of %v", signedWeight, totalWeight)
+	}
+	return nil
+}
+
+// findPrevBlock returns a reference to a previous masterchain block from a proof of a masterchain state.
+func findPrevBlock(stateRoot *boc.Cell, seqno uint32) (tlb.KeyExtBlkRef, error) {
+	extra, err := mcStateExtra(stateRoot)
+	if err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	// masterchain_state_extra#cc26 shard_hashes:ShardHashes config:ConfigParams
+	// ^[ flags:(## 16) validator_info:ValidatorInfo prev_blocks:OldMcBlocksInfo ... ] ...
+	if err := skipMaybeRef(extra); err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	if err := extra.Skip(256); err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	if err := extra.SkipRef(); err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	other, err := extra.NextRef()
+	if err != nil {
+		return tlb.KeyExtBlkRef{}, fmt.Errorf("list of previous blocks: %w", err)
+	}
+	var otherPrefix struct {
+		Flags         uint16
+		ValidatorInfo tlb.ValidatorInfo
+	}
+	if err := tlb.Unmarshal(other, &otherPrefix); err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	// _ (HashmapAugE 32 KeyExtBlkRef KeyMaxLt) = OldMcBlocksInfo;
+	notEmpty, err := other.ReadBit()
+	if err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	if !notEmpty {
+		return tlb.KeyExtBlkRef{}, fmt.Errorf("block %v not found in previous blocks", seqno)
+	}
+	root, err := other.NextRef()
+	if err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	value, err := findUint32Key(root, seqno)
+	if err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	if value == nil {
+		return tlb.KeyExtBlkRef{}, fmt.Errorf("block %v not found in previous blocks", seqno)
+	}
+	var item struct {
+		Extra tlb.KeyMaxLt
+		Value tlb.KeyExtBlkRef
+	}
+	if err := tlb.Unmarshal(value, &item); err != nil {
+		return tlb.KeyExtBlkRef{}, err
+	}
+	return item.Value, nil
+}
+
+// trustedChain keeps masterchain blocks proven starting from a trusted block.
+type trustedChain struct {
+	mu sync.Mutex
+	// keyBlock is the latest proven key block, new blocks are proven starting from it.
+	keyBlock ton.BlockIDExt
+	// last is the latest proven block.
+	last ton.BlockIDExt
+}
+
+func newTrustedChain(trusted ton.BlockIDExt) *trustedChain {
+	return &trustedChain{keyBlock: trusted, last: trusted}
+}
+
+// proveMasterchainBlock checks that the given masterchain block belongs to the chain of the trusted block.
+// It does nothing if the client is not configured with a trusted block.
+func (c *Client) proveMasterchainBlock(ctx context.Context, block ton.BlockIDExt) error {
+	if c.trustedChain == nil {
+		return nil
+	}
+	c.trustedChain.mu.Lock()
+	known, last := c.trustedChain.keyBlock, c.trustedChain.last
+	c.trustedChain.mu.Unlock()
+	if block == known || block == last {
+		return nil
+	}
+	keyBlock := known
+	for {
+		res, err := c.GetBlockProofRaw(ctx, known, &block)
+		if err != nil {
+			return err
+		}
+		proven, provenKeyBlock, err := verifyBlockProof(known, res)
+		if err != nil {
+			return err
+		}
+		if provenKeyBlock.Seqno > keyBlock.Seqno {
+			keyBlock = provenKeyBlock
+		}
+		if res.Complete {
+			if proven != block {
+				return fmt.Errorf("%w: proof ends with block %v instead of %v", ErrInvalidProof, proven, block)
+			}
+			break
+		}
+		if proven == known {
+			return fmt.Errorf("%w: incomplete proof without steps", ErrInvalidProof)
+		}
+		known = proven
+	}
+	c.trustedChain.mu.Lock()
+	defer c.trustedChain.mu.Unlock()
+	if keyBlock.Seqno > c.trustedChain.keyBlock.Seqno {
+		c.trustedChain.keyBlock = keyBlock
+	}
+	if block.Seqno > c.trustedChain.last.Seqno {
+		c.trustedChain.last = block
+	}
+	return nil
+}
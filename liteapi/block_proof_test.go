@@ -0,0 +1,492 @@
+package liteapi
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/adnl"
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type testConfigParam struct {
+	Param boc.Cell `tlb:"^"`
+}
+
+type testBlockInfo struct {
+	Magic tlb.Magic `tlb:"block_info#9bc7a987"`
+	Info  tlb.BlockInfoPart
+}
+
+type testBlockExtra struct {
+	Magic         tlb.Magic `tlb:"block_extra#4a33f6fd"`
+	InMsgDescr    boc.Cell  `tlb:"^"`
+	OutMsgDescr   boc.Cell  `tlb:"^"`
+	AccountBlocks boc.Cell  `tlb:"^"`
+	RandSeed      tlb.Bits256
+	CreatedBy     tlb.Bits256
+	Custom        *boc.Cell `tlb:"maybe^"`
+}
+
+type testMcBlockExtra struct {
+	Magic          tlb.Magic `tlb:"masterchain_block_extra#cca5"`
+	KeyBlock       bool
+	ShardHashes    tlb.HashmapE[tlb.Uint32, testBinTreeRef]
+	ShardFees      bool
+	ShardFeesExtra tlb.ShardFeeCreated
+	Other          boc.Cell `tlb:"^"`
+	ConfigAddr     tlb.Bits256
+	Config         tlb.Hashmap[tlb.Uint32, testConfigParam] `tlb:"^"`
+}
+
+type testValidator struct {
+	privateKey ed25519.PrivateKey
+	weight     uint64
+}
+
+func testValidators(n int) []testValidator {
+	validators := make([]testValidator, 0, n)
+	for i := 0; i < n; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(i + 1)
+		validators = append(validators, testValidator{
+			privateKey: ed25519.NewKeyFromSeed(seed),
+			weight:     uint64(10 + i),
+		})
+	}
+	return validators
+}
+
+func testValidatorSet(validators []testValidator) tlb.ValidatorSet {
+	var set tlb.ValidatorSet
+	set.SumType = "Validators"
+	set.Validators.Total = uint16(len(validators))
+	set.Validators.Main = uint16(len(validators))
+	var keys []tlb.Uint16
+	var descrs []tlb.ValidatorDescr
+	for i, v := range validators {
+		var descr tlb.ValidatorDescr
+		descr.SumType = "Validator"
+		descr.Validator = &struct {
+			PublicKey tlb.SigPubKey
+			Weight    uint64
+		}{Weight: v.weight}
+		copy(descr.Validator.PublicKey.PubKey[:], v.privateKey.Public().(ed25519.PublicKey))
+		keys = append(keys, tlb.Uint16(i))
+		descrs = append(descrs, descr)
+	}
+	set.Validators.List = tlb.NewHashmap(keys, descrs)
+	return set
+}
+
+func testBlockInfoCell(t *testing.T, seqno uint32, keyBlock bool, validatorSetHash, catchainSeqno uint32) *boc.Cell {
+	t.Helper()
+	var info testBlockInfo
+	info.Info.SeqNo = seqno
+	info.Info.KeyBlock = keyBlock
+	info.Info.Shard.WorkchainID = -1
+	info.Info.GenValidatorListHashShort = validatorSetHash
+	info.Info.GenCatchainSeqno = catchainSeqno
+	return testutil.MustMarshal(t, info)
+}
+
+func signBlock(validators []testValidator, blockID ton.BlockIDExt, catchainSeqno, validatorSetHash uint32) liteclient.LiteServerSignatureSetC {
+	message := binary.LittleEndian.AppendUint32(nil, magicTonBlockID)
+	message = append(message, blockID.RootHash[:]...)
+	message = append(message, blockID.FileHash[:]...)
+	set := liteclient.LiteServerSignatureSetC{
+		ValidatorSetHash: validatorSetHash,
+		CatchainSeqno:    catchainSeqno,
+	}
+	for _, v := range validators {
+		set.Signatures = append(set.Signatures, liteclient.LiteServerSignatureC{
+			NodeIdShort: adnl.KeyID(v.privateKey.Public().(ed25519.PublicKey)),
+			Signature:   ed25519.Sign(v.privateKey, message),
+		})
+	}
+	return set
+}
+
+// testChain contains a key block, a block signed by validators of the key block
+// and a block between them which is proven by the state of the signed block.
+type testChain struct {
+	validators []testValidator
+	// catchainSeqno and validatorSetHash are values stored in the header of the signed block.
+	catchainSeqno    uint32
+	validatorSetHash uint32
+
+	keyBlock    ton.BlockIDExt
+	keyBlockBoc []byte
+
+	prevBlock    ton.BlockIDExt
+	prevBlockBoc []byte
+
+	block      ton.BlockIDExt
+	blockBoc   []byte
+	blockState []byte
+}
+
+func newTestChain(t *testing.T) testChain {
+	t.Helper()
+	chain := testChain{
+		validators:    testValidators(5),
+		catchainSeqno: 7,
+	}
+	var catchainConfig tlb.ConfigParam28
+	catchainConfig.CatchainConfig.SumType = "CatchainConfigNew"
+	catchainConfig.CatchainConfig.CatchainConfigNew.ShuffleMcValidators = true
+	currentValidators := tlb.ConfigParam34{CurValidators: testValidatorSet(chain.validators)}
+	config := tlb.NewHashmap(
+		[]tlb.Uint32{28, 34},
+		[]testConfigParam{
+			{Param: *testutil.MustMarshal(t, catchainConfig)},
+			{Param: *testutil.MustMarshal(t, currentValidators)},
+		})
+	mcExtra := testutil.MustMarshal(t, testMcBlockExtra{
+		KeyBlock: true,
+		Other:    *testutil.CellWithUint(t, 5),
+		Config:   config,
+	})
+	extra := testutil.MustMarshal(t, testBlockExtra{
+		InMsgDescr:    *testutil.CellWithUint(t, 6),
+		OutMsgDescr:   *testutil.CellWithUint(t, 7),
+		AccountBlocks: *testutil.CellWithUint(t, 8),
+		Custom:        mcExtra,
+	})
+	keyBlock := testBlockCell(t, testBlockInfoCell(t, 10, true, 0, 0), testutil.CellWithUint(t, 10), extra)
+	chain.keyBlock = ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: -1, Shard: masterchainShard, Seqno: 10},
+		RootHash: testutil.MustHash(t, keyBlock),
+		FileHash: ton.Bits256{10},
+	}
+	chain.keyBlockBoc = testutil.MustBoc(t, merkleProof(t, keyBlock))
+
+	prevBlock := testBlockCell(t, testBlockInfoCell(t, 20, false, 0, 0), testutil.CellWithUint(t, 20), testutil.CellWithUint(t, 2))
+	chain.prevBlock = ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: -1, Shard: masterchainShard, Seqno: 20},
+		RootHash: testutil.MustHash(t, prevBlock),
+		FileHash: ton.Bits256{20},
+	}
+	chain.prevBlockBoc = testutil.MustBoc(t, merkleProof(t, prevBlock))
+
+	shuffled, err := masterchainValidators(currentValidators.CurValidators, true, chain.catchainSeqno)
+	if err != nil {
+		t.Fatalf("masterchainValidators() failed: %v", err)
+	}
+	chain.validatorSetHash = validatorSetHash(chain.catchainSeqno, shuffled)
+
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           30,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 3),
+		Other:           *testutil.CellWithUint(t, 4),
+		Custom: testutil.MustMarshal(t, testMcStateExtra{
+			Config: *testutil.CellWithUint(t, 5),
+			Other: testMcStateOther{
+				PrevBlocks: tlb.NewHashmapE(
+					[]tlb.Uint32{10, 20},
+					[]testPrevBlock{
+						{Value: tlb.KeyExtBlkRef{Key: true, BlkRef: tlb.ExtBlkRef{
+							SeqNo:    10,
+							RootHash: tlb.Bits256(chain.keyBlock.RootHash),
+							FileHash: tlb.Bits256(chain.keyBlock.FileHash),
+						}}},
+						{Value: tlb.KeyExtBlkRef{BlkRef: tlb.ExtBlkRef{
+							SeqNo:    20,
+							RootHash: tlb.Bits256(chain.prevBlock.RootHash),
+							FileHash: tlb.Bits256(chain.prevBlock.FileHash),
+						}}},
+					}),
+			},
+		}),
+	})
+	block := testBlockCell(t, testBlockInfoCell(t, 30, false, chain.validatorSetHash, chain.catchainSeqno), state, testutil.CellWithUint(t, 2))
+	chain.block = ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: -1, Shard: masterchainShard, Seqno: 30},
+		RootHash: testutil.MustHash(t, block),
+		FileHash: ton.Bits256{30},
+	}
+	chain.blockBoc = testutil.MustBoc(t, merkleProof(t, block))
+	chain.blockState = testutil.MustBoc(t, merkleProof(t, state))
+	return chain
+}
+
+func (c testChain) forward(signatures liteclient.LiteServerSignatureSetC) liteclient.LiteServerBlockLink {
+	link := liteclient.LiteServerBlockLink{SumType: "LiteServerBlockLinkForward"}
+	link.LiteServerBlockLinkForward.From = liteclient.BlockIDExt(c.keyBlock)
+	link.LiteServerBlockLinkForward.To = liteclient.BlockIDExt(c.block)
+	link.LiteServerBlockLinkForward.DestProof = c.blockBoc
+	link.LiteServerBlockLinkForward.ConfigProof = c.keyBlockBoc
+	link.LiteServerBlockLinkForward.Signatures = liteclient.LiteServerSignatureSet(signatures)
+	return link
+}
+
+func (c testChain) back(to ton.BlockIDExt, toKeyBlock bool, destProof []byte) liteclient.LiteServerBlockLink {
+	link := liteclient.LiteServerBlockLink{SumType: "LiteServerBlockLinkBack"}
+	link.LiteServerBlockLinkBack.ToKeyBlock = toKeyBlock
+	link.LiteServerBlockLinkBack.From = liteclient.BlockIDExt(c.block)
+	link.LiteServerBlockLinkBack.To = liteclient.BlockIDExt(to)
+	link.LiteServerBlockLinkBack.DestProof = destProof
+	link.LiteServerBlockLinkBack.Proof = c.blockBoc
+	link.LiteServerBlockLinkBack.StateProof = c.blockState
+	return link
+}
+
+func TestVerifyBlockProof(t *testing.T) {
+	chain := newTestChain(t)
+	signed := func(validators ...int) liteclient.LiteServerSignatureSetC {
+		var signers []testValidator
+		for _, i := range validators {
+			signers = append(signers, chain.validators[i])
+		}
+		return signBlock(signers, chain.block, chain.catchainSeqno, chain.validatorSetHash)
+	}
+	badSignature := signed(0, 1, 2, 3)
+	badSignature.Signatures[1].Signature = badSignature.Signatures[0].Signature
+
+	tests := []struct {
+		name    string
+		trusted ton.BlockIDExt
+		to      ton.BlockIDExt
+		steps   []liteclient.LiteServerBlockLink
+		want    ton.BlockIDExt
+		wantErr bool
+	}{
+		{
+			name:    "no steps",
+			trusted: chain.keyBlock,
+			to:      chain.keyBlock,
+			want:    chain.keyBlock,
+		},
+		{
+			name:    "forward link",
+			trusted: chain.keyBlock,
+			to:      chain.block,
+			steps:   []liteclient.LiteServerBlockLink{chain.forward(signed(0, 1, 2, 3))},
+			want:    chain.block,
+		},
+		{
+			name:    "forward and backward links",
+			trusted: chain.keyBlock,
+			to:      chain.prevBlock,
+			steps: []liteclient.LiteServerBlockLink{
+				chain.forward(signed(4, 3, 2, 1)),
+				chain.back(chain.prevBlock, false, chain.prevBlockBoc),
+			},
+			want: chain.prevBlock,
+		},
+		{
+			name:    "backward link to key block without dest proof",
+			trusted: chain.keyBlock,
+			to:      chain.keyBlock,
+			steps: []liteclient.LiteServerBlockLink{
+				chain.forward(signed(0, 1, 2, 4)),
+				chain.back(chain.keyBlock, true, nil),
+			},
+			want: chain.keyBlock,
+		},
+		{
+			name:    "not enough signatures",
+			trusted: chain.keyBlock,
+			to:      chain.block,
+			steps:   []liteclient.LiteServerBlockLink{chain.forward(signed(0, 1, 2))},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate signatures",
+			trusted: chain.keyBlock,
+			to:      chain.block,
+			steps:   []liteclient.LiteServerBlockLink{chain.forward(signed(2, 3, 4, 4))},
+			wantErr: true,
+		},
+		{
+			name:    "invalid signature",
+			trusted: chain.keyBlock,
+			to:      chain.block,
+			steps:   []liteclient.LiteServerBlockLink{chain.forward(badSignature)},
+			wantErr: true,
+		},
+		{
+			name:    "unknown validator",
+			trusted: chain.keyBlock,
+			to:      chain.block,
+			steps: []liteclient.LiteServerBlockLink{chain.forward(
+				signBlock(append(testValidators(6)[5:], chain.validators...), chain.block, chain.catchainSeqno, chain.validatorSetHash),
+			)},
+			wantErr: true,
+		},
+		{
+			name:    "another validator set",
+			trusted: chain.keyBlock,
+			to:      chain.block,
+			steps: []liteclient.LiteServerBlockLink{chain.forward(
+				signBlock(chain.validators, chain.block, chain.catchainSeqno, chain.validatorSetHash+1),
+			)},
+			wantErr: true,
+		},
+		{
+			name:    "untrusted start",
+			trusted: chain.prevBlock,
+			to:      chain.block,
+			steps:   []liteclient.LiteServerBlockLink{chain.forward(signed(0, 1, 2, 3))},
+			wantErr: true,
+		},
+		{
+			name:    "wrong end",
+			trusted: chain.keyBlock,
+			to:      chain.prevBlock,
+			steps:   []liteclient.LiteServerBlockLink{chain.forward(signed(0, 1, 2, 3))},
+			wantErr: true,
+		},
+		{
+			name:    "backward link to not a key block",
+			trusted: chain.keyBlock,
+			to:      chain.prevBlock,
+			steps: []liteclient.LiteServerBlockLink{
+				chain.forward(signed(0, 1, 2, 3)),
+				chain.back(chain.prevBlock, true, nil),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "backward link to unknown block",
+			trusted: chain.keyBlock,
+			to:      ton.BlockIDExt{BlockID: chain.prevBlock.BlockID},
+			steps: []liteclient.LiteServerBlockLink{
+				chain.forward(signed(0, 1, 2, 3)),
+				chain.back(ton.BlockIDExt{BlockID: chain.prevBlock.BlockID}, false, nil),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := liteclient.LiteServerPartialBlockProofC{
+				Complete: true,
+				From:     liteclient.BlockIDExt(tt.trusted),
+				To:       liteclient.BlockIDExt(tt.to),
+				Steps:    tt.steps,
+			}
+			got, err := VerifyBlockProof(tt.trusted, proof)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidProof) {
+					t.Fatalf("want ErrInvalidProof, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyBlockProof() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_masterchainValidators(t *testing.T) {
+	set := testValidatorSet(testValidators(20))
+	set.Validators.Main = 16
+	shuffled, err := masterchainValidators(set, true, 1)
+	if err != nil {
+		t.Fatalf("masterchainValidators() failed: %v", err)
+	}
+	ordered, err := masterchainValidators(set, false, 1)
+	if err != nil {
+		t.Fatalf("masterchainValidators() failed: %v", err)
+	}
+	if len(shuffled) != 16 || len(ordered) != 16 {
+		t.Fatalf("want 16 validators, got: %v and %v", len(shuffled), len(ordered))
+	}
+	seen := make(map[tl.Int256]bool)
+	for i, v := range shuffled {
+		seen[adnl.KeyID(v.publicKey)] = true
+		if !ordered[i].publicKey.Equal(ed25519.PublicKey(testValidators(20)[i].privateKey.Public().(ed25519.PublicKey))) {
+			t.Fatalf("validators without shuffling must keep their order")
+		}
+	}
+	for _, v := range ordered {
+		if !seen[adnl.KeyID(v.publicKey)] {
+			t.Fatalf("shuffled validators must be a permutation of the first 16 validators")
+		}
+	}
+	if validatorSetHash(1, shuffled) == validatorSetHash(1, ordered) {
+		t.Fatalf("shuffled validators must have another order")
+	}
+}
+
+func TestBlockProofHelpersOnRecordedAnswer(t *testing.T) {
+	// A liteServer.configInfo answer for mainnet masterchain block 26309435
+	// requested with the validator set params.
+	data, err := os.ReadFile("../ton/testdata/get-last-config-all-2.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	var res liteclient.LiteServerConfigInfoC
+	if err := res.UnmarshalTL(bytes.NewReader(data[4:])); err != nil {
+		t.Fatalf("UnmarshalTL() failed: %v", err)
+	}
+	blockID := res.Id.ToBlockIdExt()
+	if blockID.Seqno != 26309435 {
+		t.Fatalf("want block 26309435, got %v", blockID.Seqno)
+	}
+	proof, err := boc.DeserializeBoc(res.StateProof)
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	info, err := blockInfo(proof[0], blockID)
+	if err != nil {
+		t.Fatalf("blockInfo() failed: %v", err)
+	}
+	if info.GenCatchainSeqno != 391431 || info.PrevKeyBlockSeqno != 26299993 {
+		t.Fatalf("unexpected block info: catchain %v, prev key block %v", info.GenCatchainSeqno, info.PrevKeyBlockSeqno)
+	}
+	proof[0].ResetCounters()
+	stateHash, err := blockStateHash(proof[0], blockID)
+	if err != nil {
+		t.Fatalf("blockStateHash() failed: %v", err)
+	}
+	stateProof, err := boc.DeserializeBoc(res.ConfigProof)
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	state, err := boc.VerifyMerkleProof(stateProof[0], stateHash)
+	if err != nil {
+		t.Fatalf("VerifyMerkleProof() failed: %v", err)
+	}
+	extra, err := mcStateExtra(state)
+	if err != nil {
+		t.Fatalf("mcStateExtra() failed: %v", err)
+	}
+	// masterchain_state_extra#cc26 shard_hashes:ShardHashes config:ConfigParams ...
+	// shard hashes are pruned from the proof.
+	if err := skipMaybeRef(extra); err != nil {
+		t.Fatalf("skipMaybeRef() failed: %v", err)
+	}
+	if err := extra.Skip(256); err != nil {
+		t.Fatalf("Skip() failed: %v", err)
+	}
+	config, err := extra.NextRef()
+	if err != nil {
+		t.Fatalf("NextRef() failed: %v", err)
+	}
+	var current tlb.ConfigParam34
+	found, err := configParam(config, 34, &current)
+	if err != nil || !found {
+		t.Fatalf("configParam(34) = %v, %v", found, err)
+	}
+	validators, err := masterchainValidators(current.CurValidators, true, info.GenCatchainSeqno)
+	if err != nil {
+		t.Fatalf("masterchainValidators() failed: %v", err)
+	}
+	if len(validators) != 100 {
+		t.Fatalf("want 100 masterchain validators, got %v", len(validators))
+	}
+}
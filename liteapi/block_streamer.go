@@ -0,0 +1,289 @@
+package liteapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/caigou-xyz/tongo/liteapi/pool"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+const (
+	defaultStreamerWaitTimeout = 10 * time.Second
+	defaultStreamerRetryDelay  = time.Second
+)
+
+// StreamedBlock is a block delivered by BlockStreamer.
+type StreamedBlock struct {
+	ID ton.BlockIDExt
+	// Block is a decoded block. It is nil if the streamer is configured with WithStreamTransactionsOnly.
+	Block *tlb.Block
+	// Transactions are transactions of the block ordered by lt.
+	// They are set only if the streamer is configured with WithStreamTransactionsOnly.
+	Transactions []ton.Transaction
+}
+
+// StreamedMasterchainBlock is a masterchain block together with
+// all shard blocks committed to the masterchain for the first time by this block.
+type StreamedMasterchainBlock struct {
+	StreamedBlock
+	// ShardBlocks are new shard blocks of all workchains, each block goes after its parents.
+	ShardBlocks []StreamedBlock
+}
+
+// StreamerOptions holds parameters to configure a BlockStreamer.
+type StreamerOptions struct {
+	// Cursor is the last processed masterchain block, the streamer continues with the next one.
+	// If nil, the streamer starts with a masterchain block following the current masterchain head.
+	Cursor *ton.BlockIDExt
+	// TransactionsOnly makes the streamer deliver transactions of blocks instead of decoded blocks.
+	TransactionsOnly bool
+	// WaitTimeout specifies how long a lite server waits for a next masterchain block in one request.
+	WaitTimeout time.Duration
+	// RetryDelay is a pause before repeating a request that failed with a retryable error.
+	RetryDelay time.Duration
+}
+
+type StreamerOption func(o *StreamerOptions)
+
+// WithStreamCursor makes the streamer resume after the given masterchain block.
+// Usually, it is the ID of the last StreamedMasterchainBlock processed before a restart.
+func WithStreamCursor(block ton.BlockIDExt) StreamerOption {
+	return func(o *StreamerOptions) {
+		o.Cursor = &block
+	}
+}
+
+// WithStreamTransactionsOnly makes the streamer deliver transactions of blocks instead of decoded blocks.
+func WithStreamTransactionsOnly() StreamerOption {
+	return func(o *StreamerOptions) {
+		o.TransactionsOnly = true
+	}
+}
+
+// WithStreamWaitTimeout sets how long a lite server waits for a next masterchain block in one request.
+// By default, it is 10 seconds.
+func WithStreamWaitTimeout(timeout time.Duration) StreamerOption {
+	return func(o *StreamerOptions) {
+		o.WaitTimeout = timeout
+	}
+}
+
+// blockSource is a part of Client used by BlockStreamer.
+type blockSource interface {
+	GetMasterchainInfo(ctx context.Context) (ton.BlockIDExt, error)
+	WaitMasterchainBlock(ctx context.Context, seqno uint32, timeout time.Duration) (ton.BlockIDExt, error)
+	GetBlock(ctx context.Context, blockID ton.BlockIDExt) (tlb.Block, error)
+	proveMasterchainBlock(ctx context.Context, block ton.BlockIDExt) error
+}
+
+// clientBlockSource adapts Client to blockSource.
+type clientBlockSource struct {
+	*Client
+}
+
+func (s clientBlockSource) GetMasterchainInfo(ctx context.Context) (ton.BlockIDExt, error) {
+	info, err := s.Client.GetMasterchainInfo(ctx)
+	if err != nil {
+		return ton.BlockIDExt{}, err
+	}
+	return info.Last.ToBlockIdExt(), nil
+}
+
+// BlockStreamer follows the blockchain and delivers masterchain blocks in order,
+// each one with shard blocks it commits.
+//
+// Shard blocks are found by walking back from the shard tops of a masterchain block
+// to the shard tops of the previous masterchain block,
+// so the streamer doesn't miss any shard block even when a shard splits or merges
+// or when several blocks of a shard are committed by one masterchain block.
+type BlockStreamer struct {
+	source  blockSource
+	options StreamerOptions
+}
+
+// NewBlockStreamer returns a streamer getting blocks with the given client.
+// Proofs of blocks are checked according to the client's proof policy.
+func NewBlockStreamer(client *Client, opts ...StreamerOption) *BlockStreamer {
+	return newBlockStreamer(clientBlockSource{Client: client}, opts...)
+}
+
+func newBlockStreamer(source blockSource, opts ...StreamerOption) *BlockStreamer {
+	options := StreamerOptions{
+		WaitTimeout: defaultStreamerWaitTimeout,
+		RetryDelay:  defaultStreamerRetryDelay,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return &BlockStreamer{source: source, options: options}
+}
+
+// Run streams blocks to the given channel until the context is canceled or an error occurs.
+// The streamer doesn't fetch a next masterchain block until the previous one is sent,
+// so a slow reader makes the streamer wait.
+// Run closes the channel before returning.
+func (s *BlockStreamer) Run(ctx context.Context, out chan<- StreamedMasterchainBlock) error {
+	defer close(out)
+	cursor := s.options.Cursor
+	if cursor == nil {
+		head, err := retry(ctx, s.options.RetryDelay, func() (ton.BlockIDExt, error) {
+			return s.source.GetMasterchainInfo(ctx)
+		})
+		if err != nil {
+			return err
+		}
+		cursor = &head
+	}
+	prev, err := s.getBlock(ctx, *cursor)
+	if err != nil {
+		return err
+	}
+	prevID, prevTops := *cursor, ton.ShardIDs(&prev)
+	for {
+		next, err := retry(ctx, s.options.RetryDelay, func() (ton.BlockIDExt, error) {
+			return s.source.WaitMasterchainBlock(ctx, prevID.Seqno+1, s.options.WaitTimeout)
+		})
+		if err != nil {
+			return err
+		}
+		block, tops, err := s.masterchainBlock(ctx, prevID, prevTops, next)
+		if err != nil {
+			return err
+		}
+		select {
+		case out <- block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		prevID, prevTops = next, tops
+	}
+}
+
+// masterchainBlock returns the next masterchain block after prevID and its shard tops.
+func (s *BlockStreamer) masterchainBlock(ctx context.Context, prevID ton.BlockIDExt, prevTops []ton.BlockIDExt, id ton.BlockIDExt) (StreamedMasterchainBlock, []ton.BlockIDExt, error) {
+	if id.Workchain != masterchainID || id.Seqno != prevID.Seqno+1 {
+		return StreamedMasterchainBlock{}, nil, fmt.Errorf("expected masterchain block %v, got %v", prevID.Seqno+1, id)
+	}
+	if err := s.source.proveMasterchainBlock(ctx, id); err != nil {
+		return StreamedMasterchainBlock{}, nil, err
+	}
+	block, err := s.getBlock(ctx, id)
+	if err != nil {
+		return StreamedMasterchainBlock{}, nil, err
+	}
+	parents, err := ton.GetParents(block.Info)
+	if err != nil {
+		return StreamedMasterchainBlock{}, nil, err
+	}
+	if len(parents) != 1 || parents[0] != prevID {
+		return StreamedMasterchainBlock{}, nil, fmt.Errorf("masterchain block %v doesn't follow %v", id, prevID)
+	}
+	tops := ton.ShardIDs(&block)
+	shardBlocks, err := s.newShardBlocks(ctx, prevTops, tops)
+	if err != nil {
+		return StreamedMasterchainBlock{}, nil, err
+	}
+	return StreamedMasterchainBlock{
+		StreamedBlock: s.streamedBlock(id, &block),
+		ShardBlocks:   shardBlocks,
+	}, tops, nil
+}
+
+// newShardBlocks returns shard blocks between the previous shard tops and the current ones.
+// Blocks are ordered so that each block goes after its parents.
+func (s *BlockStreamer) newShardBlocks(ctx context.Context, prevTops, tops []ton.BlockIDExt) ([]StreamedBlock, error) {
+	seen := make(map[ton.BlockIDExt]struct{}, len(prevTops))
+	for _, top := range prevTops {
+		seen[top] = struct{}{}
+	}
+	var blocks []StreamedBlock
+	var walk func(id ton.BlockIDExt) error
+	walk = func(id ton.BlockIDExt) error {
+		if _, ok := seen[id]; ok || id.Seqno == 0 {
+			return nil
+		}
+		if err := checkNotBehind(prevTops, id); err != nil {
+			return err
+		}
+		seen[id] = struct{}{}
+		block, err := s.getBlock(ctx, id)
+		if err != nil {
+			return err
+		}
+		parents, err := ton.GetParents(block.Info)
+		if err != nil {
+			return fmt.Errorf("block %v: %w", id, err)
+		}
+		for _, parent := range parents {
+			if err := walk(parent); err != nil {
+				return err
+			}
+		}
+		blocks = append(blocks, s.streamedBlock(id, &block))
+		return nil
+	}
+	for _, top := range tops {
+		if err := walk(top); err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// checkNotBehind returns an error if the block is not newer than a previous top of an overlapping shard.
+// Walking back from new shard tops always reaches the previous tops first,
+// so such a block means that the shard chain doesn't connect to the previous tops.
+func checkNotBehind(prevTops []ton.BlockIDExt, id ton.BlockIDExt) error {
+	for _, top := range prevTops {
+		if top.Workchain != id.Workchain || top.Seqno < id.Seqno {
+			continue
+		}
+		shard, err := ton.ParseShardID(int64(top.Shard))
+		if err != nil {
+			return err
+		}
+		if shard.MatchBlockID(id.BlockID) {
+			return fmt.Errorf("shard block %v doesn't descend from %v", id, top)
+		}
+	}
+	return nil
+}
+
+func (s *BlockStreamer) getBlock(ctx context.Context, id ton.BlockIDExt) (tlb.Block, error) {
+	return retry(ctx, s.options.RetryDelay, func() (tlb.Block, error) {
+		return s.source.GetBlock(ctx, id)
+	})
+}
+
+func (s *BlockStreamer) streamedBlock(id ton.BlockIDExt, block *tlb.Block) StreamedBlock {
+	if !s.options.TransactionsOnly {
+		return StreamedBlock{ID: id, Block: block}
+	}
+	txs := block.AllTransactions()
+	transactions := make([]ton.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		transactions = append(transactions, ton.Transaction{Transaction: *tx, BlockID: id})
+	}
+	// a block stores transactions grouped by account.
+	sort.Slice(transactions, func(i, j int) bool { return transactions[i].Lt < transactions[j].Lt })
+	return StreamedBlock{ID: id, Transactions: transactions}
+}
+
+// retry calls f until it succeeds or fails with an error that is not retryable according to pool.IsRetryable.
+func retry[T any](ctx context.Context, delay time.Duration, f func() (T, error)) (T, error) {
+	for {
+		res, err := f()
+		if err == nil || !pool.IsRetryable(err) {
+			return res, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return res, ctx.Err()
+		}
+	}
+}
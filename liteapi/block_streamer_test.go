@@ -0,0 +1,247 @@
+package liteapi
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+var errNoMoreBlocks = errors.New("no more blocks")
+
+type fakeBlockSource struct {
+	head        ton.BlockIDExt
+	masterchain map[uint32]ton.BlockIDExt
+	blocks      map[ton.BlockIDExt]tlb.Block
+	proven      []ton.BlockIDExt
+}
+
+func newFakeBlockSource() *fakeBlockSource {
+	return &fakeBlockSource{
+		masterchain: map[uint32]ton.BlockIDExt{},
+		blocks:      map[ton.BlockIDExt]tlb.Block{},
+	}
+}
+
+func (s *fakeBlockSource) GetMasterchainInfo(ctx context.Context) (ton.BlockIDExt, error) {
+	return s.head, nil
+}
+
+func (s *fakeBlockSource) WaitMasterchainBlock(ctx context.Context, seqno uint32, timeout time.Duration) (ton.BlockIDExt, error) {
+	id, ok := s.masterchain[seqno]
+	if !ok {
+		return ton.BlockIDExt{}, errNoMoreBlocks
+	}
+	return id, nil
+}
+
+func (s *fakeBlockSource) GetBlock(ctx context.Context, blockID ton.BlockIDExt) (tlb.Block, error) {
+	block, ok := s.blocks[blockID]
+	if !ok {
+		return tlb.Block{}, errors.New("block not found")
+	}
+	return block, nil
+}
+
+func (s *fakeBlockSource) proveMasterchainBlock(ctx context.Context, block ton.BlockIDExt) error {
+	s.proven = append(s.proven, block)
+	return nil
+}
+
+func testBlockID(workchain int32, shard uint64, seqno uint32) ton.BlockIDExt {
+	return ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: workchain, Shard: shard, Seqno: seqno},
+		RootHash: ton.Bits256{byte(workchain), byte(shard >> 56), byte(seqno)},
+		FileHash: ton.Bits256{byte(seqno)},
+	}
+}
+
+func extBlkRef(id ton.BlockIDExt) tlb.ExtBlkRef {
+	return tlb.ExtBlkRef{SeqNo: id.Seqno, RootHash: tlb.Bits256(id.RootHash), FileHash: tlb.Bits256(id.FileHash)}
+}
+
+// addShardBlock adds a shard block with the given parents.
+// Two parents mean a merge, a parent of another shard means a split.
+func (s *fakeBlockSource) addShardBlock(id ton.BlockIDExt, parents ...ton.BlockIDExt) {
+	var info tlb.BlockInfo
+	info.SeqNo = id.Seqno
+	info.Shard = tlb.ShardIdent{
+		ShardPfxBits: tlb.Uint6(63 - bits.TrailingZeros64(id.Shard)),
+		WorkchainID:  id.Workchain,
+		ShardPrefix:  id.Shard & (id.Shard - 1),
+	}
+	switch len(parents) {
+	case 1:
+		info.AfterSplit = parents[0].Shard != id.Shard
+		info.PrevRef.SumType = "PrevBlkInfo"
+		info.PrevRef.PrevBlkInfo = &struct{ Prev tlb.ExtBlkRef }{Prev: extBlkRef(parents[0])}
+	case 2:
+		info.AfterMerge = true
+		info.PrevRef.SumType = "PrevBlksInfo"
+		info.PrevRef.PrevBlksInfo = &struct {
+			Prev1 tlb.ExtBlkRef
+			Prev2 tlb.ExtBlkRef
+		}{Prev1: extBlkRef(parents[0]), Prev2: extBlkRef(parents[1])}
+	}
+	s.blocks[id] = tlb.Block{Info: info}
+}
+
+// addMasterchainBlock adds a masterchain block following prev with the given shard tops of workchain 0.
+func (s *fakeBlockSource) addMasterchainBlock(id, prev ton.BlockIDExt, tops ...ton.BlockIDExt) {
+	s.addShardBlock(id, prev)
+	block := s.blocks[id]
+	var descs []tlb.ShardDesc
+	for _, top := range tops {
+		var desc tlb.ShardDesc
+		desc.SumType = "New"
+		desc.New.SeqNo = top.Seqno
+		desc.New.RootHash = tlb.Bits256(top.RootHash)
+		desc.New.FileHash = tlb.Bits256(top.FileHash)
+		desc.New.NextValidatorShard = int64(top.Shard)
+		descs = append(descs, desc)
+	}
+	block.Extra.Custom.Exists = true
+	block.Extra.Custom.Value.Value.ShardHashes = tlb.NewHashmapE(
+		[]tlb.Uint32{0},
+		[]tlb.Ref[tlb.ShardInfoBinTree]{{Value: tlb.ShardInfoBinTree{BinTree: tlb.BinTree[tlb.ShardDesc]{Values: descs}}}})
+	s.blocks[id] = block
+	s.masterchain[id.Seqno] = id
+}
+
+const (
+	testShardFull  = 0x8000000000000000
+	testShardLeft  = 0x4000000000000000
+	testShardRight = 0xc000000000000000
+)
+
+func streamedIDs(blocks []StreamedBlock) []ton.BlockIDExt {
+	var ids []ton.BlockIDExt
+	for _, block := range blocks {
+		ids = append(ids, block.ID)
+	}
+	return ids
+}
+
+func TestBlockStreamer_Run(t *testing.T) {
+	mc := func(seqno uint32) ton.BlockIDExt { return testBlockID(-1, testShardFull, seqno) }
+	a9, a10, a11, a12 := testBlockID(0, testShardFull, 9), testBlockID(0, testShardFull, 10), testBlockID(0, testShardFull, 11), testBlockID(0, testShardFull, 12)
+	l13, l14, r13 := testBlockID(0, testShardLeft, 13), testBlockID(0, testShardLeft, 14), testBlockID(0, testShardRight, 13)
+	m15 := testBlockID(0, testShardFull, 15)
+
+	source := func() *fakeBlockSource {
+		s := newFakeBlockSource()
+		s.addShardBlock(a10, a9)
+		s.addShardBlock(a11, a10)
+		s.addShardBlock(a12, a11)
+		s.addShardBlock(l13, a12)
+		s.addShardBlock(r13, a12)
+		s.addShardBlock(l14, l13)
+		s.addShardBlock(m15, l14, r13)
+		s.addMasterchainBlock(mc(100), mc(99), a10)
+		s.addMasterchainBlock(mc(101), mc(100), a12)
+		s.addMasterchainBlock(mc(102), mc(101), l13, r13)
+		s.addMasterchainBlock(mc(103), mc(102), m15)
+		s.addMasterchainBlock(mc(104), mc(103), m15)
+		s.head = mc(100)
+		return s
+	}
+	want := map[uint32][]ton.BlockIDExt{
+		101: {a11, a12},
+		102: {l13, r13},
+		103: {l14, m15},
+		104: nil,
+	}
+
+	tests := []struct {
+		name       string
+		source     func() *fakeBlockSource
+		opts       []StreamerOption
+		wantSeqnos []uint32
+		wantErr    bool
+	}{
+		{
+			name:       "from masterchain head",
+			source:     source,
+			wantSeqnos: []uint32{101, 102, 103, 104},
+		},
+		{
+			name:       "from cursor",
+			source:     source,
+			opts:       []StreamerOption{WithStreamCursor(mc(102))},
+			wantSeqnos: []uint32{103, 104},
+		},
+		{
+			name:       "transactions only",
+			source:     source,
+			opts:       []StreamerOption{WithStreamCursor(mc(103)), WithStreamTransactionsOnly()},
+			wantSeqnos: []uint32{104},
+		},
+		{
+			name: "shard chain doesn't descend from previous top",
+			source: func() *fakeBlockSource {
+				s := source()
+				fork := testBlockID(0, testShardFull, 11)
+				fork.RootHash = ton.Bits256{0xff}
+				s.addShardBlock(fork, a9)
+				s.addMasterchainBlock(mc(101), mc(100), fork)
+				return s
+			},
+			wantErr: true,
+		},
+		{
+			name: "masterchain block doesn't follow cursor",
+			source: func() *fakeBlockSource {
+				s := source()
+				s.addMasterchainBlock(mc(101), mc(98), a12)
+				return s
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.source()
+			streamer := newBlockStreamer(s, tt.opts...)
+			out := make(chan StreamedMasterchainBlock)
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- streamer.Run(context.Background(), out)
+			}()
+			var seqnos []uint32
+			for block := range out {
+				seqnos = append(seqnos, block.ID.Seqno)
+				if block.ID != mc(block.ID.Seqno) {
+					t.Fatalf("unexpected masterchain block: %v", block.ID)
+				}
+				if ids := streamedIDs(block.ShardBlocks); !reflect.DeepEqual(ids, want[block.ID.Seqno]) {
+					t.Fatalf("masterchain block %v, want shard blocks: %v, got: %v", block.ID.Seqno, want[block.ID.Seqno], ids)
+				}
+				transactionsOnly := block.Block == nil
+				if transactionsOnly != streamer.options.TransactionsOnly {
+					t.Fatalf("want transactions only: %v", streamer.options.TransactionsOnly)
+				}
+			}
+			err := <-errCh
+			if tt.wantErr {
+				if err == nil || errors.Is(err, errNoMoreBlocks) {
+					t.Fatalf("want error, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errNoMoreBlocks) {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if !reflect.DeepEqual(seqnos, tt.wantSeqnos) {
+				t.Fatalf("want masterchain blocks: %v, got: %v", tt.wantSeqnos, seqnos)
+			}
+			if len(s.proven) != len(seqnos) {
+				t.Fatalf("want %v proven blocks, got: %v", len(seqnos), len(s.proven))
+			}
+		})
+	}
+}
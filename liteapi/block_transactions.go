@@ -0,0 +1,158 @@
+package liteapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// maxBlockTransactionCount is the largest number of transactions a lite server returns in one listBlockTransactions page.
+const maxBlockTransactionCount = 256
+
+// listTransactionsAfter is a mode of listBlockTransactionsExt to start after the given transaction.
+const listTransactionsAfter = 128
+
+// BlockTransactionsOptions holds parameters to configure a BlockTransactionIterator.
+type BlockTransactionsOptions struct {
+	// AccountID limits the iterator to transactions of one account.
+	AccountID *ton.AccountID
+	// PageSize is a number of transactions requested at once.
+	PageSize uint32
+}
+
+type BlockTransactionsOption func(o *BlockTransactionsOptions)
+
+// WithBlockTransactionsAccount makes the iterator return only transactions of the given account.
+func WithBlockTransactionsAccount(accountID ton.AccountID) BlockTransactionsOption {
+	return func(o *BlockTransactionsOptions) {
+		o.AccountID = &accountID
+	}
+}
+
+// WithBlockTransactionsPageSize sets a number of transactions requested at once, 256 at most.
+func WithBlockTransactionsPageSize(size uint32) BlockTransactionsOption {
+	return func(o *BlockTransactionsOptions) {
+		o.PageSize = size
+	}
+}
+
+// blockTransactionSource is a part of Client used by BlockTransactionIterator.
+type blockTransactionSource interface {
+	listProvenBlockTransactions(ctx context.Context, blockID ton.BlockIDExt, mode, count uint32, after *liteclient.LiteServerTransactionId3C) ([]ton.Transaction, bool, error)
+}
+
+// BlockTransactionIterator walks over all transactions of a block ordered by account and lt.
+//
+// Each page of transactions is downloaded with a single listBlockTransactionsExt request
+// and checked to continue the previous page in order.
+// Each page comes with a proof that its transactions are included into the block,
+// the proof is checked under every proof policy.
+type BlockTransactionIterator struct {
+	source  blockTransactionSource
+	blockID ton.BlockIDExt
+	options BlockTransactionsOptions
+	after   *liteclient.LiteServerTransactionId3C
+	page    []ton.Transaction
+	done    bool
+}
+
+// IterateBlockTransactions returns an iterator over transactions of the given block.
+func (c *Client) IterateBlockTransactions(blockID ton.BlockIDExt, opts ...BlockTransactionsOption) *BlockTransactionIterator {
+	return newBlockTransactionIterator(c, blockID, opts...)
+}
+
+func newBlockTransactionIterator(source blockTransactionSource, blockID ton.BlockIDExt, opts ...BlockTransactionsOption) *BlockTransactionIterator {
+	options := BlockTransactionsOptions{PageSize: maxBlockTransactionCount}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.PageSize == 0 || options.PageSize > maxBlockTransactionCount {
+		options.PageSize = maxBlockTransactionCount
+	}
+	it := &BlockTransactionIterator{source: source, blockID: blockID, options: options}
+	if options.AccountID != nil {
+		if options.AccountID.Workchain != blockID.Workchain {
+			it.done = true
+		}
+		// transactions of the account go right after the position with zero lt.
+		it.after = &liteclient.LiteServerTransactionId3C{Account: tl.Int256(options.AccountID.Address)}
+	}
+	return it
+}
+
+// Next returns the next transaction of the block.
+// It returns io.EOF when there are no more transactions.
+func (it *BlockTransactionIterator) Next(ctx context.Context) (ton.Transaction, error) {
+	for len(it.page) == 0 {
+		if it.done {
+			return ton.Transaction{}, io.EOF
+		}
+		if err := it.fetch(ctx); err != nil {
+			return ton.Transaction{}, err
+		}
+	}
+	tx := it.page[0]
+	it.page = it.page[1:]
+	return tx, nil
+}
+
+// All returns all remaining transactions of the block.
+func (it *BlockTransactionIterator) All(ctx context.Context) ([]ton.Transaction, error) {
+	var txs []ton.Transaction
+	for {
+		tx, err := it.Next(ctx)
+		if err == io.EOF {
+			return txs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+}
+
+// fetch requests the next page of transactions.
+func (it *BlockTransactionIterator) fetch(ctx context.Context) error {
+	var mode uint32
+	if it.after != nil {
+		mode |= listTransactionsAfter
+	}
+	txs, incomplete, err := it.source.listProvenBlockTransactions(ctx, it.blockID, mode, it.options.PageSize, it.after)
+	if err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		it.done = true
+		return nil
+	}
+	if len(txs) > int(it.options.PageSize) {
+		return fmt.Errorf("got %v transactions instead of %v at most in block %v", len(txs), it.options.PageSize, it.blockID)
+	}
+	prev := it.after
+	for _, tx := range txs {
+		id := liteclient.LiteServerTransactionId3C{Account: tl.Int256(tx.AccountAddr), Lt: tx.Lt}
+		// a page going backwards would make the iterator loop forever.
+		if prev != nil && compareTransactionIDs(*prev, id) >= 0 {
+			return fmt.Errorf("transactions of block %v are not ordered by account and lt", it.blockID)
+		}
+		prev = &id
+	}
+	last := txs[len(txs)-1]
+	it.after = &liteclient.LiteServerTransactionId3C{Account: tl.Int256(last.AccountAddr), Lt: last.Lt}
+	it.done = !incomplete
+	if it.options.AccountID != nil {
+		for i, tx := range txs {
+			if ton.Bits256(tx.AccountAddr) != it.options.AccountID.Address {
+				// transactions are ordered by account, so the account has no more transactions.
+				txs, it.done = txs[:i], true
+				break
+			}
+		}
+	}
+	it.page = txs
+	return nil
+}
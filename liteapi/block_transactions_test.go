@@ -0,0 +1,155 @@
+package liteapi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// fakeBlockTransactionSource serves transactions of one block ordered by account and lt.
+type fakeBlockTransactionSource struct {
+	txs []ton.Transaction
+	// ignoreAfter makes listProvenBlockTransactions return the first page again and again.
+	ignoreAfter bool
+	// limit limits a number of transactions returned by listProvenBlockTransactions.
+	limit int
+	calls int
+}
+
+func (s *fakeBlockTransactionSource) page(mode, count uint32, after *liteclient.LiteServerTransactionId3C) ([]ton.Transaction, bool) {
+	start := 0
+	if mode&listTransactionsAfter != 0 && !s.ignoreAfter {
+		for start < len(s.txs) {
+			tx := s.txs[start]
+			if compareTransactionIDs(*after, liteclient.LiteServerTransactionId3C{Account: tl.Int256(tx.AccountAddr), Lt: tx.Lt}) < 0 {
+				break
+			}
+			start++
+		}
+	}
+	end := min(start+int(count), len(s.txs))
+	return s.txs[start:end], end < len(s.txs)
+}
+
+func (s *fakeBlockTransactionSource) listProvenBlockTransactions(ctx context.Context, blockID ton.BlockIDExt, mode, count uint32, after *liteclient.LiteServerTransactionId3C) ([]ton.Transaction, bool, error) {
+	s.calls += 1
+	txs, incomplete := s.page(mode, count, after)
+	if s.limit > 0 && len(txs) > s.limit {
+		txs, incomplete = txs[:s.limit], true
+	}
+	return txs, incomplete, nil
+}
+
+func TestBlockTransactionIterator(t *testing.T) {
+	decoded := decodeTestTransactions(t)
+	accounts := []tlb.Bits256{{0x10}, {0x20}, {0x30}}
+	var txs []ton.Transaction
+	lt := uint64(1)
+	for i, count := range []int{2, 3, 1} {
+		for j := 0; j < count; j++ {
+			tx := decoded[j%len(decoded)]
+			tx.AccountAddr = accounts[i]
+			tx.Lt = lt
+			lt++
+			txs = append(txs, tx)
+		}
+	}
+	blockID := testBlockID(0, testShardFull, 100)
+	account := func(i int) ton.AccountID {
+		return ton.AccountID{Workchain: 0, Address: ton.Bits256(accounts[i])}
+	}
+
+	tests := []struct {
+		name      string
+		source    *fakeBlockTransactionSource
+		opts      []BlockTransactionsOption
+		wantLts   []uint64
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "all transactions",
+			source:    &fakeBlockTransactionSource{txs: txs},
+			wantLts:   []uint64{1, 2, 3, 4, 5, 6},
+			wantCalls: 1,
+		},
+		{
+			name:      "several pages",
+			source:    &fakeBlockTransactionSource{txs: txs},
+			opts:      []BlockTransactionsOption{WithBlockTransactionsPageSize(4)},
+			wantLts:   []uint64{1, 2, 3, 4, 5, 6},
+			wantCalls: 2,
+		},
+		{
+			name:      "page size is a divisor of the number of transactions",
+			source:    &fakeBlockTransactionSource{txs: txs},
+			opts:      []BlockTransactionsOption{WithBlockTransactionsPageSize(3)},
+			wantLts:   []uint64{1, 2, 3, 4, 5, 6},
+			wantCalls: 2,
+		},
+		{
+			name:      "account in the middle",
+			source:    &fakeBlockTransactionSource{txs: txs},
+			opts:      []BlockTransactionsOption{WithBlockTransactionsAccount(account(1)), WithBlockTransactionsPageSize(2)},
+			wantLts:   []uint64{3, 4, 5},
+			wantCalls: 2,
+		},
+		{
+			name:      "last account",
+			source:    &fakeBlockTransactionSource{txs: txs},
+			opts:      []BlockTransactionsOption{WithBlockTransactionsAccount(account(2))},
+			wantLts:   []uint64{6},
+			wantCalls: 1,
+		},
+		{
+			name:      "account without transactions",
+			source:    &fakeBlockTransactionSource{txs: txs},
+			opts:      []BlockTransactionsOption{WithBlockTransactionsAccount(ton.AccountID{Workchain: 0, Address: ton.Bits256{0x15}})},
+			wantCalls: 1,
+		},
+		{
+			name:    "account of another workchain",
+			source:  &fakeBlockTransactionSource{txs: txs},
+			opts:    []BlockTransactionsOption{WithBlockTransactionsAccount(ton.AccountID{Workchain: -1, Address: ton.Bits256(accounts[0])})},
+			wantLts: nil,
+		},
+		{
+			name:      "short pages",
+			source:    &fakeBlockTransactionSource{txs: txs, limit: 2},
+			wantLts:   []uint64{1, 2, 3, 4, 5, 6},
+			wantCalls: 3,
+		},
+		{
+			name:    "page repeats previous one",
+			source:  &fakeBlockTransactionSource{txs: txs, ignoreAfter: true},
+			opts:    []BlockTransactionsOption{WithBlockTransactionsPageSize(4)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := newBlockTransactionIterator(tt.source, blockID, tt.opts...)
+			got, err := it.All(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("All() failed: %v", err)
+			}
+			if !reflect.DeepEqual(txLts(got), tt.wantLts) {
+				t.Fatalf("want lts %v, got: %v", tt.wantLts, txLts(got))
+			}
+			if tt.source.calls != tt.wantCalls {
+				t.Fatalf("want %v requests, got: %v", tt.wantCalls, tt.source.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Package cache provides storages for immutable data returned by lite servers,
+// like blocks, transactions and library cells.
+package cache
+
+// Cache stores immutable answers of lite servers.
+// Keys are built by liteapi.Client and uniquely identify a value,
+// so a value stored once never changes.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns a value stored with the given key.
+	Get(key string) ([]byte, bool)
+	// Set stores the value with the given key.
+	// A cache can evict other values to keep within its size limit or ignore the value if it is too large.
+	Set(key string, value []byte)
+	// Stats returns statistics of the cache.
+	Stats() Stats
+}
+
+// Stats contains statistics of a cache.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	// Entries is a number of values in the cache.
+	Entries int
+	// Size is a total size of values in the cache in bytes.
+	Size int64
+	// Evictions is a number of values removed to keep the cache within its size limit.
+	Evictions uint64
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tmpSuffix = ".tmp"
+
+// Filesystem is a cache keeping values in files of a directory,
+// so cached data survives restarts of an application.
+// It evicts the least recently used values when the total size of values exceeds its limit.
+// The directory must not be shared with other Filesystem caches running at the same time.
+type Filesystem struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	files   map[string]*list.Element
+	recency *list.List // the most recently used file goes first.
+	stats   Stats
+}
+
+type fsEntry struct {
+	name string
+	size int64
+}
+
+var _ Cache = &Filesystem{}
+
+// NewFilesystem returns a cache keeping at most maxSize bytes of values in the given directory.
+// Values stored in the directory by a previous instance are reused.
+func NewFilesystem(dir string, maxSize int64) (*Filesystem, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type file struct {
+		fsEntry
+		modTime time.Time
+	}
+	var existing []file
+	for _, e := range dirEntries {
+		if e.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(e.Name(), tmpSuffix) {
+			// a leftover of an interrupted write.
+			os.Remove(filepath.Join(dir, e.Name()))
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, file{fsEntry: fsEntry{name: e.Name(), size: info.Size()}, modTime: info.ModTime()})
+	}
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i].modTime.After(existing[j].modTime)
+	})
+	c := &Filesystem{
+		dir:     dir,
+		maxSize: maxSize,
+		files:   map[string]*list.Element{},
+		recency: list.New(),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range existing {
+		c.files[f.name] = c.recency.PushBack(&fsEntry{name: f.name, size: f.size})
+		c.stats.Entries += 1
+		c.stats.Size += f.size
+	}
+	c.evict()
+	return c, nil
+}
+
+func filename(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *Filesystem) Get(key string) ([]byte, bool) {
+	name := filename(key)
+	c.mu.Lock()
+	_, ok := c.files[name]
+	c.mu.Unlock()
+	if !ok {
+		c.miss()
+		return nil, false
+	}
+	path := filepath.Join(c.dir, name)
+	value, err := os.ReadFile(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.files[name]
+	if err != nil || !ok {
+		if ok {
+			// the file has been removed or damaged.
+			c.remove(elem)
+		}
+		c.stats.Misses += 1
+		return nil, false
+	}
+	c.stats.Hits += 1
+	c.recency.MoveToFront(elem)
+	// the modification time keeps the order of values between restarts.
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return value, true
+}
+
+func (c *Filesystem) miss() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Misses += 1
+}
+
+func (c *Filesystem) Set(key string, value []byte) {
+	size := int64(len(value))
+	if size > c.maxSize {
+		return
+	}
+	name := filename(key)
+	// the value is written to a temporary file first, so a reader never sees a partially written file.
+	tmp, err := os.CreateTemp(c.dir, "*"+tmpSuffix)
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(value)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, name))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.files[name]; ok {
+		// the file has been replaced.
+		c.remove(elem)
+	}
+	c.files[name] = c.recency.PushFront(&fsEntry{name: name, size: size})
+	c.stats.Entries += 1
+	c.stats.Size += size
+	c.evict()
+}
+
+// evict removes the least recently used files until the cache fits its size limit.
+func (c *Filesystem) evict() {
+	for c.stats.Size > c.maxSize {
+		entry := c.remove(c.recency.Back())
+		os.Remove(filepath.Join(c.dir, entry.name))
+		c.stats.Evictions += 1
+	}
+}
+
+func (c *Filesystem) remove(elem *list.Element) *fsEntry {
+	entry := c.recency.Remove(elem).(*fsEntry)
+	delete(c.files, entry.name)
+	c.stats.Entries -= 1
+	c.stats.Size -= entry.size
+	return entry
+}
+
+func (c *Filesystem) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
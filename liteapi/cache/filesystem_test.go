@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestFilesystem(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewFilesystem(dir, 10)
+	if err != nil {
+		t.Fatalf("NewFilesystem() failed: %v", err)
+	}
+	c.Set("a", []byte("aaaa"))
+	c.Set("b", []byte("bbbb"))
+	if value, ok := c.Get("a"); !ok || !bytes.Equal(value, []byte("aaaa")) {
+		t.Fatalf("want a value, got: %s, %v", value, ok)
+	}
+	// "b" is the least recently used value now.
+	c.Set("c", []byte("cccc"))
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("b must be evicted")
+	}
+	want := Stats{Hits: 1, Misses: 1, Entries: 2, Size: 8, Evictions: 1}
+	if stats := c.Stats(); stats != want {
+		t.Fatalf("want stats: %+v, got: %+v", want, stats)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("want 2 files, got: %v", len(files))
+	}
+
+	// values are kept between restarts.
+	restarted, err := NewFilesystem(dir, 10)
+	if err != nil {
+		t.Fatalf("NewFilesystem() failed: %v", err)
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := restarted.Get(key); !ok {
+			t.Fatalf("%v must be cached", key)
+		}
+	}
+	if stats := restarted.Stats(); stats.Entries != 2 || stats.Size != 8 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	// a smaller limit evicts the least recently used values.
+	shrunk, err := NewFilesystem(dir, 4)
+	if err != nil {
+		t.Fatalf("NewFilesystem() failed: %v", err)
+	}
+	if _, ok := shrunk.Get("c"); !ok {
+		t.Fatalf("c must be cached")
+	}
+	if _, ok := shrunk.Get("a"); ok {
+		t.Fatalf("a must be evicted")
+	}
+}
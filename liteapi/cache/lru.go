@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is an in-memory cache that evicts the least recently used values
+// when the total size of values exceeds its limit.
+type LRU struct {
+	maxSize int64
+
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	recency *list.List // the most recently used entry goes first.
+	stats   Stats
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+var _ Cache = &LRU{}
+
+// NewLRU returns an in-memory cache keeping at most maxSize bytes of values.
+func NewLRU(maxSize int64) *LRU {
+	return &LRU{
+		maxSize: maxSize,
+		items:   map[string]*list.Element{},
+		recency: list.New(),
+	}
+}
+
+func (c *LRU) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.stats.Misses += 1
+		return nil, false
+	}
+	c.stats.Hits += 1
+	c.recency.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (c *LRU) Set(key string, value []byte) {
+	size := int64(len(value))
+	if size > c.maxSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	c.items[key] = c.recency.PushFront(&lruEntry{key: key, value: value})
+	c.stats.Entries += 1
+	c.stats.Size += size
+	for c.stats.Size > c.maxSize {
+		c.remove(c.recency.Back())
+		c.stats.Evictions += 1
+	}
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	entry := c.recency.Remove(elem).(*lruEntry)
+	delete(c.items, entry.key)
+	c.stats.Entries -= 1
+	c.stats.Size -= int64(len(entry.value))
+}
+
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
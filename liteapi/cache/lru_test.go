@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU(10)
+	c.Set("a", []byte("aaaa"))
+	c.Set("b", []byte("bbbb"))
+	if value, ok := c.Get("a"); !ok || !bytes.Equal(value, []byte("aaaa")) {
+		t.Fatalf("want a value, got: %s, %v", value, ok)
+	}
+	// "b" is the least recently used value now.
+	c.Set("c", []byte("cccc"))
+	if _, ok := c.Get("b"); ok {
+		t.Fatalf("b must be evicted")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatalf("c must be cached")
+	}
+	// a value exceeding the limit is not cached.
+	c.Set("d", make([]byte, 11))
+	if _, ok := c.Get("d"); ok {
+		t.Fatalf("d must not be cached")
+	}
+	want := Stats{Hits: 2, Misses: 2, Entries: 2, Size: 8, Evictions: 1}
+	if stats := c.Stats(); stats != want {
+		t.Fatalf("want stats: %+v, got: %+v", want, stats)
+	}
+}
@@ -0,0 +1,79 @@
+package liteapi
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/liteapi/cache"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// WithCache configures a client to keep immutable data in the given cache:
+// blocks, transactions, library cells and configs of blocks pinned with WithBlock().
+// An answer is stored only after it passes the checks of the client's proof policy.
+// Take a look at cache.NewLRU() and cache.NewFilesystem().
+func WithCache(c cache.Cache) Option {
+	return func(o *Options) error {
+		o.Cache = c
+		return nil
+	}
+}
+
+// CacheStats returns statistics of the cache configured with WithCache().
+func (c *Client) CacheStats() (cache.Stats, bool) {
+	if c.cache == nil {
+		return cache.Stats{}, false
+	}
+	return c.cache.Stats(), true
+}
+
+func blockCacheKey(blockID ton.BlockIDExt) string {
+	return "block" + blockID.String()
+}
+
+func transactionCacheKey(blockID ton.BlockIDExt, accountID ton.AccountID, lt uint64) string {
+	return fmt.Sprintf("tx%v%v:%d", blockID.String(), accountID.ToRaw(), lt)
+}
+
+func transactionsCacheKey(count uint32, accountID ton.AccountID, lt uint64, hash ton.Bits256) string {
+	return fmt.Sprintf("txs%v:%d:%x:%d", accountID.ToRaw(), lt, hash, count)
+}
+
+func libraryCacheKey(hash ton.Bits256) string {
+	return fmt.Sprintf("lib%x", hash)
+}
+
+func configCacheKey(blockID ton.BlockIDExt, mode ConfigMode) string {
+	return fmt.Sprintf("config%v:%d", blockID.String(), mode)
+}
+
+// cachedQuery returns a TL-encoded answer stored with the given key in the client's cache.
+// Otherwise, it gets the answer with the query function.
+// The second return value reports whether the answer is taken from the cache.
+// A new answer is not stored: a caller stores it with cacheAnswer once the answer passes verification,
+// so an answer of a lying lite server never gets into the cache.
+func cachedQuery[T any](c *Client, key string, query func() (T, error)) (T, bool, error) {
+	if c.cache == nil {
+		res, err := query()
+		return res, false, err
+	}
+	var res T
+	if data, ok := c.cache.Get(key); ok {
+		if err := tl.Unmarshal(bytes.NewReader(data), &res); err == nil {
+			return res, true, nil
+		}
+	}
+	res, err := query()
+	return res, false, err
+}
+
+// cacheAnswer stores a TL-encoded answer with the given key in the client's cache.
+func cacheAnswer[T any](c *Client, key string, res T) {
+	if c.cache == nil {
+		return
+	}
+	if data, err := tl.Marshal(res); err == nil {
+		c.cache.Set(key, data)
+	}
+}
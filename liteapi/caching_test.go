@@ -0,0 +1,256 @@
+package liteapi
+
+import (
+	"context"
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"encoding/base64"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteapi/cache"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type librariesTestHandler struct {
+	cassetteTestHandler
+
+	libraries map[ton.Bits256][]byte
+
+	mu        sync.Mutex
+	requested [][]ton.Bits256
+}
+
+func (h *librariesTestHandler) LiteServerGetLibraries(ctx context.Context, request liteclient.LiteServerGetLibrariesRequest) (liteclient.LiteServerLibraryResultC, error) {
+	var res liteclient.LiteServerLibraryResultC
+	var requested []ton.Bits256
+	for _, hash := range request.LibraryList {
+		requested = append(requested, ton.Bits256(hash))
+		if data, ok := h.libraries[ton.Bits256(hash)]; ok {
+			res.Result = append(res.Result, liteclient.LiteServerLibraryEntryC{Hash: hash, Data: data})
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requested = append(h.requested, requested)
+	return res, nil
+}
+
+func TestClient_GetLibrariesCache(t *testing.T) {
+	handler := &librariesTestHandler{libraries: map[ton.Bits256][]byte{}}
+	var hashes []ton.Bits256
+	for i := 0; i < 3; i++ {
+		cell := boc.NewCell()
+		if err := cell.WriteUint(uint64(i), 32); err != nil {
+			t.Fatalf("WriteUint() failed: %v", err)
+		}
+		hash, err := cell.Hash256()
+		if err != nil {
+			t.Fatalf("Hash256() failed: %v", err)
+		}
+		data, err := cell.ToBoc()
+		if err != nil {
+			t.Fatalf("ToBoc() failed: %v", err)
+		}
+		hashes = append(hashes, hash)
+		handler.libraries[hash] = data
+	}
+	// a lite server returns a library under a wrong hash.
+	wrong := ton.Bits256{1}
+	handler.libraries[wrong] = handler.libraries[hashes[0]]
+
+	_, key, err := ed25519.GenerateKey(crand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	server := liteclient.NewServer(key, handler)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	go server.Serve(l)
+	defer server.Close()
+
+	servers := []config.LiteServer{{Host: l.Addr().String(), Key: base64.StdEncoding.EncodeToString(server.PublicKey())}}
+	api, err := NewClient(WithLiteServers(servers), WithCache(cache.NewLRU(1<<20)))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	defer api.Close(context.Background())
+
+	ctx := context.Background()
+	for _, list := range [][]ton.Bits256{
+		{hashes[0], hashes[1], wrong},
+		{hashes[0], hashes[1], hashes[2], wrong},
+		{hashes[2], hashes[1]},
+	} {
+		libs, err := api.GetLibraries(ctx, list)
+		if err != nil {
+			t.Fatalf("GetLibraries() failed: %v", err)
+		}
+		if len(libs) != len(list) {
+			t.Fatalf("want %v libraries, got: %v", len(list), len(libs))
+		}
+	}
+	want := [][]ton.Bits256{
+		{hashes[0], hashes[1], wrong},
+		{hashes[2], wrong},
+	}
+	if !reflect.DeepEqual(handler.requested, want) {
+		t.Fatalf("want requested libraries: %x, got: %x", want, handler.requested)
+	}
+	stats, ok := api.CacheStats()
+	if !ok {
+		t.Fatalf("cache is not configured")
+	}
+	if stats.Entries != 3 || stats.Hits != 4 || stats.Misses != 5 {
+		t.Fatalf("unexpected cache stats: %+v", stats)
+	}
+}
+
+func Test_cachedQuery(t *testing.T) {
+	c := &Client{cache: cache.NewLRU(1 << 20)}
+	calls := 0
+	query := func() (liteclient.LiteServerBlockDataC, error) {
+		calls += 1
+		return liteclient.LiteServerBlockDataC{
+			Id:   liteclient.TonNodeBlockIdExtC{Workchain: 0xffffffff, Seqno: 100},
+			Data: []byte{1, 2, 3},
+		}, nil
+	}
+	first, cached, err := cachedQuery(c, "block", query)
+	if err != nil || cached {
+		t.Fatalf("want a query, got: cached %v, %v", cached, err)
+	}
+	// an answer gets into the cache only with cacheAnswer.
+	if _, cached, err := cachedQuery(c, "block", query); err != nil || cached || calls != 2 {
+		t.Fatalf("want a query, got: %v calls, cached %v, %v", calls, cached, err)
+	}
+	cacheAnswer(c, "block", first)
+	second, cached, err := cachedQuery(c, "block", query)
+	if err != nil || !cached {
+		t.Fatalf("want a cached answer, got: cached %v, %v", cached, err)
+	}
+	if calls != 2 {
+		t.Fatalf("want 2 calls, got: %v", calls)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("want %v, got: %v", first, second)
+	}
+	// damaged data is requested again.
+	c.cache.Set("damaged", []byte{1})
+	if _, cached, err := cachedQuery(c, "damaged", query); err != nil || cached || calls != 3 {
+		t.Fatalf("want a query, got: %v calls, %v", calls, err)
+	}
+}
+
+type blockTestHandler struct {
+	cassetteTestHandler
+
+	block []byte
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (h *blockTestHandler) LiteServerGetBlock(ctx context.Context, request liteclient.LiteServerGetBlockRequest) (liteclient.LiteServerBlockDataC, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	return liteclient.LiteServerBlockDataC{Id: request.Id, Data: h.block}, nil
+}
+
+func (h *blockTestHandler) callCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls
+}
+
+func TestClient_GetBlockCache(t *testing.T) {
+	data, err := os.ReadFile("../tlb/testdata/block-4/block.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	root, err := boc.DeserializeSingleRootBoc(data)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	tests := []struct {
+		name     string
+		getBlock func(api *Client, ctx context.Context, blockID ton.BlockIDExt) error
+	}{
+		{
+			name: "GetBlock",
+			getBlock: func(api *Client, ctx context.Context, blockID ton.BlockIDExt) error {
+				_, err := api.GetBlock(ctx, blockID)
+				return err
+			},
+		},
+		{
+			name: "GetBlockRaw",
+			getBlock: func(api *Client, ctx context.Context, blockID ton.BlockIDExt) error {
+				_, err := api.GetBlockRaw(ctx, blockID)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &blockTestHandler{block: data}
+
+			_, key, err := ed25519.GenerateKey(crand.Reader)
+			if err != nil {
+				t.Fatalf("GenerateKey() failed: %v", err)
+			}
+			server := liteclient.NewServer(key, handler)
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Listen() failed: %v", err)
+			}
+			go server.Serve(l)
+			defer server.Close()
+
+			servers := []config.LiteServer{{Host: l.Addr().String(), Key: base64.StdEncoding.EncodeToString(server.PublicKey())}}
+			api, err := NewClient(WithLiteServers(servers), WithCache(cache.NewLRU(1<<20)), WithProofPolicy(ProofPolicyFast))
+			if err != nil {
+				t.Fatalf("NewClient() failed: %v", err)
+			}
+			defer api.Close(context.Background())
+
+			ctx := context.Background()
+			// a lying lite server returns the block for another block ID.
+			lie := ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 1 << 63, Seqno: 1}, RootHash: ton.Bits256{1}}
+			for i := 0; i < 2; i++ {
+				if err := tt.getBlock(api, ctx, lie); err == nil {
+					t.Fatalf("want error")
+				}
+			}
+			if calls := handler.callCount(); calls != 2 {
+				t.Fatalf("want the lie to be requested twice, got: %v calls", calls)
+			}
+			if stats, _ := api.CacheStats(); stats.Entries != 0 {
+				t.Fatalf("want an empty cache, got: %+v", stats)
+			}
+
+			block := ton.BlockIDExt{BlockID: lie.BlockID, RootHash: ton.Bits256(testutil.MustHash(t, root))}
+			for i := 0; i < 2; i++ {
+				if err := tt.getBlock(api, ctx, block); err != nil {
+					t.Fatalf("%v() failed: %v", tt.name, err)
+				}
+			}
+			if calls := handler.callCount(); calls != 3 {
+				t.Fatalf("want the block to be requested once, got: %v calls", calls-2)
+			}
+			if stats, _ := api.CacheStats(); stats.Entries != 1 {
+				t.Fatalf("want 1 cache entry, got: %+v", stats)
+			}
+		})
+	}
+}
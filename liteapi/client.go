@@ -13,6 +13,7 @@ import (
 
 	"github.com/caigou-xyz/tongo/boc"
 	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteapi/cache"
 	"github.com/caigou-xyz/tongo/liteapi/pool"
 	"github.com/caigou-xyz/tongo/liteclient"
 	"github.com/caigou-xyz/tongo/tl"
@@ -43,7 +44,28 @@ type ProofPolicy uint32
 const (
 	// ProofPolicyUnsafe disables proof checks.
 	ProofPolicyUnsafe ProofPolicy = iota
+	// ProofPolicyFast runs only checks that need neither extra requests nor merkle proofs:
+	// a block returned by GetBlock must match its root hash,
+	// and a chain returned by GetBlockProof is verified with VerifyBlockProof.
 	ProofPolicyFast
+	// ProofPolicySecure includes checks of ProofPolicyFast
+	// and verifies proofs of account states returned by GetAccountState and GetAccountStateRaw:
+	// the shard block proof against the masterchain block and
+	// the account state proof against the shard block.
+	// A response that fails verification is rejected with ErrInvalidProof.
+	//
+	// The proofs are verified against the masterchain block a request is sent for,
+	// so the block must be trusted.
+	// It also proves that transactions returned by GetTransactions and GetOneTransactionFromBlock
+	// are included into the blocks they are reported with.
+	// Results of LookupBlockWithProof, GetAccountStatePrunned, GetLibrariesWithProof,
+	// ListBlockTransactionsExt, GetDispatchQueueInfo, GetDispatchQueueMessages and GetConfigAll
+	// are verified against their proofs as well.
+	//
+	// Pin a trusted block with WithBlock if lite servers are not trusted either,
+	// or configure a trusted key block with WithTrustedBlock,
+	// so the masterchain head itself is proven with a chain of block proofs.
+	ProofPolicySecure
 )
 
 // Client provides a convenient way to interact with TON blockchain.
@@ -70,6 +92,16 @@ type Client struct {
 	// the underlying connections pool maintains information about which nodes are archive nodes.
 	archiveDetectionEnabled bool
 
+	// trustedChain, if set, keeps masterchain blocks proven starting from a trusted block.
+	// Masterchain heads returned by GetMasterchainInfo and GetMasterchainInfoExt are proven against it.
+	trustedChain *trustedChain
+
+	// methodEmulator, if set, is used to check results of get methods.
+	methodEmulator MethodEmulator
+
+	// cache, if set, keeps immutable data like blocks and transactions.
+	cache cache.Cache
+
 	// mu protects targetBlockID and networkGlobalID.
 	mu              sync.RWMutex
 	targetBlockID   *ton.BlockIDExt
@@ -93,6 +125,30 @@ type Options struct {
 
 	SyncConnectionsInitialization bool
 	PoolStrategy                  pool.Strategy
+
+	// Recorder, if set, records all lite server queries and their answers.
+	Recorder *liteclient.Cassette
+	// Replay, if set, makes the client answer queries from the cassette
+	// instead of connecting to lite servers.
+	Replay *liteclient.Cassette
+
+	// RequestHooks are notified about every request sent to lite servers.
+	RequestHooks []liteclient.RequestHook
+	// PoolEventHandler is notified about changes in the state of the connections pool.
+	PoolEventHandler pool.EventHandler
+	// RetryPolicy, if set, configures retries of failed requests on other connections of the pool.
+	RetryPolicy *pool.RetryPolicy
+	// ServerSource, if set, is used to reload the list of lite servers every ServerReloadInterval.
+	ServerSource         pool.ServerSource
+	ServerReloadInterval time.Duration
+	// QuorumPolicy, if set, configures how many lite servers must agree on an answer with pool.QuorumStrategy.
+	QuorumPolicy *pool.QuorumPolicy
+	// TrustedBlock, if set, is a masterchain key block all masterchain heads are proven from.
+	TrustedBlock *ton.BlockIDExt
+	// MethodEmulator, if set, is used to check results of get methods returned by lite servers.
+	MethodEmulator MethodEmulator
+	// Cache, if set, keeps immutable data returned by lite servers.
+	Cache cache.Cache
 }
 
 type Option func(o *Options) error
@@ -150,6 +206,8 @@ func WithProofPolicy(policy ProofPolicy) Option {
 	}
 }
 
+// WithDetectArchiveNodes makes connections detect and periodically refresh ranges of blocks their lite servers keep.
+// Queries about old blocks and transactions are routed to lite servers keeping them.
 func WithDetectArchiveNodes() Option {
 	return func(o *Options) error {
 		o.DetectArchiveNodes = true
@@ -166,6 +224,109 @@ func WithInitializationContext(ctx context.Context) Option {
 	}
 }
 
+// WithRecorder configures a client to record all queries to lite servers and their answers to the given cassette.
+// Queries the connections pool sends in background to track lite servers are marked with liteclient.WithBackground,
+// so they don't change the order in which the session is replayed.
+// Save the cassette with Cassette.Save and use it later with WithReplay to run the same session offline.
+func WithRecorder(cassette *liteclient.Cassette) Option {
+	return func(o *Options) error {
+		o.Recorder = cassette
+		return nil
+	}
+}
+
+// WithReplay configures a client to answer queries from the given cassette without connecting to lite servers.
+// Queries missing in the cassette fail with liteclient.ErrNotRecorded.
+// Some methods use the latest known masterchain block,
+// so use WithBlock() to get exactly the same queries during recording and replaying.
+func WithReplay(cassette *liteclient.Cassette) Option {
+	return func(o *Options) error {
+		o.Replay = cassette
+		return nil
+	}
+}
+
+// WithRequestHook adds a hook to be notified about every request sent to lite servers by any method of a client.
+// RequestInfo.ConnID is an ID of the pool's connection that served the request, see GetPoolStatus().
+func WithRequestHook(hook liteclient.RequestHook) Option {
+	return func(o *Options) error {
+		o.RequestHooks = append(o.RequestHooks, hook)
+		return nil
+	}
+}
+
+// WithPoolEventHandler sets a handler to be notified about
+// masterchain head updates, archive nodes detection and switches to another connection in the connections pool.
+func WithPoolEventHandler(handler pool.EventHandler) Option {
+	return func(o *Options) error {
+		o.PoolEventHandler = handler
+		return nil
+	}
+}
+
+// WithRetryPolicy configures a client to retry idempotent requests on other connections of the pool
+// when a lite server fails to answer, and optionally to send hedged requests.
+// SendMessage is never retried.
+// Retries make sense only with several connections, take a look at WithMaxConnectionsNumber().
+func WithRetryPolicy(policy pool.RetryPolicy) Option {
+	return func(o *Options) error {
+		o.RetryPolicy = &policy
+		return nil
+	}
+}
+
+// WithQuorumPolicy configures a client to send queries pinned to a block to several lite servers
+// and to fail with pool.QuorumMismatchError if their answers differ.
+// It is a cheaper alternative to proof checks, the quorum size must not exceed WithMaxConnectionsNumber().
+func WithQuorumPolicy(policy pool.QuorumPolicy) Option {
+	return func(o *Options) error {
+		o.PoolStrategy = pool.QuorumStrategy
+		o.QuorumPolicy = &policy
+		return nil
+	}
+}
+
+// WithLiteServersReloading configures a client to reload the list of lite servers from the given source
+// every interval, so the client follows changes of a global configuration without a restart.
+// The client connects to new lite servers and drains connections to removed ones without failing in-flight requests,
+// changes are reported to the handler set with WithPoolEventHandler().
+// If no lite servers are configured with other options, the initial list is taken from the source as well.
+// Take a look at LiteServersFromURL(), LiteServersFromFile() and LiteServersFromEnvs().
+func WithLiteServersReloading(source pool.ServerSource, interval time.Duration) Option {
+	return func(o *Options) error {
+		if interval <= 0 {
+			return fmt.Errorf("reload interval must be positive")
+		}
+		o.ServerSource = source
+		o.ServerReloadInterval = interval
+		return nil
+	}
+}
+
+// WithTrustedBlock configures a client to prove every masterchain head it gets from lite servers
+// with a chain of block proofs starting from the given key block.
+// The init block of the global configuration file, see config.GlobalConfigurationFile.Validator,
+// is a good candidate.
+// A head that can't be proven is rejected with ErrInvalidProof.
+func WithTrustedBlock(block ton.BlockIDExt) Option {
+	return func(o *Options) error {
+		o.TrustedBlock = &block
+		return nil
+	}
+}
+
+// WithMethodEmulator configures a client to check results of get methods returned by lite servers.
+// RunSmcMethod and RunSmcMethodByID request proofs of the account state used for execution,
+// verify them against the target block and run the method locally with the given emulator.
+// A result that doesn't match the local execution is rejected with ErrInvalidProof.
+// Take a look at tvm.NewMethodEmulator().
+func WithMethodEmulator(emulator MethodEmulator) Option {
+	return func(o *Options) error {
+		o.MethodEmulator = emulator
+		return nil
+	}
+}
+
 // FromEnvsOrMainnet configures a client to use lite servers from the LITE_SERVERS env variable.
 // If LITE_SERVERS is not set, it downloads public config for mainnet from ton.org.
 func FromEnvsOrMainnet() Option {
@@ -281,38 +442,95 @@ func NewClient(options ...Option) (*Client, error) {
 			return nil, err
 		}
 	}
-	if len(opts.LiteServers) == 0 {
-		return nil, fmt.Errorf("server list empty")
-	}
 	connPool := pool.New(opts.PoolStrategy)
-	initCh := connPool.InitializeConnections(opts.InitCtx, opts.Timeout, opts.MaxConnections, opts.WorkersPerConnection, opts.DetectArchiveNodes, opts.LiteServers)
-	if opts.SyncConnectionsInitialization {
-		if err := <-initCh; err != nil {
+	if opts.PoolEventHandler != nil {
+		connPool.SetEventHandler(opts.PoolEventHandler)
+	}
+	if opts.RetryPolicy != nil {
+		connPool.SetRetryPolicy(*opts.RetryPolicy)
+	}
+	if opts.QuorumPolicy != nil {
+		connPool.SetQuorumPolicy(*opts.QuorumPolicy)
+	}
+	var clientOptions []liteclient.Options
+	for _, hook := range opts.RequestHooks {
+		clientOptions = append(clientOptions, liteclient.OptionRequestHook(hook))
+	}
+	if opts.Replay != nil {
+		clientOptions = append(clientOptions, liteclient.OptionTimeout(opts.Timeout))
+		cli := liteclient.NewReplayClient(opts.Replay, clientOptions...)
+		if err := connPool.AddClient(0, cli, "", opts.DetectArchiveNodes); err != nil {
 			return nil, err
 		}
+	} else {
+		if len(opts.LiteServers) == 0 && opts.ServerSource != nil {
+			servers, err := opts.ServerSource(opts.InitCtx)
+			if err != nil {
+				return nil, err
+			}
+			opts.LiteServers = servers
+		}
+		if len(opts.LiteServers) == 0 {
+			return nil, fmt.Errorf("server list empty")
+		}
+		if opts.Recorder != nil {
+			clientOptions = append(clientOptions, liteclient.OptionRecorder(opts.Recorder))
+		}
+		initCh := connPool.InitializeConnections(opts.InitCtx, opts.Timeout, opts.MaxConnections, opts.WorkersPerConnection, opts.DetectArchiveNodes, opts.LiteServers, clientOptions...)
+		if opts.SyncConnectionsInitialization {
+			if err := <-initCh; err != nil {
+				connPool.Close(opts.InitCtx)
+				return nil, err
+			}
+		}
+		if opts.ServerSource != nil {
+			connPool.WatchServers(opts.ServerSource, opts.ServerReloadInterval)
+		}
 	}
 	client := Client{
 		pool:                    connPool,
 		proofPolicy:             opts.ProofPolicy,
 		archiveDetectionEnabled: opts.DetectArchiveNodes,
+		methodEmulator:          opts.MethodEmulator,
+		cache:                   opts.Cache,
+	}
+	if opts.TrustedBlock != nil {
+		client.trustedChain = newTrustedChain(*opts.TrustedBlock)
 	}
 	go client.pool.Run(context.TODO())
 	return &client, nil
 }
 
-func (c *Client) targetBlockOr(blockID ton.BlockIDExt) ton.BlockIDExt {
+// Close closes all connections to lite servers and stops background goroutines of the client.
+// It waits for the goroutines to exit until ctx is done.
+// In-flight and subsequent requests fail with liteclient.ErrClosed.
+// Clients returned by WithBlock share connections with their parent, so closing any of them closes all of them.
+func (c *Client) Close(ctx context.Context) error {
+	return c.pool.Close(ctx)
+}
+
+// targetClient returns a connection to a lite server keeping the target block of the client and the target block.
+// If the client has no target block, it returns the best connection and its masterchain head.
+func (c *Client) targetClient(ctx context.Context) (*liteclient.Client, ton.BlockIDExt, error) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if c.targetBlockID == nil {
-		return blockID
+	target := c.targetBlockID
+	c.mu.RUnlock()
+	if target == nil {
+		return c.pool.BestMasterchainClient(ctx)
 	}
-	return *c.targetBlockID
+	client, _, err := c.pool.BestClientByMasterchainSeqno(ctx, target.Seqno)
+	return client, *target, err
 }
 
 func (c *Client) WithBlock(block ton.BlockIDExt) *Client {
 	return &Client{
-		pool:          c.pool,
-		targetBlockID: &block,
+		pool:                    c.pool,
+		proofPolicy:             c.proofPolicy,
+		archiveDetectionEnabled: c.archiveDetectionEnabled,
+		trustedChain:            c.trustedChain,
+		methodEmulator:          c.methodEmulator,
+		cache:                   c.cache,
+		targetBlockID:           &block,
 	}
 }
 
@@ -321,7 +539,14 @@ func (c *Client) GetMasterchainInfo(ctx context.Context) (liteclient.LiteServerM
 	if conn == nil {
 		return liteclient.LiteServerMasterchainInfoC{}, pool.ErrNoConnections
 	}
-	return conn.LiteServerGetMasterchainInfo(ctx)
+	res, err := conn.LiteServerGetMasterchainInfo(ctx)
+	if err != nil {
+		return liteclient.LiteServerMasterchainInfoC{}, err
+	}
+	if err := c.proveMasterchainBlock(ctx, res.Last.ToBlockIdExt()); err != nil {
+		return liteclient.LiteServerMasterchainInfoC{}, err
+	}
+	return res, nil
 }
 
 func (c *Client) GetMasterchainInfoExt(ctx context.Context, mode uint32) (liteclient.LiteServerMasterchainInfoExtC, error) {
@@ -329,7 +554,14 @@ func (c *Client) GetMasterchainInfoExt(ctx context.Context, mode uint32) (litecl
 	if conn == nil {
 		return liteclient.LiteServerMasterchainInfoExtC{}, pool.ErrNoConnections
 	}
-	return conn.LiteServerGetMasterchainInfoExt(ctx, liteclient.LiteServerGetMasterchainInfoExtRequest{Mode: mode})
+	res, err := conn.LiteServerGetMasterchainInfoExt(ctx, liteclient.LiteServerGetMasterchainInfoExtRequest{Mode: mode})
+	if err != nil {
+		return liteclient.LiteServerMasterchainInfoExtC{}, err
+	}
+	if err := c.proveMasterchainBlock(ctx, res.Last.ToBlockIdExt()); err != nil {
+		return liteclient.LiteServerMasterchainInfoExtC{}, err
+	}
+	return res, nil
 }
 
 func (c *Client) GetTime(ctx context.Context) (uint32, error) {
@@ -350,7 +582,9 @@ func (c *Client) GetVersion(ctx context.Context) (liteclient.LiteServerVersionC,
 }
 
 func (c *Client) GetBlock(ctx context.Context, blockID ton.BlockIDExt) (tlb.Block, error) {
-	res, err := c.GetBlockRaw(ctx, blockID)
+	res, cached, err := cachedQuery(c, blockCacheKey(blockID), func() (liteclient.LiteServerBlockDataC, error) {
+		return c.getBlockRaw(ctx, blockID)
+	})
 	if err != nil {
 		return tlb.Block{}, err
 	}
@@ -366,32 +600,57 @@ func (c *Client) GetBlock(ctx context.Context, blockID ton.BlockIDExt) (tlb.Bloc
 	if err := decoder.Unmarshal(cells[0], &block); err != nil {
 		return tlb.Block{}, err
 	}
-	if c.proofPolicy == ProofPolicyUnsafe {
-		return block, nil
-	}
-	// this should be quite fast because
-	// when unmarshalling a block, we calculate hashes for transactions and messages.
-	// so most of the cells' hashes should be in the cache.
-	hash, err := decoder.Hasher().Hash(cells[0])
-	if err != nil {
-		return tlb.Block{}, fmt.Errorf("failed to calculate block hash: %w", err)
+	if c.proofPolicy != ProofPolicyUnsafe {
+		// this should be quite fast because
+		// when unmarshalling a block, we calculate hashes for transactions and messages.
+		// so most of the cells' hashes should be in the cache.
+		hash, err := decoder.Hasher().Hash(cells[0])
+		if err != nil {
+			return tlb.Block{}, fmt.Errorf("failed to calculate block hash: %w", err)
+		}
+		if !bytes.Equal(hash[:], blockID.RootHash[:]) {
+			return tlb.Block{}, fmt.Errorf("block hash mismatch")
+		}
 	}
-	if !bytes.Equal(hash[:], blockID.RootHash[:]) {
-		return tlb.Block{}, fmt.Errorf("block hash mismatch")
+	if !cached {
+		cacheAnswer(c, blockCacheKey(blockID), res)
 	}
 	return block, nil
 }
 
+// GetBlockRaw returns the block's BoC.
+// Unless ProofPolicyUnsafe is used, the hash of the block's root is checked against blockID
+// before the answer is cached.
 func (c *Client) GetBlockRaw(ctx context.Context, blockID ton.BlockIDExt) (liteclient.LiteServerBlockDataC, error) {
-	client, err := c.pool.BestClientByBlockID(ctx, blockID.BlockID)
-	if err != nil {
-		return liteclient.LiteServerBlockDataC{}, err
+	res, cached, err := cachedQuery(c, blockCacheKey(blockID), func() (liteclient.LiteServerBlockDataC, error) {
+		return c.getBlockRaw(ctx, blockID)
+	})
+	if err != nil || cached {
+		return res, err
 	}
-	res, err := client.LiteServerGetBlock(ctx, liteclient.LiteServerGetBlockRequest{liteclient.BlockIDExt(blockID)})
+	if c.proofPolicy != ProofPolicyUnsafe {
+		root, err := boc.DeserializeSingleRootBoc(res.Data)
+		if err != nil {
+			return liteclient.LiteServerBlockDataC{}, err
+		}
+		hash, err := root.Hash256()
+		if err != nil {
+			return liteclient.LiteServerBlockDataC{}, fmt.Errorf("failed to calculate block hash: %w", err)
+		}
+		if hash != blockID.RootHash {
+			return liteclient.LiteServerBlockDataC{}, fmt.Errorf("block hash mismatch")
+		}
+	}
+	cacheAnswer(c, blockCacheKey(blockID), res)
+	return res, nil
+}
+
+func (c *Client) getBlockRaw(ctx context.Context, blockID ton.BlockIDExt) (liteclient.LiteServerBlockDataC, error) {
+	client, err := c.pool.BestClientByBlockID(ctx, blockID.BlockID)
 	if err != nil {
 		return liteclient.LiteServerBlockDataC{}, err
 	}
-	return res, err
+	return client.LiteServerGetBlock(ctx, liteclient.LiteServerGetBlockRequest{Id: liteclient.BlockIDExt(blockID)})
 }
 
 func (c *Client) GetState(ctx context.Context, blockID ton.BlockIDExt) ([]byte, ton.Bits256, ton.Bits256, error) {
@@ -439,8 +698,19 @@ func (c *Client) GetBlockHeaderRaw(ctx context.Context, blockID ton.BlockIDExt,
 	return res, nil
 }
 
+// LookupBlock looks up a block by its seqno (mode 1), logical time (mode 2) or generation time (mode 4).
+// With WithDetectArchiveNodes, the query goes to a lite server that keeps the requested block.
 func (c *Client) LookupBlock(ctx context.Context, blockID ton.BlockID, mode uint32, lt *uint64, utime *uint32) (ton.BlockIDExt, tlb.BlockInfo, error) {
-	client, err := c.pool.BestClientByBlockID(ctx, blockID)
+	var client *liteclient.Client
+	var err error
+	switch {
+	case mode&2 != 0 && lt != nil:
+		client, _, err = c.pool.BestClientByLt(ctx, *lt)
+	case mode&4 != 0 && utime != nil:
+		client, _, err = c.pool.BestClientByUtime(ctx, *utime)
+	default:
+		client, err = c.pool.BestClientByBlockID(ctx, blockID)
+	}
 	if err != nil {
 		return ton.BlockIDExt{}, tlb.BlockInfo{}, err
 	}
@@ -460,6 +730,53 @@ func (c *Client) LookupBlock(ctx context.Context, blockID ton.BlockID, mode uint
 	return decodeBlockHeader(res)
 }
 
+// LookupBlockWithProof looks up a block like LookupBlock does
+// and proves that the found block belongs to the chain of the masterchain block of the client:
+// its target block or the latest masterchain block.
+// With ProofPolicySecure, the proof is verified and
+// a block that doesn't match the query is rejected with ErrInvalidProof.
+func (c *Client) LookupBlockWithProof(ctx context.Context, blockID ton.BlockID, mode uint32, lt *uint64, utime *uint32) (ton.BlockIDExt, tlb.BlockInfo, error) {
+	res, mcBlock, err := c.lookupBlockWithProofRaw(ctx, blockID, mode, lt, utime)
+	if err != nil {
+		return ton.BlockIDExt{}, tlb.BlockInfo{}, err
+	}
+	if c.proofPolicy != ProofPolicySecure {
+		_, info, err := decodeBlockHeader(liteclient.LiteServerBlockHeaderC{Id: res.Id, HeaderProof: res.Header})
+		return res.Id.ToBlockIdExt(), info, err
+	}
+	if err := c.proveMasterchainBlock(ctx, mcBlock); err != nil {
+		return ton.BlockIDExt{}, tlb.BlockInfo{}, err
+	}
+	return verifyLookupBlock(mcBlock, blockID, mode, lt, utime, res)
+}
+
+func (c *Client) LookupBlockWithProofRaw(ctx context.Context, blockID ton.BlockID, mode uint32, lt *uint64, utime *uint32) (liteclient.LiteServerLookupBlockResultC, error) {
+	res, _, err := c.lookupBlockWithProofRaw(ctx, blockID, mode, lt, utime)
+	return res, err
+}
+
+func (c *Client) lookupBlockWithProofRaw(ctx context.Context, blockID ton.BlockID, mode uint32, lt *uint64, utime *uint32) (liteclient.LiteServerLookupBlockResultC, ton.BlockIDExt, error) {
+	client, mcBlock, err := c.targetClient(ctx)
+	if err != nil {
+		return liteclient.LiteServerLookupBlockResultC{}, ton.BlockIDExt{}, err
+	}
+	res, err := client.LiteServerLookupBlockWithProof(ctx, liteclient.LiteServerLookupBlockWithProofRequest{
+		Mode: mode,
+		Id: liteclient.TonNodeBlockIdC{
+			Workchain: uint32(blockID.Workchain),
+			Shard:     blockID.Shard,
+			Seqno:     blockID.Seqno,
+		},
+		McBlockId: liteclient.BlockIDExt(mcBlock),
+		Lt:        lt,
+		Utime:     utime,
+	})
+	if err != nil {
+		return liteclient.LiteServerLookupBlockResultC{}, ton.BlockIDExt{}, err
+	}
+	return res, mcBlock, nil
+}
+
 func decodeBlockHeader(header liteclient.LiteServerBlockHeaderC) (ton.BlockIDExt, tlb.BlockInfo, error) {
 	cells, err := boc.DeserializeBoc(header.HeaderProof)
 	if err != nil {
@@ -479,6 +796,7 @@ func decodeBlockHeader(header liteclient.LiteServerBlockHeaderC) (ton.BlockIDExt
 }
 
 // SendMessage verifies that the given payload contains an external message and sends it to a lite server.
+// The message is sent exactly once, a retry policy of the client doesn't apply to it.
 func (c *Client) SendMessage(ctx context.Context, payload []byte) (uint32, error) {
 	if err := VerifySendMessagePayload(payload); err != nil {
 		return 0, err
@@ -501,23 +819,34 @@ func (c *Client) RunSmcMethodByID(ctx context.Context, accountID ton.AccountID,
 	if err != nil {
 		return 0, tlb.VmStack{}, err
 	}
-	client, masterHead, err := c.pool.BestClientByAccountID(ctx, accountID, false)
+	client, blockID, err := c.targetClient(ctx)
 	if err != nil {
 		return 0, tlb.VmStack{}, err
 	}
 	req := liteclient.LiteServerRunSmcMethodRequest{
-		Mode:     4,
-		Id:       liteclient.BlockIDExt(c.targetBlockOr(masterHead)),
+		Mode:     runSmcMethodMode,
+		Id:       liteclient.BlockIDExt(blockID),
 		Account:  liteclient.AccountID(accountID),
 		MethodId: uint64(methodID),
 		Params:   b,
 	}
+	if c.methodEmulator != nil {
+		req.Mode = runSmcMethodProofMode
+	}
 	res, err := client.LiteServerRunSmcMethod(ctx, req)
 	if err != nil {
 		return 0, tlb.VmStack{}, err
 	}
+	if c.methodEmulator != nil {
+		if err := c.proveMasterchainBlock(ctx, blockID); err != nil {
+			return 0, tlb.VmStack{}, err
+		}
+		if err := c.verifyRunSmcMethod(ctx, client, blockID, accountID, methodID, params, res); err != nil {
+			return 0, tlb.VmStack{}, err
+		}
+	}
 	var result tlb.VmStack
-	if res.ExitCode == 4294967040 { //-256
+	if res.ExitCode == accountNotFoundExitCode {
 		return res.ExitCode, nil, ErrAccountNotFound
 	}
 	cells, err := boc.DeserializeBoc(res.Result)
@@ -560,16 +889,15 @@ func (c *Client) GetAccountState(ctx context.Context, accountID ton.AccountID) (
 	if err != nil {
 		return tlb.ShardAccount{}, err
 	}
-	lt, hash, err := decodeAccountDataFromProof(res.Proof, accountID)
+	lt, hash, err := c.accountDataFromProof(res, accountID)
 	return tlb.ShardAccount{Account: acc, LastTransHash: hash, LastTransLt: lt}, err
 }
 
 func (c *Client) GetAccountStateRaw(ctx context.Context, accountID ton.AccountID) (liteclient.LiteServerAccountStateC, error) {
-	client, masterHead, err := c.pool.BestClientByAccountID(ctx, accountID, false)
+	client, blockID, err := c.targetClient(ctx)
 	if err != nil {
 		return liteclient.LiteServerAccountStateC{}, err
 	}
-	blockID := c.targetBlockOr(masterHead)
 	res, err := client.LiteServerGetAccountState(ctx, liteclient.LiteServerGetAccountStateRequest{
 		Account: liteclient.AccountID(accountID),
 		Id:      liteclient.BlockIDExt(blockID),
@@ -577,9 +905,83 @@ func (c *Client) GetAccountStateRaw(ctx context.Context, accountID ton.AccountID
 	if err != nil {
 		return liteclient.LiteServerAccountStateC{}, err
 	}
+	if c.proofPolicy == ProofPolicySecure {
+		if err := c.proveMasterchainBlock(ctx, blockID); err != nil {
+			return liteclient.LiteServerAccountStateC{}, err
+		}
+		if err := verifyAccountState(blockID, accountID, res); err != nil {
+			return liteclient.LiteServerAccountStateC{}, err
+		}
+	}
 	return res, nil
 }
 
+// GetAccountStatePrunned returns a state of the account with pruned code, data and other cells referenced by the account,
+// so only fields stored in the account's root cell are available, for example, its status and balance.
+// It is much cheaper than GetAccountState for accounts with a large state.
+// With ProofPolicySecure, the state is verified against the masterchain block.
+func (c *Client) GetAccountStatePrunned(ctx context.Context, accountID ton.AccountID) (tlb.ShardAccount, error) {
+	res, err := c.GetAccountStatePrunnedRaw(ctx, accountID)
+	if err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	if len(res.State) == 0 {
+		return tlb.ShardAccount{Account: tlb.Account{SumType: "AccountNone"}}, nil
+	}
+	root, _, err := decodePrunedAccount(res.State)
+	if err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	var acc tlb.Account
+	if err := tlb.Unmarshal(root, &acc); err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	lt, hash, err := c.accountDataFromProof(res, accountID)
+	return tlb.ShardAccount{Account: acc, LastTransHash: hash, LastTransLt: lt}, err
+}
+
+func (c *Client) GetAccountStatePrunnedRaw(ctx context.Context, accountID ton.AccountID) (liteclient.LiteServerAccountStateC, error) {
+	client, blockID, err := c.targetClient(ctx)
+	if err != nil {
+		return liteclient.LiteServerAccountStateC{}, err
+	}
+	res, err := client.LiteServerGetAccountStatePrunned(ctx, liteclient.LiteServerGetAccountStatePrunnedRequest{
+		Account: liteclient.AccountID(accountID),
+		Id:      liteclient.BlockIDExt(blockID),
+	})
+	if err != nil {
+		return liteclient.LiteServerAccountStateC{}, err
+	}
+	if c.proofPolicy != ProofPolicySecure {
+		return res, nil
+	}
+	if err := c.proveMasterchainBlock(ctx, blockID); err != nil {
+		return liteclient.LiteServerAccountStateC{}, err
+	}
+	var accountHash *ton.Bits256
+	if len(res.State) > 0 {
+		_, hash, err := decodePrunedAccount(res.State)
+		if err != nil {
+			return liteclient.LiteServerAccountStateC{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		accountHash = &hash
+	}
+	if err := verifyPrunedAccountState(blockID, accountID, res, accountHash); err != nil {
+		return liteclient.LiteServerAccountStateC{}, err
+	}
+	return res, nil
+}
+
+// accountDataFromProof returns last_trans_lt and last_trans_hash of the account
+// from the proof of the shard state returned along with the account's state.
+// With ProofPolicySecure, the proof is verified against the answer's shard block.
+func (c *Client) accountDataFromProof(res liteclient.LiteServerAccountStateC, account ton.AccountID) (uint64, tlb.Bits256, error) {
+	if c.proofPolicy == ProofPolicySecure {
+		return decodeVerifiedAccountData(res, account)
+	}
+	return decodeAccountDataFromProof(res.Proof, account)
+}
+
 func decodeAccountDataFromProof(bocBytes []byte, account ton.AccountID) (uint64, tlb.Bits256, error) {
 	cells, err := boc.DeserializeBoc(bocBytes)
 	if err != nil {
@@ -605,6 +1007,39 @@ func decodeAccountDataFromProof(bocBytes []byte, account ton.AccountID) (uint64,
 	return 0, tlb.Bits256{}, fmt.Errorf("account not found in ShardAccounts")
 }
 
+// decodeVerifiedAccountData is like decodeAccountDataFromProof,
+// but it checks the proof against the shard block of the answer.
+func decodeVerifiedAccountData(res liteclient.LiteServerAccountStateC, account ton.AccountID) (uint64, tlb.Bits256, error) {
+	blockProof, stateProof, err := proofRoots(res.Proof)
+	if err != nil {
+		return 0, tlb.Bits256{}, err
+	}
+	stateHash, err := blockStateHash(blockProof, res.Shardblk.ToBlockIdExt())
+	if err != nil {
+		return 0, tlb.Bits256{}, err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProof, stateHash)
+	if err != nil {
+		return 0, tlb.Bits256{}, fmt.Errorf("shard state: %w", err)
+	}
+	value, err := findShardAccount(stateRoot, account)
+	if err != nil {
+		return 0, tlb.Bits256{}, err
+	}
+	if value == nil {
+		return 0, tlb.Bits256{}, fmt.Errorf("account not found in ShardAccounts")
+	}
+	// account_descr$_ account:^Account last_trans_hash:bits256 last_trans_lt:uint64 = ShardAccount;
+	var data struct {
+		LastTransHash tlb.Bits256
+		LastTransLt   uint64
+	}
+	if err := tlb.Unmarshal(value, &data); err != nil {
+		return 0, tlb.Bits256{}, err
+	}
+	return data.LastTransLt, data.LastTransHash, nil
+}
+
 func (c *Client) GetShardInfo(
 	ctx context.Context,
 	blockID ton.BlockIDExt,
@@ -682,14 +1117,16 @@ func (c *Client) GetOneTransactionFromBlock(
 	blockId ton.BlockIDExt,
 	lt uint64,
 ) (ton.Transaction, error) {
-	client, _, err := c.pool.BestClientByAccountID(ctx, accountID, false)
-	if err != nil {
-		return ton.Transaction{}, err
-	}
-	r, err := client.LiteServerGetOneTransaction(ctx, liteclient.LiteServerGetOneTransactionRequest{
-		Id:      liteclient.BlockIDExt(blockId),
-		Account: liteclient.AccountID(accountID),
-		Lt:      lt,
+	r, cached, err := cachedQuery(c, transactionCacheKey(blockId, accountID, lt), func() (liteclient.LiteServerTransactionInfoC, error) {
+		client, err := c.pool.BestClientByBlockID(ctx, blockId.BlockID)
+		if err != nil {
+			return liteclient.LiteServerTransactionInfoC{}, err
+		}
+		return client.LiteServerGetOneTransaction(ctx, liteclient.LiteServerGetOneTransactionRequest{
+			Id:      liteclient.BlockIDExt(blockId),
+			Account: liteclient.AccountID(accountID),
+			Lt:      lt,
+		})
 	})
 	if err != nil {
 		return ton.Transaction{}, err
@@ -705,10 +1142,36 @@ func (c *Client) GetOneTransactionFromBlock(
 		return ton.Transaction{}, boc.ErrNotSingleRoot
 	}
 	var t tlb.Transaction
-	err = tlb.Unmarshal(cells[0], &t)
-	return ton.Transaction{Transaction: t, BlockID: r.Id.ToBlockIdExt()}, err
+	if err := tlb.Unmarshal(cells[0], &t); err != nil {
+		return ton.Transaction{}, err
+	}
+	if c.proofPolicy == ProofPolicySecure {
+		if r.Id.ToBlockIdExt() != blockId {
+			return ton.Transaction{}, fmt.Errorf("%w: transaction is from block %v instead of %v", ErrInvalidProof, r.Id.ToBlockIdExt(), blockId)
+		}
+		if t.Lt != lt || ton.Bits256(t.AccountAddr) != accountID.Address {
+			return ton.Transaction{}, fmt.Errorf("%w: transaction %v of account %x instead of %v", ErrInvalidProof, t.Lt, t.AccountAddr, lt)
+		}
+		proof, err := boc.DeserializeSingleRootBoc(r.Proof)
+		if err != nil {
+			return ton.Transaction{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+		if err := checkTransactionProof(blockId, accountID, lt, ton.Bits256(t.Hash()), proof); err != nil {
+			return ton.Transaction{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+	}
+	if !cached {
+		cacheAnswer(c, transactionCacheKey(blockId, accountID, lt), r)
+	}
+	return ton.Transaction{Transaction: t, BlockID: r.Id.ToBlockIdExt()}, nil
 }
 
+// GetTransactions returns up to count transactions of the account
+// going back in time starting from the transaction with the given lt and hash.
+// It checks that the transactions form an unbroken chain starting from the given transaction.
+// With ProofPolicySecure, it also proves that each block of the transactions
+// is linked to a proven masterchain block and that the transactions are included into their blocks.
+// Transactions of one block are proven together with a single listBlockTransactionsExt request.
 func (c *Client) GetTransactions(
 	ctx context.Context,
 	count uint32,
@@ -716,10 +1179,25 @@ func (c *Client) GetTransactions(
 	lt uint64,
 	hash ton.Bits256,
 ) ([]ton.Transaction, error) {
-	r, err := c.GetTransactionsRaw(ctx, count, accountID, lt, hash)
+	key := transactionsCacheKey(count, accountID, lt, hash)
+	r, cached, err := cachedQuery(c, key, func() (liteclient.LiteServerTransactionListC, error) {
+		return c.getTransactionsRaw(ctx, count, accountID, lt, hash)
+	})
 	if err != nil {
 		return nil, err
 	}
+	res, err := c.decodeTransactions(ctx, count, accountID, lt, hash, r)
+	if err != nil {
+		return nil, err
+	}
+	if !cached {
+		cacheAnswer(c, key, r)
+	}
+	return res, nil
+}
+
+// decodeTransactions decodes the answer of liteServer.getTransactions and checks it as GetTransactions describes.
+func (c *Client) decodeTransactions(ctx context.Context, count uint32, accountID ton.AccountID, lt uint64, hash ton.Bits256, r liteclient.LiteServerTransactionListC) ([]ton.Transaction, error) {
 	if len(r.Transactions) == 0 {
 		return []ton.Transaction{}, nil
 	}
@@ -727,6 +1205,9 @@ func (c *Client) GetTransactions(
 	if err != nil {
 		return nil, err
 	}
+	if c.proofPolicy == ProofPolicySecure && (len(cells) != len(r.Ids) || len(cells) > int(count)) {
+		return nil, fmt.Errorf("%w: %v transactions, %v block ids", ErrInvalidProof, len(cells), len(r.Ids))
+	}
 	var res []ton.Transaction
 	for i, cell := range cells {
 		var t tlb.Transaction
@@ -740,13 +1221,85 @@ func (c *Client) GetTransactions(
 			BlockID:     r.Ids[i].ToBlockIdExt(),
 		})
 	}
+	if err := verifyTransactionChain(accountID, lt, hash, res); err != nil {
+		return nil, err
+	}
+	if c.proofPolicy == ProofPolicySecure {
+		if err := c.proveTransactionBlocks(ctx, accountID, res); err != nil {
+			return nil, err
+		}
+	}
 	return res, nil
 }
 
+// proveTransactionBlocks proves that each block of the account's transactions
+// is linked to a proven masterchain block and that the transactions are included into it.
+// The transactions must form a chain going back in time as checked by verifyTransactionChain.
+func (c *Client) proveTransactionBlocks(ctx context.Context, accountID ton.AccountID, txs []ton.Transaction) error {
+	for start := 0; start < len(txs); {
+		block := txs[start].BlockID
+		end := start + 1
+		for end < len(txs) && txs[end].BlockID == block {
+			end++
+		}
+		// LookupBlockWithProof links the shard block to a proven masterchain block with ProofPolicySecure.
+		found, _, err := c.LookupBlockWithProof(ctx, block.BlockID, 1, nil, nil)
+		if err != nil {
+			return err
+		}
+		if found != block {
+			return fmt.Errorf("%w: block %v is proven as %v", ErrInvalidProof, block, found)
+		}
+		// the chain goes back in time, so the block's transactions of the account
+		// are the ones right after the position preceding the oldest of them.
+		oldest := txs[end-1]
+		after := &liteclient.LiteServerTransactionId3C{Account: tl.Int256(accountID.Address), Lt: oldest.Lt - 1}
+		// ListBlockTransactionsExt checks the proof of inclusion with ProofPolicySecure.
+		listed, _, err := c.ListBlockTransactionsExt(ctx, block, listTransactionsAfter, uint32(end-start), after)
+		if err != nil {
+			return err
+		}
+		if len(listed) != end-start {
+			return fmt.Errorf("%w: block %v has %v transactions of the account instead of %v", ErrInvalidProof, block, len(listed), end-start)
+		}
+		for i, tx := range listed {
+			expected := txs[end-1-i]
+			if tx.AccountAddr != expected.AccountAddr || tx.Lt != expected.Lt || tx.Hash() != expected.Hash() {
+				return fmt.Errorf("%w: transaction %v is not included into block %v", ErrInvalidProof, expected.Lt, block)
+			}
+		}
+		start = end
+	}
+	return nil
+}
+
+// GetTransactionsRaw returns the answer of liteServer.getTransactions.
+// The answer is checked the same way GetTransactions checks it before it is cached.
 func (c *Client) GetTransactionsRaw(ctx context.Context, count uint32, accountID ton.AccountID, lt uint64, hash ton.Bits256) (liteclient.LiteServerTransactionListC, error) {
+	key := transactionsCacheKey(count, accountID, lt, hash)
+	res, cached, err := cachedQuery(c, key, func() (liteclient.LiteServerTransactionListC, error) {
+		return c.getTransactionsRaw(ctx, count, accountID, lt, hash)
+	})
+	if err != nil || cached {
+		return res, err
+	}
+	if _, err := c.decodeTransactions(ctx, count, accountID, lt, hash, res); err != nil {
+		return liteclient.LiteServerTransactionListC{}, err
+	}
+	cacheAnswer(c, key, res)
+	return res, nil
+}
+
+func (c *Client) getTransactionsRaw(ctx context.Context, count uint32, accountID ton.AccountID, lt uint64, hash ton.Bits256) (liteclient.LiteServerTransactionListC, error) {
 	archiveRequired := false
 	for {
-		client, _, err := c.pool.BestClientByAccountID(ctx, accountID, archiveRequired)
+		var client *liteclient.Client
+		var err error
+		if archiveRequired {
+			client, _, err = c.pool.BestClientByAccountID(ctx, accountID, true)
+		} else {
+			client, _, err = c.pool.BestClientByLt(ctx, lt)
+		}
 		if err != nil {
 			return liteclient.LiteServerTransactionListC{}, err
 		}
@@ -775,11 +1328,8 @@ func (c *Client) GetTransactionsRaw(ctx context.Context, count uint32, accountID
 }
 
 func truncatedHistory(err error) bool {
-	if err == nil {
-		return false
-	}
-	e, ok := err.(liteclient.LiteServerErrorC)
-	return ok && int32(e.Code) == -400
+	var e liteclient.LiteServerErrorC
+	return errors.As(err, &e) && int32(e.Code) == -400
 }
 
 func (c *Client) GetLastTransactions(ctx context.Context, a ton.AccountID, limit int) ([]ton.Transaction, error) {
@@ -799,7 +1349,7 @@ func (c *Client) GetLastTransactions(ctx context.Context, a ton.AccountID, limit
 		}
 		txs, err := c.GetTransactions(ctx, uint32(transactionCount), a, lastLt, ton.Bits256(lastHash))
 		if err != nil {
-			if e, ok := err.(liteclient.LiteServerErrorC); ok && int32(e.Code) == -400 { // liteserver can store not full history. in that case it return error -400 for old transactions
+			if truncatedHistory(err) { // liteserver can store not full history. in that case it return error -400 for old transactions
 				break
 			}
 			return nil, err
@@ -848,6 +1398,83 @@ func (c *Client) ListBlockTransactionsRaw(ctx context.Context, blockID ton.Block
 	return res, nil
 }
 
+// ListBlockTransactionsExt returns up to count transactions of the block ordered by account and lt
+// starting after the given transaction, mode 64 requests the reverse order.
+// The second return value reports whether the block has more transactions.
+// With ProofPolicySecure, a proof is requested (mode 32)
+// to check that each transaction is included into the block.
+func (c *Client) ListBlockTransactionsExt(
+	ctx context.Context,
+	blockID ton.BlockIDExt,
+	mode, count uint32,
+	after *liteclient.LiteServerTransactionId3C,
+) ([]ton.Transaction, bool, error) {
+	return c.listBlockTransactionsExt(ctx, blockID, mode, count, after, c.proofPolicy == ProofPolicySecure)
+}
+
+// listProvenBlockTransactions works like ListBlockTransactionsExt
+// but checks the proof of the transactions under every proof policy.
+func (c *Client) listProvenBlockTransactions(ctx context.Context, blockID ton.BlockIDExt, mode, count uint32, after *liteclient.LiteServerTransactionId3C) ([]ton.Transaction, bool, error) {
+	return c.listBlockTransactionsExt(ctx, blockID, mode, count, after, true)
+}
+
+func (c *Client) listBlockTransactionsExt(ctx context.Context, blockID ton.BlockIDExt, mode, count uint32, after *liteclient.LiteServerTransactionId3C, prove bool) ([]ton.Transaction, bool, error) {
+	if prove {
+		mode |= 32
+	}
+	res, err := c.ListBlockTransactionsExtRaw(ctx, blockID, mode, count, after)
+	if err != nil {
+		return nil, false, err
+	}
+	var txs []ton.Transaction
+	if len(res.Transactions) > 0 {
+		cells, err := boc.DeserializeBoc(res.Transactions)
+		if err != nil {
+			return nil, false, err
+		}
+		txs = make([]ton.Transaction, 0, len(cells))
+		for _, cell := range cells {
+			var tx tlb.Transaction
+			if err := tlb.Unmarshal(cell, &tx); err != nil {
+				return nil, false, err
+			}
+			txs = append(txs, ton.Transaction{Transaction: tx, BlockID: res.Id.ToBlockIdExt()})
+		}
+	}
+	if prove {
+		if mode&128 == 0 {
+			// a lite server ignores the transaction to start after without mode 128.
+			after = nil
+		}
+		if err := verifyBlockTransactions(blockID, mode, after, txs, res); err != nil {
+			return nil, false, err
+		}
+	}
+	return txs, res.Incomplete, nil
+}
+
+func (c *Client) ListBlockTransactionsExtRaw(ctx context.Context, blockID ton.BlockIDExt, mode, count uint32, after *liteclient.LiteServerTransactionId3C) (liteclient.LiteServerBlockTransactionsExtC, error) {
+	client, err := c.pool.BestClientByBlockID(ctx, blockID.BlockID)
+	if err != nil {
+		return liteclient.LiteServerBlockTransactionsExtC{}, err
+	}
+	res, err := client.LiteServerListBlockTransactionsExt(ctx, liteclient.LiteServerListBlockTransactionsExtRequest{
+		Id:    liteclient.BlockIDExt(blockID),
+		Mode:  mode,
+		Count: count,
+		After: after,
+	})
+	if err != nil {
+		return liteclient.LiteServerBlockTransactionsExtC{}, err
+	}
+	return res, nil
+}
+
+// GetBlockProof returns a chain of masterchain blocks from the known block to the target block
+// or to the latest masterchain block if the target block is nil.
+// The chain can be incomplete, then it must be requested again starting from its last block.
+// Unless the proof policy is ProofPolicyUnsafe, the chain is verified with VerifyBlockProof,
+// so the known block must be trusted.
 func (c *Client) GetBlockProof(
 	ctx context.Context,
 	knownBlock ton.BlockIDExt,
@@ -857,7 +1484,16 @@ func (c *Client) GetBlockProof(
 	if err != nil {
 		return liteclient.LiteServerPartialBlockProofC{}, err
 	}
-	// TODO: decode block proof
+	if c.proofPolicy == ProofPolicyUnsafe {
+		return res, nil
+	}
+	last, err := VerifyBlockProof(knownBlock, res)
+	if err != nil {
+		return liteclient.LiteServerPartialBlockProofC{}, err
+	}
+	if res.Complete && targetBlock != nil && last != *targetBlock {
+		return liteclient.LiteServerPartialBlockProofC{}, fmt.Errorf("%w: proof ends with block %v instead of %v", ErrInvalidProof, last, *targetBlock)
+	}
 	return res, nil
 }
 
@@ -893,37 +1529,111 @@ func (c *Client) GetBlockProofRaw(ctx context.Context, knownBlock ton.BlockIDExt
 }
 
 // GetConfigAll returns a current configuration of the blockchain.
+// With ProofPolicySecure, the configuration is checked against the proven masterchain state.
 func (c *Client) GetConfigAll(ctx context.Context, mode ConfigMode) (tlb.ConfigParams, error) {
-	res, err := c.GetConfigAllRaw(ctx, mode)
+	c.mu.RLock()
+	target := c.targetBlockID
+	c.mu.RUnlock()
+	if target == nil {
+		// the current config changes over time.
+		res, blockID, err := c.getConfigAllRaw(ctx, mode)
+		if err != nil {
+			return tlb.ConfigParams{}, err
+		}
+		return c.decodeConfigAll(ctx, blockID, res)
+	}
+	key := configCacheKey(*target, mode)
+	res, cached, err := cachedQuery(c, key, func() (liteclient.LiteServerConfigInfoC, error) {
+		res, _, err := c.getConfigAllRaw(ctx, mode)
+		return res, err
+	})
+	if err != nil {
+		return tlb.ConfigParams{}, err
+	}
+	params, err := c.decodeConfigAll(ctx, *target, res)
 	if err != nil {
 		return tlb.ConfigParams{}, err
 	}
+	if !cached {
+		cacheAnswer(c, key, res)
+	}
+	return params, nil
+}
+
+// decodeConfigAll decodes the answer of liteServer.getConfigAll for the given masterchain block.
+// With ProofPolicySecure, it checks the answer's proof.
+func (c *Client) decodeConfigAll(ctx context.Context, blockID ton.BlockIDExt, res liteclient.LiteServerConfigInfoC) (tlb.ConfigParams, error) {
+	if c.proofPolicy == ProofPolicySecure {
+		if res.Id.ToBlockIdExt() != blockID {
+			return tlb.ConfigParams{}, fmt.Errorf("%w: config is for block %v instead of %v", ErrInvalidProof, res.Id.ToBlockIdExt(), blockID)
+		}
+		if err := c.proveMasterchainBlock(ctx, blockID); err != nil {
+			return tlb.ConfigParams{}, err
+		}
+		blockProof, err := boc.DeserializeSingleRootBoc(res.StateProof)
+		if err != nil {
+			return tlb.ConfigParams{}, fmt.Errorf("%w: config proof: %v", ErrInvalidProof, err)
+		}
+		stateProof, err := boc.DeserializeSingleRootBoc(res.ConfigProof)
+		if err != nil {
+			return tlb.ConfigParams{}, fmt.Errorf("%w: config proof: %v", ErrInvalidProof, err)
+		}
+		if _, err := configHash(blockID, blockProof, stateProof); err != nil {
+			return tlb.ConfigParams{}, fmt.Errorf("%w: config proof: %v", ErrInvalidProof, err)
+		}
+	}
 	return ton.DecodeConfigParams(res.ConfigProof)
 }
 
+// GetConfigAllRaw returns the answer of liteServer.getConfigAll.
+// With a target block, the answer is checked the same way GetConfigAll checks it before it is cached.
 func (c *Client) GetConfigAllRaw(ctx context.Context, mode ConfigMode) (liteclient.LiteServerConfigInfoC, error) {
-	client, masterHead, err := c.pool.BestMasterchainClient(ctx)
-	if err != nil {
+	c.mu.RLock()
+	target := c.targetBlockID
+	c.mu.RUnlock()
+	if target == nil {
+		// the current config changes over time.
+		res, _, err := c.getConfigAllRaw(ctx, mode)
+		return res, err
+	}
+	key := configCacheKey(*target, mode)
+	res, cached, err := cachedQuery(c, key, func() (liteclient.LiteServerConfigInfoC, error) {
+		res, _, err := c.getConfigAllRaw(ctx, mode)
+		return res, err
+	})
+	if err != nil || cached {
+		return res, err
+	}
+	if _, err := c.decodeConfigAll(ctx, *target, res); err != nil {
 		return liteclient.LiteServerConfigInfoC{}, err
 	}
+	cacheAnswer(c, key, res)
+	return res, nil
+}
+
+func (c *Client) getConfigAllRaw(ctx context.Context, mode ConfigMode) (liteclient.LiteServerConfigInfoC, ton.BlockIDExt, error) {
+	client, blockID, err := c.targetClient(ctx)
+	if err != nil {
+		return liteclient.LiteServerConfigInfoC{}, ton.BlockIDExt{}, err
+	}
 	res, err := client.LiteServerGetConfigAll(ctx, liteclient.LiteServerGetConfigAllRequest{
 		Mode: uint32(mode),
-		Id:   liteclient.BlockIDExt(c.targetBlockOr(masterHead)),
+		Id:   liteclient.BlockIDExt(blockID),
 	})
 	if err != nil {
-		return liteclient.LiteServerConfigInfoC{}, err
+		return liteclient.LiteServerConfigInfoC{}, ton.BlockIDExt{}, err
 	}
-	return res, nil
+	return res, blockID, nil
 }
 
 func (c *Client) GetConfigParams(ctx context.Context, mode ConfigMode, paramList []uint32) (tlb.ConfigParams, error) {
-	client, masterHead, err := c.pool.BestMasterchainClient(ctx)
+	client, blockID, err := c.targetClient(ctx)
 	if err != nil {
 		return tlb.ConfigParams{}, err
 	}
 	r, err := client.LiteServerGetConfigParams(ctx, liteclient.LiteServerGetConfigParamsRequest{
 		Mode:      uint32(mode),
-		Id:        liteclient.BlockIDExt(c.targetBlockOr(masterHead)),
+		Id:        liteclient.BlockIDExt(blockID),
 		ParamList: paramList,
 	})
 	if err != nil {
@@ -938,7 +1648,7 @@ func (c *Client) GetValidatorStats(
 	startAfter *ton.Bits256,
 	modifiedAfter *uint32,
 ) (*tlb.McStateExtra, error) {
-	client, masterHead, err := c.pool.BestMasterchainClient(ctx)
+	client, blockID, err := c.targetClient(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -949,7 +1659,7 @@ func (c *Client) GetValidatorStats(
 	}
 	r, err := client.LiteServerGetValidatorStats(ctx, liteclient.LiteServerGetValidatorStatsRequest{
 		Mode:          mode,
-		Id:            liteclient.BlockIDExt(c.targetBlockOr(masterHead)),
+		Id:            liteclient.BlockIDExt(blockID),
 		Limit:         limit,
 		StartAfter:    sa,
 		ModifiedAfter: modifiedAfter,
@@ -977,21 +1687,32 @@ func (c *Client) GetValidatorStats(
 }
 
 func (c *Client) GetLibraries(ctx context.Context, libraryList []ton.Bits256) (map[ton.Bits256]*boc.Cell, error) {
-	client, _, err := c.pool.BestMasterchainClient(ctx)
-	if err != nil {
-		return nil, err
-	}
+	libs := make(map[ton.Bits256]*boc.Cell, len(libraryList))
 	var ll []tl.Int256
 	for _, l := range libraryList {
+		if c.cache != nil {
+			if data, ok := c.cache.Get(libraryCacheKey(l)); ok {
+				if cell, err := boc.DeserializeSingleRootBoc(data); err == nil {
+					libs[l] = cell
+					continue
+				}
+			}
+		}
 		ll = append(ll, tl.Int256(l))
 	}
+	if len(ll) == 0 {
+		return libs, nil
+	}
+	client, _, err := c.pool.BestMasterchainClient(ctx)
+	if err != nil {
+		return nil, err
+	}
 	r, err := client.LiteServerGetLibraries(ctx, liteclient.LiteServerGetLibrariesRequest{
 		LibraryList: ll,
 	})
 	if err != nil {
 		return nil, err
 	}
-	libs := make(map[ton.Bits256]*boc.Cell, len(r.Result))
 	for _, lib := range r.Result {
 		data, err := boc.DeserializeBoc(lib.Data)
 		if err != nil {
@@ -1001,10 +1722,68 @@ func (c *Client) GetLibraries(ctx context.Context, libraryList []ton.Bits256) (m
 			return nil, fmt.Errorf("multiroot lib is not supported")
 		}
 		libs[ton.Bits256(lib.Hash)] = data[0]
+		if c.cache == nil {
+			continue
+		}
+		// a library is identified by its hash, so a wrong answer is never cached.
+		if hash, err := data[0].Hash256(); err == nil && hash == ton.Bits256(lib.Hash) {
+			c.cache.Set(libraryCacheKey(ton.Bits256(lib.Hash)), lib.Data)
+		}
 	}
 	return libs, nil
 }
 
+// GetLibrariesWithProof returns libraries from the masterchain state of the client's masterchain block:
+// its target block or the latest masterchain block.
+// Libraries that don't exist are not included into the result.
+// With ProofPolicySecure, the answer is checked against the masterchain state,
+// so a lite server can neither hide nor forge a library.
+func (c *Client) GetLibrariesWithProof(ctx context.Context, libraryList []ton.Bits256) (map[ton.Bits256]*boc.Cell, error) {
+	res, mcBlock, err := c.getLibrariesWithProofRaw(ctx, libraryList)
+	if err != nil {
+		return nil, err
+	}
+	if c.proofPolicy == ProofPolicySecure {
+		if err := c.proveMasterchainBlock(ctx, mcBlock); err != nil {
+			return nil, err
+		}
+		return verifyLibraries(mcBlock, libraryList, res)
+	}
+	libs := make(map[ton.Bits256]*boc.Cell, len(res.Result))
+	for _, lib := range res.Result {
+		cell, err := boc.DeserializeSingleRootBoc(lib.Data)
+		if err != nil {
+			return nil, err
+		}
+		libs[ton.Bits256(lib.Hash)] = cell
+	}
+	return libs, nil
+}
+
+func (c *Client) GetLibrariesWithProofRaw(ctx context.Context, libraryList []ton.Bits256) (liteclient.LiteServerLibraryResultWithProofC, error) {
+	res, _, err := c.getLibrariesWithProofRaw(ctx, libraryList)
+	return res, err
+}
+
+func (c *Client) getLibrariesWithProofRaw(ctx context.Context, libraryList []ton.Bits256) (liteclient.LiteServerLibraryResultWithProofC, ton.BlockIDExt, error) {
+	client, mcBlock, err := c.targetClient(ctx)
+	if err != nil {
+		return liteclient.LiteServerLibraryResultWithProofC{}, ton.BlockIDExt{}, err
+	}
+	ll := make([]tl.Int256, 0, len(libraryList))
+	for _, l := range libraryList {
+		ll = append(ll, tl.Int256(l))
+	}
+	res, err := client.LiteServerGetLibrariesWithProof(ctx, liteclient.LiteServerGetLibrariesWithProofRequest{
+		Id:          liteclient.BlockIDExt(mcBlock),
+		LibraryList: ll,
+	})
+	if err != nil {
+		return liteclient.LiteServerLibraryResultWithProofC{}, ton.BlockIDExt{}, err
+	}
+	return res, mcBlock, nil
+}
+
 func (c *Client) GetShardBlockProof(ctx context.Context) (liteclient.LiteServerShardBlockProofC, error) {
 	res, err := c.GetShardBlockProofRaw(ctx)
 	if err != nil {
@@ -1014,12 +1793,12 @@ func (c *Client) GetShardBlockProof(ctx context.Context) (liteclient.LiteServerS
 }
 
 func (c *Client) GetShardBlockProofRaw(ctx context.Context) (liteclient.LiteServerShardBlockProofC, error) {
-	client, masterHead, err := c.pool.BestMasterchainClient(ctx)
+	client, blockID, err := c.targetClient(ctx)
 	if err != nil {
 		return liteclient.LiteServerShardBlockProofC{}, err
 	}
 	return client.LiteServerGetShardBlockProof(ctx, liteclient.LiteServerGetShardBlockProofRequest{
-		Id: liteclient.BlockIDExt(c.targetBlockOr(masterHead)),
+		Id: liteclient.BlockIDExt(blockID),
 	})
 }
 
@@ -1042,6 +1821,112 @@ func (c *Client) GetOutMsgQueueSizes(ctx context.Context) (liteclient.LiteServer
 	return res, nil
 }
 
+// GetDispatchQueueInfo returns dispatch queues of up to maxAccounts accounts of the shard block
+// in ascending order of their addresses starting after the given address.
+// The second return value reports whether there are no more accounts with deferred messages.
+// With ProofPolicySecure, a proof is requested (mode 1)
+// to check each queue against the state of the block.
+// Messages of each account are returned by GetDispatchQueueMessages.
+func (c *Client) GetDispatchQueueInfo(ctx context.Context, blockID ton.BlockIDExt, after *ton.Bits256, maxAccounts uint32) ([]AccountDispatchQueue, bool, error) {
+	var mode uint32
+	if c.proofPolicy == ProofPolicySecure {
+		mode |= 1
+	}
+	res, err := c.GetDispatchQueueInfoRaw(ctx, blockID, mode, after, maxAccounts)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.proofPolicy == ProofPolicySecure {
+		if err := verifyDispatchQueueInfo(blockID, after, res); err != nil {
+			return nil, false, err
+		}
+	}
+	queues := make([]AccountDispatchQueue, 0, len(res.AccountDispatchQueues))
+	for _, q := range res.AccountDispatchQueues {
+		queues = append(queues, AccountDispatchQueue{
+			AccountID: ton.AccountID{Workchain: blockID.Workchain, Address: ton.Bits256(q.Addr)},
+			Size:      q.Size,
+			MinLt:     q.MinLt,
+			MaxLt:     q.MaxLt,
+		})
+	}
+	return queues, res.Complete, nil
+}
+
+func (c *Client) GetDispatchQueueInfoRaw(ctx context.Context, blockID ton.BlockIDExt, mode uint32, after *ton.Bits256, maxAccounts uint32) (liteclient.LiteServerDispatchQueueInfoC, error) {
+	client, err := c.pool.BestClientByBlockID(ctx, blockID.BlockID)
+	if err != nil {
+		return liteclient.LiteServerDispatchQueueInfoC{}, err
+	}
+	req := liteclient.LiteServerGetDispatchQueueInfoRequest{
+		Mode:        mode,
+		Id:          liteclient.BlockIDExt(blockID),
+		MaxAccounts: maxAccounts,
+	}
+	if after != nil {
+		addr := tl.Int256(*after)
+		req.Mode |= 2
+		req.AfterAddr = &addr
+	}
+	res, err := client.LiteServerGetDispatchQueueInfo(ctx, req)
+	if err != nil {
+		return liteclient.LiteServerDispatchQueueInfoC{}, err
+	}
+	return res, nil
+}
+
+// GetDispatchQueueMessages returns up to maxMessages deferred messages of the account
+// waiting in the dispatch queue of the shard block, in ascending order of their lt starting after afterLt.
+// The second return value reports whether the account has no more messages in the queue.
+// With ProofPolicySecure, a proof is requested (mode 1)
+// to check each message against the state of the block.
+func (c *Client) GetDispatchQueueMessages(ctx context.Context, blockID ton.BlockIDExt, accountID ton.AccountID, afterLt uint64, maxMessages uint32) ([]DispatchQueueMessage, bool, error) {
+	// mode 2 limits the result to messages of the account.
+	mode := uint32(2)
+	if c.proofPolicy == ProofPolicySecure {
+		mode |= 1
+	}
+	res, err := c.GetDispatchQueueMessagesRaw(ctx, blockID, mode, accountID, afterLt, maxMessages)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.proofPolicy == ProofPolicySecure {
+		if err := verifyDispatchQueueMessages(blockID, accountID, afterLt, res); err != nil {
+			return nil, false, err
+		}
+	}
+	messages := make([]DispatchQueueMessage, 0, len(res.Messages))
+	for _, m := range res.Messages {
+		messages = append(messages, DispatchQueueMessage{
+			AccountID:   ton.AccountID{Workchain: blockID.Workchain, Address: ton.Bits256(m.Addr)},
+			Lt:          m.Lt,
+			Hash:        ton.Bits256(m.Hash),
+			Depth:       m.Metadata.Depth,
+			Initiator:   ton.AccountID{Workchain: int32(m.Metadata.Initiator.Workchain), Address: ton.Bits256(m.Metadata.Initiator.Id)},
+			InitiatorLt: m.Metadata.InitiatorLt,
+		})
+	}
+	return messages, res.Complete, nil
+}
+
+func (c *Client) GetDispatchQueueMessagesRaw(ctx context.Context, blockID ton.BlockIDExt, mode uint32, accountID ton.AccountID, afterLt uint64, maxMessages uint32) (liteclient.LiteServerDispatchQueueMessagesC, error) {
+	client, err := c.pool.BestClientByBlockID(ctx, blockID.BlockID)
+	if err != nil {
+		return liteclient.LiteServerDispatchQueueMessagesC{}, err
+	}
+	res, err := client.LiteServerGetDispatchQueueMessages(ctx, liteclient.LiteServerGetDispatchQueueMessagesRequest{
+		Mode:        mode,
+		Id:          liteclient.BlockIDExt(blockID),
+		Addr:        tl.Int256(accountID.Address),
+		AfterLt:     afterLt,
+		MaxMessages: maxMessages,
+	})
+	if err != nil {
+		return liteclient.LiteServerDispatchQueueMessagesC{}, err
+	}
+	return res, nil
+}
+
 var configCache = make(map[string]*config.GlobalConfigurationFile)
 var configCacheMutex sync.RWMutex
 
@@ -3,6 +3,9 @@ package liteapi
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	crand "crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -10,12 +13,15 @@ import (
 	"log"
 	"math/big"
 	"math/rand"
+	"net"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/caigou-xyz/tongo/config"
 	"github.com/caigou-xyz/tongo/liteapi/pool"
+	"github.com/caigou-xyz/tongo/liteclient"
 	"github.com/caigou-xyz/tongo/tlb"
 	"github.com/caigou-xyz/tongo/ton"
 	"golang.org/x/exp/maps"
@@ -642,3 +648,104 @@ func TestWaitMasterchainBlock(t *testing.T) {
 	}
 	fmt.Printf("Next block seqno    : %v\n", bl.Seqno)
 }
+
+type cassetteTestHandler struct {
+	liteclient.UnimplementedLiteServerHandler
+}
+
+func (h *cassetteTestHandler) LiteServerGetMasterchainInfo(ctx context.Context) (liteclient.LiteServerMasterchainInfoC, error) {
+	return liteclient.LiteServerMasterchainInfoC{
+		Last: liteclient.TonNodeBlockIdExtC{Workchain: 0xffffffff, Shard: 0x8000000000000000, Seqno: 100500},
+	}, nil
+}
+
+func (h *cassetteTestHandler) LiteServerGetTime(ctx context.Context) (liteclient.LiteServerCurrentTimeC, error) {
+	return liteclient.LiteServerCurrentTimeC{Now: 1700000000}, nil
+}
+
+func TestClient_RecordAndReplay(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(crand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	server := liteclient.NewServer(key, &cassetteTestHandler{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	go server.Serve(l)
+	defer server.Close()
+
+	recorder := liteclient.NewCassette()
+	servers := []config.LiteServer{{Host: l.Addr().String(), Key: base64.StdEncoding.EncodeToString(server.PublicKey())}}
+	api, err := NewClient(WithLiteServers(servers), WithRecorder(recorder))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := api.GetMasterchainInfo(ctx); err != nil {
+		t.Fatalf("GetMasterchainInfo() failed: %v", err)
+	}
+	if _, err := api.GetTime(ctx); err != nil {
+		t.Fatalf("GetTime() failed: %v", err)
+	}
+	api.Close(context.Background())
+	server.Close()
+	// the pool tracks the lite server in background, this traffic is not a part of the session.
+	if recorder.Len() != 2 {
+		t.Fatalf("want 2 recorded interactions, got: %v", recorder.Interactions())
+	}
+
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorder.Save(filename); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	cassette, err := liteclient.LoadCassette(filename)
+	if err != nil {
+		t.Fatalf("LoadCassette() failed: %v", err)
+	}
+	replay, err := NewClient(WithReplay(cassette))
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	defer replay.Close(context.Background())
+	info, err := replay.GetMasterchainInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetMasterchainInfo() failed: %v", err)
+	}
+	if info.Last.Seqno != 100500 {
+		t.Fatalf("want seqno 100500, got: %v", info.Last.Seqno)
+	}
+	now, err := replay.GetTime(ctx)
+	if err != nil {
+		t.Fatalf("GetTime() failed: %v", err)
+	}
+	if now != 1700000000 {
+		t.Fatalf("want 1700000000, got: %v", now)
+	}
+	if _, err := replay.GetConfigAll(ctx, 0); !errors.Is(err, liteclient.ErrNotRecorded) {
+		t.Fatalf("want ErrNotRecorded, got: %v", err)
+	}
+}
+
+func Test_truncatedHistory(t *testing.T) {
+	truncated := liteclient.LiteServerErrorC{Code: 0xfffffe70, Message: "cannot load block"} // -400
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error"},
+		{name: "truncated history", err: truncated, want: true},
+		{name: "wrapped", err: fmt.Errorf("get transactions: %w", truncated), want: true},
+		{name: "another code", err: liteclient.LiteServerErrorC{Code: 651}},
+		{name: "another error", err: errors.New("timeout")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncatedHistory(tt.err); got != tt.want {
+				t.Fatalf("want %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package liteapi
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// AccountDispatchQueue describes deferred messages of an account waiting in a dispatch queue of a shard.
+type AccountDispatchQueue struct {
+	AccountID ton.AccountID
+	// Size is a number of messages in the queue.
+	Size uint64
+	// MinLt and MaxLt are the lowest and the highest logical times the messages were created at.
+	MinLt uint64
+	MaxLt uint64
+}
+
+// DispatchQueueMessage describes a deferred message waiting in a dispatch queue of a shard.
+type DispatchQueueMessage struct {
+	AccountID ton.AccountID
+	// Lt is the logical time the message was created at.
+	Lt uint64
+	// Hash is a hash of the message's cell.
+	Hash ton.Bits256
+	// Depth, Initiator and InitiatorLt are taken from the message's metadata.
+	// Initiator is the account whose transaction started the chain of messages
+	// and Depth is a number of messages in the chain before this one.
+	Depth       uint32
+	Initiator   ton.AccountID
+	InitiatorLt uint64
+}
+
+// verifyDispatchQueueInfo checks liteServer.dispatchQueueInfo returned for the given block.
+// The proof consists of a proof of the block's header and a proof of its state
+// which contains the dispatch queue of each returned account.
+// Accounts must go in ascending order of their addresses starting after the given one.
+func verifyDispatchQueueInfo(blockID ton.BlockIDExt, after *ton.Bits256, res liteclient.LiteServerDispatchQueueInfoC) error {
+	if res.Id.ToBlockIdExt() != blockID {
+		return fmt.Errorf("%w: dispatch queue is for block %v instead of %v", ErrInvalidProof, res.Id.ToBlockIdExt(), blockID)
+	}
+	prev := after
+	for _, queue := range res.AccountDispatchQueues {
+		addr := ton.Bits256(queue.Addr)
+		if prev != nil && bytes.Compare(addr[:], prev[:]) <= 0 {
+			return fmt.Errorf("%w: dispatch queues are not sorted by account", ErrInvalidProof)
+		}
+		prev = &addr
+	}
+	if len(res.AccountDispatchQueues) == 0 {
+		return nil
+	}
+	blockProof, stateProof, err := proofRoots(res.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: dispatch queue proof: %v", ErrInvalidProof, err)
+	}
+	root, err := provenDispatchQueue(blockID, blockProof, stateProof)
+	if err != nil {
+		return fmt.Errorf("%w: dispatch queue proof: %v", ErrInvalidProof, err)
+	}
+	for _, queue := range res.AccountDispatchQueues {
+		if err := checkAccountDispatchQueue(root, queue); err != nil {
+			return fmt.Errorf("%w: dispatch queue of account %x: %v", ErrInvalidProof, queue.Addr, err)
+		}
+	}
+	return nil
+}
+
+// provenDispatchQueue checks proofs of a shard block and its state
+// and returns the root of the dispatch queue dictionary or nil if the queue is empty.
+func provenDispatchQueue(blockID ton.BlockIDExt, blockProof, stateProof *boc.Cell) (*boc.Cell, error) {
+	stateHash, err := blockStateHash(blockProof, blockID)
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProof, stateHash)
+	if err != nil {
+		return nil, fmt.Errorf("shard state: %w", err)
+	}
+	// shard_state#9023afe2 ... out_msg_queue_info:^OutMsgQueueInfo ...
+	if magic, err := stateRoot.ReadUint(32); err != nil || magic != shardStateMagic {
+		return nil, fmt.Errorf("invalid shard state")
+	}
+	refs := stateRoot.Refs()
+	if len(refs) < 3 {
+		return nil, fmt.Errorf("invalid shard state")
+	}
+	queueInfo := refs[0]
+	if queueInfo.CellType() == boc.PrunedBranchCell {
+		return nil, fmt.Errorf("out message queue info is pruned")
+	}
+	queueInfo.ResetCounters()
+	// _ out_queue:OutMsgQueue proc_info:ProcessedInfo extra:(Maybe OutMsgQueueExtra) = OutMsgQueueInfo;
+	// _ (HashmapAugE 352 EnqueuedMsg uint64) = OutMsgQueue;
+	// _ (HashmapE 96 ProcessedUpto) = ProcessedInfo;
+	if err := skipMaybeRef(queueInfo); err != nil {
+		return nil, err
+	}
+	if err := queueInfo.Skip(64); err != nil {
+		return nil, err
+	}
+	if err := skipMaybeRef(queueInfo); err != nil {
+		return nil, err
+	}
+	hasExtra, err := queueInfo.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !hasExtra {
+		return nil, nil
+	}
+	// out_msg_queue_extra#0 dispatch_queue:DispatchQueue out_queue_size:(Maybe uint48) = OutMsgQueueExtra;
+	// _ (HashmapAugE 256 AccountDispatchQueue uint64) = DispatchQueue;
+	if tag, err := queueInfo.ReadUint(4); err != nil || tag != 0 {
+		return nil, fmt.Errorf("invalid out message queue extra")
+	}
+	notEmpty, err := queueInfo.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !notEmpty {
+		return nil, nil
+	}
+	return queueInfo.NextRef()
+}
+
+// checkAccountDispatchQueue checks information about an account's dispatch queue against a proof of the queue.
+func checkAccountDispatchQueue(root *boc.Cell, queue liteclient.LiteServerAccountDispatchQueueInfoC) error {
+	if root == nil {
+		return fmt.Errorf("dispatch queue is empty")
+	}
+	key := boc.NewBitString(256)
+	if err := key.WriteBytes(queue.Addr[:]); err != nil {
+		return err
+	}
+	value, err := tlb.FindHashmapValue(root, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("account not found")
+	}
+	// the augmentation of the dictionary is the lowest lt of the account's messages.
+	// _ messages:(HashmapE 64 EnqueuedMsg) count:uint48 = AccountDispatchQueue;
+	// the messages can be pruned from the proof, so only the bit of the dictionary is read.
+	minLt, err := value.ReadUint(64)
+	if err != nil {
+		return err
+	}
+	if err := skipMaybeRef(value); err != nil {
+		return err
+	}
+	count, err := value.ReadUint(48)
+	if err != nil {
+		return err
+	}
+	if count != queue.Size || minLt != queue.MinLt {
+		return fmt.Errorf("queue has %v messages since lt %v", count, minLt)
+	}
+	if queue.MaxLt < queue.MinLt {
+		return fmt.Errorf("max lt %v is lower than min lt %v", queue.MaxLt, queue.MinLt)
+	}
+	return nil
+}
+
+// verifyDispatchQueueMessages checks liteServer.dispatchQueueMessages returned for messages of the given account.
+// The proof consists of a proof of the block's header and a proof of its state
+// which contains the account's dispatch queue with each returned message.
+// Messages must go in ascending order of their lt starting after the given one.
+func verifyDispatchQueueMessages(blockID ton.BlockIDExt, accountID ton.AccountID, afterLt uint64, res liteclient.LiteServerDispatchQueueMessagesC) error {
+	if res.Id.ToBlockIdExt() != blockID {
+		return fmt.Errorf("%w: dispatch queue is for block %v instead of %v", ErrInvalidProof, res.Id.ToBlockIdExt(), blockID)
+	}
+	lt := afterLt
+	for _, msg := range res.Messages {
+		if ton.Bits256(msg.Addr) != accountID.Address {
+			return fmt.Errorf("%w: message %v belongs to account %x", ErrInvalidProof, msg.Lt, msg.Addr)
+		}
+		if msg.Lt <= lt {
+			return fmt.Errorf("%w: dispatch queue messages are not sorted by lt", ErrInvalidProof)
+		}
+		lt = msg.Lt
+	}
+	if len(res.Messages) == 0 {
+		return nil
+	}
+	blockProof, stateProof, err := proofRoots(res.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: dispatch queue proof: %v", ErrInvalidProof, err)
+	}
+	root, err := provenDispatchQueue(blockID, blockProof, stateProof)
+	if err != nil {
+		return fmt.Errorf("%w: dispatch queue proof: %v", ErrInvalidProof, err)
+	}
+	messages, err := accountDispatchMessages(root, accountID.Address)
+	if err != nil {
+		return fmt.Errorf("%w: dispatch queue of account %x: %v", ErrInvalidProof, accountID.Address, err)
+	}
+	for _, msg := range res.Messages {
+		if err := checkDispatchQueueMessage(messages, msg); err != nil {
+			return fmt.Errorf("%w: dispatch queue message %v: %v", ErrInvalidProof, msg.Lt, err)
+		}
+	}
+	return nil
+}
+
+// accountDispatchMessages returns the root of the dictionary of an account's messages from a proof of a dispatch queue.
+func accountDispatchMessages(root *boc.Cell, addr ton.Bits256) (*boc.Cell, error) {
+	if root == nil {
+		return nil, fmt.Errorf("dispatch queue is empty")
+	}
+	key := boc.NewBitString(256)
+	if err := key.WriteBytes(addr[:]); err != nil {
+		return nil, err
+	}
+	value, err := tlb.FindHashmapValue(root, key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("account not found")
+	}
+	// the augmentation of the dictionary is the lowest lt of the account's messages.
+	// _ messages:(HashmapE 64 EnqueuedMsg) count:uint48 = AccountDispatchQueue;
+	if err := value.Skip(64); err != nil {
+		return nil, err
+	}
+	notEmpty, err := value.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !notEmpty {
+		return nil, fmt.Errorf("account has no messages")
+	}
+	return value.NextRef()
+}
+
+// checkDispatchQueueMessage checks a message of an account's dispatch queue
+// against a proof of the dictionary of the account's messages.
+func checkDispatchQueueMessage(root *boc.Cell, msg liteclient.LiteServerDispatchQueueMessageC) error {
+	key := boc.NewBitString(64)
+	if err := key.WriteUint(msg.Lt, 64); err != nil {
+		return err
+	}
+	value, err := tlb.FindHashmapValue(root, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("message not found")
+	}
+	// _ enqueued_lt:uint64 out_msg:^MsgEnvelope = EnqueuedMsg;
+	if err := value.Skip(64); err != nil {
+		return err
+	}
+	envelope, err := value.NextRef()
+	if err != nil {
+		return err
+	}
+	// msg_envelope#4 cur_addr:IntermediateAddress next_addr:IntermediateAddress fwd_fee_remaining:Grams
+	// msg:^(Message Any) = MsgEnvelope;
+	// msg_envelope_v2#5 cur_addr:IntermediateAddress next_addr:IntermediateAddress fwd_fee_remaining:Grams
+	// msg:^(Message Any) emitted_lt:(Maybe uint64) metadata:(Maybe MsgMetadata) = MsgEnvelope;
+	tag, err := envelope.ReadUint(4)
+	if err != nil {
+		return err
+	}
+	if tag != 4 && tag != 5 {
+		return fmt.Errorf("invalid message envelope")
+	}
+	var header struct {
+		CurrentAddress  tlb.IntermediateAddress
+		NextAddress     tlb.IntermediateAddress
+		FwdFeeRemaining tlb.Grams
+	}
+	if err := tlb.Unmarshal(envelope, &header); err != nil {
+		return err
+	}
+	// the message itself can be pruned from the proof, only its hash is needed.
+	refs := envelope.Refs()
+	if len(refs) == 0 {
+		return fmt.Errorf("invalid message envelope")
+	}
+	hash, err := refs[0].HashAtLevel(0)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, msg.Hash[:]) {
+		return fmt.Errorf("message hash mismatch")
+	}
+	if tag == 4 {
+		return nil
+	}
+	if err := envelope.SkipRef(); err != nil {
+		return err
+	}
+	var tail struct {
+		EmittedLt *uint64          `tlb:"maybe"`
+		Metadata  *tlb.MsgMetadata `tlb:"maybe"`
+	}
+	if err := tlb.Unmarshal(envelope, &tail); err != nil {
+		return err
+	}
+	if tail.Metadata == nil {
+		return nil
+	}
+	initiator, err := ton.AccountIDFromTlb(tail.Metadata.InitiatorAddr)
+	if err != nil {
+		return err
+	}
+	if initiator == nil || tail.Metadata.Depth != msg.Metadata.Depth || tail.Metadata.InitiatorLT != msg.Metadata.InitiatorLt ||
+		initiator.Workchain != int32(msg.Metadata.Initiator.Workchain) || initiator.Address != ton.Bits256(msg.Metadata.Initiator.Id) {
+		return fmt.Errorf("message metadata mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package liteapi
+
+import (
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// testDispatchQueueItem is a leaf of the dispatch queue prefixed with its augmentation.
+type testDispatchQueueItem struct {
+	MinLt    uint64
+	Messages tlb.Maybe[tlb.Ref[boc.Cell]]
+	Count    tlb.Uint48
+}
+
+type testOutMsgQueueInfo struct {
+	OutQueue      tlb.Maybe[tlb.Ref[boc.Cell]]
+	OutQueueMinLt uint64
+	ProcInfo      tlb.Maybe[tlb.Ref[boc.Cell]]
+	HasExtra      bool
+	ExtraTag      tlb.Uint4
+	DispatchQueue tlb.HashmapE[tlb.Bits256, testDispatchQueueItem]
+	DispatchMinLt uint64
+	OutQueueSize  tlb.Maybe[tlb.Uint48]
+}
+
+func Test_provenDispatchQueue(t *testing.T) {
+	accounts := []tlb.Bits256{{1}, {2}}
+	items := []testDispatchQueueItem{{MinLt: 100, Count: 2}, {MinLt: 200, Count: 1}}
+	queueInfo := testutil.MustMarshal(t, testOutMsgQueueInfo{
+		HasExtra:      true,
+		DispatchQueue: tlb.NewHashmapE(accounts, items),
+		DispatchMinLt: 100,
+	})
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           100,
+		OutMsgQueueInfo: *queueInfo,
+		Other:           *testutil.CellWithUint(t, 3),
+	})
+	blockID, blockProof := testBlock(t, ton.BlockID{Workchain: 0, Shard: masterchainShard, Seqno: 100}, state)
+	root, err := provenDispatchQueue(blockID, blockProof, merkleProof(t, state))
+	if err != nil {
+		t.Fatalf("provenDispatchQueue() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		queue   liteclient.LiteServerAccountDispatchQueueInfoC
+		wantErr bool
+	}{
+		{
+			name:  "account queue",
+			queue: liteclient.LiteServerAccountDispatchQueueInfoC{Addr: tl.Int256(accounts[0]), Size: 2, MinLt: 100, MaxLt: 150},
+		},
+		{
+			name:  "another account queue",
+			queue: liteclient.LiteServerAccountDispatchQueueInfoC{Addr: tl.Int256(accounts[1]), Size: 1, MinLt: 200, MaxLt: 200},
+		},
+		{
+			name:    "wrong size",
+			queue:   liteclient.LiteServerAccountDispatchQueueInfoC{Addr: tl.Int256(accounts[0]), Size: 1, MinLt: 100, MaxLt: 150},
+			wantErr: true,
+		},
+		{
+			name:    "wrong min lt",
+			queue:   liteclient.LiteServerAccountDispatchQueueInfoC{Addr: tl.Int256(accounts[1]), Size: 1, MinLt: 150, MaxLt: 200},
+			wantErr: true,
+		},
+		{
+			name:    "max lt below min lt",
+			queue:   liteclient.LiteServerAccountDispatchQueueInfoC{Addr: tl.Int256(accounts[0]), Size: 2, MinLt: 100, MaxLt: 50},
+			wantErr: true,
+		},
+		{
+			name:    "unknown account",
+			queue:   liteclient.LiteServerAccountDispatchQueueInfoC{Addr: tl.Int256{3}, Size: 1, MinLt: 100, MaxLt: 100},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAccountDispatchQueue(root, tt.queue)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("checkAccountDispatchQueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_provenDispatchQueue_noExtra(t *testing.T) {
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           100,
+		OutMsgQueueInfo: *testutil.MustMarshal(t, testOutMsgQueueInfo{}),
+		Other:           *testutil.CellWithUint(t, 3),
+	})
+	blockID, blockProof := testBlock(t, ton.BlockID{Workchain: 0, Shard: masterchainShard, Seqno: 100}, state)
+	root, err := provenDispatchQueue(blockID, blockProof, merkleProof(t, state))
+	if err != nil {
+		t.Fatalf("provenDispatchQueue() failed: %v", err)
+	}
+	if root != nil {
+		t.Fatalf("want empty dispatch queue")
+	}
+}
+
+func Test_verifyDispatchQueueInfo_order(t *testing.T) {
+	blockID := ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: masterchainShard, Seqno: 100}}
+	res := liteclient.LiteServerDispatchQueueInfoC{
+		Id: liteclient.BlockIDExt(blockID),
+		AccountDispatchQueues: []liteclient.LiteServerAccountDispatchQueueInfoC{
+			{Addr: tl.Int256{2}}, {Addr: tl.Int256{1}},
+		},
+	}
+	if err := verifyDispatchQueueInfo(blockID, nil, res); err == nil {
+		t.Fatalf("want error for unsorted accounts")
+	}
+	after := ton.Bits256{2}
+	res.AccountDispatchQueues = res.AccountDispatchQueues[:1]
+	if err := verifyDispatchQueueInfo(blockID, &after, res); err == nil {
+		t.Fatalf("want error for account not after %x", after)
+	}
+}
+
+type testMsgEnvelope struct {
+	Magic           tlb.Magic `tlb:"msg_envelope_v2#5"`
+	CurrentAddress  tlb.IntermediateAddress
+	NextAddress     tlb.IntermediateAddress
+	FwdFeeRemaining tlb.Grams
+	Msg             tlb.Ref[boc.Cell]
+	EmittedLt       *uint64          `tlb:"maybe"`
+	Metadata        *tlb.MsgMetadata `tlb:"maybe"`
+}
+
+type testEnqueuedMsg struct {
+	EnqueuedLt uint64
+	Envelope   tlb.Ref[testMsgEnvelope]
+}
+
+func Test_verifyDispatchQueueMessages(t *testing.T) {
+	account := ton.AccountID{Workchain: 0, Address: ton.Bits256{1}}
+	initiator := ton.AccountID{Workchain: -1, Address: ton.Bits256{7}}
+	regular := tlb.IntermediateAddress{SumType: "IntermediateAddressRegular"}
+	msg := testutil.CellWithUint(t, 42)
+	envelope := testMsgEnvelope{
+		CurrentAddress: regular,
+		NextAddress:    regular,
+		Msg:            tlb.Ref[boc.Cell]{Value: *msg},
+		Metadata:       &tlb.MsgMetadata{Depth: 3, InitiatorAddr: initiator.ToMsgAddress(), InitiatorLT: 90},
+	}
+	messages := testutil.MustMarshal(t, tlb.NewHashmapE([]tlb.Uint64{100}, []testEnqueuedMsg{
+		{EnqueuedLt: 100, Envelope: tlb.Ref[testMsgEnvelope]{Value: envelope}},
+	}))
+	queueInfo := testutil.MustMarshal(t, testOutMsgQueueInfo{
+		HasExtra: true,
+		DispatchQueue: tlb.NewHashmapE([]tlb.Bits256{tlb.Bits256(account.Address)}, []testDispatchQueueItem{{
+			MinLt:    100,
+			Messages: tlb.Maybe[tlb.Ref[boc.Cell]]{Exists: true, Value: tlb.Ref[boc.Cell]{Value: *messages.Refs()[0]}},
+			Count:    1,
+		}}),
+		DispatchMinLt: 100,
+	})
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           100,
+		OutMsgQueueInfo: *queueInfo,
+		Other:           *testutil.CellWithUint(t, 3),
+	})
+	blockID, blockProof := testBlock(t, ton.BlockID{Workchain: 0, Shard: masterchainShard, Seqno: 100}, state)
+	proof, err := boc.SerializeBocWithMode([]*boc.Cell{blockProof, merkleProof(t, state)}, 0)
+	if err != nil {
+		t.Fatalf("SerializeBocWithMode() failed: %v", err)
+	}
+	queueMessage := func() liteclient.LiteServerDispatchQueueMessageC {
+		return liteclient.LiteServerDispatchQueueMessageC{
+			Addr: tl.Int256(account.Address),
+			Lt:   100,
+			Hash: tl.Int256(testutil.MustHash(t, msg)),
+			Metadata: liteclient.LiteServerTransactionMetadataC{
+				Depth:       3,
+				Initiator:   liteclient.LiteServerAccountIdC{Workchain: uint32(initiator.Workchain), Id: tl.Int256(initiator.Address)},
+				InitiatorLt: 90,
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		afterLt uint64
+		update  func(m *liteclient.LiteServerDispatchQueueMessageC)
+		wantErr bool
+	}{
+		{name: "valid message"},
+		{name: "message not after lt", afterLt: 100, wantErr: true},
+		{name: "wrong hash", update: func(m *liteclient.LiteServerDispatchQueueMessageC) { m.Hash = tl.Int256{1} }, wantErr: true},
+		{name: "wrong lt", update: func(m *liteclient.LiteServerDispatchQueueMessageC) { m.Lt = 101 }, wantErr: true},
+		{name: "wrong depth", update: func(m *liteclient.LiteServerDispatchQueueMessageC) { m.Metadata.Depth = 4 }, wantErr: true},
+		{name: "wrong initiator", update: func(m *liteclient.LiteServerDispatchQueueMessageC) { m.Metadata.Initiator.Id = tl.Int256{8} }, wantErr: true},
+		{name: "another account", update: func(m *liteclient.LiteServerDispatchQueueMessageC) { m.Addr = tl.Int256{2} }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := queueMessage()
+			if tt.update != nil {
+				tt.update(&m)
+			}
+			res := liteclient.LiteServerDispatchQueueMessagesC{
+				Mode:     3,
+				Id:       liteclient.BlockIDExt(blockID),
+				Messages: []liteclient.LiteServerDispatchQueueMessageC{m},
+				Complete: true,
+				Proof:    proof,
+			}
+			err := verifyDispatchQueueMessages(blockID, account, tt.afterLt, res)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("verifyDispatchQueueMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
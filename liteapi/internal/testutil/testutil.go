@@ -0,0 +1,50 @@
+// Package testutil contains helpers shared by tests of liteapi and its subpackages.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/tlb"
+)
+
+// MustMarshal returns a cell with the given value serialized with tlb.Marshal.
+func MustMarshal(t testing.TB, v any) *boc.Cell {
+	t.Helper()
+	c := boc.NewCell()
+	if err := tlb.Marshal(c, v); err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	return c
+}
+
+// MustBoc serializes the given cell to a BoC.
+func MustBoc(t testing.TB, c *boc.Cell) []byte {
+	t.Helper()
+	data, err := c.ToBoc()
+	if err != nil {
+		t.Fatalf("ToBoc() failed: %v", err)
+	}
+	return data
+}
+
+// MustHash returns a hash of the given cell.
+func MustHash(t testing.TB, c *boc.Cell) [32]byte {
+	t.Helper()
+	hash, err := c.Hash256()
+	if err != nil {
+		t.Fatalf("Hash256() failed: %v", err)
+	}
+	return hash
+}
+
+// CellWithUint returns a cell with the given 64-bit integer.
+// Cells with different integers have different hashes.
+func CellWithUint(t testing.TB, v uint64) *boc.Cell {
+	t.Helper()
+	c := boc.NewCell()
+	if err := c.WriteUint(v, 64); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	return c
+}
@@ -0,0 +1,142 @@
+package liteapi
+
+import (
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// verifyLibraries checks liteServer.libraryResultWithProof returned for the given masterchain block
+// and returns the requested libraries found in the masterchain state.
+// state_proof is a proof of the block's header and data_proof is a proof of the block's state
+// which contains a path in the libraries dictionary for each requested library.
+// A library missing in the answer must be proven to be absent in the dictionary.
+func verifyLibraries(blockID ton.BlockIDExt, libraryList []ton.Bits256, res liteclient.LiteServerLibraryResultWithProofC) (map[ton.Bits256]*boc.Cell, error) {
+	if res.Id.ToBlockIdExt() != blockID {
+		return nil, fmt.Errorf("%w: libraries are for block %v instead of %v", ErrInvalidProof, res.Id.ToBlockIdExt(), blockID)
+	}
+	libraries, err := provenLibraries(blockID, res.StateProof, res.DataProof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: libraries proof: %v", ErrInvalidProof, err)
+	}
+	requested := make(map[ton.Bits256]struct{}, len(libraryList))
+	for _, hash := range libraryList {
+		requested[hash] = struct{}{}
+	}
+	libs := make(map[ton.Bits256]*boc.Cell, len(res.Result))
+	for _, lib := range res.Result {
+		hash := ton.Bits256(lib.Hash)
+		if _, ok := requested[hash]; !ok {
+			return nil, fmt.Errorf("%w: library %x is not requested", ErrInvalidProof, hash)
+		}
+		cell, err := boc.DeserializeSingleRootBoc(lib.Data)
+		if err != nil {
+			return nil, err
+		}
+		dataHash, err := cell.Hash256()
+		if err != nil {
+			return nil, err
+		}
+		if dataHash != hash {
+			return nil, fmt.Errorf("%w: library %x has hash %x", ErrInvalidProof, hash, dataHash)
+		}
+		libs[hash] = cell
+	}
+	for _, hash := range libraryList {
+		exists, err := libraryExists(libraries, hash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: library %x: %v", ErrInvalidProof, hash, err)
+		}
+		if _, ok := libs[hash]; ok != exists {
+			return nil, fmt.Errorf("%w: library %x is missing in the answer or in the state", ErrInvalidProof, hash)
+		}
+	}
+	return libs, nil
+}
+
+// provenLibraries checks proofs of a masterchain block and its state
+// and returns the root of the libraries dictionary or nil if the dictionary is empty.
+func provenLibraries(blockID ton.BlockIDExt, blockProof, stateProof []byte) (*boc.Cell, error) {
+	blockProofCell, err := boc.DeserializeSingleRootBoc(blockProof)
+	if err != nil {
+		return nil, err
+	}
+	stateHash, err := blockStateHash(blockProofCell, blockID)
+	if err != nil {
+		return nil, err
+	}
+	stateProofCell, err := boc.DeserializeSingleRootBoc(stateProof)
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProofCell, stateHash)
+	if err != nil {
+		return nil, fmt.Errorf("masterchain state: %w", err)
+	}
+	// shard_state#9023afe2 ... accounts:^ShardAccounts
+	// ^[ overload_history:uint64 underload_history:uint64
+	// total_balance:CurrencyCollection total_validator_fees:CurrencyCollection
+	// libraries:(HashmapE 256 LibDescr) master_ref:(Maybe BlkMasterInfo) ] ...
+	if magic, err := stateRoot.ReadUint(32); err != nil || magic != shardStateMagic {
+		return nil, fmt.Errorf("invalid masterchain state")
+	}
+	refs := stateRoot.Refs()
+	if len(refs) < 3 {
+		return nil, fmt.Errorf("invalid masterchain state")
+	}
+	other := refs[2]
+	if other.CellType() == boc.PrunedBranchCell {
+		return nil, fmt.Errorf("libraries are pruned")
+	}
+	other.ResetCounters()
+	if err := other.Skip(64 + 64); err != nil {
+		return nil, err
+	}
+	for i := 0; i < 2; i++ {
+		if err := skipCurrencyCollection(other); err != nil {
+			return nil, err
+		}
+	}
+	notEmpty, err := other.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !notEmpty {
+		return nil, nil
+	}
+	return other.NextRef()
+}
+
+// libraryExists looks for a library in a proof of the libraries dictionary.
+func libraryExists(root *boc.Cell, hash ton.Bits256) (bool, error) {
+	if root == nil {
+		return false, nil
+	}
+	key := boc.NewBitString(256)
+	if err := key.WriteBytes(hash[:]); err != nil {
+		return false, err
+	}
+	value, err := tlb.FindHashmapValue(root, key)
+	if err != nil || value == nil {
+		return false, err
+	}
+	// shared_lib_descr$00 lib:^Cell publishers:(Hashmap 256 True) = LibDescr;
+	if tag, err := value.ReadUint(2); err != nil || tag != 0 {
+		return false, fmt.Errorf("invalid library description")
+	}
+	lib, err := value.NextRef()
+	if err != nil {
+		return false, err
+	}
+	libHash, err := lib.HashAtLevel(0)
+	if err != nil {
+		return false, err
+	}
+	if ton.Bits256(libHash) != hash {
+		return false, fmt.Errorf("library is stored under another hash")
+	}
+	return true, nil
+}
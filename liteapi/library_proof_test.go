@@ -0,0 +1,130 @@
+package liteapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type testLibDescr struct {
+	Magic      tlb.Magic `tlb:"shared_lib_descr$00"`
+	Lib        boc.Cell  `tlb:"^"`
+	Publishers tlb.Hashmap[tlb.Bits256, struct{}]
+}
+
+type testShardStateOther struct {
+	OverloadHistory    uint64
+	UnderloadHistory   uint64
+	TotalBalance       tlb.CurrencyCollection
+	TotalValidatorFees tlb.CurrencyCollection
+	Libraries          tlb.HashmapE[tlb.Bits256, testLibDescr]
+	MasterRef          bool
+}
+
+func Test_verifyLibraries(t *testing.T) {
+	var libs []*boc.Cell
+	var hashes []ton.Bits256
+	for i := 0; i < 3; i++ {
+		lib := testutil.CellWithUint(t, uint64(1000+i))
+		libs = append(libs, lib)
+		hashes = append(hashes, testutil.MustHash(t, lib))
+	}
+	// the third library isn't published in the masterchain.
+	var keys []tlb.Bits256
+	var descrs []testLibDescr
+	for i := 0; i < 2; i++ {
+		keys = append(keys, tlb.Bits256(hashes[i]))
+		descrs = append(descrs, testLibDescr{
+			Lib:        *libs[i],
+			Publishers: tlb.NewHashmap([]tlb.Bits256{{1}}, []struct{}{{}}),
+		})
+	}
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           100,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 3),
+		Other:           *testutil.MustMarshal(t, testShardStateOther{Libraries: tlb.NewHashmapE(keys, descrs)}),
+	})
+	mcBlock, blockProof := testBlock(t, ton.BlockID{Workchain: -1, Shard: masterchainShard, Seqno: 100}, state)
+	entry := func(i int) liteclient.LiteServerLibraryEntryC {
+		return liteclient.LiteServerLibraryEntryC{Hash: tl.Int256(hashes[i]), Data: testutil.MustBoc(t, libs[i])}
+	}
+
+	tests := []struct {
+		name        string
+		libraryList []ton.Bits256
+		result      []liteclient.LiteServerLibraryEntryC
+		want        []ton.Bits256
+		wantErr     bool
+	}{
+		{
+			name:        "all libraries found",
+			libraryList: hashes[:2],
+			result:      []liteclient.LiteServerLibraryEntryC{entry(0), entry(1)},
+			want:        hashes[:2],
+		},
+		{
+			name:        "missing library",
+			libraryList: hashes,
+			result:      []liteclient.LiteServerLibraryEntryC{entry(1), entry(0)},
+			want:        hashes[:2],
+		},
+		{
+			name:        "hidden library",
+			libraryList: hashes[:2],
+			result:      []liteclient.LiteServerLibraryEntryC{entry(0)},
+			wantErr:     true,
+		},
+		{
+			name:        "forged library",
+			libraryList: hashes,
+			result:      []liteclient.LiteServerLibraryEntryC{entry(0), entry(1), entry(2)},
+			wantErr:     true,
+		},
+		{
+			name:        "wrong data",
+			libraryList: hashes[:1],
+			result:      []liteclient.LiteServerLibraryEntryC{{Hash: tl.Int256(hashes[0]), Data: testutil.MustBoc(t, libs[1])}},
+			wantErr:     true,
+		},
+		{
+			name:        "not requested library",
+			libraryList: hashes[1:2],
+			result:      []liteclient.LiteServerLibraryEntryC{entry(0), entry(1)},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := liteclient.LiteServerLibraryResultWithProofC{
+				Id:         liteclient.BlockIDExt(mcBlock),
+				Result:     tt.result,
+				StateProof: testutil.MustBoc(t, blockProof),
+				DataProof:  testutil.MustBoc(t, merkleProof(t, state)),
+			}
+			got, err := verifyLibraries(mcBlock, tt.libraryList, res)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidProof) {
+					t.Fatalf("want ErrInvalidProof, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyLibraries() failed: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("want %v libraries, got: %v", len(tt.want), len(got))
+			}
+			for _, hash := range tt.want {
+				if lib, ok := got[hash]; !ok || testutil.MustHash(t, lib) != hash {
+					t.Fatalf("library %x not found", hash)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package liteapi
+
+import (
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// verifyLookupBlock checks liteServer.lookupBlockResult returned for the given masterchain block
+// and returns the found block and its header.
+//
+// The answer proves that the found block matches the query and belongs to the chain of the masterchain block:
+//   - header is a proof of the found block's header;
+//   - mc_block_proof is a proof of the header of the first masterchain block referring to the found block;
+//   - client_mc_state_proof proves the masterchain block above with the state of the given masterchain block
+//     unless they are the same block;
+//   - shard_links go from the masterchain block above through shard blocks down to the found block,
+//     each link is a proof of the previous block of the chain referring to the block of the link.
+func verifyLookupBlock(mcBlock ton.BlockIDExt, blockID ton.BlockID, mode uint32, lt *uint64, utime *uint32, res liteclient.LiteServerLookupBlockResultC) (ton.BlockIDExt, tlb.BlockInfo, error) {
+	if res.McBlockId.ToBlockIdExt() != mcBlock {
+		return ton.BlockIDExt{}, tlb.BlockInfo{}, fmt.Errorf("%w: block is looked up in block %v instead of %v", ErrInvalidProof, res.McBlockId.ToBlockIdExt(), mcBlock)
+	}
+	found := res.Id.ToBlockIdExt()
+	if err := checkLookupResult(found, blockID, mode, lt, utime, res.Header); err != nil {
+		return ton.BlockIDExt{}, tlb.BlockInfo{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if err := checkLookupChain(mcBlock, found, res); err != nil {
+		return ton.BlockIDExt{}, tlb.BlockInfo{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	_, info, err := decodeBlockHeader(liteclient.LiteServerBlockHeaderC{Id: res.Id, HeaderProof: res.Header})
+	if err != nil {
+		return ton.BlockIDExt{}, tlb.BlockInfo{}, err
+	}
+	return found, info, nil
+}
+
+// checkLookupResult checks that the found block matches the query of liteServer.lookupBlockWithProof.
+func checkLookupResult(found ton.BlockIDExt, blockID ton.BlockID, mode uint32, lt *uint64, utime *uint32, header []byte) error {
+	if found.Workchain != blockID.Workchain || !shardsIntersect(found.Shard, blockID.Shard) {
+		return fmt.Errorf("block %v doesn't match the query %v", found, blockID)
+	}
+	headerProof, err := boc.DeserializeSingleRootBoc(header)
+	if err != nil {
+		return err
+	}
+	info, err := blockInfo(headerProof, found)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mode&1 != 0 && found.Seqno != blockID.Seqno:
+		return fmt.Errorf("block %v has another seqno than %v", found, blockID.Seqno)
+	case mode&2 != 0 && lt != nil && (*lt < info.StartLt || *lt > info.EndLt):
+		return fmt.Errorf("block %v with lt range [%v, %v] doesn't contain lt %v", found, info.StartLt, info.EndLt, *lt)
+	case mode&4 != 0 && utime != nil && info.GenUtime > *utime:
+		return fmt.Errorf("block %v is generated at %v after %v", found, info.GenUtime, *utime)
+	}
+	return nil
+}
+
+// checkLookupChain checks that the found block belongs to the chain of the masterchain block.
+func checkLookupChain(mcBlock, found ton.BlockIDExt, res liteclient.LiteServerLookupBlockResultC) error {
+	if found.Workchain == masterchainID {
+		if len(res.ShardLinks) != 0 {
+			return fmt.Errorf("unexpected shard links for masterchain block %v", found)
+		}
+		proven, err := proveOlderMcBlock(mcBlock, found.Seqno, found.RootHash, res.ClientMcStateProof)
+		if err != nil {
+			return err
+		}
+		if proven != found {
+			return fmt.Errorf("block %v is not in the chain of block %v", found, mcBlock)
+		}
+		return nil
+	}
+	// the masterchain block referring to the found block is identified by its header proof.
+	mcBlockProof, err := boc.DeserializeSingleRootBoc(res.McBlockProof)
+	if err != nil {
+		return err
+	}
+	if mcBlockProof.CellType() != boc.MerkleProofCell || mcBlockProof.RefsSize() != 1 {
+		return fmt.Errorf("masterchain block proof is not a merkle proof")
+	}
+	hash, err := mcBlockProof.Refs()[0].HashAtLevel(0)
+	if err != nil {
+		return err
+	}
+	var rootHash ton.Bits256
+	copy(rootHash[:], hash)
+	root, err := boc.VerifyMerkleProof(mcBlockProof, rootHash)
+	if err != nil {
+		return err
+	}
+	refs := root.Refs()
+	if len(refs) == 0 || refs[0].CellType() == boc.PrunedBranchCell {
+		return fmt.Errorf("masterchain block info is pruned")
+	}
+	var info struct {
+		Magic tlb.Magic `tlb:"block_info#9bc7a987"`
+		Info  tlb.BlockInfoPart
+	}
+	refs[0].ResetCounters()
+	if err := tlb.Unmarshal(refs[0], &info); err != nil {
+		return err
+	}
+	if id := blockIDFromInfo(info.Info); id.Workchain != masterchainID || id.Shard != masterchainShard {
+		return fmt.Errorf("block %v is not a masterchain block", id)
+	}
+	current, err := proveOlderMcBlock(mcBlock, info.Info.SeqNo, rootHash, res.ClientMcStateProof)
+	if err != nil {
+		return err
+	}
+	if len(res.ShardLinks) == 0 {
+		return fmt.Errorf("no shard links for block %v", found)
+	}
+	// the masterchain block keeps the top block of the leftmost shard intersecting with the found one.
+	path := found.Shard&^(found.Shard&-found.Shard) | 1
+	for i, link := range res.ShardLinks {
+		proof, err := boc.DeserializeSingleRootBoc(link.Proof)
+		if err != nil {
+			return err
+		}
+		var next ton.BlockIDExt
+		if i == 0 {
+			extra, err := mcBlockExtra(proof, current)
+			if err != nil {
+				return err
+			}
+			// masterchain_block_extra#cca5 key_block:(## 1) shard_hashes:ShardHashes ...
+			if err := extra.Skip(16 + 1); err != nil {
+				return err
+			}
+			desc, err := findShardInHashes(extra, found.Workchain, path)
+			if err != nil {
+				return err
+			}
+			next = ton.ToBlockId(desc, found.Workchain)
+		} else {
+			next, err = prevShardBlock(proof, current, found.Shard)
+			if err != nil {
+				return err
+			}
+		}
+		if next != link.Id.ToBlockIdExt() {
+			return fmt.Errorf("shard link %v is %v instead of %v", i, link.Id.ToBlockIdExt(), next)
+		}
+		current = next
+	}
+	if current != found {
+		return fmt.Errorf("shard links end with block %v instead of %v", current, found)
+	}
+	return nil
+}
+
+// proveOlderMcBlock returns an id of the masterchain block with the given seqno and root hash
+// if it is the given masterchain block or its previous block according to the state proof.
+// The state proof consists of a proof of the given block's header and a proof of its state.
+func proveOlderMcBlock(mcBlock ton.BlockIDExt, seqno uint32, rootHash ton.Bits256, stateProof []byte) (ton.BlockIDExt, error) {
+	if seqno == mcBlock.Seqno {
+		if rootHash != mcBlock.RootHash {
+			return ton.BlockIDExt{}, fmt.Errorf("block %v has another root hash %x", mcBlock, rootHash)
+		}
+		return mcBlock, nil
+	}
+	if seqno > mcBlock.Seqno {
+		return ton.BlockIDExt{}, fmt.Errorf("block %v is newer than block %v", seqno, mcBlock)
+	}
+	blockProof, stateProofCell, err := proofRoots(stateProof)
+	if err != nil {
+		return ton.BlockIDExt{}, fmt.Errorf("masterchain state proof: %w", err)
+	}
+	return checkPrevMcBlock(mcBlock, seqno, rootHash, blockProof, stateProofCell)
+}
+
+// checkPrevMcBlock returns an id of a previous masterchain block with the given seqno and root hash
+// taken from the state of the given masterchain block.
+func checkPrevMcBlock(mcBlock ton.BlockIDExt, seqno uint32, rootHash ton.Bits256, blockProof, stateProof *boc.Cell) (ton.BlockIDExt, error) {
+	stateHash, err := blockStateHash(blockProof, mcBlock)
+	if err != nil {
+		return ton.BlockIDExt{}, err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProof, stateHash)
+	if err != nil {
+		return ton.BlockIDExt{}, fmt.Errorf("masterchain state: %w", err)
+	}
+	ref, err := findPrevBlock(stateRoot, seqno)
+	if err != nil {
+		return ton.BlockIDExt{}, err
+	}
+	if ref.BlkRef.SeqNo != seqno || ton.Bits256(ref.BlkRef.RootHash) != rootHash {
+		return ton.BlockIDExt{}, fmt.Errorf("block %v with root hash %x is not a previous block of %v", seqno, rootHash, mcBlock)
+	}
+	return ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: masterchainID, Shard: masterchainShard, Seqno: seqno},
+		RootHash: rootHash,
+		FileHash: ton.Bits256(ref.BlkRef.FileHash),
+	}, nil
+}
+
+// prevShardBlock checks a proof of a shard block's header
+// and returns the previous block of the shard block intersecting with the given shard.
+func prevShardBlock(proof *boc.Cell, blockID ton.BlockIDExt, shard uint64) (ton.BlockIDExt, error) {
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		return ton.BlockIDExt{}, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	var header tlb.BlockHeader
+	if err := tlb.Unmarshal(root, &header); err != nil {
+		return ton.BlockIDExt{}, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	if blockIDFromInfo(header.Info.BlockInfoPart) != blockID.BlockID {
+		return ton.BlockIDExt{}, fmt.Errorf("block %v: block info doesn't match block id", blockID)
+	}
+	parents, err := ton.GetParents(header.Info)
+	if err != nil {
+		return ton.BlockIDExt{}, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	for _, parent := range parents {
+		if shardsIntersect(parent.Shard, shard) {
+			return parent, nil
+		}
+	}
+	return ton.BlockIDExt{}, fmt.Errorf("block %v has no previous block in shard %x", blockID, shard)
+}
+
+// shardsIntersect reports whether one of the given shards contains the other one.
+func shardsIntersect(a, b uint64) bool {
+	bit := max(a&-a, b&-b)
+	mask := ^(bit<<1 - 1)
+	return a&mask == b&mask
+}
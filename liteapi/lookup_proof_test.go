@@ -0,0 +1,269 @@
+package liteapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// testShardBlock returns a block of the basechain with the given previous block and a proof of the block.
+func testShardBlock(t *testing.T, seqno uint32, prev ton.BlockIDExt, startLt, endLt uint64) (ton.BlockIDExt, *boc.Cell) {
+	t.Helper()
+	var info testBlockInfo
+	info.Info.NotMaster = true
+	info.Info.SeqNo = seqno
+	info.Info.StartLt = startLt
+	info.Info.EndLt = endLt
+	infoCell := testutil.MustMarshal(t, info)
+	prevRef := tlb.ExtBlkRef{SeqNo: prev.Seqno, RootHash: tlb.Bits256(prev.RootHash), FileHash: tlb.Bits256(prev.FileHash)}
+	for _, err := range []error{
+		infoCell.AddRef(testutil.MustMarshal(t, tlb.BlkMasterInfo{})),
+		infoCell.AddRef(testutil.MustMarshal(t, prevRef)),
+	} {
+		if err != nil {
+			t.Fatalf("failed to build block info: %v", err)
+		}
+	}
+	block := testBlockCell(t, infoCell, testutil.CellWithUint(t, uint64(seqno)), testutil.CellWithUint(t, 2))
+	blockID := ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: 0, Shard: masterchainShard, Seqno: seqno},
+		RootHash: testutil.MustHash(t, block),
+		FileHash: ton.Bits256{byte(seqno)},
+	}
+	return blockID, merkleProof(t, block)
+}
+
+func Test_verifyLookupBlock(t *testing.T) {
+	// the masterchain block refers to shard block 5 which follows block 4.
+	block3 := ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: masterchainShard, Seqno: 3}, RootHash: ton.Bits256{3}}
+	block4, block4Proof := testShardBlock(t, 4, block3, 400, 499)
+	block5, block5Proof := testShardBlock(t, 5, block4, 500, 599)
+
+	var desc tlb.ShardDesc
+	desc.SumType = "New"
+	desc.New.SeqNo = block5.Seqno
+	desc.New.RootHash = tlb.Bits256(block5.RootHash)
+	desc.New.FileHash = tlb.Bits256(block5.FileHash)
+	desc.New.NextValidatorShard = int64(block5.Shard)
+	leaf := boc.NewCell()
+	if err := leaf.WriteBit(false); err != nil {
+		t.Fatalf("WriteBit() failed: %v", err)
+	}
+	if err := tlb.Marshal(leaf, desc); err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	mcExtra := testutil.MustMarshal(t, testMcBlockExtra{
+		ShardHashes: tlb.NewHashmapE([]tlb.Uint32{0}, []testBinTreeRef{{Tree: *leaf}}),
+		Other:       *testutil.CellWithUint(t, 5),
+		Config:      tlb.NewHashmap([]tlb.Uint32{28}, []testConfigParam{{Param: *testutil.CellWithUint(t, 6)}}),
+	})
+	extra := testutil.MustMarshal(t, testBlockExtra{
+		InMsgDescr:    *testutil.CellWithUint(t, 6),
+		OutMsgDescr:   *testutil.CellWithUint(t, 7),
+		AccountBlocks: *testutil.CellWithUint(t, 8),
+		Custom:        mcExtra,
+	})
+	mcInfo := testBlockInfoCell(t, 100, false, 0, 0)
+	if err := mcInfo.AddRef(testutil.MustMarshal(t, tlb.ExtBlkRef{SeqNo: 99})); err != nil {
+		t.Fatalf("AddRef() failed: %v", err)
+	}
+	mc := testBlockCell(t, mcInfo, testutil.CellWithUint(t, 100), extra)
+	mcBlock := ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: -1, Shard: masterchainShard, Seqno: 100},
+		RootHash: testutil.MustHash(t, mc),
+		FileHash: ton.Bits256{100},
+	}
+	mcProof := testutil.MustBoc(t, merkleProof(t, mc))
+	lookup := func(found ton.BlockIDExt, header *boc.Cell, links ...liteclient.LiteServerShardBlockLinkC) liteclient.LiteServerLookupBlockResultC {
+		return liteclient.LiteServerLookupBlockResultC{
+			Id:           liteclient.BlockIDExt(found),
+			McBlockId:    liteclient.BlockIDExt(mcBlock),
+			McBlockProof: mcProof,
+			ShardLinks:   links,
+			Header:       testutil.MustBoc(t, header),
+		}
+	}
+	link := func(id ton.BlockIDExt, proof []byte) liteclient.LiteServerShardBlockLinkC {
+		return liteclient.LiteServerShardBlockLinkC{Id: liteclient.BlockIDExt(id), Proof: proof}
+	}
+	lt := uint64(450)
+
+	tests := []struct {
+		name    string
+		blockID ton.BlockID
+		mode    uint32
+		lt      *uint64
+		mcBlock ton.BlockIDExt
+		res     liteclient.LiteServerLookupBlockResultC
+		want    ton.BlockIDExt
+		wantErr bool
+	}{
+		{
+			name:    "top shard block",
+			blockID: block5.BlockID,
+			mode:    1,
+			mcBlock: mcBlock,
+			res:     lookup(block5, block5Proof, link(block5, mcProof)),
+			want:    block5,
+		},
+		{
+			name:    "previous shard block by lt",
+			blockID: ton.BlockID{Workchain: 0, Shard: masterchainShard},
+			mode:    2,
+			lt:      &lt,
+			mcBlock: mcBlock,
+			res:     lookup(block4, block4Proof, link(block5, mcProof), link(block4, testutil.MustBoc(t, block5Proof))),
+			want:    block4,
+		},
+		{
+			name:    "masterchain block",
+			blockID: mcBlock.BlockID,
+			mode:    1,
+			mcBlock: mcBlock,
+			res:     lookup(mcBlock, merkleProof(t, mc)),
+			want:    mcBlock,
+		},
+		{
+			name:    "another seqno",
+			blockID: block4.BlockID,
+			mode:    1,
+			mcBlock: mcBlock,
+			res:     lookup(block5, block5Proof, link(block5, mcProof)),
+			wantErr: true,
+		},
+		{
+			name:    "lt out of block",
+			blockID: ton.BlockID{Workchain: 0, Shard: masterchainShard},
+			mode:    2,
+			lt:      &lt,
+			mcBlock: mcBlock,
+			res:     lookup(block5, block5Proof, link(block5, mcProof)),
+			wantErr: true,
+		},
+		{
+			name:    "broken chain",
+			blockID: block4.BlockID,
+			mode:    1,
+			mcBlock: mcBlock,
+			res:     lookup(block4, block4Proof, link(block4, mcProof)),
+			wantErr: true,
+		},
+		{
+			name:    "chain doesn't reach the block",
+			blockID: block4.BlockID,
+			mode:    1,
+			mcBlock: mcBlock,
+			res:     lookup(block4, block4Proof, link(block5, mcProof)),
+			wantErr: true,
+		},
+		{
+			name:    "another masterchain block",
+			blockID: block5.BlockID,
+			mode:    1,
+			mcBlock: ton.BlockIDExt{BlockID: mcBlock.BlockID, RootHash: ton.Bits256{1}},
+			res:     lookup(block5, block5Proof, link(block5, mcProof)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, info, err := verifyLookupBlock(tt.mcBlock, tt.blockID, tt.mode, tt.lt, nil, tt.res)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidProof) {
+					t.Fatalf("want ErrInvalidProof, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyLookupBlock() failed: %v", err)
+			}
+			if got != tt.want || info.SeqNo != tt.want.Seqno {
+				t.Fatalf("want block %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_checkPrevMcBlock(t *testing.T) {
+	chain := newTestChain(t)
+	blockProof, err := boc.DeserializeSingleRootBoc(chain.blockBoc)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	stateProof, err := boc.DeserializeSingleRootBoc(chain.blockState)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	tests := []struct {
+		name     string
+		seqno    uint32
+		rootHash ton.Bits256
+		want     ton.BlockIDExt
+		wantErr  bool
+	}{
+		{
+			name:     "previous block",
+			seqno:    chain.prevBlock.Seqno,
+			rootHash: chain.prevBlock.RootHash,
+			want:     chain.prevBlock,
+		},
+		{
+			name:     "previous key block",
+			seqno:    chain.keyBlock.Seqno,
+			rootHash: chain.keyBlock.RootHash,
+			want:     chain.keyBlock,
+		},
+		{
+			name:     "root hash mismatch",
+			seqno:    chain.prevBlock.Seqno,
+			rootHash: chain.keyBlock.RootHash,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown block",
+			seqno:    25,
+			rootHash: chain.prevBlock.RootHash,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkPrevMcBlock(chain.block, tt.seqno, tt.rootHash, blockProof, stateProof)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkPrevMcBlock() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_shardsIntersect(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want bool
+	}{
+		{a: 0x8000000000000000, b: 0x4000000000000000, want: true},
+		{a: 0x4000000000000000, b: 0x2000000000000000, want: true},
+		{a: 0x4000000000000000, b: 0xc000000000000000, want: false},
+		{a: 0x6000000000000000, b: 0x2000000000000000, want: false},
+		{a: 0x6000000000000000, b: 0x6000000000000000, want: true},
+	}
+	for _, tt := range tests {
+		if got := shardsIntersect(tt.a, tt.b); got != tt.want || shardsIntersect(tt.b, tt.a) != tt.want {
+			t.Errorf("shardsIntersect(%x, %x) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
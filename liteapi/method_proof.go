@@ -0,0 +1,316 @@
+package liteapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/caigou-xyz/tongo/boc"
+	tongocode "github.com/caigou-xyz/tongo/code"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+const (
+	// runSmcMethodMode is a mode of liteServer.runSmcMethod that returns only the result of a get method.
+	runSmcMethodMode = 1 << 2
+	// runSmcMethodProofMode is a mode of liteServer.runSmcMethod that additionally returns
+	// proofs of the shard block and the account (bit 0), a proof of the account's cells used by the method (bit 1)
+	// and the initial c7 register (bit 3).
+	runSmcMethodProofMode = 1<<0 | 1<<1 | runSmcMethodMode | 1<<3
+
+	// accountNotFoundExitCode is an exit code returned by liteServer.runSmcMethod for a nonexistent account.
+	accountNotFoundExitCode = 0xffffff00 // -256
+
+	// smartContractInfoMagic is the first element of the SmartContractInfo tuple stored in c7.
+	smartContractInfoMagic = 0x076ef1ea
+)
+
+// MethodEmulator runs get methods locally.
+// A client configured with WithMethodEmulator uses it to check results of get methods returned by lite servers.
+// tvm.MethodEmulator implements MethodEmulator with the TVM emulator.
+type MethodEmulator interface {
+	// RunGetMethod runs a get method in the given environment and
+	// returns its exit code and the resulting stack serialized as VmStack.
+	RunGetMethod(ctx context.Context, env ton.MethodEnv, methodID int, params tlb.VmStack) (uint32, *boc.Cell, error)
+}
+
+// verifyRunSmcMethod checks that the result of liteServer.runSmcMethod is produced by the account's code and data
+// proven against the given masterchain block.
+// The algorithm follows the check of get method results in the reference lite client:
+// it proves the account state and then runs the method locally with the client's method emulator.
+// The balance and the blockchain configuration of the initial c7 register are checked against proven states,
+// libraries the code refers to are requested from the lite server and checked by their hashes.
+func (c *Client) verifyRunSmcMethod(ctx context.Context, client *liteclient.Client, blockID ton.BlockIDExt, accountID ton.AccountID, methodID int, params tlb.VmStack, res liteclient.LiteServerRunMethodResultC) error {
+	if res.Mode != runSmcMethodProofMode {
+		return fmt.Errorf("%w: get method result has mode %v instead of %v", ErrInvalidProof, res.Mode, runSmcMethodProofMode)
+	}
+	shardBlock, err := verifyAccountShardBlock(blockID, accountID, res.Id.ToBlockIdExt(), res.Shardblk.ToBlockIdExt(), res.ShardProof)
+	if err != nil {
+		return err
+	}
+	blockProof, stateProof, err := proofRoots(res.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %v", ErrInvalidProof, err)
+	}
+	accountHash, err := provenAccountHash(shardBlock, accountID, blockProof, stateProof)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %v", ErrInvalidProof, err)
+	}
+	if accountHash == nil {
+		if res.ExitCode == accountNotFoundExitCode {
+			return nil
+		}
+		return fmt.Errorf("%w: proof shows that account doesn't exist, but get method was executed", ErrInvalidProof)
+	}
+	if res.ExitCode == accountNotFoundExitCode {
+		return fmt.Errorf("%w: proof shows that account exists, but lite server didn't find it", ErrInvalidProof)
+	}
+	env, err := methodEnv(accountID, accountHash, res.StateProof, res.InitC7)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	configHash, err := c.provenConfigHash(ctx, client, blockID, shardBlock, res)
+	if err != nil {
+		return err
+	}
+	if env.Config == nil {
+		return fmt.Errorf("%w: init c7 has no global config", ErrInvalidProof)
+	}
+	if hash, err := env.Config.Hash256(); err != nil || hash != configHash {
+		return fmt.Errorf("%w: global config of init c7 doesn't match the masterchain state", ErrInvalidProof)
+	}
+	if env.Libraries, err = c.methodLibraries(ctx, env.Code); err != nil {
+		return err
+	}
+	exitCode, stack, err := c.methodEmulator.RunGetMethod(ctx, env, methodID, params)
+	if err != nil {
+		return fmt.Errorf("failed to run get method locally: %w", err)
+	}
+	if exitCode != res.ExitCode {
+		return fmt.Errorf("%w: get method exit code is %v, but local execution gives %v", ErrInvalidProof, res.ExitCode, exitCode)
+	}
+	result, err := boc.DeserializeSingleRootBoc(res.Result)
+	if err != nil {
+		return err
+	}
+	expected, err := stack.Hash()
+	if err != nil {
+		return err
+	}
+	hash, err := result.Hash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, expected) {
+		return fmt.Errorf("%w: get method result doesn't match local execution", ErrInvalidProof)
+	}
+	return nil
+}
+
+// methodEnv returns an environment of a get method
+// from a proof of the account's cells used during execution and the initial c7 register.
+func methodEnv(accountID ton.AccountID, accountHash []byte, stateProof, initC7 []byte) (ton.MethodEnv, error) {
+	proofCell, err := boc.DeserializeSingleRootBoc(stateProof)
+	if err != nil {
+		return ton.MethodEnv{}, fmt.Errorf("account state proof: %w", err)
+	}
+	var hash [32]byte
+	copy(hash[:], accountHash)
+	// cells of the account which weren't used during execution are pruned from the proof.
+	accountCell, err := prunedProofRoot(proofCell, hash)
+	if err != nil {
+		return ton.MethodEnv{}, fmt.Errorf("account state proof: %w", err)
+	}
+	var account tlb.Account
+	if err := tlb.Unmarshal(accountCell, &account); err != nil {
+		return ton.MethodEnv{}, fmt.Errorf("account state proof: %w", err)
+	}
+	if account.SumType != "Account" || account.Account.Storage.State.SumType != "AccountActive" {
+		return ton.MethodEnv{}, fmt.Errorf("account is not active")
+	}
+	stateInit := account.Account.Storage.State.AccountActive.StateInit
+	if !stateInit.Code.Exists || !stateInit.Data.Exists {
+		return ton.MethodEnv{}, fmt.Errorf("account has no code or data")
+	}
+	env := ton.MethodEnv{
+		AccountID: accountID,
+		Code:      &stateInit.Code.Value.Value,
+		Data:      &stateInit.Data.Value.Value,
+	}
+	if err := decodeInitC7(initC7, &env); err != nil {
+		return ton.MethodEnv{}, fmt.Errorf("init c7: %w", err)
+	}
+	if balance := uint64(account.Account.Storage.Balance.Grams); env.Balance != balance {
+		return ton.MethodEnv{}, fmt.Errorf("init c7: balance %v doesn't match the account's balance %v", env.Balance, balance)
+	}
+	return env, nil
+}
+
+// provenConfigHash returns a hash of the blockchain configuration of the given masterchain block.
+// The configuration is looked up in the proof of the masterchain state returned along with a get method result,
+// if the proof doesn't contain it, it is requested with liteServer.getConfigParams.
+func (c *Client) provenConfigHash(ctx context.Context, client *liteclient.Client, blockID, shardBlock ton.BlockIDExt, res liteclient.LiteServerRunMethodResultC) (ton.Bits256, error) {
+	proof := res.ShardProof
+	if shardBlock == blockID {
+		// the account is in the masterchain, so its proof is a proof of the masterchain state.
+		proof = res.Proof
+	}
+	if blockProof, stateProof, err := proofRoots(proof); err == nil {
+		if hash, err := configHash(blockID, blockProof, stateProof); err == nil {
+			return hash, nil
+		}
+	}
+	config, err := client.LiteServerGetConfigParams(ctx, liteclient.LiteServerGetConfigParamsRequest{
+		Id: liteclient.BlockIDExt(blockID),
+	})
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	if config.Id.ToBlockIdExt() != blockID {
+		return ton.Bits256{}, fmt.Errorf("%w: config is for block %v instead of %v", ErrInvalidProof, config.Id.ToBlockIdExt(), blockID)
+	}
+	blockProof, err := boc.DeserializeSingleRootBoc(config.StateProof)
+	if err != nil {
+		return ton.Bits256{}, fmt.Errorf("%w: config proof: %v", ErrInvalidProof, err)
+	}
+	stateProof, err := boc.DeserializeSingleRootBoc(config.ConfigProof)
+	if err != nil {
+		return ton.Bits256{}, fmt.Errorf("%w: config proof: %v", ErrInvalidProof, err)
+	}
+	hash, err := configHash(blockID, blockProof, stateProof)
+	if err != nil {
+		return ton.Bits256{}, fmt.Errorf("%w: config proof: %v", ErrInvalidProof, err)
+	}
+	return hash, nil
+}
+
+// configHash checks proofs of a masterchain block and its state
+// and returns a hash of the root cell of the blockchain configuration.
+func configHash(blockID ton.BlockIDExt, blockProof, stateProof *boc.Cell) (ton.Bits256, error) {
+	stateHash, err := blockStateHash(blockProof, blockID)
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProof, stateHash)
+	if err != nil {
+		return ton.Bits256{}, fmt.Errorf("masterchain state: %w", err)
+	}
+	extra, err := mcStateExtra(stateRoot)
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	// masterchain_state_extra#cc26 shard_hashes:ShardHashes config:ConfigParams ...
+	// _ config_addr:bits256 config:^(Hashmap 32 ^Cell) = ConfigParams;
+	if err := skipMaybeRef(extra); err != nil {
+		return ton.Bits256{}, err
+	}
+	if err := extra.Skip(256); err != nil {
+		return ton.Bits256{}, err
+	}
+	config, err := extra.NextRef()
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	hash, err := config.HashAtLevel(0)
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	return ton.Bits256(hash), nil
+}
+
+// methodLibraries returns libraries the given code refers to.
+// A library is identified by its hash, so a lite server can't forge it.
+func (c *Client) methodLibraries(ctx context.Context, code *boc.Cell) (map[ton.Bits256]*boc.Cell, error) {
+	hashes, err := tongocode.FindLibraries(code)
+	if err != nil || len(hashes) == 0 {
+		return nil, err
+	}
+	libs, err := c.GetLibrariesWithProof(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	for hash, lib := range libs {
+		libHash, err := lib.Hash256()
+		if err != nil {
+			return nil, err
+		}
+		if libHash != hash {
+			return nil, fmt.Errorf("%w: library %x has hash %x", ErrInvalidProof, hash, libHash)
+		}
+	}
+	return libs, nil
+}
+
+// decodeInitC7 decodes the initial c7 register a lite server ran a get method with.
+func decodeInitC7(initC7 []byte, env *ton.MethodEnv) error {
+	cell, err := boc.DeserializeSingleRootBoc(initC7)
+	if err != nil {
+		return err
+	}
+	var c7 tlb.VmStackValue
+	if err := tlb.Unmarshal(cell, &c7); err != nil {
+		return err
+	}
+	// c7 is a tuple with SmartContractInfo as its first element:
+	// [magic, actions, msgs_sent, unixtime, block_lt, trans_lt, rand_seed, balance, myself, global_config, ...].
+	if c7.SumType != "VmStkTuple" || c7.VmStkTuple.Len == 0 || c7.VmStkTuple.Data == nil {
+		return fmt.Errorf("c7 is not a tuple")
+	}
+	items, err := tupleItems(c7.VmStkTuple)
+	if err != nil {
+		return err
+	}
+	info := items[0]
+	if info.SumType != "VmStkTuple" || info.VmStkTuple.Len < 10 || info.VmStkTuple.Data == nil {
+		return fmt.Errorf("invalid SmartContractInfo")
+	}
+	values, err := tupleItems(info.VmStkTuple)
+	if err != nil {
+		return err
+	}
+	for _, idx := range []int{0, 3, 6} {
+		if !values[idx].IsInt() {
+			return fmt.Errorf("SmartContractInfo element %v is not an integer", idx)
+		}
+	}
+	if values[0].Int64() != smartContractInfoMagic {
+		return fmt.Errorf("invalid SmartContractInfo")
+	}
+	env.UnixTime = uint32(values[3].Uint64())
+	seed := big.Int(values[6].Int257())
+	if seed.Sign() < 0 || seed.BitLen() > 256 {
+		return fmt.Errorf("invalid rand seed")
+	}
+	seed.FillBytes(env.RandSeed[:])
+	balance := values[7]
+	if balance.SumType != "VmStkTuple" || balance.VmStkTuple.Len == 0 || balance.VmStkTuple.Data == nil {
+		return fmt.Errorf("invalid balance")
+	}
+	balanceItems, err := tupleItems(balance.VmStkTuple)
+	if err != nil {
+		return err
+	}
+	if !balanceItems[0].IsInt() {
+		return fmt.Errorf("invalid balance")
+	}
+	env.Balance = balanceItems[0].Uint64()
+	switch config := values[9]; config.SumType {
+	case "VmStkCell":
+		env.Config = &config.VmStkCell.Value
+	case "VmStkNull":
+	default:
+		return fmt.Errorf("invalid global config")
+	}
+	return nil
+}
+
+// tupleItems returns elements of a tuple.
+func tupleItems(tuple tlb.VmStkTuple) ([]tlb.VmStackValue, error) {
+	if tuple.Len == 1 {
+		return []tlb.VmStackValue{tuple.Data.Tail}, nil
+	}
+	return tuple.Data.RecursiveToSlice(int(tuple.Len))
+}
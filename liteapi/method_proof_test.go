@@ -0,0 +1,231 @@
+package liteapi
+
+import (
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// tupleCell returns a VmStackValue with a tuple of the given VmStackValue cells.
+func tupleCell(t *testing.T, items ...*boc.Cell) *boc.Cell {
+	t.Helper()
+	c := boc.NewCell()
+	if err := c.WriteUint(7, 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := c.WriteUint(uint64(len(items)), 16); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	writeVmTuple(t, c, items)
+	return c
+}
+
+// writeVmTuple writes VmTuple:
+// vm_tuple_tcons$_ {n:#} head:(VmTupleRef n) tail:^VmStackValue = VmTuple (n + 1);
+func writeVmTuple(t *testing.T, c *boc.Cell, items []*boc.Cell) {
+	t.Helper()
+	n := len(items)
+	if n == 0 {
+		return
+	}
+	// vm_tupref_single$_ entry:^VmStackValue = VmTupleRef 1;
+	// vm_tupref_any$_ {n:#} ref:^(VmTuple (n + 2)) = VmTupleRef (n + 2);
+	switch n - 1 {
+	case 0:
+	case 1:
+		if err := c.AddRef(items[0]); err != nil {
+			t.Fatalf("AddRef() failed: %v", err)
+		}
+	default:
+		ref := boc.NewCell()
+		writeVmTuple(t, ref, items[:n-1])
+		if err := c.AddRef(ref); err != nil {
+			t.Fatalf("AddRef() failed: %v", err)
+		}
+	}
+	if err := c.AddRef(items[n-1]); err != nil {
+		t.Fatalf("AddRef() failed: %v", err)
+	}
+}
+
+func tinyIntCell(t *testing.T, v int64) *boc.Cell {
+	return testutil.MustMarshal(t, tlb.VmStackValue{SumType: "VmStkTinyInt", VmStkTinyInt: v})
+}
+
+func testInitC7(t *testing.T, magic int64, config *boc.Cell) []byte {
+	t.Helper()
+	configValue := testutil.MustMarshal(t, tlb.VmStackValue{SumType: "VmStkNull"})
+	if config != nil {
+		configValue = testutil.MustMarshal(t, tlb.VmStackValue{SumType: "VmStkCell", VmStkCell: tlb.Ref[boc.Cell]{Value: *config}})
+	}
+	info := tupleCell(t,
+		tinyIntCell(t, magic),
+		tinyIntCell(t, 0),
+		tinyIntCell(t, 0),
+		tinyIntCell(t, 1700000000),
+		tinyIntCell(t, 100),
+		tinyIntCell(t, 101),
+		tinyIntCell(t, 0x1234),
+		tupleCell(t, tinyIntCell(t, 5_000_000_000), testutil.MustMarshal(t, tlb.VmStackValue{SumType: "VmStkNull"})),
+		testutil.MustMarshal(t, tlb.VmStackValue{SumType: "VmStkNull"}),
+		configValue,
+	)
+	return testutil.MustBoc(t, tupleCell(t, info))
+}
+
+func Test_methodEnv(t *testing.T) {
+	accountID := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x02}}
+	code, data, config := testutil.CellWithUint(t, 1), testutil.CellWithUint(t, 2), testutil.CellWithUint(t, 3)
+
+	var account tlb.Account
+	account.SumType = "Account"
+	account.Account.Addr.SumType = "AddrStd"
+	account.Account.Addr.AddrStd.Address = tlb.Bits256(accountID.Address)
+	account.Account.Storage.Balance.Grams = 5_000_000_000
+	account.Account.Storage.State.SumType = "AccountActive"
+	stateInit := &account.Account.Storage.State.AccountActive.StateInit
+	stateInit.Code = tlb.Maybe[tlb.Ref[boc.Cell]]{Exists: true, Value: tlb.Ref[boc.Cell]{Value: *code}}
+	stateInit.Data = tlb.Maybe[tlb.Ref[boc.Cell]]{Exists: true, Value: tlb.Ref[boc.Cell]{Value: *data}}
+	accountCell := testutil.MustMarshal(t, account)
+	accountHash := testutil.MustHash(t, accountCell)
+
+	poor := account
+	poor.Account.Storage.Balance.Grams = 1
+	poorCell := testutil.MustMarshal(t, poor)
+	poorHash := testutil.MustHash(t, poorCell)
+
+	var uninit tlb.Account
+	uninit.SumType = "Account"
+	uninit.Account.Addr = account.Account.Addr
+	uninit.Account.Storage.State.SumType = "AccountUninit"
+	uninitCell := testutil.MustMarshal(t, uninit)
+	uninitHash := testutil.MustHash(t, uninitCell)
+
+	tests := []struct {
+		name        string
+		accountHash [32]byte
+		stateProof  []byte
+		initC7      []byte
+		wantConfig  bool
+		wantErr     bool
+	}{
+		{
+			name:        "all good",
+			accountHash: accountHash,
+			stateProof:  testutil.MustBoc(t, merkleProof(t, accountCell)),
+			initC7:      testInitC7(t, smartContractInfoMagic, config),
+			wantConfig:  true,
+		},
+		{
+			name:        "no config",
+			accountHash: accountHash,
+			stateProof:  testutil.MustBoc(t, merkleProof(t, accountCell)),
+			initC7:      testInitC7(t, smartContractInfoMagic, nil),
+		},
+		{
+			name:        "proof of another account",
+			accountHash: accountHash,
+			stateProof:  testutil.MustBoc(t, merkleProof(t, uninitCell)),
+			initC7:      testInitC7(t, smartContractInfoMagic, config),
+			wantErr:     true,
+		},
+		{
+			name:        "inactive account",
+			accountHash: uninitHash,
+			stateProof:  testutil.MustBoc(t, merkleProof(t, uninitCell)),
+			initC7:      testInitC7(t, smartContractInfoMagic, config),
+			wantErr:     true,
+		},
+		{
+			name:        "balance mismatch",
+			accountHash: poorHash,
+			stateProof:  testutil.MustBoc(t, merkleProof(t, poorCell)),
+			initC7:      testInitC7(t, smartContractInfoMagic, config),
+			wantErr:     true,
+		},
+		{
+			name:        "invalid c7",
+			accountHash: accountHash,
+			stateProof:  testutil.MustBoc(t, merkleProof(t, accountCell)),
+			initC7:      testInitC7(t, 1, config),
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := methodEnv(accountID, tt.accountHash[:], tt.stateProof, tt.initC7)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("methodEnv() failed: %v", err)
+			}
+			if env.AccountID != accountID {
+				t.Fatalf("want account: %v, got: %v", accountID, env.AccountID)
+			}
+			if testutil.MustHash(t, env.Code) != testutil.MustHash(t, code) || testutil.MustHash(t, env.Data) != testutil.MustHash(t, data) {
+				t.Fatalf("code or data mismatch")
+			}
+			if env.UnixTime != 1700000000 || env.Balance != 5_000_000_000 || env.RandSeed != (ton.Bits256{30: 0x12, 31: 0x34}) {
+				t.Fatalf("unexpected env: %v %v %x", env.UnixTime, env.Balance, env.RandSeed)
+			}
+			if (env.Config != nil) != tt.wantConfig {
+				t.Fatalf("want config: %v, got: %v", tt.wantConfig, env.Config)
+			}
+		})
+	}
+}
+
+func Test_configHash(t *testing.T) {
+	config := testutil.CellWithUint(t, 9)
+	mcState := testutil.MustMarshal(t, testShardState{
+		SeqNo:           100,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 3),
+		Other:           *testutil.CellWithUint(t, 4),
+		Custom:          testutil.MustMarshal(t, testMcStateExtra{Config: *config}),
+	})
+	mcBlock, blockProof := testBlock(t, ton.BlockID{Workchain: -1, Shard: 0x8000000000000000, Seqno: 100}, mcState)
+	anotherBlock, anotherProof := testBlock(t, ton.BlockID{Workchain: -1, Shard: 0x8000000000000000, Seqno: 101}, testutil.CellWithUint(t, 5))
+
+	tests := []struct {
+		name       string
+		blockID    ton.BlockIDExt
+		blockProof *boc.Cell
+		wantErr    bool
+	}{
+		{
+			name:       "all good",
+			blockID:    mcBlock,
+			blockProof: blockProof,
+		},
+		{
+			name:       "state of another block",
+			blockID:    anotherBlock,
+			blockProof: anotherProof,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := configHash(tt.blockID, tt.blockProof, merkleProof(t, mcState))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("configHash() failed: %v", err)
+			}
+			if want := ton.Bits256(testutil.MustHash(t, config)); hash != want {
+				t.Fatalf("want config hash: %x, got: %x", want, hash)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package pool
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/caigou-xyz/tongo/config"
@@ -19,6 +21,8 @@ const (
 
 var (
 	ErrNoConnections = fmt.Errorf("no connections available")
+	// ErrBlockNotAvailable is returned when lite servers of all connections have already removed a requested block.
+	ErrBlockNotAvailable = fmt.Errorf("block is not available on any lite server")
 )
 
 type Strategy string
@@ -26,25 +30,41 @@ type Strategy string
 const (
 	BestPingStrategy       = "best-ping"
 	FirstWorkingConnection = "first-working"
+	QuorumStrategy         = "quorum"
 )
 
 // ConnPool is a pool of connections to lite servers
-// that implements two different strategy:
+// that implements three different strategies:
 //  1. BestPingStrategy - it'll switch to a connection with the best ping.
 //  2. FirstWorkingConnection - it'll switch to the first working connection.
+//  3. QuorumStrategy - it works as BestPingStrategy but sends queries pinned to a block to several connections
+//     and returns QuorumMismatchError if lite servers give different answers. Take a look at SetQuorumPolicy.
 //
-// For both strategies, a connection has to be not more than 1 block behind the head of masterchain to be considered as working.
+// For all strategies, a connection has to be not more than 1 block behind the head of masterchain to be considered as working.
 type ConnPool struct {
 	strategy           Strategy
 	updateBestInterval time.Duration
 
 	masterHeadUpdatedCh chan masterHeadUpdated
 
+	// ctx is canceled when the pool is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	mu         sync.RWMutex
 	conns      []conn
 	bestConn   conn
 	waitListID uint64
 	waitList   map[uint64]chan ton.BlockIDExt
+
+	// nextConnID is an ID of a next connection opened by the pool.
+	nextConnID int
+
+	// settings are parameters of connections, set by InitializeConnections.
+	settings     atomic.Pointer[connSettings]
+	eventHandler atomic.Pointer[EventHandler]
+	retryPolicy  atomic.Pointer[RetryPolicy]
+	quorumPolicy atomic.Pointer[QuorumPolicy]
 }
 
 // conn contains all methods needed by a pool.
@@ -57,59 +77,77 @@ type conn interface {
 	Client() *liteclient.Client
 	Run(ctx context.Context, detectArchive bool)
 	IsArchiveNode() bool
+	AvailableRange() BlockRange
 	AverageRoundTrip() time.Duration
+	Server() config.LiteServer
+	Drain(ctx context.Context, timeout time.Duration) error
 	Status() ConnStatus
+	Close(ctx context.Context) error
 }
 
 // New returns a new instance of a connections pool.
 func New(strategy Strategy) *ConnPool {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &ConnPool{
 		strategy:            strategy,
 		updateBestInterval:  updateBestConnectionInterval,
 		waitList:            map[uint64]chan ton.BlockIDExt{},
 		masterHeadUpdatedCh: make(chan masterHeadUpdated, 10),
+		ctx:                 ctx,
+		cancel:              cancel,
 	}
 }
 
-func (p *ConnPool) InitializeConnections(ctx context.Context, timeout time.Duration, maxConnections int, workersPerConnection int, detectArchiveNodes bool, servers []config.LiteServer) chan error {
-	ch := make(chan error, 1)
-	go func() {
-		clientsCh := make(chan clientWrapper, len(servers))
-		for connID, server := range servers {
-			go func(connID int, server config.LiteServer) {
-				cli, _ := connect(ctx, timeout, server, workersPerConnection)
-				// TODO: log error
-				clientsCh <- clientWrapper{
-					connID:     connID,
-					cli:        cli,
-					serverHost: server.Host,
-				}
-			}(connID, server)
-		}
+// Close stops all background goroutines of the pool, closes its connections
+// and waits for goroutines of the connections to exit until ctx is done.
+// Requests to a closed pool fail with liteclient.ErrClosed.
+func (p *ConnPool) Close(ctx context.Context) error {
+	p.cancel()
 
-		processedConnections := 0
-		for {
-			if processedConnections == len(servers) {
-				break
-			}
-			select {
-			case <-ctx.Done():
-				break
-			case wrapper := <-clientsCh:
-				processedConnections += 1
-				if wrapper.cli == nil {
-					continue
-				}
-				if p.ConnectionsNumber() < maxConnections {
-					c := p.addConnection(wrapper.connID, wrapper.cli, wrapper.serverHost)
-					go c.Run(context.TODO(), detectArchiveNodes)
-				}
-				if p.ConnectionsNumber() == maxConnections {
-					processedConnections = len(servers)
-					break
-				}
-			}
+	p.mu.RLock()
+	conns := p.conns
+	p.mu.RUnlock()
+	var err error
+	for _, c := range conns {
+		// every connection is closed even if ctx is already done.
+		if closeErr := c.Close(ctx); closeErr != nil && err == nil {
+			err = closeErr
 		}
+	}
+	return err
+}
+
+// discardClient closes a client that is not needed anymore without waiting for its goroutines to exit.
+func discardClient(cli *liteclient.Client) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cli.Close(ctx)
+}
+
+// connSettings are parameters of connections opened by a pool, see InitializeConnections.
+type connSettings struct {
+	timeout              time.Duration
+	maxConnections       int
+	workersPerConnection int
+	detectArchiveNodes   bool
+	clientOptions        []liteclient.Options
+}
+
+// InitializeConnections connects to the given lite servers in background
+// and reports the result of the initialization to the returned channel.
+// clientOptions are applied to every liteclient.Client created by the pool.
+func (p *ConnPool) InitializeConnections(ctx context.Context, timeout time.Duration, maxConnections int, workersPerConnection int, detectArchiveNodes bool, servers []config.LiteServer, clientOptions ...liteclient.Options) chan error {
+	settings := &connSettings{
+		timeout:              timeout,
+		maxConnections:       maxConnections,
+		workersPerConnection: workersPerConnection,
+		detectArchiveNodes:   detectArchiveNodes,
+		clientOptions:        clientOptions,
+	}
+	p.settings.Store(settings)
+	ch := make(chan error, 1)
+	go func() {
+		p.connectServers(ctx, settings, servers, maxConnections)
 		if p.ConnectionsNumber() == 0 {
 			ch <- fmt.Errorf("all liteservers are unavailable")
 			return
@@ -119,7 +157,78 @@ func (p *ConnPool) InitializeConnections(ctx context.Context, timeout time.Durat
 	return ch
 }
 
-func connect(ctx context.Context, timeout time.Duration, server config.LiteServer, n int) (*liteclient.Client, error) {
+// connectServers connects to the given lite servers concurrently
+// and adds at most limit connections to the pool.
+// It returns the number of added connections.
+func (p *ConnPool) connectServers(ctx context.Context, settings *connSettings, servers []config.LiteServer, limit int) int {
+	firstConnID := p.reserveConnIDs(len(servers))
+	clientsCh := make(chan clientWrapper, len(servers))
+	for i, server := range servers {
+		go func(connID int, server config.LiteServer) {
+			inFlight := new(atomic.Int64)
+			opts := append([]liteclient.Options{
+				liteclient.OptionConnID(connID),
+				liteclient.OptionRequestInterceptor(p.interceptor(connID, inFlight)),
+			}, settings.clientOptions...)
+			cli, _ := connect(ctx, settings.timeout, server, settings.workersPerConnection, opts...)
+			// TODO: log error
+			clientsCh <- clientWrapper{
+				connID:   connID,
+				cli:      cli,
+				server:   server,
+				inFlight: inFlight,
+			}
+		}(firstConnID+i, server)
+	}
+
+	added := 0
+	processedConnections := 0
+loop:
+	for processedConnections < len(servers) {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-p.ctx.Done():
+			break loop
+		case wrapper := <-clientsCh:
+			processedConnections += 1
+			if wrapper.cli == nil {
+				continue
+			}
+			if added >= limit {
+				discardClient(wrapper.cli)
+				continue
+			}
+			if c := p.addConnection(wrapper, settings.detectArchiveNodes); c == nil {
+				continue
+			}
+			added += 1
+			if added == limit {
+				break loop
+			}
+		}
+	}
+	// the remaining clients are not needed anymore.
+	go func(remaining int) {
+		for i := 0; i < remaining; i++ {
+			if wrapper := <-clientsCh; wrapper.cli != nil {
+				discardClient(wrapper.cli)
+			}
+		}
+	}(len(servers) - processedConnections)
+	return added
+}
+
+// reserveConnIDs returns the first of n consecutive IDs for new connections.
+func (p *ConnPool) reserveConnIDs(n int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	first := p.nextConnID
+	p.nextConnID += n
+	return first
+}
+
+func connect(ctx context.Context, timeout time.Duration, server config.LiteServer, n int, clientOptions ...liteclient.Options) (*liteclient.Client, error) {
 	serverPubkey, err := base64.StdEncoding.DecodeString(server.Key)
 	if err != nil {
 		return nil, err
@@ -128,36 +237,83 @@ func connect(ctx context.Context, timeout time.Duration, server config.LiteServe
 	if err != nil {
 		return nil, err
 	}
-	cli := liteclient.NewClient(c, liteclient.OptionTimeout(timeout), liteclient.OptionWorkersPerConnection(n))
-	if _, err := cli.LiteServerGetMasterchainInfo(ctx); err != nil {
+	opts := append([]liteclient.Options{liteclient.OptionTimeout(timeout), liteclient.OptionWorkersPerConnection(n)}, clientOptions...)
+	cli := liteclient.NewClient(c, opts...)
+	if _, err := cli.LiteServerGetMasterchainInfo(liteclient.WithBackground(withoutRetries(ctx))); err != nil {
+		discardClient(cli)
 		return nil, err
 	}
 	return cli, nil
 }
 
+// AddClient adds an already created client to the pool and starts tracking its masterchain head.
+// It is useful for clients that don't need a connection to a lite server,
+// like the ones created by liteclient.NewReplayClient.
+func (p *ConnPool) AddClient(connID int, cli *liteclient.Client, serverHost string, detectArchiveNodes bool) error {
+	wrapper := clientWrapper{
+		connID:   connID,
+		cli:      cli,
+		server:   config.LiteServer{Host: serverHost},
+		inFlight: new(atomic.Int64),
+	}
+	if c := p.addConnection(wrapper, detectArchiveNodes); c == nil {
+		return liteclient.ErrClosed
+	}
+	return nil
+}
+
 type clientWrapper struct {
-	connID     int
-	cli        *liteclient.Client
-	serverHost string
+	connID int
+	cli    *liteclient.Client
+	server config.LiteServer
+	// inFlight counts requests the client is processing.
+	inFlight *atomic.Int64
 }
 
-func (p *ConnPool) addConnection(connID int, cli *liteclient.Client, serverHost string) *connection {
+// addConnection adds a new connection to the pool and starts tracking its masterchain head.
+// If the pool is closed, it closes the given client and returns nil.
+func (p *ConnPool) addConnection(wrapper clientWrapper, detectArchiveNodes bool) *connection {
+	c, best := p.appendConnection(wrapper, detectArchiveNodes)
+	if c == nil {
+		return nil
+	}
+	p.emit(ConnectionAddedEvent, c, c.MasterHead())
+	if best {
+		p.emit(BestConnectionChangedEvent, c, c.MasterHead())
+	}
+	return c
+}
+
+func (p *ConnPool) appendConnection(wrapper clientWrapper, detectArchiveNodes bool) (*connection, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if p.ctx.Err() != nil {
+		discardClient(wrapper.cli)
+		return nil, false
+	}
+	// the connection is stopped either when the pool is closed or when the connection is removed from the pool.
+	ctx, stop := context.WithCancel(p.ctx)
 	c := &connection{
-		id:                  connID,
-		serverHost:          serverHost,
-		client:              cli,
+		id:                  wrapper.connID,
+		serverHost:          wrapper.server.Host,
+		serverKey:           wrapper.server.Key,
+		client:              wrapper.cli,
+		inFlight:            wrapper.inFlight,
+		stop:                stop,
 		masterHeadUpdatedCh: p.masterHeadUpdatedCh,
+		done:                p.ctx.Done(),
+		emit:                p.emit,
 	}
+	go c.Run(ctx, detectArchiveNodes)
 	p.conns = append(p.conns, c)
 	sort.Slice(p.conns, func(i, j int) bool {
 		return p.conns[i].ID() < p.conns[j].ID()
 	})
 	if len(p.conns) == 1 {
 		p.bestConn = c
+		return c, true
 	}
-	return c
+	return c, false
 }
 
 func (p *ConnPool) Run(ctx context.Context) {
@@ -168,22 +324,33 @@ func (p *ConnPool) Run(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
+		case <-p.ctx.Done():
+			return
 		case <-tickTock.C:
 			p.updateBest()
 		case update := <-p.masterHeadUpdatedCh:
 			p.notifySubscribers(update)
+			p.emit(MasterHeadUpdatedEvent, update.Conn, update.Head)
 		}
 	}
 }
 
 // updateBest finds the best suitable connection to work with and switches to it.
 func (p *ConnPool) updateBest() {
+	if c, changed := p.switchBest(); changed {
+		p.emit(BestConnectionChangedEvent, c, c.MasterHead())
+	}
+}
+
+// switchBest switches to the best suitable connection and reports if the best connection has changed.
+func (p *ConnPool) switchBest() (conn, bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	if len(p.conns) == 0 {
-		return
+		return nil, false
 	}
+	prev := p.bestConn
 
 	var maxSeqno uint32
 	for _, c := range p.conns {
@@ -194,7 +361,7 @@ func (p *ConnPool) updateBest() {
 	}
 
 	switch p.strategy {
-	case BestPingStrategy:
+	case BestPingStrategy, QuorumStrategy:
 		if bestConn := p.findBestPingConnection(maxSeqno); bestConn != nil {
 			p.bestConn = bestConn
 		}
@@ -203,6 +370,7 @@ func (p *ConnPool) updateBest() {
 			p.bestConn = bestConn
 		}
 	}
+	return p.bestConn, p.bestConn != prev
 }
 
 func (p *ConnPool) findFirstWorkingConnection(maxSeqno uint32) conn {
@@ -269,6 +437,9 @@ func (p *ConnPool) BestMasterchainInfoClient() *MasterchainInfoClient {
 
 // BestMasterchainClient returns a liteclient and its known masterchain head.
 func (p *ConnPool) BestMasterchainClient(ctx context.Context) (*liteclient.Client, ton.BlockIDExt, error) {
+	if p.ctx.Err() != nil {
+		return nil, ton.BlockIDExt{}, liteclient.ErrClosed
+	}
 	bestConnection := p.bestConnection()
 	if bestConnection == nil {
 		return nil, ton.BlockIDExt{}, ErrNoConnections
@@ -285,11 +456,15 @@ func (p *ConnPool) BestMasterchainClient(ctx context.Context) (*liteclient.Clien
 	select {
 	case <-ctx.Done():
 		return nil, ton.BlockIDExt{}, ctx.Err()
+	case <-p.ctx.Done():
+		return nil, ton.BlockIDExt{}, liteclient.ErrClosed
 	case head := <-ch:
 		return bestConnection.Client(), head, nil
 	}
 }
 func (p *ConnPool) BestArchiveClient(ctx context.Context) (*liteclient.Client, ton.BlockIDExt, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	for _, c := range p.conns {
 		if c.IsOK() && c.IsArchiveNode() {
 			return c.Client(), c.MasterHead(), nil
@@ -306,12 +481,126 @@ func (p *ConnPool) BestClientByAccountID(ctx context.Context, accountID ton.Acco
 	return p.BestMasterchainClient(ctx)
 }
 
-// BestClientByBlockID returns a liteclient and its known masterchain head.
+// BestClientByBlockID returns a liteclient whose lite server keeps the given block.
+// Masterchain blocks are routed by their seqno.
+// Seqno of a shard block tells nothing about its age,
+// so queries about shard blocks go to a connection keeping all blocks:
+// the best connection if it does, otherwise an archive node or a connection with an unknown range.
+// If there is no such connection, the best connection is used,
+// because recent shard blocks are kept by every lite server.
 func (p *ConnPool) BestClientByBlockID(ctx context.Context, blockID ton.BlockID) (*liteclient.Client, error) {
-	server, _, err := p.BestMasterchainClient(ctx)
+	if blockID.Workchain != -1 {
+		server, _, err := p.bestClientCovering(ctx, func(r BlockRange) bool {
+			return r.CoversSeqno(archiveNodeMinSeqno)
+		})
+		if errors.Is(err, ErrBlockNotAvailable) {
+			server, _, err = p.BestMasterchainClient(ctx)
+		}
+		return server, err
+	}
+	server, _, err := p.BestClientByMasterchainSeqno(ctx, blockID.Seqno)
 	return server, err
 }
 
+// BestClientByMasterchainSeqno returns a liteclient whose lite server keeps the masterchain block with the given seqno
+// and the client's known masterchain head.
+func (p *ConnPool) BestClientByMasterchainSeqno(ctx context.Context, seqno uint32) (*liteclient.Client, ton.BlockIDExt, error) {
+	return p.bestClientCovering(ctx, func(r BlockRange) bool {
+		return r.CoversSeqno(seqno)
+	})
+}
+
+// BestClientByLt returns a liteclient whose lite server keeps blocks with the given logical time
+// and the client's known masterchain head.
+// Logical time of shard blocks is close to logical time of masterchain blocks,
+// so the choice is approximate for shard blocks.
+func (p *ConnPool) BestClientByLt(ctx context.Context, lt uint64) (*liteclient.Client, ton.BlockIDExt, error) {
+	return p.bestClientCovering(ctx, func(r BlockRange) bool {
+		return r.CoversLt(lt)
+	})
+}
+
+// BestClientByUtime returns a liteclient whose lite server keeps blocks generated at the given time
+// and the client's known masterchain head.
+func (p *ConnPool) BestClientByUtime(ctx context.Context, utime uint32) (*liteclient.Client, ton.BlockIDExt, error) {
+	return p.bestClientCovering(ctx, func(r BlockRange) bool {
+		return r.CoversUtime(utime)
+	})
+}
+
+// bestClientCovering returns the best connection if its lite server keeps requested blocks.
+// Otherwise, it returns a working connection with the lowest round trip among the ones keeping requested blocks.
+// A connection with an unknown range is supposed to keep all blocks.
+func (p *ConnPool) bestClientCovering(ctx context.Context, covers func(r BlockRange) bool) (*liteclient.Client, ton.BlockIDExt, error) {
+	client, head, err := p.BestMasterchainClient(ctx)
+	if err != nil {
+		return nil, ton.BlockIDExt{}, err
+	}
+	c, err := p.connCovering(covers)
+	if err != nil {
+		return nil, ton.BlockIDExt{}, err
+	}
+	if c == nil {
+		return client, head, nil
+	}
+	return c.Client(), c.MasterHead(), nil
+}
+
+// connCovering returns a connection keeping requested blocks or nil if the best connection keeps them.
+// The best connection can be gone since the caller got it, then all connections are scanned.
+func (p *ConnPool) connCovering(covers func(r BlockRange) bool) (conn, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.bestConn != nil {
+		if r := p.bestConn.AvailableRange(); !r.Known() || covers(r) {
+			return nil, nil
+		}
+	}
+	var found, unknown conn
+	for _, c := range p.conns {
+		if !c.IsOK() {
+			continue
+		}
+		r := c.AvailableRange()
+		if !r.Known() {
+			if unknown == nil {
+				unknown = c
+			}
+			continue
+		}
+		if !covers(r) {
+			continue
+		}
+		if found == nil || c.AverageRoundTrip() < found.AverageRoundTrip() {
+			found = c
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+	if unknown != nil {
+		return unknown, nil
+	}
+	return nil, ErrBlockNotAvailable
+}
+
+// WorkingClients returns liteclients of all working connections, the best connection goes first.
+// It is useful to send the same query to several lite servers, for example, to broadcast a message.
+func (p *ConnPool) WorkingClients() []*liteclient.Client {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var clients []*liteclient.Client
+	if p.bestConn != nil && p.bestConn.IsOK() {
+		clients = append(clients, p.bestConn.Client())
+	}
+	for _, c := range p.conns {
+		if c != p.bestConn && c.IsOK() {
+			clients = append(clients, c.Client())
+		}
+	}
+	return clients
+}
+
 // ConnectionsNumber returns a number of connections in this pool.
 func (p *ConnPool) ConnectionsNumber() int {
 	p.mu.RLock()
@@ -364,6 +653,8 @@ func (p *ConnPool) WaitMasterchainSeqno(ctx context.Context, seqno uint32, timeo
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-p.ctx.Done():
+			return liteclient.ErrClosed
 		case <-time.After(timeout):
 			return fmt.Errorf("timeout")
 		case head := <-ch:
@@ -2,18 +2,23 @@ package pool
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/caigou-xyz/tongo/config"
 	"github.com/caigou-xyz/tongo/liteclient"
 	"github.com/caigou-xyz/tongo/ton"
 )
 
 type mockConn struct {
-	id           int
-	seqno        uint32
-	isOK         bool
-	avgRoundTrip time.Duration
+	id             int
+	seqno          uint32
+	isOK           bool
+	avgRoundTrip   time.Duration
+	availableRange BlockRange
+	client         *liteclient.Client
 }
 
 func (m *mockConn) AverageRoundTrip() time.Duration {
@@ -24,8 +29,12 @@ func (m *mockConn) IsArchiveNode() bool {
 	return false
 }
 
+func (m *mockConn) AvailableRange() BlockRange {
+	return m.availableRange
+}
+
 func (m *mockConn) ID() int {
-	return 0
+	return m.id
 }
 
 func (m *mockConn) MasterHead() ton.BlockIDExt {
@@ -44,16 +53,28 @@ func (m *mockConn) IsOK() bool {
 }
 
 func (m *mockConn) Client() *liteclient.Client {
-	panic("implement me")
+	return m.client
 }
 
 func (m *mockConn) Status() ConnStatus {
-	panic("implement me")
+	return ConnStatus{Connected: m.isOK}
 }
 
 func (m *mockConn) Run(ctx context.Context, detectArchiveNodes bool) {
 }
 
+func (m *mockConn) Server() config.LiteServer {
+	return config.LiteServer{Host: fmt.Sprintf("127.0.0.1:%v", m.id)}
+}
+
+func (m *mockConn) Drain(ctx context.Context, timeout time.Duration) error {
+	return nil
+}
+
+func (m *mockConn) Close(ctx context.Context) error {
+	return nil
+}
+
 var _ conn = &mockConn{}
 
 func TestConnPool_updateBest(t *testing.T) {
@@ -146,11 +167,10 @@ func TestConnPool_updateBest(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := &ConnPool{
-				conns:              tt.conns,
-				updateBestInterval: time.Second,
-				strategy:           tt.strategy,
-			}
+			p := New(tt.strategy)
+			defer p.Close(context.Background())
+			p.conns = tt.conns
+			p.updateBestInterval = time.Second
 			ctx := context.Background()
 			go p.Run(ctx)
 			p.updateBest()
@@ -161,3 +181,217 @@ func TestConnPool_updateBest(t *testing.T) {
 		})
 	}
 }
+
+func TestConnPool_SetEventHandler(t *testing.T) {
+	p := New(BestPingStrategy)
+	defer p.Close(context.Background())
+	var events []Event
+	p.SetEventHandler(func(e Event) {
+		events = append(events, e)
+	})
+	p.conns = []conn{
+		&mockConn{seqno: 100, isOK: false, id: 0, avgRoundTrip: 10 * time.Millisecond},
+		&mockConn{seqno: 100, isOK: true, id: 1, avgRoundTrip: 20 * time.Millisecond},
+	}
+	p.bestConn = p.conns[0]
+
+	p.updateBest()
+	p.updateBest()
+	if len(events) != 1 {
+		t.Fatalf("want 1 event, got: %v", len(events))
+	}
+	if events[0].Type != BestConnectionChangedEvent || events[0].ConnID != 1 || events[0].MasterHead.Seqno != 100 {
+		t.Fatalf("unexpected event: %+v", events[0])
+	}
+}
+
+func TestConnPool_BestClientByMasterchainSeqno(t *testing.T) {
+	archive := BlockRange{MinSeqno: 2, MinLt: 1, MinUtime: 1}
+	recent := BlockRange{MinSeqno: 90, MinLt: 9000, MinUtime: 1700000000}
+	tests := []struct {
+		name    string
+		conns   []*mockConn
+		seqno   uint32
+		wantID  int
+		wantErr error
+	}{
+		{
+			name: "best connection keeps the block",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: true, availableRange: archive},
+			},
+			seqno:  95,
+			wantID: 0,
+		},
+		{
+			name: "range of best connection is unknown",
+			conns: []*mockConn{
+				{id: 0, isOK: true},
+				{id: 1, isOK: true, availableRange: archive},
+			},
+			seqno:  10,
+			wantID: 0,
+		},
+		{
+			name: "connection with the lowest round trip among the ones keeping the block",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: false, availableRange: archive, avgRoundTrip: time.Millisecond},
+				{id: 2, isOK: true, availableRange: archive, avgRoundTrip: 30 * time.Millisecond},
+				{id: 3, isOK: true, availableRange: archive, avgRoundTrip: 20 * time.Millisecond},
+				{id: 4, isOK: true, availableRange: recent, avgRoundTrip: 10 * time.Millisecond},
+			},
+			seqno:  10,
+			wantID: 3,
+		},
+		{
+			name: "connection with unknown range",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: true},
+			},
+			seqno:  10,
+			wantID: 1,
+		},
+		{
+			name: "no connection keeps the block",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: true, availableRange: recent},
+			},
+			seqno:   10,
+			wantErr: ErrBlockNotAvailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(BestPingStrategy)
+			defer p.Close(context.Background())
+			for _, c := range tt.conns {
+				c.seqno = 100
+				c.client = &liteclient.Client{}
+				p.conns = append(p.conns, c)
+			}
+			p.bestConn = p.conns[0]
+
+			client, head, err := p.BestClientByMasterchainSeqno(context.Background(), tt.seqno)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want error: %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BestClientByMasterchainSeqno() failed: %v", err)
+			}
+			if client != tt.conns[tt.wantID].client {
+				t.Fatalf("want connection: %v", tt.wantID)
+			}
+			if head.Seqno != 100 {
+				t.Fatalf("want head: 100, got: %v", head.Seqno)
+			}
+		})
+	}
+}
+
+func TestConnPool_BestClientByBlockID_shard(t *testing.T) {
+	archive := BlockRange{MinSeqno: 2, MinLt: 1, MinUtime: 1}
+	recent := BlockRange{MinSeqno: 90, MinLt: 9000, MinUtime: 1700000000}
+	tests := []struct {
+		name   string
+		conns  []*mockConn
+		wantID int
+	}{
+		{
+			name: "best connection keeps all blocks",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: archive},
+				{id: 1, isOK: true, availableRange: archive},
+			},
+			wantID: 0,
+		},
+		{
+			name: "archive node",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: true, availableRange: recent},
+				{id: 2, isOK: true, availableRange: archive},
+			},
+			wantID: 2,
+		},
+		{
+			name: "connection with unknown range",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: true},
+			},
+			wantID: 1,
+		},
+		{
+			name: "no connection keeps all blocks",
+			conns: []*mockConn{
+				{id: 0, isOK: true, availableRange: recent},
+				{id: 1, isOK: true, availableRange: recent},
+			},
+			wantID: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(BestPingStrategy)
+			defer p.Close(context.Background())
+			for _, c := range tt.conns {
+				c.seqno = 100
+				c.client = &liteclient.Client{}
+				p.conns = append(p.conns, c)
+			}
+			p.bestConn = p.conns[0]
+
+			client, err := p.BestClientByBlockID(context.Background(), ton.BlockID{Workchain: 0, Shard: 0x8000000000000000, Seqno: 10})
+			if err != nil {
+				t.Fatalf("BestClientByBlockID() failed: %v", err)
+			}
+			if client != tt.conns[tt.wantID].client {
+				t.Fatalf("want connection: %v", tt.wantID)
+			}
+		})
+	}
+}
+
+func TestConnPool_connCovering(t *testing.T) {
+	archive := BlockRange{MinSeqno: 2, MinLt: 1, MinUtime: 1}
+	p := New(BestPingStrategy)
+	defer p.Close(context.Background())
+	p.conns = []conn{
+		&mockConn{id: 0, isOK: true, availableRange: BlockRange{MinSeqno: 90, MinLt: 9000, MinUtime: 1700000000}},
+		&mockConn{id: 1, isOK: true, availableRange: archive},
+	}
+	// the pool has no best connection yet.
+	c, err := p.connCovering(func(r BlockRange) bool { return r.CoversSeqno(10) })
+	if err != nil {
+		t.Fatalf("connCovering() failed: %v", err)
+	}
+	if c == nil || c.ID() != 1 {
+		t.Fatalf("want connection 1, got: %v", c)
+	}
+	if _, err := p.connCovering(func(r BlockRange) bool { return r.CoversSeqno(1) }); !errors.Is(err, ErrBlockNotAvailable) {
+		t.Fatalf("want ErrBlockNotAvailable, got: %v", err)
+	}
+}
+
+func TestBlockRange(t *testing.T) {
+	r := BlockRange{MinSeqno: 90, MinLt: 9000, MinUtime: 1700000000}
+	if !r.Known() || (BlockRange{}).Known() {
+		t.Fatalf("Known() is wrong")
+	}
+	if !r.CoversSeqno(90) || r.CoversSeqno(89) {
+		t.Fatalf("CoversSeqno() is wrong")
+	}
+	if !r.CoversLt(9000) || r.CoversLt(8999) {
+		t.Fatalf("CoversLt() is wrong")
+	}
+	if !r.CoversUtime(1700000000) || r.CoversUtime(1699999999) {
+		t.Fatalf("CoversUtime() is wrong")
+	}
+}
@@ -2,25 +2,83 @@ package pool
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/config"
 	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
 	"github.com/caigou-xyz/tongo/ton"
 )
 
+const (
+	// availableRangeRefreshInterval specifies how often a connection checks
+	// which blocks its lite server still keeps.
+	availableRangeRefreshInterval = time.Hour
+	// availableRangeRetryInterval is a pause before checking available blocks again after a failure.
+	availableRangeRetryInterval = time.Minute
+	// archiveNodeMinSeqno is the earliest masterchain block FindMinAvailableMasterchainSeqno can report.
+	// Lite servers keeping it are considered archive nodes.
+	archiveNodeMinSeqno = 2
+	// drainCheckInterval specifies how often a removed connection checks if its requests are done.
+	drainCheckInterval = 100 * time.Millisecond
+)
+
+// BlockRange describes the earliest masterchain block a lite server keeps.
+// A lite server is expected to keep all blocks following it.
+// The zero BlockRange means that the range is unknown.
+type BlockRange struct {
+	MinSeqno uint32
+	// MinLt and MinUtime are the start logical time and the generation time of the earliest block.
+	MinLt    uint64
+	MinUtime uint32
+}
+
+// Known returns true if the range has been detected.
+func (r BlockRange) Known() bool {
+	return r.MinSeqno > 0
+}
+
+// CoversSeqno reports whether a masterchain block with the given seqno is available.
+func (r BlockRange) CoversSeqno(seqno uint32) bool {
+	return r.MinSeqno <= seqno
+}
+
+// CoversLt reports whether blocks with the given logical time are available.
+func (r BlockRange) CoversLt(lt uint64) bool {
+	return r.MinLt <= lt
+}
+
+// CoversUtime reports whether blocks generated at the given time are available.
+func (r BlockRange) CoversUtime(utime uint32) bool {
+	return r.MinUtime <= utime
+}
+
 type connection struct {
 	id         int
 	serverHost string
+	serverKey  string
 	client     *liteclient.Client
+	// inFlight, if set, counts requests the client is processing.
+	inFlight *atomic.Int64
+	// stop, if set, stops background goroutines of the connection.
+	stop context.CancelFunc
 
 	// masterHeadUpdatedCh is used to send a notification when a known master head is changed.
 	masterHeadUpdatedCh chan masterHeadUpdated
+	// done is closed when the pool is closed and nobody reads masterHeadUpdatedCh anymore.
+	done <-chan struct{}
+	// emit, if set, reports changes of the connection to the pool.
+	emit func(EventType, conn, ton.BlockIDExt)
 
 	mu sync.RWMutex
 	// masterHead is the latest known masterchain head.
 	masterHead ton.BlockIDExt
-	isArchive  bool
+	// availableRange describes blocks the lite server keeps.
+	availableRange BlockRange
 }
 
 type masterHeadUpdated struct {
@@ -29,19 +87,11 @@ type masterHeadUpdated struct {
 }
 
 func (c *connection) Run(ctx context.Context, detectArchive bool) {
+	// the connection tracks the state of its own lite server,
+	// this traffic is not a part of a recorded session.
+	ctx = liteclient.WithBackground(withoutRetries(ctx))
 	if detectArchive {
-		go func() {
-			ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-			defer cancel()
-			// TODO: retry several times on error
-			seqno, err := c.FindMinAvailableMasterchainSeqno(ctx)
-			if err != nil {
-				return
-			}
-			if seqno == 2 {
-				c.setArchive(true)
-			}
-		}()
+		go c.trackAvailableRange(ctx)
 	}
 	for {
 		var head ton.BlockIDExt
@@ -49,7 +99,9 @@ func (c *connection) Run(ctx context.Context, detectArchive bool) {
 			res, err := c.client.LiteServerGetMasterchainInfo(ctx)
 			if err != nil {
 				// TODO: log error
-				time.Sleep(1000 * time.Millisecond)
+				if !sleep(ctx, 1000*time.Millisecond) {
+					return
+				}
 				continue
 			}
 			head = res.Last.ToBlockIdExt()
@@ -60,7 +112,9 @@ func (c *connection) Run(ctx context.Context, detectArchive bool) {
 			res, err := c.client.WaitMasterchainBlock(ctx, head.Seqno+1, 15_000)
 			if err != nil {
 				// TODO: log error
-				time.Sleep(1000 * time.Millisecond)
+				if !sleep(ctx, 1000*time.Millisecond) {
+					return
+				}
 				// we want to request seqno again with LiteServerGetMasterchainInfo
 				// to avoid situation when this server has been offline for too long,
 				// and it doesn't contain a block with the latest known seqno anymore.
@@ -75,6 +129,44 @@ func (c *connection) Run(ctx context.Context, detectArchive bool) {
 	}
 }
 
+// trackAvailableRange periodically detects blocks the lite server keeps,
+// because lite servers remove old blocks as time goes.
+func (c *connection) trackAvailableRange(ctx context.Context) {
+	for {
+		findCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+		r, err := c.FindAvailableRange(findCtx)
+		cancel()
+		if err != nil {
+			// TODO: log error
+			if !sleep(ctx, availableRangeRetryInterval) {
+				return
+			}
+			continue
+		}
+		wasArchive := c.IsArchiveNode()
+		if c.setAvailableRange(r) && c.emit != nil {
+			c.emit(AvailableRangeUpdatedEvent, c, c.MasterHead())
+			if !wasArchive && c.IsArchiveNode() {
+				c.emit(ArchiveDetectedEvent, c, c.MasterHead())
+			}
+		}
+		if !sleep(ctx, availableRangeRefreshInterval) {
+			return
+		}
+	}
+}
+
+// sleep pauses the current goroutine for the given duration.
+// It returns false if the context is done before the duration elapses.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
 // IsOK returns true if there is no problems with the underlying liteclient and its connection to a lite server.
 func (c *connection) IsOK() bool {
 	return c.client.IsOK()
@@ -99,11 +191,39 @@ func (c *connection) SetMasterHead(head ton.BlockIDExt) {
 	defer c.mu.Unlock()
 	if head.Seqno > c.masterHead.Seqno {
 		c.masterHead = head
-		c.masterHeadUpdatedCh <- masterHeadUpdated{
-			Head: head,
-			Conn: c,
+		select {
+		case c.masterHeadUpdatedCh <- masterHeadUpdated{Head: head, Conn: c}:
+		case <-c.done:
+		}
+	}
+}
+
+// Server returns the lite server of the connection.
+func (c *connection) Server() config.LiteServer {
+	return config.LiteServer{Host: c.serverHost, Key: c.serverKey}
+}
+
+// Drain stops tracking the lite server and closes the connection once its in-flight requests are done,
+// but waits no longer than the given timeout.
+func (c *connection) Drain(ctx context.Context, timeout time.Duration) error {
+	if c.stop != nil {
+		c.stop()
+	}
+	deadline := time.Now().Add(timeout)
+	for c.inFlight != nil && c.inFlight.Load() > 0 && time.Now().Before(deadline) {
+		if !sleep(ctx, drainCheckInterval) {
+			break
 		}
 	}
+	return c.Close(ctx)
+}
+
+// Close stops tracking the lite server and closes the underlying liteclient.
+func (c *connection) Close(ctx context.Context) error {
+	if c.stop != nil {
+		c.stop()
+	}
+	return c.client.Close(ctx)
 }
 
 func (c *connection) FindMinAvailableMasterchainSeqno(ctx context.Context) (uint32, error) {
@@ -140,16 +260,63 @@ func (c *connection) FindMinAvailableMasterchainSeqno(ctx context.Context) (uint
 	return min, nil
 }
 
+// FindAvailableRange finds the earliest masterchain block the lite server keeps.
+func (c *connection) FindAvailableRange(ctx context.Context) (BlockRange, error) {
+	seqno, err := c.FindMinAvailableMasterchainSeqno(ctx)
+	if err != nil {
+		return BlockRange{}, err
+	}
+	workchain := -1
+	res, err := c.client.LiteServerLookupBlock(ctx, liteclient.LiteServerLookupBlockRequest{
+		Mode: 1,
+		Id: liteclient.TonNodeBlockIdC{
+			Workchain: uint32(workchain),
+			Shard:     0x8000000000000000,
+			Seqno:     seqno,
+		},
+	})
+	if err != nil {
+		return BlockRange{}, err
+	}
+	cells, err := boc.DeserializeBoc(res.HeaderProof)
+	if err != nil {
+		return BlockRange{}, err
+	}
+	if len(cells) != 1 {
+		return BlockRange{}, boc.ErrNotSingleRoot
+	}
+	var proof struct {
+		Proof tlb.MerkleProof[tlb.BlockHeader]
+	}
+	if err := tlb.Unmarshal(cells[0], &proof); err != nil {
+		return BlockRange{}, fmt.Errorf("failed to decode header of block %v: %w", seqno, err)
+	}
+	info := proof.Proof.VirtualRoot.Info
+	return BlockRange{MinSeqno: seqno, MinLt: info.StartLt, MinUtime: info.GenUtime}, nil
+}
+
 func (c *connection) IsArchiveNode() bool {
+	r := c.AvailableRange()
+	return r.Known() && r.MinSeqno <= archiveNodeMinSeqno
+}
+
+// AvailableRange returns blocks the lite server keeps.
+// It is unknown unless the connection detects archive nodes.
+func (c *connection) AvailableRange() BlockRange {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.isArchive
+	return c.availableRange
 }
 
-func (c *connection) setArchive(archive bool) {
+// setAvailableRange updates the range and reports if it has changed.
+func (c *connection) setAvailableRange(r BlockRange) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.isArchive = archive
+	if c.availableRange == r {
+		return false
+	}
+	c.availableRange = r
+	return true
 }
 
 func (c *connection) AverageRoundTrip() time.Duration {
@@ -160,12 +327,15 @@ type ConnStatus struct {
 	ServerHost string
 	Connected  bool
 	Archive    bool
+	// AvailableRange describes blocks the lite server keeps.
+	AvailableRange BlockRange
 }
 
 func (c *connection) Status() ConnStatus {
 	return ConnStatus{
-		ServerHost: c.serverHost,
-		Connected:  c.IsOK(),
-		Archive:    c.IsArchiveNode(),
+		ServerHost:     c.serverHost,
+		Connected:      c.IsOK(),
+		Archive:        c.IsArchiveNode(),
+		AvailableRange: c.AvailableRange(),
 	}
 }
@@ -0,0 +1,57 @@
+package pool
+
+import (
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// EventType is a type of a change in a pool's state.
+type EventType string
+
+const (
+	// MasterHeadUpdatedEvent is emitted when a connection learns about a new masterchain block.
+	MasterHeadUpdatedEvent EventType = "master-head-updated"
+	// ArchiveDetectedEvent is emitted when a connection is detected to be connected to an archive node.
+	ArchiveDetectedEvent EventType = "archive-detected"
+	// AvailableRangeUpdatedEvent is emitted when a connection detects a new range of blocks its lite server keeps.
+	AvailableRangeUpdatedEvent EventType = "available-range-updated"
+	// BestConnectionChangedEvent is emitted when a pool switches to another connection.
+	BestConnectionChangedEvent EventType = "best-connection-changed"
+	// ConnectionAddedEvent is emitted when a pool adds a connection to a lite server.
+	ConnectionAddedEvent EventType = "connection-added"
+	// ConnectionRemovedEvent is emitted when a pool removes a connection
+	// to a lite server missing in an updated server list, see ConnPool.UpdateServers.
+	ConnectionRemovedEvent EventType = "connection-removed"
+)
+
+// Event describes a change in a pool's state.
+type Event struct {
+	Type EventType
+	// ConnID is an ID of the connection the event is about.
+	ConnID int
+	// Status is a status of the connection after the change.
+	Status ConnStatus
+	// MasterHead is the latest masterchain block known to the connection.
+	MasterHead ton.BlockIDExt
+}
+
+// EventHandler is called for every event of a pool.
+// It is called synchronously from the pool's goroutines, so it must not block.
+type EventHandler func(Event)
+
+// SetEventHandler sets a handler to be notified about changes in the pool's state.
+func (p *ConnPool) SetEventHandler(handler EventHandler) {
+	p.eventHandler.Store(&handler)
+}
+
+func (p *ConnPool) emit(eventType EventType, c conn, head ton.BlockIDExt) {
+	handler := p.eventHandler.Load()
+	if handler == nil || *handler == nil {
+		return
+	}
+	(*handler)(Event{
+		Type:       eventType,
+		ConnID:     c.ID(),
+		Status:     c.Status(),
+		MasterHead: head,
+	})
+}
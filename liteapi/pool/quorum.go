@@ -0,0 +1,228 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/caigou-xyz/tongo/liteclient"
+)
+
+const defaultQuorumSize = 2
+
+// ErrNoQuorum is returned when a pool with QuorumStrategy can't get enough answers to compare.
+var ErrNoQuorum = errors.New("not enough lite servers answered to reach a quorum")
+
+// QuorumPolicy configures how a pool with QuorumStrategy compares answers of lite servers.
+type QuorumPolicy struct {
+	// Size is a number of lite servers that have to give the same answer to a query.
+	Size int
+	// Equal reports whether two answers to a query are the same.
+	// If nil, answers are compared byte for byte.
+	Equal func(method liteclient.RequestName, a, b []byte) bool
+}
+
+// quorumMethods are queries pinned to a particular block,
+// so all lite servers are expected to give the same answer.
+// Queries about the latest state of the blockchain, like liteServer.getMasterchainInfo, are answered by one server.
+var quorumMethods = map[liteclient.RequestName]struct{}{
+	liteclient.LiteServerGetAccountStateRequestName:          {},
+	liteclient.LiteServerGetAccountStatePrunnedRequestName:   {},
+	liteclient.LiteServerRunSmcMethodRequestName:             {},
+	liteclient.LiteServerGetConfigAllRequestName:             {},
+	liteclient.LiteServerGetConfigParamsRequestName:          {},
+	liteclient.LiteServerGetBlockRequestName:                 {},
+	liteclient.LiteServerGetBlockHeaderRequestName:           {},
+	liteclient.LiteServerGetStateRequestName:                 {},
+	liteclient.LiteServerGetShardInfoRequestName:             {},
+	liteclient.LiteServerGetAllShardsInfoRequestName:         {},
+	liteclient.LiteServerGetOneTransactionRequestName:        {},
+	liteclient.LiteServerGetTransactionsRequestName:          {},
+	liteclient.LiteServerListBlockTransactionsRequestName:    {},
+	liteclient.LiteServerListBlockTransactionsExtRequestName: {},
+	liteclient.LiteServerLookupBlockWithProofRequestName:     {},
+	liteclient.LiteServerGetLibrariesRequestName:             {},
+	liteclient.LiteServerGetLibrariesWithProofRequestName:    {},
+	liteclient.LiteServerGetValidatorStatsRequestName:        {},
+	liteclient.LiteServerGetShardBlockProofRequestName:       {},
+	liteclient.LiteServerGetDispatchQueueInfoRequestName:     {},
+}
+
+// QuorumAnswer is an answer of a lite server to a query sent to several lite servers.
+type QuorumAnswer struct {
+	ConnID     int
+	ServerHost string
+	Answer     []byte
+}
+
+// QuorumMismatchError is returned when lite servers give different answers to the same query.
+type QuorumMismatchError struct {
+	Method liteclient.RequestName
+	// Majority contains answers of the largest group of lite servers agreeing with each other.
+	Majority []QuorumAnswer
+	// Deviated contains answers of lite servers disagreeing with the majority.
+	Deviated []QuorumAnswer
+}
+
+func (e *QuorumMismatchError) Error() string {
+	hosts := make([]string, 0, len(e.Deviated))
+	for _, a := range e.Deviated {
+		hosts = append(hosts, fmt.Sprintf("%v (%v)", a.ConnID, a.ServerHost))
+	}
+	return fmt.Sprintf("lite servers disagree on %v: %v of %v answers deviate, servers: %v",
+		e.Method, len(e.Deviated), len(e.Deviated)+len(e.Majority), strings.Join(hosts, ", "))
+}
+
+// SetQuorumPolicy configures a pool with QuorumStrategy.
+func (p *ConnPool) SetQuorumPolicy(policy QuorumPolicy) {
+	p.quorumPolicy.Store(&policy)
+}
+
+// quorumPolicyFor returns a quorum policy for the given query or nil if the query is answered by one lite server.
+func (p *ConnPool) quorumPolicyFor(method liteclient.RequestName) *QuorumPolicy {
+	if p.strategy != QuorumStrategy {
+		return nil
+	}
+	if _, ok := quorumMethods[method]; !ok {
+		return nil
+	}
+	if policy := p.quorumPolicy.Load(); policy != nil {
+		return policy
+	}
+	return &QuorumPolicy{Size: defaultQuorumSize}
+}
+
+type quorumResult struct {
+	attemptResult
+	connID int
+}
+
+// quorum sends the query to policy.Size connections and checks that all of them give the same answer.
+// Connections failing with a retryable error are replaced with other connections.
+func (p *ConnPool) quorum(ctx context.Context, policy QuorumPolicy, connID int, q []byte, invoker liteclient.RequestInvoker) ([]byte, error) {
+	size := max(policy.Size, 1)
+	method := liteclient.QueryMethod(q)
+	equal := policy.Equal
+	if equal == nil {
+		equal = func(method liteclient.RequestName, a, b []byte) bool {
+			return bytes.Equal(a, b)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the pool can get new connections while the query is in flight,
+	// so senders give up on ctx.Done() instead of relying on the channel's capacity.
+	results := make(chan quorumResult)
+	send := func(connID int, invoker liteclient.RequestInvoker) {
+		go func() {
+			answer, err := invoker(ctx, q)
+			if err == nil {
+				if e := liteclient.LiteServerErrorFromAnswer(answer); e != nil {
+					err = *e
+				}
+			}
+			select {
+			case results <- quorumResult{attemptResult: attemptResult{answer: answer, err: err}, connID: connID}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	tried := map[int]struct{}{connID: {}}
+	next := func() bool {
+		c := p.untriedConnection(tried)
+		if c == nil {
+			return false
+		}
+		tried[c.ID()] = struct{}{}
+		send(c.ID(), c.Client().Request)
+		return true
+	}
+
+	send(connID, invoker)
+	inFlight := 1
+	for inFlight < size && next() {
+		inFlight++
+	}
+	var answers []quorumResult
+	var lastErr error
+	for inFlight > 0 {
+		var res quorumResult
+		select {
+		case res = <-results:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		inFlight--
+		if IsRetryable(res.err) {
+			lastErr = res.err
+			if ctx.Err() == nil && next() {
+				inFlight++
+			}
+			continue
+		}
+		if _, ok := res.err.(liteclient.LiteServerErrorC); res.err != nil && !ok {
+			return nil, res.err
+		}
+		answers = append(answers, res)
+	}
+	if len(answers) < size {
+		return nil, fmt.Errorf("%w: %v of %v answers for %v, last error: %v", ErrNoQuorum, len(answers), size, method, lastErr)
+	}
+
+	// group answers by equality, the first answer of each group represents it.
+	var groups [][]quorumResult
+	for _, res := range answers {
+		found := false
+		for i, group := range groups {
+			if equal(method, group[0].answer, res.answer) {
+				groups[i] = append(group, res)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []quorumResult{res})
+		}
+	}
+	if len(groups) == 1 {
+		// prefer the answer of the connection the query was sent to.
+		for _, res := range answers {
+			if res.connID == connID {
+				return answerOrError(res.attemptResult)
+			}
+		}
+		return answerOrError(answers[0].attemptResult)
+	}
+	majority := 0
+	for i, group := range groups {
+		if len(group) > len(groups[majority]) {
+			majority = i
+		}
+	}
+	mismatch := &QuorumMismatchError{Method: method}
+	for i, group := range groups {
+		for _, res := range group {
+			answer := QuorumAnswer{ConnID: res.connID, ServerHost: p.serverHost(res.connID), Answer: res.answer}
+			if i == majority {
+				mismatch.Majority = append(mismatch.Majority, answer)
+			} else {
+				mismatch.Deviated = append(mismatch.Deviated, answer)
+			}
+		}
+	}
+	return nil, mismatch
+}
+
+func (p *ConnPool) serverHost(connID int) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, c := range p.conns {
+		if c.ID() == connID {
+			return c.Status().ServerHost
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteclient"
+)
+
+type quorumTestHandler struct {
+	retryTestHandler
+
+	configProof []byte
+}
+
+func (h *quorumTestHandler) LiteServerGetConfigAll(ctx context.Context, request liteclient.LiteServerGetConfigAllRequest) (liteclient.LiteServerConfigInfoC, error) {
+	if h.errorCode != 0 {
+		return liteclient.LiteServerConfigInfoC{}, liteclient.LiteServerErrorC{Code: h.errorCode, Message: "not ready"}
+	}
+	return liteclient.LiteServerConfigInfoC{ConfigProof: h.configProof}, nil
+}
+
+func TestConnPool_Quorum(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       *QuorumPolicy
+		handlers     []*quorumTestHandler
+		request      func(ctx context.Context, cli *liteclient.Client) ([]byte, error)
+		want         []byte
+		wantErr      error
+		wantDeviated int
+	}{
+		{
+			name: "servers agree",
+			handlers: []*quorumTestHandler{
+				{configProof: []byte{1}},
+				{configProof: []byte{1}},
+			},
+			request: getConfigProof,
+			want:    []byte{1},
+		},
+		{
+			name:   "server deviates",
+			policy: &QuorumPolicy{Size: 3},
+			handlers: []*quorumTestHandler{
+				{configProof: []byte{1}},
+				{configProof: []byte{1}},
+				{configProof: []byte{2}},
+			},
+			request:      getConfigProof,
+			wantErr:      &QuorumMismatchError{},
+			wantDeviated: 1,
+		},
+		{
+			name:   "custom comparison",
+			policy: &QuorumPolicy{Size: 2, Equal: func(method liteclient.RequestName, a, b []byte) bool { return true }},
+			handlers: []*quorumTestHandler{
+				{configProof: []byte{1}},
+				{configProof: []byte{2}},
+			},
+			request: getConfigProof,
+			want:    []byte{1},
+		},
+		{
+			name: "not ready server is replaced",
+			handlers: []*quorumTestHandler{
+				{configProof: []byte{1}},
+				{retryTestHandler: retryTestHandler{errorCode: errorCodeNotReady}},
+				{configProof: []byte{1}},
+			},
+			request: getConfigProof,
+			want:    []byte{1},
+		},
+		{
+			name: "no quorum",
+			handlers: []*quorumTestHandler{
+				{configProof: []byte{1}},
+				{retryTestHandler: retryTestHandler{errorCode: errorCodeNotReady}},
+			},
+			request: getConfigProof,
+			wantErr: ErrNoQuorum,
+		},
+		{
+			name: "query not pinned to a block",
+			handlers: []*quorumTestHandler{
+				{retryTestHandler: retryTestHandler{now: 1}},
+				{retryTestHandler: retryTestHandler{errorCode: errorCodeNotReady}},
+			},
+			request: func(ctx context.Context, cli *liteclient.Client) ([]byte, error) {
+				now, err := getTime(ctx, cli)
+				return []byte{byte(now)}, err
+			},
+			want: []byte{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var servers []config.LiteServer
+			for _, h := range tt.handlers {
+				servers = append(servers, startRetryTestServer(t, h))
+			}
+			p := New(QuorumStrategy)
+			defer p.Close(context.Background())
+			if tt.policy != nil {
+				p.SetQuorumPolicy(*tt.policy)
+			}
+			if err := <-p.InitializeConnections(context.Background(), 5*time.Second, len(servers), 1, false, servers); err != nil {
+				t.Fatalf("InitializeConnections() failed: %v", err)
+			}
+			for p.ConnectionsNumber() < len(servers) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			p.mu.RLock()
+			first := p.conns[0].Client()
+			p.mu.RUnlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			got, err := tt.request(ctx, first)
+			if tt.wantErr != nil {
+				var mismatch *QuorumMismatchError
+				switch {
+				case errors.As(tt.wantErr, &mismatch):
+					if !errors.As(err, &mismatch) {
+						t.Fatalf("want QuorumMismatchError, got: %v", err)
+					}
+					if len(mismatch.Deviated) != tt.wantDeviated {
+						t.Fatalf("want %v deviated answers, got: %v", tt.wantDeviated, len(mismatch.Deviated))
+					}
+				case !errors.Is(err, tt.wantErr):
+					t.Fatalf("want error: %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("want %x, got: %x", tt.want, got)
+			}
+		})
+	}
+}
+
+func getConfigProof(ctx context.Context, cli *liteclient.Client) ([]byte, error) {
+	res, err := cli.LiteServerGetConfigAll(ctx, liteclient.LiteServerGetConfigAllRequest{})
+	return res.ConfigProof, err
+}
@@ -0,0 +1,117 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/caigou-xyz/tongo/config"
+)
+
+// ServerSource returns the current list of lite servers, for example, from a global configuration file.
+type ServerSource func(ctx context.Context) ([]config.LiteServer, error)
+
+// WatchServers periodically gets lite servers from the given source and updates the pool with UpdateServers,
+// until the pool is closed.
+// Failures to get servers or to connect to them leave the pool as is until the next attempt.
+func (p *ConnPool) WatchServers(source ServerSource, interval time.Duration) {
+	go func() {
+		for sleep(p.ctx, interval) {
+			ctx, cancel := context.WithTimeout(p.ctx, interval)
+			servers, err := source(ctx)
+			if err == nil {
+				err = p.UpdateServers(ctx, servers)
+			}
+			cancel()
+			// TODO: log error
+		}
+	}()
+}
+
+// UpdateServers makes the pool work with the given lite servers.
+//
+// The pool connects to servers it isn't connected to yet,
+// keeping the number of connections within maxConnections of InitializeConnections.
+// Connections to servers missing in the list are removed from the pool,
+// they don't get new requests and are closed once their in-flight requests are done.
+// If none of the given servers is available, the pool keeps its connections and UpdateServers returns an error.
+//
+// Changes are reported with ConnectionAddedEvent and ConnectionRemovedEvent.
+func (p *ConnPool) UpdateServers(ctx context.Context, servers []config.LiteServer) error {
+	settings := p.settings.Load()
+	if settings == nil {
+		return fmt.Errorf("connections are not initialized")
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("server list empty")
+	}
+	wanted := make(map[config.LiteServer]struct{}, len(servers))
+	for _, server := range servers {
+		wanted[server] = struct{}{}
+	}
+	connected := map[config.LiteServer]struct{}{}
+	kept := 0
+	p.mu.RLock()
+	for _, c := range p.conns {
+		connected[c.Server()] = struct{}{}
+		if _, ok := wanted[c.Server()]; ok {
+			kept += 1
+		}
+	}
+	p.mu.RUnlock()
+
+	var candidates []config.LiteServer
+	for _, server := range servers {
+		if _, ok := connected[server]; !ok {
+			candidates = append(candidates, server)
+		}
+	}
+	if missing := settings.maxConnections - kept; missing > 0 && len(candidates) > 0 {
+		p.connectServers(ctx, settings, candidates, missing)
+	}
+
+	removed, bestRemoved, err := p.removeConnections(wanted)
+	if err != nil {
+		return err
+	}
+	for _, c := range removed {
+		p.emit(ConnectionRemovedEvent, c, c.MasterHead())
+		go c.Drain(p.ctx, settings.timeout)
+	}
+	if c, changed := p.switchBest(); c != nil && (changed || bestRemoved) {
+		p.emit(BestConnectionChangedEvent, c, c.MasterHead())
+	}
+	return nil
+}
+
+// removeConnections removes connections to lite servers missing in the wanted set
+// and reports if the best connection has been removed.
+// It removes nothing if no connection would remain.
+func (p *ConnPool) removeConnections(wanted map[config.LiteServer]struct{}) ([]conn, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var kept, removed []conn
+	for _, c := range p.conns {
+		if _, ok := wanted[c.Server()]; ok {
+			kept = append(kept, c)
+			continue
+		}
+		removed = append(removed, c)
+	}
+	if len(removed) == 0 {
+		return nil, false, nil
+	}
+	if len(kept) == 0 {
+		return nil, false, fmt.Errorf("none of the given lite servers is available")
+	}
+	p.conns = kept
+	for _, c := range removed {
+		if c == p.bestConn {
+			// switchBest picks a working connection later, but the best connection must be in the pool anyway.
+			p.bestConn = kept[0]
+			return removed, true, nil
+		}
+	}
+	return removed, false, nil
+}
@@ -0,0 +1,94 @@
+package pool
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteclient"
+)
+
+func TestConnPool_UpdateServers(t *testing.T) {
+	first := startRetryTestServer(t, &retryTestHandler{now: 1, delay: 300 * time.Millisecond})
+	second := startRetryTestServer(t, &retryTestHandler{now: 2})
+	third := startRetryTestServer(t, &retryTestHandler{now: 3})
+	unavailable := config.LiteServer{Host: "127.0.0.1:1", Key: base64.StdEncoding.EncodeToString(make([]byte, 32))}
+
+	p := New(FirstWorkingConnection)
+	defer p.Close(context.Background())
+	var mu sync.Mutex
+	events := map[EventType][]string{}
+	p.SetEventHandler(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[e.Type] = append(events[e.Type], e.Status.ServerHost)
+	})
+	servers := []config.LiteServer{first, second}
+	if err := <-p.InitializeConnections(context.Background(), 5*time.Second, 2, 1, false, servers); err != nil {
+		t.Fatalf("InitializeConnections() failed: %v", err)
+	}
+	for p.ConnectionsNumber() < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.mu.RLock()
+	removedClient := p.conns[0].Client()
+	p.mu.RUnlock()
+
+	// a request in flight is not failed by removing its connection.
+	type result struct {
+		now uint32
+		err error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		now, err := getTime(context.Background(), removedClient)
+		resultCh <- result{now: now, err: err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.UpdateServers(ctx, []config.LiteServer{second, third}); err != nil {
+		t.Fatalf("UpdateServers() failed: %v", err)
+	}
+	status := p.Status()
+	if len(status.Connections) != 2 || status.Connections[0].ServerHost != second.Host || status.Connections[1].ServerHost != third.Host {
+		t.Fatalf("unexpected connections: %+v", status.Connections)
+	}
+	if res := <-resultCh; res.err != nil || res.now != 1 {
+		t.Fatalf("in-flight request failed: %v", res.err)
+	}
+	// the removed connection is closed once its requests are done.
+	time.Sleep(2 * drainCheckInterval)
+	if _, err := getTime(context.Background(), removedClient); !errors.Is(err, liteclient.ErrClosed) {
+		t.Fatalf("want ErrClosed, got: %v", err)
+	}
+	client, _, err := p.BestMasterchainClient(ctx)
+	if err != nil {
+		t.Fatalf("BestMasterchainClient() failed: %v", err)
+	}
+	if now, err := getTime(ctx, client); err != nil || now != 2 {
+		t.Fatalf("want the best connection to be switched to %v, got: %v, %v", second.Host, now, err)
+	}
+
+	// the pool keeps working connections if none of the new servers is available.
+	if err := p.UpdateServers(ctx, []config.LiteServer{unavailable}); err == nil {
+		t.Fatalf("want error")
+	}
+	if p.ConnectionsNumber() != 2 {
+		t.Fatalf("want 2 connections, got: %v", p.ConnectionsNumber())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if added := events[ConnectionAddedEvent]; len(added) != 3 || added[2] != third.Host {
+		t.Fatalf("unexpected %v events: %v", ConnectionAddedEvent, added)
+	}
+	if removed := events[ConnectionRemovedEvent]; len(removed) != 1 || removed[0] != first.Host {
+		t.Fatalf("unexpected %v events: %v", ConnectionRemovedEvent, removed)
+	}
+}
@@ -0,0 +1,190 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/caigou-xyz/tongo/liteclient"
+)
+
+// Codes of liteServer.error a lite server returns when another server could answer the same query.
+const (
+	errorCodeNotReady  = 651
+	errorCodeTimeout   = 652
+	errorCodeCancelled = 653
+)
+
+// RetryPolicy configures how a pool retries failed requests on its other connections.
+//
+// Only idempotent queries are retried.
+// liteServer.sendMessage is never retried nor hedged,
+// because broadcasting the same message several times is a decision a caller has to make.
+type RetryPolicy struct {
+	// MaxAttempts is a maximum number of attempts to send a query, including the first one.
+	// Each attempt goes to a different connection.
+	MaxAttempts int
+	// HedgeDelay enables hedged requests if positive.
+	// If an attempt doesn't complete within HedgeDelay,
+	// a duplicate query is sent to another connection and the first valid answer is used.
+	HedgeDelay time.Duration
+	// Retryable reports whether a query that failed with err can succeed on another lite server.
+	// If nil, IsRetryable is used.
+	Retryable func(err error) bool
+}
+
+// IsRetryable reports whether a query that failed with err can succeed on another lite server.
+// It returns true for connection problems, timeouts and liteServer.error codes
+// meaning that a lite server is not ready to answer the query.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, liteclient.ErrClosed) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var liteServerErr liteclient.LiteServerErrorC
+	if errors.As(err, &liteServerErr) {
+		switch liteServerErr.Code {
+		case errorCodeNotReady, errorCodeTimeout, errorCodeCancelled:
+			return true
+		}
+		return false
+	}
+	return liteclient.IsNotConnectedYet(err) || liteclient.IsClientError(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// SetRetryPolicy configures the pool to retry failed requests on other connections.
+func (p *ConnPool) SetRetryPolicy(policy RetryPolicy) {
+	p.retryPolicy.Store(&policy)
+}
+
+type withoutRetriesKey struct{}
+
+// withoutRetries returns a context for requests that must be served by a particular connection.
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRetriesKey{}, true)
+}
+
+// interceptor returns a request interceptor for a connection with the given ID
+// that applies the pool's retry and quorum policies.
+// inFlight counts requests sent through the connection, so the connection can be drained before closing.
+func (p *ConnPool) interceptor(connID int, inFlight *atomic.Int64) liteclient.RequestInterceptor {
+	return func(ctx context.Context, q []byte, invoker liteclient.RequestInvoker) ([]byte, error) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		policy := p.retryPolicy.Load()
+		if (policy == nil && p.strategy != QuorumStrategy) || ctx.Value(withoutRetriesKey{}) != nil {
+			return invoker(ctx, q)
+		}
+		method := liteclient.QueryMethod(q)
+		if quorum := p.quorumPolicyFor(method); quorum != nil {
+			return p.quorum(withoutRetries(ctx), *quorum, connID, q, invoker)
+		}
+		if policy == nil || method == liteclient.LiteServerSendMessageRequestName {
+			return invoker(ctx, q)
+		}
+		return p.retry(withoutRetries(ctx), *policy, connID, q, invoker)
+	}
+}
+
+type attemptResult struct {
+	answer []byte
+	err    error
+}
+
+func (p *ConnPool) retry(ctx context.Context, policy RetryPolicy, connID int, q []byte, invoker liteclient.RequestInvoker) ([]byte, error) {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	maxAttempts := max(policy.MaxAttempts, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan attemptResult, maxAttempts)
+	send := func(invoker liteclient.RequestInvoker) {
+		go func() {
+			answer, err := invoker(ctx, q)
+			if err == nil {
+				if e := liteclient.LiteServerErrorFromAnswer(answer); e != nil {
+					err = *e
+				}
+			}
+			results <- attemptResult{answer: answer, err: err}
+		}()
+	}
+	var hedge *time.Timer
+	var hedgeCh <-chan time.Time
+	if policy.HedgeDelay > 0 && maxAttempts > 1 {
+		hedge = time.NewTimer(policy.HedgeDelay)
+		defer hedge.Stop()
+		hedgeCh = hedge.C
+	}
+	tried := map[int]struct{}{connID: {}}
+	// next starts a new attempt on a connection that hasn't been tried yet.
+	next := func() bool {
+		c := p.untriedConnection(tried)
+		if c == nil {
+			return false
+		}
+		tried[c.ID()] = struct{}{}
+		send(c.Client().Request)
+		if hedge != nil {
+			hedge.Reset(policy.HedgeDelay)
+		}
+		return true
+	}
+
+	send(invoker)
+	attempts, inFlight := 1, 1
+	var last attemptResult
+	for inFlight > 0 {
+		select {
+		case res := <-results:
+			inFlight--
+			if !retryable(res.err) {
+				return answerOrError(res)
+			}
+			last = res
+			if attempts < maxAttempts && ctx.Err() == nil && next() {
+				attempts++
+				inFlight++
+			}
+		case <-hedgeCh:
+			if attempts < maxAttempts && next() {
+				attempts++
+				inFlight++
+				continue
+			}
+			hedgeCh = nil
+		}
+	}
+	return answerOrError(last)
+}
+
+// answerOrError returns a result of an attempt to a caller of Client.Request.
+// A liteServer.error is a valid answer, a caller decodes it from the answer.
+func answerOrError(res attemptResult) ([]byte, error) {
+	if _, ok := res.err.(liteclient.LiteServerErrorC); ok {
+		return res.answer, nil
+	}
+	return res.answer, res.err
+}
+
+// untriedConnection returns a working connection with the best ping that is not in the tried set.
+func (p *ConnPool) untriedConnection(tried map[int]struct{}) conn {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var best conn
+	for _, c := range p.conns {
+		if _, ok := tried[c.ID()]; ok || !c.IsOK() {
+			continue
+		}
+		if best == nil || c.AverageRoundTrip() < best.AverageRoundTrip() {
+			best = c
+		}
+	}
+	return best
+}
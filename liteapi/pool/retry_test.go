@@ -0,0 +1,191 @@
+package pool
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteclient"
+)
+
+type retryTestHandler struct {
+	liteclient.UnimplementedLiteServerHandler
+
+	now       uint32
+	delay     time.Duration
+	errorCode uint32
+}
+
+func (h *retryTestHandler) LiteServerGetMasterchainInfo(ctx context.Context) (liteclient.LiteServerMasterchainInfoC, error) {
+	return liteclient.LiteServerMasterchainInfoC{
+		Last: liteclient.TonNodeBlockIdExtC{Workchain: 0xffffffff, Shard: 0x8000000000000000, Seqno: 100500},
+	}, nil
+}
+
+func (h *retryTestHandler) LiteServerGetTime(ctx context.Context) (liteclient.LiteServerCurrentTimeC, error) {
+	select {
+	case <-ctx.Done():
+		return liteclient.LiteServerCurrentTimeC{}, ctx.Err()
+	case <-time.After(h.delay):
+	}
+	if h.errorCode != 0 {
+		return liteclient.LiteServerCurrentTimeC{}, liteclient.LiteServerErrorC{Code: h.errorCode, Message: "not ready"}
+	}
+	return liteclient.LiteServerCurrentTimeC{Now: h.now}, nil
+}
+
+func (h *retryTestHandler) LiteServerSendMessage(ctx context.Context, request liteclient.LiteServerSendMessageRequest) (liteclient.LiteServerSendMsgStatusC, error) {
+	if h.errorCode != 0 {
+		return liteclient.LiteServerSendMsgStatusC{}, liteclient.LiteServerErrorC{Code: h.errorCode, Message: "not ready"}
+	}
+	return liteclient.LiteServerSendMsgStatusC{Status: 1}, nil
+}
+
+func startRetryTestServer(t *testing.T, handler liteclient.LiteServerHandler) config.LiteServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	server := liteclient.NewServer(key, handler)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	go server.Serve(l)
+	t.Cleanup(func() { server.Close() })
+	return config.LiteServer{
+		Host: l.Addr().String(),
+		Key:  base64.StdEncoding.EncodeToString(server.PublicKey()),
+	}
+}
+
+func TestConnPool_RetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		first   *retryTestHandler
+		second  *retryTestHandler
+		request func(ctx context.Context, cli *liteclient.Client) (uint32, error)
+		want    uint32
+		wantErr bool
+	}{
+		{
+			name:    "no retry policy",
+			first:   &retryTestHandler{errorCode: errorCodeNotReady},
+			second:  &retryTestHandler{now: 2},
+			request: getTime,
+			wantErr: true,
+		},
+		{
+			name:    "retry on not ready",
+			policy:  &RetryPolicy{MaxAttempts: 2},
+			first:   &retryTestHandler{errorCode: errorCodeNotReady},
+			second:  &retryTestHandler{now: 2},
+			request: getTime,
+			want:    2,
+		},
+		{
+			name:    "not retryable error",
+			policy:  &RetryPolicy{MaxAttempts: 2},
+			first:   &retryTestHandler{errorCode: 601},
+			second:  &retryTestHandler{now: 2},
+			request: getTime,
+			wantErr: true,
+		},
+		{
+			name:    "hedged request",
+			policy:  &RetryPolicy{MaxAttempts: 2, HedgeDelay: 50 * time.Millisecond},
+			first:   &retryTestHandler{now: 1, delay: 3 * time.Second},
+			second:  &retryTestHandler{now: 2},
+			request: getTime,
+			want:    2,
+		},
+		{
+			name:    "send message is not retried",
+			policy:  &RetryPolicy{MaxAttempts: 2},
+			first:   &retryTestHandler{errorCode: errorCodeNotReady},
+			second:  &retryTestHandler{},
+			request: sendMessage,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := []config.LiteServer{
+				startRetryTestServer(t, tt.first),
+				startRetryTestServer(t, tt.second),
+			}
+			p := New(FirstWorkingConnection)
+			defer p.Close(context.Background())
+			if tt.policy != nil {
+				p.SetRetryPolicy(*tt.policy)
+			}
+			if err := <-p.InitializeConnections(context.Background(), 5*time.Second, 2, 1, false, servers); err != nil {
+				t.Fatalf("InitializeConnections() failed: %v", err)
+			}
+			for p.ConnectionsNumber() < 2 {
+				time.Sleep(10 * time.Millisecond)
+			}
+			p.mu.RLock()
+			first := p.conns[0].Client()
+			p.mu.RUnlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			got, err := tt.request(ctx, first)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func getTime(ctx context.Context, cli *liteclient.Client) (uint32, error) {
+	res, err := cli.LiteServerGetTime(ctx)
+	return res.Now, err
+}
+
+func sendMessage(ctx context.Context, cli *liteclient.Client) (uint32, error) {
+	res, err := cli.LiteServerSendMessage(ctx, liteclient.LiteServerSendMessageRequest{Body: []byte{1}})
+	return res.Status, err
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "not connected yet", err: errors.New("not connected yet"), want: true},
+		{name: "closed client", err: liteclient.ErrClosed, want: false},
+		{name: "not ready", err: liteclient.LiteServerErrorC{Code: errorCodeNotReady}, want: true},
+		{name: "lite server timeout", err: liteclient.LiteServerErrorC{Code: errorCodeTimeout}, want: true},
+		{name: "truncated history", err: liteclient.LiteServerErrorC{Code: 0xfffffe70}, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Fatalf("want %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
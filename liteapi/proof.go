@@ -0,0 +1,428 @@
+package liteapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// ErrInvalidProof is returned when a lite server's answer doesn't match its proof.
+var ErrInvalidProof = errors.New("invalid proof")
+
+const (
+	blockMagic      = 0x11ef55aa
+	blockExtraMagic = 0x4a33f6fd
+	shardStateMagic = 0x9023afe2
+	mcStateExtraTag = 0xcc26
+	mcBlockExtraTag = 0xcca5
+)
+
+// verifyAccountState checks that liteServer.accountState contains a state of the given account
+// in the given masterchain block.
+// The algorithm follows block::AccountState::validate of the reference lite client.
+func verifyAccountState(blockID ton.BlockIDExt, accountID ton.AccountID, res liteclient.LiteServerAccountStateC) error {
+	shardBlock, err := verifyAccountShardBlock(blockID, accountID, res.Id.ToBlockIdExt(), res.Shardblk.ToBlockIdExt(), res.ShardProof)
+	if err != nil {
+		return err
+	}
+	blockProof, stateProof, err := proofRoots(res.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %v", ErrInvalidProof, err)
+	}
+	if err := checkAccountProof(shardBlock, accountID, blockProof, stateProof, res.State); err != nil {
+		return fmt.Errorf("%w: account proof: %v", ErrInvalidProof, err)
+	}
+	return nil
+}
+
+// verifyPrunedAccountState checks that liteServer.accountState returned by liteServer.getAccountStatePrunned
+// contains a pruned state of the given account in the given masterchain block.
+// accountHash is a hash of the account's root cell taken from the pruned state, nil means the state is empty.
+func verifyPrunedAccountState(blockID ton.BlockIDExt, accountID ton.AccountID, res liteclient.LiteServerAccountStateC, accountHash *ton.Bits256) error {
+	shardBlock, err := verifyAccountShardBlock(blockID, accountID, res.Id.ToBlockIdExt(), res.Shardblk.ToBlockIdExt(), res.ShardProof)
+	if err != nil {
+		return err
+	}
+	blockProof, stateProof, err := proofRoots(res.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %v", ErrInvalidProof, err)
+	}
+	expected, err := provenAccountHash(shardBlock, accountID, blockProof, stateProof)
+	if err != nil {
+		return fmt.Errorf("%w: account proof: %v", ErrInvalidProof, err)
+	}
+	switch {
+	case expected == nil && accountHash != nil:
+		return fmt.Errorf("%w: proof shows that account doesn't exist, but its state is not empty", ErrInvalidProof)
+	case expected != nil && accountHash == nil:
+		return fmt.Errorf("%w: proof shows that account exists, but its state is empty", ErrInvalidProof)
+	case expected != nil && !bytes.Equal(expected, accountHash[:]):
+		return fmt.Errorf("%w: account state hash mismatch", ErrInvalidProof)
+	}
+	return nil
+}
+
+// decodePrunedAccount returns the root cell of an account and its hash from a state returned by
+// liteServer.getAccountStatePrunned. The state is a merkle proof keeping only the root cell of the account.
+func decodePrunedAccount(state []byte) (*boc.Cell, ton.Bits256, error) {
+	proof, err := boc.DeserializeSingleRootBoc(state)
+	if err != nil {
+		return nil, ton.Bits256{}, err
+	}
+	if proof.CellType() != boc.MerkleProofCell || proof.RefsSize() != 1 {
+		return nil, ton.Bits256{}, fmt.Errorf("pruned account state is not a merkle proof")
+	}
+	hash, err := proof.Refs()[0].HashAtLevel(0)
+	if err != nil {
+		return nil, ton.Bits256{}, err
+	}
+	var accountHash ton.Bits256
+	copy(accountHash[:], hash)
+	root, err := prunedProofRoot(proof, accountHash)
+	if err != nil {
+		return nil, ton.Bits256{}, err
+	}
+	return root, accountHash, nil
+}
+
+// verifyAccountShardBlock checks that the shard block returned by a lite server along with an account's data
+// is the latest block of the account's shard according to the given masterchain block.
+func verifyAccountShardBlock(blockID ton.BlockIDExt, accountID ton.AccountID, id, shardBlock ton.BlockIDExt, shardProof []byte) (ton.BlockIDExt, error) {
+	if id != blockID {
+		return ton.BlockIDExt{}, fmt.Errorf("%w: account state is for block %v instead of %v", ErrInvalidProof, id, blockID)
+	}
+	shard, err := ton.ParseShardID(int64(shardBlock.Shard))
+	if err != nil {
+		return ton.BlockIDExt{}, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if shardBlock.Workchain != accountID.Workchain || !shard.MatchAccountID(accountID) {
+		return ton.BlockIDExt{}, fmt.Errorf("%w: shard block %v can't contain account %v", ErrInvalidProof, shardBlock, accountID)
+	}
+	if shardBlock != blockID {
+		blockProof, stateProof, err := proofRoots(shardProof)
+		if err != nil {
+			return ton.BlockIDExt{}, fmt.Errorf("%w: shard proof: %v", ErrInvalidProof, err)
+		}
+		if err := checkShardProof(blockID, shardBlock, blockProof, stateProof); err != nil {
+			return ton.BlockIDExt{}, fmt.Errorf("%w: shard proof: %v", ErrInvalidProof, err)
+		}
+	}
+	return shardBlock, nil
+}
+
+// prunedProofRoot checks a merkle proof and returns the root of the proven tree as it is in the proof,
+// with pruned branch cells in place of missing branches.
+// It is only for answers whose pruned branches are a part of the data, like a pruned account state
+// or cells of an account used by a get method. Other proofs are read with boc.VerifyMerkleProof.
+func prunedProofRoot(proof *boc.Cell, hash [32]byte) (*boc.Cell, error) {
+	if _, err := boc.VerifyMerkleProof(proof, hash); err != nil {
+		return nil, err
+	}
+	root := proof.Refs()[0]
+	root.ResetCounters()
+	return root, nil
+}
+
+// proofRoots returns a proof of a block's header and a proof of the block's state
+// which lite servers put together in a single BoC.
+func proofRoots(proof []byte) (*boc.Cell, *boc.Cell, error) {
+	cells, err := boc.DeserializeBoc(proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cells) != 2 {
+		return nil, nil, fmt.Errorf("must be exactly two root cells")
+	}
+	return cells[0], cells[1], nil
+}
+
+// checkShardProof checks that the given shard block is the latest block of its shard
+// according to the given masterchain block.
+func checkShardProof(mcBlock, shardBlock ton.BlockIDExt, blockProof, stateProof *boc.Cell) error {
+	if mcBlock.Workchain != -1 {
+		return fmt.Errorf("block %v is not a masterchain block", mcBlock)
+	}
+	stateHash, err := blockStateHash(blockProof, mcBlock)
+	if err != nil {
+		return err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProof, stateHash)
+	if err != nil {
+		return fmt.Errorf("masterchain state: %w", err)
+	}
+	desc, err := findShardDesc(stateRoot, shardBlock)
+	if err != nil {
+		return err
+	}
+	if top := ton.ToBlockId(desc, shardBlock.Workchain); top != shardBlock {
+		return fmt.Errorf("top block of shard is %v instead of %v", top, shardBlock)
+	}
+	return nil
+}
+
+// checkAccountProof checks that the given account state is the state of the account in the given shard block.
+// An empty state means the account doesn't exist.
+func checkAccountProof(shardBlock ton.BlockIDExt, accountID ton.AccountID, blockProof, stateProof *boc.Cell, state []byte) error {
+	expected, err := provenAccountHash(shardBlock, accountID, blockProof, stateProof)
+	if err != nil {
+		return err
+	}
+	if expected == nil {
+		if len(state) != 0 {
+			return fmt.Errorf("proof shows that account doesn't exist, but its state is not empty")
+		}
+		return nil
+	}
+	if len(state) == 0 {
+		return fmt.Errorf("proof shows that account exists, but its state is empty")
+	}
+	stateCells, err := boc.DeserializeBoc(state)
+	if err != nil {
+		return err
+	}
+	if len(stateCells) != 1 {
+		return boc.ErrNotSingleRoot
+	}
+	hash, err := stateCells[0].Hash()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, expected) {
+		return fmt.Errorf("account state hash mismatch")
+	}
+	return nil
+}
+
+// provenAccountHash returns a hash of the given account's root cell from a proof of a shard state.
+// It returns nil if the proof shows that the account doesn't exist.
+func provenAccountHash(shardBlock ton.BlockIDExt, accountID ton.AccountID, blockProof, stateProof *boc.Cell) ([]byte, error) {
+	stateHash, err := blockStateHash(blockProof, shardBlock)
+	if err != nil {
+		return nil, err
+	}
+	stateRoot, err := boc.VerifyMerkleProof(stateProof, stateHash)
+	if err != nil {
+		return nil, fmt.Errorf("shard state: %w", err)
+	}
+	value, err := findShardAccount(stateRoot, accountID)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	// account_descr$_ account:^Account last_trans_hash:bits256 last_trans_lt:uint64 = ShardAccount;
+	// the account goes after an optional dictionary of extra currencies of DepthBalanceInfo.
+	refs := value.Refs()
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("invalid shard account")
+	}
+	return refs[len(refs)-1].HashAtLevel(0)
+}
+
+// blockStateHash checks a merkle proof of a block's header and returns a hash of the block's state.
+func blockStateHash(proof *boc.Cell, blockID ton.BlockIDExt) ([32]byte, error) {
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("block %v: %w", blockID, err)
+	}
+	// block#11ef55aa global_id:int32
+	// info:^BlockInfo value_flow:^ValueFlow
+	// state_update:^(MERKLE_UPDATE ShardState)
+	// extra:^BlockExtra = Block;
+	if magic, err := root.ReadUint(32); err != nil || magic != blockMagic {
+		return [32]byte{}, fmt.Errorf("block %v: invalid block header", blockID)
+	}
+	refs := root.Refs()
+	if len(refs) != 4 {
+		return [32]byte{}, fmt.Errorf("block %v: invalid block header", blockID)
+	}
+	update := refs[2]
+	if update.CellType() != boc.MerkleUpdateCell || update.RefsSize() != 2 {
+		return [32]byte{}, fmt.Errorf("block %v: invalid state update", blockID)
+	}
+	hash, err := update.Refs()[1].HashAtLevel(0)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	var stateHash [32]byte
+	copy(stateHash[:], hash)
+	return stateHash, nil
+}
+
+// findShardAccount returns a cell with ShardAccount of the given account from a proof of a shard state.
+// The bits read cursor of the cell is positioned at ShardAccount, refs of the cell are not read.
+// It returns nil if the proof shows that the account doesn't exist.
+func findShardAccount(stateRoot *boc.Cell, accountID ton.AccountID) (*boc.Cell, error) {
+	// shard_state#9023afe2 ... out_msg_queue_info:^OutMsgQueueInfo before_split:(## 1) accounts:^ShardAccounts ...
+	if magic, err := stateRoot.ReadUint(32); err != nil || magic != shardStateMagic {
+		return nil, fmt.Errorf("invalid shard state")
+	}
+	refs := stateRoot.Refs()
+	if len(refs) < 3 {
+		return nil, fmt.Errorf("invalid shard state")
+	}
+	accounts := refs[1]
+	if accounts.CellType() == boc.PrunedBranchCell {
+		return nil, fmt.Errorf("shard accounts are pruned")
+	}
+	accounts.ResetCounters()
+	// ahme_empty$0 {n:#} {X:Type} {Y:Type} extra:Y = HashmapAugE n X Y;
+	// ahme_root$1 {n:#} {X:Type} {Y:Type} root:^(HashmapAug n X Y) extra:Y = HashmapAugE n X Y;
+	isRoot, err := accounts.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !isRoot {
+		return nil, nil
+	}
+	root, err := accounts.NextRef()
+	if err != nil {
+		return nil, err
+	}
+	key := boc.NewBitString(256)
+	if err := key.WriteBytes(accountID.Address[:]); err != nil {
+		return nil, err
+	}
+	value, err := tlb.FindHashmapValue(root, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	// depth_balance$_ split_depth:(#<= 30) balance:CurrencyCollection = DepthBalanceInfo;
+	// currencies$_ grams:Grams other:ExtraCurrencyCollection = CurrencyCollection;
+	// the dictionary of extra currencies can be pruned, so only its bit is skipped.
+	var extra struct {
+		SplitDepth tlb.Uint5
+		Grams      tlb.Grams
+	}
+	if err := tlb.Unmarshal(value, &extra); err != nil {
+		return nil, err
+	}
+	if _, err := value.ReadBit(); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// findShardDesc returns a description of the given shard from a proof of a masterchain state.
+func findShardDesc(stateRoot *boc.Cell, shardBlock ton.BlockIDExt) (tlb.ShardDesc, error) {
+	extra, err := mcStateExtra(stateRoot)
+	if err != nil {
+		return tlb.ShardDesc{}, err
+	}
+	// masterchain_state_extra#cc26 shard_hashes:ShardHashes ...
+	return findShardInHashes(extra, shardBlock.Workchain, shardBlock.Shard)
+}
+
+// findShardInHashes returns a description of the given shard from ShardHashes
+// which the read cursor of the given cell is positioned at.
+func findShardInHashes(hashes *boc.Cell, workchain int32, shard uint64) (tlb.ShardDesc, error) {
+	// _ (HashmapE 32 ^(BinTree ShardDescr)) = ShardHashes;
+	notEmpty, err := hashes.ReadBit()
+	if err != nil {
+		return tlb.ShardDesc{}, err
+	}
+	if !notEmpty {
+		return tlb.ShardDesc{}, fmt.Errorf("shard %v:%x not found", workchain, shard)
+	}
+	root, err := hashes.NextRef()
+	if err != nil {
+		return tlb.ShardDesc{}, err
+	}
+	value, err := findUint32Key(root, uint32(workchain))
+	if err != nil {
+		return tlb.ShardDesc{}, err
+	}
+	if value == nil {
+		return tlb.ShardDesc{}, fmt.Errorf("workchain %v not found", workchain)
+	}
+	node, err := value.NextRef()
+	if err != nil {
+		return tlb.ShardDesc{}, err
+	}
+	// bt_leaf$0 {X:Type} leaf:X = BinTree X;
+	// bt_fork$1 {X:Type} left:^(BinTree X) right:^(BinTree X) = BinTree X;
+	// A shard is a path in the tree: its bits go from the most significant one till the last 1 bit.
+	path := shard
+	for {
+		if node.CellType() == boc.PrunedBranchCell {
+			return tlb.ShardDesc{}, fmt.Errorf("shard %v:%x is pruned", workchain, shard)
+		}
+		node.ResetCounters()
+		isFork, err := node.ReadBit()
+		if err != nil {
+			return tlb.ShardDesc{}, err
+		}
+		if !isFork {
+			break
+		}
+		if path == 1<<63 {
+			return tlb.ShardDesc{}, fmt.Errorf("shard %v:%x not found", workchain, shard)
+		}
+		idx := path >> 63
+		path <<= 1
+		children := node.Refs()
+		if len(children) != 2 {
+			return tlb.ShardDesc{}, boc.ErrNotEnoughRefs
+		}
+		node = children[idx]
+	}
+	var desc tlb.ShardDesc
+	if err := tlb.Unmarshal(node, &desc); err != nil {
+		return tlb.ShardDesc{}, err
+	}
+	return desc, nil
+}
+
+// mcStateExtra returns McStateExtra from a proof of a masterchain state.
+// The read cursor of the returned cell is positioned right after the tag.
+func mcStateExtra(stateRoot *boc.Cell) (*boc.Cell, error) {
+	// shard_state#9023afe2 ... custom:(Maybe ^McStateExtra) = ShardStateUnsplit;
+	if magic, err := stateRoot.ReadUint(32); err != nil || magic != shardStateMagic {
+		return nil, fmt.Errorf("invalid masterchain state")
+	}
+	refs := stateRoot.Refs()
+	if len(refs) != 4 {
+		return nil, fmt.Errorf("masterchain state has no McStateExtra")
+	}
+	extra := refs[3]
+	if extra.CellType() == boc.PrunedBranchCell {
+		return nil, fmt.Errorf("McStateExtra is pruned")
+	}
+	extra.ResetCounters()
+	if tag, err := extra.ReadUint(16); err != nil || tag != mcStateExtraTag {
+		return nil, fmt.Errorf("invalid McStateExtra")
+	}
+	return extra, nil
+}
+
+// skipMaybeRef skips Maybe ^X or HashmapE without reading the ref which a proof can prune.
+func skipMaybeRef(c *boc.Cell) error {
+	exists, err := c.ReadBit()
+	if err != nil || !exists {
+		return err
+	}
+	return c.SkipRef()
+}
+
+// skipCurrencyCollection skips CurrencyCollection without reading the dictionary of extra currencies
+// which a proof can prune.
+func skipCurrencyCollection(c *boc.Cell) error {
+	// currencies$_ grams:Grams other:ExtraCurrencyCollection = CurrencyCollection;
+	var grams tlb.Grams
+	if err := tlb.Unmarshal(c, &grams); err != nil {
+		return err
+	}
+	return skipMaybeRef(c)
+}
+
+// findUint32Key looks for a 32-bit key in a hashmap, see tlb.FindHashmapValue.
+func findUint32Key(root *boc.Cell, key uint32) (*boc.Cell, error) {
+	bits := boc.NewBitString(32)
+	if err := bits.WriteUint(uint64(key), 32); err != nil {
+		return nil, err
+	}
+	return tlb.FindHashmapValue(root, bits)
+}
@@ -0,0 +1,546 @@
+package liteapi
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type testShardAccount struct {
+	Extra         tlb.DepthBalanceInfo
+	Account       boc.Cell `tlb:"^"`
+	LastTransHash tlb.Bits256
+	LastTransLt   uint64
+}
+
+type testShardState struct {
+	Magic           tlb.Magic `tlb:"shard_state#9023afe2"`
+	SeqNo           uint32
+	OutMsgQueueInfo boc.Cell                                    `tlb:"^"`
+	Accounts        tlb.HashmapE[tlb.Bits256, testShardAccount] `tlb:"^"`
+	Other           boc.Cell                                    `tlb:"^"`
+	Custom          *boc.Cell                                   `tlb:"maybe^"`
+}
+
+type testBinTreeRef struct {
+	Tree boc.Cell `tlb:"^"`
+}
+
+type testPrevBlock struct {
+	Extra tlb.KeyMaxLt
+	Value tlb.KeyExtBlkRef
+}
+
+type testMcStateOther struct {
+	Flags           uint16
+	ValidatorInfo   tlb.ValidatorInfo
+	PrevBlocks      tlb.HashmapE[tlb.Uint32, testPrevBlock]
+	PrevBlocksExtra tlb.KeyMaxLt
+}
+
+type testMcStateExtra struct {
+	Magic       tlb.Magic `tlb:"masterchain_state_extra#cc26"`
+	ShardHashes tlb.HashmapE[tlb.Uint32, testBinTreeRef]
+	ConfigAddr  tlb.Bits256
+	Config      boc.Cell         `tlb:"^"`
+	Other       testMcStateOther `tlb:"^"`
+}
+
+func cellDepth(c *boc.Cell) int {
+	depth := 0
+	for _, ref := range c.Refs() {
+		depth = max(depth, cellDepth(ref)+1)
+	}
+	return depth
+}
+
+// roundTrip serializes and deserializes the given cell, so it looks like a cell received from a lite server.
+func roundTrip(t *testing.T, c *boc.Cell) *boc.Cell {
+	t.Helper()
+	bocBytes, err := boc.SerializeBoc(c, false, false, false, 0)
+	if err != nil {
+		t.Fatalf("SerializeBoc() failed: %v", err)
+	}
+	cell, err := boc.DeserializeSingleRootBoc(bocBytes)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	return cell
+}
+
+// merkleProof returns a merkle proof cell that keeps the whole tree of the given cell.
+func merkleProof(t *testing.T, c *boc.Cell) *boc.Cell {
+	t.Helper()
+	hash := testutil.MustHash(t, c)
+	proof := boc.NewCellExotic(boc.MerkleProofCell)
+	if err := proof.WriteUint(3, 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := proof.WriteBytes(hash[:]); err != nil {
+		t.Fatalf("WriteBytes() failed: %v", err)
+	}
+	if err := proof.WriteUint(uint64(cellDepth(c)), 16); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := proof.AddRef(c); err != nil {
+		t.Fatalf("AddRef() failed: %v", err)
+	}
+	return roundTrip(t, proof)
+}
+
+// testBlock returns a block with the given state and a proof of its header.
+func testBlock(t *testing.T, id ton.BlockID, state *boc.Cell) (ton.BlockIDExt, *boc.Cell) {
+	t.Helper()
+	block := testBlockCell(t, testutil.CellWithUint(t, uint64(id.Seqno)), state, testutil.CellWithUint(t, 2))
+	blockID := ton.BlockIDExt{BlockID: id, RootHash: testutil.MustHash(t, block), FileHash: ton.Bits256{1}}
+	return blockID, merkleProof(t, block)
+}
+
+// testBlockCell returns a block with the given info, state and extra.
+func testBlockCell(t *testing.T, info, state, extra *boc.Cell) *boc.Cell {
+	t.Helper()
+	prevState := testutil.CellWithUint(t, 0)
+	prevHash, stateHash := testutil.MustHash(t, prevState), testutil.MustHash(t, state)
+	update := boc.NewCellExotic(boc.MerkleUpdateCell)
+	for _, err := range []error{
+		update.WriteUint(4, 8),
+		update.WriteBytes(prevHash[:]),
+		update.WriteBytes(stateHash[:]),
+		update.WriteUint(uint64(cellDepth(prevState)), 16),
+		update.WriteUint(uint64(cellDepth(state)), 16),
+		update.AddRef(prevState),
+		update.AddRef(state),
+	} {
+		if err != nil {
+			t.Fatalf("failed to build state update: %v", err)
+		}
+	}
+	block := boc.NewCell()
+	for _, err := range []error{
+		block.WriteUint(blockMagic, 32),
+		block.WriteInt(-239, 32),
+		block.AddRef(info),
+		block.AddRef(testutil.CellWithUint(t, 1)),
+		block.AddRef(update),
+		block.AddRef(extra),
+	} {
+		if err != nil {
+			t.Fatalf("failed to build block: %v", err)
+		}
+	}
+	return block
+}
+
+// stateProof returns a proof of the given state with out_msg_queue_info pruned.
+// Each path is a list of ref indexes leading to a cell to be pruned as well.
+func stateProof(t *testing.T, state *boc.Cell, paths ...[]int) *boc.Cell {
+	t.Helper()
+	prover, err := boc.NewMerkleProver(state)
+	if err != nil {
+		t.Fatalf("NewMerkleProver() failed: %v", err)
+	}
+	cursor := prover.Cursor()
+	cursor.Ref(0).Prune()
+	for _, path := range paths {
+		c := cursor
+		for _, ref := range path {
+			c = c.Ref(ref)
+		}
+		c.Prune()
+	}
+	bocBytes, err := prover.CreateProof(cursor)
+	if err != nil {
+		t.Fatalf("CreateProof() failed: %v", err)
+	}
+	cell, err := boc.DeserializeSingleRootBoc(bocBytes)
+	if err != nil {
+		t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+	}
+	return cell
+}
+
+func Test_checkAccountProof(t *testing.T) {
+	// two accounts with addresses differing in the first bit, so a hashmap has a fork at its root.
+	left := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x02}}
+	right := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x81, 0x02}}
+	absent := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x03}}
+	absentRight := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x81, 0x03}}
+
+	leftState, rightState := testutil.CellWithUint(t, 100), testutil.CellWithUint(t, 200)
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           10,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 3),
+		Accounts: tlb.NewHashmapE(
+			[]tlb.Bits256{tlb.Bits256(left.Address), tlb.Bits256(right.Address)},
+			[]testShardAccount{
+				{Account: *leftState, LastTransLt: 1},
+				{Account: *rightState, LastTransLt: 2},
+			}),
+		Other: *testutil.CellWithUint(t, 4),
+	})
+	shardBlock, blockProof := testBlock(t, ton.BlockID{Workchain: 0, Shard: 0x8000000000000000, Seqno: 10}, state)
+
+	// paths to cells of the state: accounts -> hashmap root -> leaf -> account.
+	rightAccount := []int{1, 0, 1}
+	leftAccountState := []int{1, 0, 0, 0}
+
+	toBoc := func(c *boc.Cell) []byte {
+		bocBytes, err := c.ToBoc()
+		if err != nil {
+			t.Fatalf("ToBoc() failed: %v", err)
+		}
+		return bocBytes
+	}
+	tests := []struct {
+		name      string
+		blockID   ton.BlockIDExt
+		accountID ton.AccountID
+		prune     [][]int
+		state     []byte
+		wantErr   bool
+	}{
+		{
+			name:      "existing account",
+			blockID:   shardBlock,
+			accountID: left,
+			state:     toBoc(leftState),
+		},
+		{
+			name:      "existing account with pruned neighbours",
+			blockID:   shardBlock,
+			accountID: left,
+			prune:     [][]int{rightAccount},
+			state:     toBoc(leftState),
+		},
+		{
+			name:      "existing account with pruned state",
+			blockID:   shardBlock,
+			accountID: left,
+			prune:     [][]int{leftAccountState},
+			state:     toBoc(leftState),
+		},
+		{
+			name:      "state of another account",
+			blockID:   shardBlock,
+			accountID: left,
+			state:     toBoc(rightState),
+			wantErr:   true,
+		},
+		{
+			name:      "existing account with empty state",
+			blockID:   shardBlock,
+			accountID: right,
+			wantErr:   true,
+		},
+		{
+			name:      "absent account",
+			blockID:   shardBlock,
+			accountID: absent,
+		},
+		{
+			name:      "absent account with state",
+			blockID:   shardBlock,
+			accountID: absent,
+			state:     toBoc(leftState),
+			wantErr:   true,
+		},
+		{
+			name:      "account in pruned branch",
+			blockID:   shardBlock,
+			accountID: absentRight,
+			prune:     [][]int{rightAccount},
+			wantErr:   true,
+		},
+		{
+			name:      "another block",
+			blockID:   ton.BlockIDExt{BlockID: shardBlock.BlockID, RootHash: ton.Bits256{1}},
+			accountID: left,
+			state:     toBoc(leftState),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof := stateProof(t, state, tt.prune...)
+			err := checkAccountProof(tt.blockID, tt.accountID, blockProof, proof, tt.state)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkAccountProof() failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_decodeVerifiedAccountData(t *testing.T) {
+	left := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x02}}
+	right := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x81, 0x02}}
+	absent := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x03}}
+	absentRight := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x81, 0x03}}
+
+	state := testutil.MustMarshal(t, testShardState{
+		SeqNo:           10,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 3),
+		Accounts: tlb.NewHashmapE(
+			[]tlb.Bits256{tlb.Bits256(left.Address), tlb.Bits256(right.Address)},
+			[]testShardAccount{
+				{Account: *testutil.CellWithUint(t, 100), LastTransHash: tlb.Bits256{5}, LastTransLt: 1},
+				{Account: *testutil.CellWithUint(t, 200), LastTransHash: tlb.Bits256{6}, LastTransLt: 2},
+			}),
+		Other: *testutil.CellWithUint(t, 4),
+	})
+	shardBlock, blockProof := testBlock(t, ton.BlockID{Workchain: 0, Shard: 0x8000000000000000, Seqno: 10}, state)
+
+	tests := []struct {
+		name      string
+		blockID   ton.BlockIDExt
+		accountID ton.AccountID
+		prune     [][]int
+		wantLt    uint64
+		wantHash  tlb.Bits256
+		wantErr   bool
+	}{
+		{
+			name:      "existing account with pruned state",
+			blockID:   shardBlock,
+			accountID: left,
+			prune:     [][]int{{1, 0, 0, 0}, {1, 0, 1}},
+			wantLt:    1,
+			wantHash:  tlb.Bits256{5},
+		},
+		{
+			name:      "absent account",
+			blockID:   shardBlock,
+			accountID: absent,
+			wantErr:   true,
+		},
+		{
+			name:      "account in pruned branch",
+			blockID:   shardBlock,
+			accountID: absentRight,
+			prune:     [][]int{{1, 0, 1}},
+			wantErr:   true,
+		},
+		{
+			name:      "another block",
+			blockID:   ton.BlockIDExt{BlockID: shardBlock.BlockID, RootHash: ton.Bits256{1}},
+			accountID: left,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := boc.SerializeBocWithMode([]*boc.Cell{blockProof, stateProof(t, state, tt.prune...)}, 0)
+			if err != nil {
+				t.Fatalf("SerializeBocWithMode() failed: %v", err)
+			}
+			res := liteclient.LiteServerAccountStateC{Shardblk: liteclient.BlockIDExt(tt.blockID), Proof: proof}
+			lt, hash, err := decodeVerifiedAccountData(res, tt.accountID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeVerifiedAccountData() failed: %v", err)
+			}
+			if lt != tt.wantLt || hash != tt.wantHash {
+				t.Fatalf("want lt %v and hash %x, got: %v and %x", tt.wantLt, tt.wantHash, lt, hash)
+			}
+		})
+	}
+}
+
+func Test_checkShardProof(t *testing.T) {
+	shardDesc := func(shard uint64, seqno uint32, rootHash tlb.Bits256) *boc.Cell {
+		var desc tlb.ShardDesc
+		desc.SumType = "New"
+		desc.New.SeqNo = seqno
+		desc.New.RootHash = rootHash
+		desc.New.NextValidatorShard = int64(shard)
+		leaf := boc.NewCell()
+		if err := leaf.WriteBit(false); err != nil {
+			t.Fatalf("WriteBit() failed: %v", err)
+		}
+		if err := tlb.Marshal(leaf, desc); err != nil {
+			t.Fatalf("Marshal() failed: %v", err)
+		}
+		return leaf
+	}
+	fork := boc.NewCell()
+	for _, err := range []error{
+		fork.WriteBit(true),
+		fork.AddRef(shardDesc(0x4000000000000000, 20, tlb.Bits256{2})),
+		fork.AddRef(shardDesc(0xc000000000000000, 30, tlb.Bits256{3})),
+	} {
+		if err != nil {
+			t.Fatalf("failed to build shards tree: %v", err)
+		}
+	}
+	mcStateExtra := testutil.MustMarshal(t, testMcStateExtra{
+		ShardHashes: tlb.NewHashmapE([]tlb.Uint32{0}, []testBinTreeRef{{Tree: *fork}}),
+	})
+	mcState := testutil.MustMarshal(t, testShardState{
+		SeqNo:           100,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 3),
+		Other:           *testutil.CellWithUint(t, 4),
+		Custom:          mcStateExtra,
+	})
+	mcBlock, blockProof := testBlock(t, ton.BlockID{Workchain: -1, Shard: 0x8000000000000000, Seqno: 100}, mcState)
+
+	tests := []struct {
+		name       string
+		shardBlock ton.BlockIDExt
+		wantErr    bool
+	}{
+		{
+			name:       "left shard",
+			shardBlock: ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 0x4000000000000000, Seqno: 20}, RootHash: ton.Bits256{2}},
+		},
+		{
+			name:       "right shard",
+			shardBlock: ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 0xc000000000000000, Seqno: 30}, RootHash: ton.Bits256{3}},
+		},
+		{
+			name:       "not the top block",
+			shardBlock: ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 0xc000000000000000, Seqno: 29}, RootHash: ton.Bits256{3}},
+			wantErr:    true,
+		},
+		{
+			name:       "root hash mismatch",
+			shardBlock: ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 0x4000000000000000, Seqno: 20}, RootHash: ton.Bits256{3}},
+			wantErr:    true,
+		},
+		{
+			name:       "split shard",
+			shardBlock: ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 0x2000000000000000, Seqno: 20}, RootHash: ton.Bits256{2}},
+			wantErr:    true,
+		},
+		{
+			name:       "unknown workchain",
+			shardBlock: ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 1, Shard: 0x4000000000000000, Seqno: 20}, RootHash: ton.Bits256{2}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkShardProof(mcBlock, tt.shardBlock, blockProof, merkleProof(t, mcState))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkShardProof() failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_decodePrunedAccount(t *testing.T) {
+	account := testutil.CellWithUint(t, 1)
+	for _, ref := range []*boc.Cell{testutil.CellWithUint(t, 2), testutil.CellWithUint(t, 3)} {
+		if err := account.AddRef(ref); err != nil {
+			t.Fatalf("AddRef() failed: %v", err)
+		}
+	}
+	prover, err := boc.NewMerkleProver(account)
+	if err != nil {
+		t.Fatalf("NewMerkleProver() failed: %v", err)
+	}
+	cursor := prover.Cursor()
+	cursor.Ref(0).Prune()
+	cursor.Ref(1).Prune()
+	pruned, err := prover.CreateProof(cursor)
+	if err != nil {
+		t.Fatalf("CreateProof() failed: %v", err)
+	}
+	root, hash, err := decodePrunedAccount(pruned)
+	if err != nil {
+		t.Fatalf("decodePrunedAccount() failed: %v", err)
+	}
+	if hash != testutil.MustHash(t, account) {
+		t.Fatalf("want hash %x, got: %x", testutil.MustHash(t, account), hash)
+	}
+	if len(root.Refs()) != 2 || root.Refs()[0].CellType() != boc.PrunedBranchCell {
+		t.Fatalf("want account with pruned refs")
+	}
+	if _, _, err := decodePrunedAccount(testutil.MustBoc(t, account)); err == nil {
+		t.Fatalf("want error for an ordinary cell")
+	}
+}
+
+func Test_decodeVerifiedAccountDataOnRecordedProof(t *testing.T) {
+	// The recorded liteServer.configInfo answer for mainnet masterchain block 26309435
+	// carries a proof of the block's header and a proof of its state
+	// which lite servers put together into accountState proofs.
+	data, err := os.ReadFile("../ton/testdata/get-last-config-all-2.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	var configInfo liteclient.LiteServerConfigInfoC
+	if err := configInfo.UnmarshalTL(bytes.NewReader(data[4:])); err != nil {
+		t.Fatalf("UnmarshalTL() failed: %v", err)
+	}
+	blockProof, err := boc.DeserializeBoc(configInfo.StateProof)
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	stateProof, err := boc.DeserializeBoc(configInfo.ConfigProof)
+	if err != nil {
+		t.Fatalf("DeserializeBoc() failed: %v", err)
+	}
+	proof, err := boc.SerializeBocWithMode([]*boc.Cell{blockProof[0], stateProof[0]}, 0)
+	if err != nil {
+		t.Fatalf("SerializeBocWithMode() failed: %v", err)
+	}
+	wrongBlock := configInfo.Id
+	wrongBlock.RootHash[0] ^= 1
+	configAccount := ton.MustParseAccountID("-1:5555555555555555555555555555555555555555555555555555555555555555")
+
+	tests := []struct {
+		name    string
+		res     liteclient.LiteServerAccountStateC
+		wantErr string
+	}{
+		{
+			name: "accounts are not included in the proof",
+			res: liteclient.LiteServerAccountStateC{
+				Id:       configInfo.Id,
+				Shardblk: configInfo.Id,
+				Proof:    proof,
+			},
+			wantErr: "shard accounts are pruned",
+		},
+		{
+			name: "proof of another block",
+			res: liteclient.LiteServerAccountStateC{
+				Id:       wrongBlock,
+				Shardblk: wrongBlock,
+				Proof:    proof,
+			},
+			wantErr: boc.ErrInvalidMerkleProof.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeVerifiedAccountData(tt.res, configAccount)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("want error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
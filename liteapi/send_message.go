@@ -0,0 +1,184 @@
+package liteapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// messageExpiryGracePeriod is how long SendMessageAndWait keeps following an account after valid_until of a message.
+// A shard block generated before valid_until can be committed to the masterchain a bit later.
+const messageExpiryGracePeriod = 30 * time.Second
+
+// ErrMessageExpired is returned by SendMessageAndWait wrapped into MessageExpiredError.
+var ErrMessageExpired = errors.New("message expired")
+
+// MessageExpiredError is returned by SendMessageAndWait
+// when a message hasn't been included into the blockchain before its valid_until.
+// Such a message can't be included anymore, so it is safe to send a new one.
+type MessageExpiredError struct {
+	// MessageHash is a normalized hash of the message, see tlb.Message.Hash.
+	MessageHash ton.Bits256
+	ValidUntil  time.Time
+	// LastBlock is the masterchain block the destination account was checked at last.
+	LastBlock ton.BlockIDExt
+}
+
+func (e *MessageExpiredError) Error() string {
+	return fmt.Sprintf("message %x is not included into the blockchain before %v", e.MessageHash, e.ValidUntil.Unix())
+}
+
+func (e *MessageExpiredError) Unwrap() error {
+	return ErrMessageExpired
+}
+
+// BroadcastMessage sends the external message to all working lite servers of the pool.
+// It returns a number of lite servers that accepted the message
+// and fails only if none of them accepted it.
+func (c *Client) BroadcastMessage(ctx context.Context, payload []byte) (int, error) {
+	if err := VerifySendMessagePayload(payload); err != nil {
+		return 0, err
+	}
+	clients := c.pool.WorkingClients()
+	if len(clients) == 0 {
+		// no connection is ready yet, let the pool pick one.
+		if _, err := c.SendMessage(ctx, payload); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(clients))
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, client *liteclient.Client) {
+			defer wg.Done()
+			_, errs[i] = client.LiteServerSendMessage(ctx, liteclient.LiteServerSendMessageRequest{Body: payload})
+		}(i, client)
+	}
+	wg.Wait()
+	accepted := 0
+	for _, err := range errs {
+		if err == nil {
+			accepted += 1
+		}
+	}
+	if accepted == 0 {
+		return 0, fmt.Errorf("no lite server accepted the message: %w", errors.Join(errs...))
+	}
+	return accepted, nil
+}
+
+// SendMessageAndWait broadcasts the external message with BroadcastMessage
+// and waits for a transaction of the destination account processing the message.
+// The message is identified by its normalized hash, see tlb.Message.Hash.
+//
+// validUntil is the time after which the destination contract rejects the message,
+// for wallets it is valid_until of the signed body.
+// If the message hasn't been included into the blockchain by then,
+// SendMessageAndWait returns MessageExpiredError.
+// Only transactions made after the message is sent are checked,
+// so sending the same message twice returns the second transaction processing it.
+func (c *Client) SendMessageAndWait(ctx context.Context, payload []byte, validUntil time.Time) (ton.Transaction, error) {
+	msg, err := ConvertSendMessagePayloadToMessage(payload)
+	if err != nil {
+		return ton.Transaction{}, err
+	}
+	accountID, err := ton.AccountIDFromTlb(msg.Info.ExtInMsgInfo.Dest)
+	if err != nil {
+		return ton.Transaction{}, err
+	}
+	if accountID == nil {
+		return ton.Transaction{}, fmt.Errorf("message has no destination")
+	}
+	source := clientBlockSource{Client: c}
+	head, err := source.GetMasterchainInfo(ctx)
+	if err != nil {
+		return ton.Transaction{}, err
+	}
+	cursor, err := source.lastTransaction(ctx, head, *accountID)
+	if err != nil {
+		return ton.Transaction{}, err
+	}
+	if _, err := c.BroadcastMessage(ctx, payload); err != nil {
+		return ton.Transaction{}, err
+	}
+	w := messageWaiter{
+		source:      source,
+		subscriber:  newTransactionSubscriber(source, WithSkipTruncatedHistory()),
+		accountID:   *accountID,
+		messageHash: ton.Bits256(msg.Hash(true)),
+		validUntil:  validUntil,
+	}
+	return w.wait(ctx, head, cursor)
+}
+
+// messageSource is a part of Client used by messageWaiter.
+type messageSource interface {
+	transactionSource
+	GetBlockHeader(ctx context.Context, blockID ton.BlockIDExt, mode uint32) (tlb.BlockInfo, error)
+}
+
+// messageWaiter follows new transactions of an account looking for one processing a message.
+type messageWaiter struct {
+	source messageSource
+	// subscriber is used to get transactions of the account between two masterchain blocks.
+	subscriber  *TransactionSubscriber
+	accountID   ton.AccountID
+	messageHash ton.Bits256
+	validUntil  time.Time
+}
+
+// wait checks the account at each masterchain block following the given one
+// until it finds a transaction processing the message or the message expires.
+// cursor is the last transaction of the account at the given block.
+func (w *messageWaiter) wait(ctx context.Context, block ton.BlockIDExt, cursor AccountCursor) (ton.Transaction, error) {
+	delay := w.subscriber.options.RetryDelay
+	deadline := w.validUntil.Add(messageExpiryGracePeriod)
+	for {
+		next, err := retry(ctx, delay, func() (ton.BlockIDExt, error) {
+			return w.source.WaitMasterchainBlock(ctx, block.Seqno+1, defaultStreamerWaitTimeout)
+		})
+		if err != nil {
+			return ton.Transaction{}, err
+		}
+		block = next
+		last, err := retry(ctx, delay, func() (AccountCursor, error) {
+			return w.source.lastTransaction(ctx, block, w.accountID)
+		})
+		if err != nil {
+			return ton.Transaction{}, err
+		}
+		if last != cursor {
+			txs, err := w.subscriber.transactionsSince(ctx, &accountUpdate{accountID: w.accountID, cursor: cursor, last: last})
+			if err != nil {
+				return ton.Transaction{}, err
+			}
+			for _, tx := range txs {
+				if tx.Msgs.InMsg.Exists && ton.Bits256(tx.Msgs.InMsg.Value.Value.Hash(true)) == w.messageHash {
+					return tx, nil
+				}
+			}
+			cursor = last
+		}
+		info, err := retry(ctx, delay, func() (tlb.BlockInfo, error) {
+			return w.source.GetBlockHeader(ctx, block, 0)
+		})
+		if err != nil {
+			return ton.Transaction{}, err
+		}
+		if time.Unix(int64(info.GenUtime), 0).After(deadline) {
+			return ton.Transaction{}, &MessageExpiredError{
+				MessageHash: w.messageHash,
+				ValidUntil:  w.validUntil,
+				LastBlock:   block,
+			}
+		}
+	}
+}
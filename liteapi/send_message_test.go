@@ -0,0 +1,122 @@
+package liteapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type fakeMessageSource struct {
+	*fakeTransactionSource
+	// states contain the last transaction of the account at masterchain blocks.
+	states map[uint32]AccountCursor
+}
+
+func (s *fakeMessageSource) lastTransaction(ctx context.Context, block ton.BlockIDExt, accountID ton.AccountID) (AccountCursor, error) {
+	return s.states[block.Seqno], nil
+}
+
+func (s *fakeMessageSource) GetBlockHeader(ctx context.Context, blockID ton.BlockIDExt, mode uint32) (tlb.BlockInfo, error) {
+	block, err := s.GetBlock(ctx, blockID)
+	if err != nil {
+		return tlb.BlockInfo{}, err
+	}
+	return block.Info, nil
+}
+
+func Test_messageWaiter_wait(t *testing.T) {
+	txs := decodeTestTransactions(t)
+	accountID := ton.AccountID{Workchain: -1, Address: ton.Bits256(txs[0].AccountAddr)}
+	mc := func(seqno uint32) ton.BlockIDExt { return testBlockID(-1, testShardFull, seqno) }
+	validUntil := time.Unix(1700000000, 0)
+	// txs[1] is the only transaction processing an external message.
+	messageHash := ton.Bits256(txs[1].Msgs.InMsg.Value.Value.Hash(true))
+
+	tests := []struct {
+		name        string
+		cursor      AccountCursor
+		states      map[uint32]AccountCursor
+		messageHash ton.Bits256
+		want        uint64
+		wantBlock   uint32
+	}{
+		{
+			name:   "message is processed",
+			cursor: TransactionCursor(txs[2]),
+			states: map[uint32]AccountCursor{
+				101: TransactionCursor(txs[2]),
+				102: TransactionCursor(txs[0]),
+			},
+			messageHash: messageHash,
+			want:        txs[1].Lt,
+		},
+		{
+			name:   "message expires",
+			cursor: TransactionCursor(txs[2]),
+			states: map[uint32]AccountCursor{
+				101: TransactionCursor(txs[2]),
+				102: TransactionCursor(txs[0]),
+				103: TransactionCursor(txs[0]),
+			},
+			messageHash: ton.Bits256{1},
+			wantBlock:   103,
+		},
+		{
+			name:   "transactions before sending are ignored",
+			cursor: TransactionCursor(txs[0]),
+			states: map[uint32]AccountCursor{
+				101: TransactionCursor(txs[0]),
+				102: TransactionCursor(txs[0]),
+				103: TransactionCursor(txs[0]),
+			},
+			messageHash: messageHash,
+			wantBlock:   103,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeMessageSource{
+				fakeTransactionSource: &fakeTransactionSource{
+					fakeBlockSource: newFakeBlockSource(),
+					history:         map[ton.AccountID][]ton.Transaction{accountID: txs},
+				},
+				states: tt.states,
+			}
+			for seqno := uint32(100); seqno <= 104; seqno++ {
+				source.addMasterchainBlock(mc(seqno), mc(seqno-1))
+				block := source.blocks[mc(seqno)]
+				// block 103 is the first one generated after the grace period.
+				block.Info.GenUtime = uint32(validUntil.Add(messageExpiryGracePeriod).Unix()) - 102 + seqno
+				source.blocks[mc(seqno)] = block
+			}
+			w := messageWaiter{
+				source:      source,
+				subscriber:  newTransactionSubscriber(source),
+				accountID:   accountID,
+				messageHash: tt.messageHash,
+				validUntil:  validUntil,
+			}
+			tx, err := w.wait(context.Background(), mc(100), tt.cursor)
+			if tt.wantBlock > 0 {
+				var expired *MessageExpiredError
+				if !errors.As(err, &expired) || !errors.Is(err, ErrMessageExpired) {
+					t.Fatalf("want MessageExpiredError, got: %v", err)
+				}
+				if expired.LastBlock != mc(tt.wantBlock) || expired.MessageHash != tt.messageHash {
+					t.Fatalf("unexpected error: %+v", expired)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wait() failed: %v", err)
+			}
+			if tx.Lt != tt.want {
+				t.Fatalf("want transaction %v, got: %v", tt.want, tx.Lt)
+			}
+		})
+	}
+}
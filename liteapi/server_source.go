@@ -0,0 +1,59 @@
+package liteapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/caigou-xyz/tongo/config"
+	"github.com/caigou-xyz/tongo/liteapi/pool"
+)
+
+// LiteServersFromURL returns a source of lite servers downloading a global configuration file from the given URL
+// every time it is called. Take a look at WithLiteServersReloading().
+func LiteServersFromURL(url string) pool.ServerSource {
+	return func(ctx context.Context) ([]config.LiteServer, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download %v: %v", url, resp.Status)
+		}
+		file, err := config.ParseConfig(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return file.LiteServers, nil
+	}
+}
+
+// LiteServersFromFile returns a source of lite servers reading a global configuration file at the given path
+// every time it is called. Take a look at WithLiteServersReloading().
+func LiteServersFromFile(path string) pool.ServerSource {
+	return func(ctx context.Context) ([]config.LiteServer, error) {
+		file, err := config.ParseConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return file.LiteServers, nil
+	}
+}
+
+// LiteServersFromEnvs returns a source of lite servers reading the LITE_SERVERS env variable
+// every time it is called. Take a look at WithLiteServersReloading().
+func LiteServersFromEnvs() pool.ServerSource {
+	return func(ctx context.Context) ([]config.LiteServer, error) {
+		value, ok := os.LookupEnv(LiteServerEnvName)
+		if !ok {
+			return nil, fmt.Errorf("%v env variable is not set", LiteServerEnvName)
+		}
+		return config.ParseLiteServersEnvVar(value)
+	}
+}
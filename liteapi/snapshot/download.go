@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+const (
+	masterchainStateFile  = "masterchain.boc"
+	shardStateFilePattern = "shard_*.boc"
+)
+
+// stateSource is a part of liteapi.Client used by Download.
+type stateSource interface {
+	GetState(ctx context.Context, blockID ton.BlockIDExt) ([]byte, ton.Bits256, ton.Bits256, error)
+	GetAllShardsInfo(ctx context.Context, blockID ton.BlockIDExt) ([]ton.BlockIDExt, error)
+}
+
+// Download stores states of the masterchain block and all shard blocks it refers to in the directory,
+// so the snapshot can be loaded later with Open.
+// A full state is large and many lite servers don't return it,
+// so the client should be connected to a lite server that does.
+func Download(ctx context.Context, client *liteapi.Client, blockID ton.BlockIDExt, dir string) error {
+	return download(ctx, client, blockID, dir)
+}
+
+func download(ctx context.Context, source stateSource, blockID ton.BlockIDExt, dir string) error {
+	if blockID.Workchain != masterchainID {
+		return fmt.Errorf("block %v is not a masterchain block", blockID)
+	}
+	shards, err := source.GetAllShardsInfo(ctx, blockID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := downloadState(ctx, source, blockID, filepath.Join(dir, masterchainStateFile)); err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		name := fmt.Sprintf("shard_%d_%016x_%d.boc", shard.Workchain, shard.Shard, shard.Seqno)
+		if err := downloadState(ctx, source, shard, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadState stores a state of the block in the file after checking that it is the state of the block.
+func downloadState(ctx context.Context, source stateSource, blockID ton.BlockIDExt, path string) error {
+	data, _, _, err := source.GetState(ctx, blockID)
+	if err != nil {
+		return fmt.Errorf("state of block %v: %w", blockID, err)
+	}
+	root, err := boc.DeserializeSingleRootBoc(data)
+	if err != nil {
+		return fmt.Errorf("state of block %v: %w", blockID, err)
+	}
+	state, _, err := decodeState(root)
+	if err != nil {
+		return fmt.Errorf("state of block %v: %w", blockID, err)
+	}
+	if state.state.blockID != blockID.BlockID {
+		return fmt.Errorf("state of block %v is returned instead of %v", state.state.blockID, blockID)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
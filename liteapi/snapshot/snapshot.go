@@ -0,0 +1,576 @@
+// Package snapshot answers queries of liteapi.Client from a frozen state of the blockchain:
+// a state of a masterchain block and states of shard blocks it refers to, loaded from disk.
+// A Snapshot can be used instead of a lite server client by wallet, abi, txemulator and contract packages
+// for reproducible analytics and tests.
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+
+	"github.com/caigou-xyz/tongo/abi"
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/code"
+	"github.com/caigou-xyz/tongo/liteapi"
+	"github.com/caigou-xyz/tongo/tep64"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+	"github.com/caigou-xyz/tongo/utils"
+)
+
+const (
+	masterchainID = -1
+	// accountNotFoundExitCode is an exit code of a get method of an account without code, as lite servers return.
+	accountNotFoundExitCode = 0xffffff00 // -256
+)
+
+var (
+	// ErrReadOnly is returned by methods changing the blockchain, like SendMessage.
+	ErrReadOnly = errors.New("snapshot is read-only")
+	// ErrNoMethodEmulator is returned by get methods of a snapshot created without WithMethodEmulator.
+	ErrNoMethodEmulator = errors.New("snapshot has no method emulator")
+	// ErrShardNotFound is returned for an account of a shard whose state is not in the snapshot.
+	ErrShardNotFound = errors.New("shard state is not in the snapshot")
+)
+
+// Options holds parameters to configure a Snapshot.
+type Options struct {
+	// MethodEmulator runs get methods of accounts.
+	MethodEmulator liteapi.MethodEmulator
+}
+
+type Option func(o *Options)
+
+// WithMethodEmulator makes the snapshot run get methods with the given emulator,
+// usually it is tvm.NewMethodEmulator().
+func WithMethodEmulator(emulator liteapi.MethodEmulator) Option {
+	return func(o *Options) {
+		o.MethodEmulator = emulator
+	}
+}
+
+// shardState is a state of a masterchain or shard block.
+type shardState struct {
+	blockID  ton.BlockID
+	genUtime uint32
+	// accounts is the root of the ShardAccounts dictionary, it is nil if the shard has no accounts.
+	accounts *boc.Cell
+}
+
+// Snapshot is a read-only blockchain backed by states of blocks.
+// It answers queries as of the masterchain block:
+// get methods are executed with the time and the configuration of the masterchain block.
+type Snapshot struct {
+	options Options
+
+	// mu serializes lookups because cells of the states keep read cursors.
+	mu          sync.Mutex
+	masterchain shardState
+	shards      []shardState
+	config      tlb.ConfigParams
+	// configRoot is the root of the configuration dictionary.
+	configRoot *boc.Cell
+	// libraries is the root of the libraries dictionary of the masterchain, it is nil if there are no libraries.
+	libraries *boc.Cell
+	// bags are files of states opened by Open, cells of the states are read from them on demand.
+	bags []*boc.LazyBoc
+}
+
+// Interfaces of blockchain clients accepted by other packages, a Snapshot can be passed to any of them.
+// Most of them are unexported, so they are repeated here.
+type (
+	// accountGetter is the interface of txemulator.NewTraceBuilder's WithAccountsSource.
+	accountGetter interface {
+		GetAccountState(ctx context.Context, a ton.AccountID) (tlb.ShardAccount, error)
+		GetLibraries(ctx context.Context, libraries []ton.Bits256) (map[ton.Bits256]*boc.Cell, error)
+	}
+	// walletBlockchain is the interface of wallet.New.
+	walletBlockchain interface {
+		GetSeqno(ctx context.Context, account ton.AccountID) (uint32, error)
+		SendMessage(ctx context.Context, payload []byte) (uint32, error)
+		GetAccountState(ctx context.Context, accountID ton.AccountID) (tlb.ShardAccount, error)
+	}
+	// jettonBlockchain is the interface of jetton.New.
+	jettonBlockchain interface {
+		GetJettonWallet(ctx context.Context, master, owner ton.AccountID) (ton.AccountID, error)
+		GetJettonData(ctx context.Context, master ton.AccountID) (tep64.Metadata, error)
+		GetJettonBalance(ctx context.Context, jettonWallet ton.AccountID) (*big.Int, error)
+	}
+)
+
+var (
+	_ abi.Executor     = &Snapshot{}
+	_ accountGetter    = &Snapshot{}
+	_ walletBlockchain = &Snapshot{}
+	_ jettonBlockchain = &Snapshot{}
+)
+
+// New returns a snapshot of the given masterchain state and states of shard blocks the masterchain block refers to.
+// States are serialized as returned by liteapi.Client.GetState.
+func New(masterchainState []byte, shardStates [][]byte, opts ...Option) (*Snapshot, error) {
+	mcRoot, err := boc.DeserializeSingleRootBoc(masterchainState)
+	if err != nil {
+		return nil, fmt.Errorf("masterchain state: %w", err)
+	}
+	shardRoots := make([]*boc.Cell, 0, len(shardStates))
+	for _, data := range shardStates {
+		root, err := boc.DeserializeSingleRootBoc(data)
+		if err != nil {
+			return nil, fmt.Errorf("shard state: %w", err)
+		}
+		shardRoots = append(shardRoots, root)
+	}
+	return newSnapshot(mcRoot, shardRoots, opts...)
+}
+
+func newSnapshot(masterchainState *boc.Cell, shardStates []*boc.Cell, opts ...Option) (*Snapshot, error) {
+	s := &Snapshot{}
+	for _, o := range opts {
+		o(&s.options)
+	}
+	mc, custom, err := decodeState(masterchainState)
+	if err != nil {
+		return nil, fmt.Errorf("masterchain state: %w", err)
+	}
+	if mc.state.blockID.Workchain != masterchainID || custom == nil {
+		return nil, fmt.Errorf("state of block %v is not a masterchain state", mc.state.blockID)
+	}
+	s.masterchain = mc.state
+	shardBlocks, err := s.decodeMasterchainExtra(custom)
+	if err != nil {
+		return nil, fmt.Errorf("masterchain state: %w", err)
+	}
+	if s.libraries, err = decodeLibraries(mc.other); err != nil {
+		return nil, fmt.Errorf("masterchain state: %w", err)
+	}
+	for _, root := range shardStates {
+		shard, _, err := decodeState(root)
+		if err != nil {
+			return nil, fmt.Errorf("shard state: %w", err)
+		}
+		if _, ok := shardBlocks[shard.state.blockID]; !ok {
+			return nil, fmt.Errorf("shard block %v is not referred to by masterchain block %v", shard.state.blockID, s.masterchain.blockID)
+		}
+		s.shards = append(s.shards, shard.state)
+	}
+	return s, nil
+}
+
+// Open returns a snapshot of states stored in the directory by Download.
+// The states are not loaded into memory: cells are read from the files with boc.LazyBoc when needed,
+// so the files are kept open until Close is called.
+func Open(dir string, opts ...Option) (*Snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(dir, shardStateFilePattern))
+	if err != nil {
+		return nil, err
+	}
+	var bags []*boc.LazyBoc
+	closeBags := func() {
+		for _, bag := range bags {
+			bag.Close()
+		}
+	}
+	var roots []*boc.Cell
+	for _, file := range append([]string{filepath.Join(dir, masterchainStateFile)}, files...) {
+		bag, err := boc.OpenLazyBocFile(file)
+		if err != nil {
+			closeBags()
+			return nil, err
+		}
+		bags = append(bags, bag)
+		if len(bag.Roots()) != 1 {
+			closeBags()
+			return nil, fmt.Errorf("%v: %w", file, boc.ErrNotSingleRoot)
+		}
+		roots = append(roots, bag.Roots()[0])
+	}
+	s, err := newSnapshot(roots[0], roots[1:], opts...)
+	if err == nil {
+		err = bagsErr(bags)
+	}
+	if err != nil {
+		closeBags()
+		return nil, err
+	}
+	s.bags = bags
+	return s, nil
+}
+
+// Close closes files of a snapshot opened with Open.
+// Cells returned by the snapshot can't be read after that.
+func (s *Snapshot) Close() error {
+	var firstErr error
+	for _, bag := range s.bags {
+		if err := bag.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// bagsErr returns the first error that happened while reading cells of the given bags.
+func bagsErr(bags []*boc.LazyBoc) error {
+	for _, bag := range bags {
+		if err := bag.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BlockID returns the masterchain block of the snapshot.
+func (s *Snapshot) BlockID() ton.BlockID {
+	return s.masterchain.blockID
+}
+
+type decodedState struct {
+	state shardState
+	other *boc.Cell
+}
+
+// decodeState decodes a header of a shard state and returns McStateExtra of the masterchain state.
+func decodeState(root *boc.Cell) (decodedState, *boc.Cell, error) {
+	var header struct {
+		Magic           tlb.Magic `tlb:"shard_state#9023afe2"`
+		GlobalID        int32
+		ShardID         tlb.ShardIdent
+		SeqNo           uint32
+		VertSeqNo       uint32
+		GenUtime        uint32
+		GenLt           uint64
+		MinRefMcSeqno   uint32
+		OutMsgQueueInfo boc.Cell `tlb:"^"`
+		BeforeSplit     bool
+		Accounts        boc.Cell `tlb:"^"`
+		Other           boc.Cell `tlb:"^"`
+		Custom          tlb.Maybe[tlb.Ref[boc.Cell]]
+	}
+	if err := tlb.Unmarshal(root, &header); err != nil {
+		return decodedState{}, nil, fmt.Errorf("only unsplit shard states are supported: %w", err)
+	}
+	// _ (HashmapAugE 256 ShardAccount DepthBalanceInfo) = ShardAccounts;
+	accounts := &header.Accounts
+	accounts.ResetCounters()
+	notEmpty, err := accounts.ReadBit()
+	if err != nil {
+		return decodedState{}, nil, err
+	}
+	var accountsRoot *boc.Cell
+	if notEmpty {
+		if accountsRoot, err = accounts.NextRef(); err != nil {
+			return decodedState{}, nil, err
+		}
+	}
+	state := decodedState{
+		state: shardState{
+			blockID: ton.BlockID{
+				Workchain: header.ShardID.WorkchainID,
+				Shard:     header.ShardID.ShardPrefix | 1<<(63-uint64(header.ShardID.ShardPfxBits)),
+				Seqno:     header.SeqNo,
+			},
+			genUtime: header.GenUtime,
+			accounts: accountsRoot,
+		},
+		other: &header.Other,
+	}
+	if !header.Custom.Exists {
+		return state, nil, nil
+	}
+	return state, &header.Custom.Value.Value, nil
+}
+
+// decodeMasterchainExtra reads the configuration from McStateExtra
+// and returns shard blocks the masterchain block refers to.
+func (s *Snapshot) decodeMasterchainExtra(custom *boc.Cell) (map[ton.BlockID]struct{}, error) {
+	custom.ResetCounters()
+	var extra struct {
+		Magic       tlb.Magic `tlb:"masterchain_state_extra#cc26"`
+		ShardHashes tlb.HashmapE[tlb.Uint32, tlb.Ref[tlb.ShardInfoBinTree]]
+		ConfigAddr  tlb.Bits256
+		Config      boc.Cell `tlb:"^"`
+	}
+	if err := tlb.Unmarshal(custom, &extra); err != nil {
+		return nil, err
+	}
+	var config tlb.Hashmap[tlb.Uint32, tlb.Ref[boc.Cell]]
+	if err := tlb.Unmarshal(&extra.Config, &config); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	extra.Config.ResetCounters()
+	s.config = tlb.ConfigParams{ConfigAddr: extra.ConfigAddr, Config: config}
+	s.configRoot = &extra.Config
+	shardBlocks := make(map[ton.BlockID]struct{})
+	for _, item := range extra.ShardHashes.Items() {
+		for _, desc := range item.Value.Value.BinTree.Values {
+			shardBlocks[ton.ToBlockId(desc, int32(item.Key)).BlockID] = struct{}{}
+		}
+	}
+	return shardBlocks, nil
+}
+
+// decodeLibraries returns the root of the libraries dictionary from the "other" part of the masterchain state.
+func decodeLibraries(other *boc.Cell) (*boc.Cell, error) {
+	other.ResetCounters()
+	// ^[ overload_history:uint64 underload_history:uint64
+	// total_balance:CurrencyCollection total_validator_fees:CurrencyCollection
+	// libraries:(HashmapE 256 LibDescr) master_ref:(Maybe BlkMasterInfo) ]
+	var prefix struct {
+		OverloadHistory    uint64
+		UnderloadHistory   uint64
+		TotalBalance       tlb.CurrencyCollection
+		TotalValidatorFees tlb.CurrencyCollection
+	}
+	if err := tlb.Unmarshal(other, &prefix); err != nil {
+		return nil, err
+	}
+	notEmpty, err := other.ReadBit()
+	if err != nil || !notEmpty {
+		return nil, err
+	}
+	return other.NextRef()
+}
+
+// accountShard returns a state of the shard containing the account.
+func (s *Snapshot) accountShard(accountID ton.AccountID) (*shardState, error) {
+	if accountID.Workchain == masterchainID {
+		return &s.masterchain, nil
+	}
+	for i, shard := range s.shards {
+		if shard.blockID.Workchain != accountID.Workchain {
+			continue
+		}
+		id, err := ton.ParseShardID(int64(shard.blockID.Shard))
+		if err != nil {
+			return nil, err
+		}
+		if id.MatchAccountID(accountID) {
+			return &s.shards[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: account %v", ErrShardNotFound, accountID)
+}
+
+// GetAccountState returns a state of the account.
+// A nonexistent account has the AccountNone state.
+func (s *Snapshot) GetAccountState(ctx context.Context, accountID ton.AccountID) (tlb.ShardAccount, error) {
+	shard, err := s.accountShard(accountID)
+	if err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	notFound := tlb.ShardAccount{Account: tlb.Account{SumType: "AccountNone"}}
+	if shard.accounts == nil {
+		return notFound, nil
+	}
+	key := boc.NewBitString(256)
+	if err := key.WriteBytes(accountID.Address[:]); err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, err := tlb.FindHashmapValue(shard.accounts, key)
+	if err == nil {
+		// a broken file can make a lookup miss the account.
+		err = bagsErr(s.bags)
+	}
+	if err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	if value == nil {
+		return notFound, nil
+	}
+	// the value of ShardAccounts is prefixed with its augmentation.
+	var item struct {
+		Extra   tlb.DepthBalanceInfo
+		Account tlb.ShardAccount
+	}
+	if err := tlb.Unmarshal(value, &item); err != nil {
+		return tlb.ShardAccount{}, err
+	}
+	return item.Account, nil
+}
+
+// GetLibraries returns libraries published in the masterchain.
+// Libraries that don't exist are not included into the result.
+func (s *Snapshot) GetLibraries(ctx context.Context, libraryList []ton.Bits256) (map[ton.Bits256]*boc.Cell, error) {
+	libs := make(map[ton.Bits256]*boc.Cell, len(libraryList))
+	if s.libraries == nil {
+		return libs, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hash := range libraryList {
+		key := boc.NewBitString(256)
+		if err := key.WriteBytes(hash[:]); err != nil {
+			return nil, err
+		}
+		value, err := tlb.FindHashmapValue(s.libraries, key)
+		if err == nil {
+			err = bagsErr(s.bags)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		var descr tlb.LibDescr
+		if err := tlb.Unmarshal(value, &descr); err != nil {
+			return nil, err
+		}
+		lib := descr.Lib
+		libs[hash] = &lib
+	}
+	return libs, nil
+}
+
+// GetConfigAll returns the configuration of the blockchain.
+// The mode is ignored, all parameters are returned.
+func (s *Snapshot) GetConfigAll(ctx context.Context, mode liteapi.ConfigMode) (tlb.ConfigParams, error) {
+	return s.config, nil
+}
+
+// RunSmcMethodByID runs a get method of the account with the method emulator.
+// The method is executed at the time of the masterchain block.
+func (s *Snapshot) RunSmcMethodByID(ctx context.Context, accountID ton.AccountID, methodID int, params tlb.VmStack) (uint32, tlb.VmStack, error) {
+	if s.options.MethodEmulator == nil {
+		return 0, nil, ErrNoMethodEmulator
+	}
+	state, err := s.GetAccountState(ctx, accountID)
+	if err != nil {
+		return 0, nil, err
+	}
+	account := state.Account.Account
+	if state.Account.SumType != "Account" || account.Storage.State.SumType != "AccountActive" {
+		return accountNotFoundExitCode, tlb.VmStack{}, nil
+	}
+	init := account.Storage.State.AccountActive.StateInit
+	if !init.Code.Exists || !init.Data.Exists {
+		return accountNotFoundExitCode, tlb.VmStack{}, nil
+	}
+	env := ton.MethodEnv{
+		AccountID: accountID,
+		Code:      &init.Code.Value.Value,
+		Data:      &init.Data.Value.Value,
+		UnixTime:  s.masterchain.genUtime,
+		Balance:   uint64(account.Storage.Balance.Grams),
+		// the block makes the random seed deterministic.
+		RandSeed: sha256.Sum256([]byte(s.masterchain.blockID.String())),
+		Config:   s.configRoot,
+	}
+	hashes, err := code.FindLibraries(env.Code)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(hashes) > 0 {
+		if env.Libraries, err = s.GetLibraries(ctx, hashes); err != nil {
+			return 0, nil, err
+		}
+	}
+	exitCode, result, err := s.options.MethodEmulator.RunGetMethod(ctx, env, methodID, params)
+	if err != nil {
+		return 0, nil, err
+	}
+	decoder := tlb.NewDecoder().WithLibraryResolver(func(hash tlb.Bits256) (*boc.Cell, error) {
+		libs, err := s.GetLibraries(ctx, []ton.Bits256{ton.Bits256(hash)})
+		if err != nil {
+			return nil, err
+		}
+		lib, ok := libs[ton.Bits256(hash)]
+		if !ok {
+			return nil, fmt.Errorf("library %x not found", hash)
+		}
+		return lib, nil
+	})
+	var stack tlb.VmStack
+	if err := decoder.Unmarshal(result, &stack); err != nil {
+		return 0, nil, err
+	}
+	return exitCode, stack, nil
+}
+
+// RunSmcMethod runs a get method of the account by its name.
+func (s *Snapshot) RunSmcMethod(ctx context.Context, accountID ton.AccountID, method string, params tlb.VmStack) (uint32, tlb.VmStack, error) {
+	return s.RunSmcMethodByID(ctx, accountID, utils.MethodIdFromName(method), params)
+}
+
+// GetSeqno returns a seqno of the wallet or 0 if the wallet is not deployed.
+func (s *Snapshot) GetSeqno(ctx context.Context, accountID ton.AccountID) (uint32, error) {
+	errCode, stack, err := s.RunSmcMethod(ctx, accountID, "seqno", tlb.VmStack{})
+	if err != nil {
+		return 0, err
+	}
+	if errCode == accountNotFoundExitCode {
+		return 0, nil
+	}
+	if errCode != 0 && errCode != 1 {
+		return 0, fmt.Errorf("method execution failed with code: %v", errCode)
+	}
+	_, res, err := abi.DecodeSeqnoResult(stack)
+	if err != nil {
+		return 0, err
+	}
+	return res.(abi.SeqnoResult).State, nil
+}
+
+// SendMessage always fails with ErrReadOnly.
+func (s *Snapshot) SendMessage(ctx context.Context, payload []byte) (uint32, error) {
+	return 0, ErrReadOnly
+}
+
+// GetJettonWallet returns an address of the owner's jetton wallet.
+func (s *Snapshot) GetJettonWallet(ctx context.Context, master, owner ton.AccountID) (ton.AccountID, error) {
+	_, res, err := abi.GetWalletAddress(ctx, s, master, owner.ToMsgAddress())
+	if err != nil {
+		return ton.AccountID{}, err
+	}
+	addr, err := ton.AccountIDFromTlb(res.(abi.GetWalletAddressResult).JettonWalletAddress)
+	if err != nil {
+		return ton.AccountID{}, err
+	}
+	if addr == nil {
+		return ton.AccountID{}, fmt.Errorf("address none")
+	}
+	return *addr, nil
+}
+
+// GetJettonData returns metadata of the jetton, only onchain metadata is supported.
+func (s *Snapshot) GetJettonData(ctx context.Context, master ton.AccountID) (tep64.Metadata, error) {
+	_, res, err := abi.GetJettonData(ctx, s, master)
+	if err != nil {
+		return tep64.Metadata{}, err
+	}
+	content := boc.Cell(res.(abi.GetJettonDataResult).JettonContent)
+	var fullContent tlb.FullContent
+	if err := tlb.Unmarshal(&content, &fullContent); err != nil {
+		return tep64.Metadata{}, err
+	}
+	if fullContent.SumType != "Onchain" {
+		return tep64.Metadata{}, liteapi.ErrOnchainContentOnly
+	}
+	return tep64.ConvertOnchainData(fullContent)
+}
+
+// GetJettonBalance returns a balance of the jetton wallet or 0 if the wallet is not deployed.
+func (s *Snapshot) GetJettonBalance(ctx context.Context, jettonWallet ton.AccountID) (*big.Int, error) {
+	errCode, stack, err := s.RunSmcMethod(ctx, jettonWallet, "get_wallet_data", tlb.VmStack{})
+	if err != nil {
+		return nil, err
+	}
+	if errCode == accountNotFoundExitCode {
+		return big.NewInt(0), nil
+	}
+	if errCode != 0 && errCode != 1 {
+		return nil, fmt.Errorf("method execution failed with code: %v", errCode)
+	}
+	_, res, err := abi.DecodeGetWalletDataResult(stack)
+	if err != nil {
+		return nil, err
+	}
+	balance := big.Int(res.(abi.GetWalletDataResult).Balance)
+	return &balance, nil
+}
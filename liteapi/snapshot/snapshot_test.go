@@ -0,0 +1,370 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type testAccountItem struct {
+	Extra   tlb.DepthBalanceInfo
+	Account tlb.ShardAccount
+}
+
+type testShardState struct {
+	Magic           tlb.Magic `tlb:"shard_state#9023afe2"`
+	GlobalID        int32
+	ShardID         tlb.ShardIdent
+	SeqNo           uint32
+	VertSeqNo       uint32
+	GenUtime        uint32
+	GenLt           uint64
+	MinRefMcSeqno   uint32
+	OutMsgQueueInfo boc.Cell `tlb:"^"`
+	BeforeSplit     bool
+	Accounts        boc.Cell  `tlb:"^"`
+	Other           boc.Cell  `tlb:"^"`
+	Custom          *boc.Cell `tlb:"maybe^"`
+}
+
+type testShardAccounts struct {
+	Accounts tlb.HashmapE[tlb.Bits256, testAccountItem]
+	Extra    tlb.DepthBalanceInfo
+}
+
+type testStateOther struct {
+	OverloadHistory    uint64
+	UnderloadHistory   uint64
+	TotalBalance       tlb.CurrencyCollection
+	TotalValidatorFees tlb.CurrencyCollection
+	Libraries          tlb.HashmapE[tlb.Bits256, tlb.LibDescr]
+	MasterRef          bool
+}
+
+type testMcStateExtra struct {
+	Magic       tlb.Magic `tlb:"masterchain_state_extra#cc26"`
+	ShardHashes tlb.HashmapE[tlb.Uint32, tlb.Ref[boc.Cell]]
+	ConfigAddr  tlb.Bits256
+	Config      tlb.Hashmap[tlb.Uint32, tlb.Ref[boc.Cell]] `tlb:"^"`
+	Other       boc.Cell                                   `tlb:"^"`
+}
+
+func activeAccount(t *testing.T, accountID ton.AccountID, balance uint64, code, data *boc.Cell) testAccountItem {
+	t.Helper()
+	var account tlb.Account
+	account.SumType = "Account"
+	account.Account.Addr = accountID.ToMsgAddress()
+	account.Account.Storage.Balance.Grams = tlb.Grams(balance)
+	account.Account.Storage.State.SumType = "AccountActive"
+	init := &account.Account.Storage.State.AccountActive.StateInit
+	init.Code.Exists, init.Code.Value.Value = true, *code
+	init.Data.Exists, init.Data.Value.Value = true, *data
+	return testAccountItem{Account: tlb.ShardAccount{Account: account, LastTransLt: 100}}
+}
+
+func stateCell(t *testing.T, workchain int32, seqno uint32, accounts map[ton.AccountID]testAccountItem, other, custom *boc.Cell) *boc.Cell {
+	t.Helper()
+	var keys []tlb.Bits256
+	var items []testAccountItem
+	for accountID, item := range accounts {
+		keys = append(keys, tlb.Bits256(accountID.Address))
+		items = append(items, item)
+	}
+	return testutil.MustMarshal(t, testShardState{
+		ShardID:         tlb.ShardIdent{WorkchainID: workchain},
+		SeqNo:           seqno,
+		GenUtime:        1700000000 + seqno,
+		OutMsgQueueInfo: *testutil.CellWithUint(t, 1),
+		Accounts:        *testutil.MustMarshal(t, testShardAccounts{Accounts: tlb.NewHashmapE(keys, items)}),
+		Other:           *other,
+		Custom:          custom,
+	})
+}
+
+type testStates struct {
+	masterchainState []byte
+	shardState       []byte
+	mcAccount        ton.AccountID
+	shardAccount     ton.AccountID
+	code, lib        *boc.Cell
+}
+
+func newTestStates(t *testing.T) testStates {
+	t.Helper()
+	lib := testutil.CellWithUint(t, 42)
+	libHash, err := lib.Hash256()
+	if err != nil {
+		t.Fatalf("Hash256() failed: %v", err)
+	}
+	// the code of the shard account is a library cell.
+	code := boc.NewCellExotic(boc.LibraryCell)
+	if err := code.WriteUint(uint64(boc.LibraryCell), 8); err != nil {
+		t.Fatalf("WriteUint() failed: %v", err)
+	}
+	if err := code.WriteBytes(libHash[:]); err != nil {
+		t.Fatalf("WriteBytes() failed: %v", err)
+	}
+	states := testStates{
+		mcAccount:    ton.AccountID{Workchain: -1, Address: ton.Bits256{0x33}},
+		shardAccount: ton.AccountID{Workchain: 0, Address: ton.Bits256{0x44}},
+		code:         code,
+		lib:          lib,
+	}
+
+	var desc tlb.ShardDesc
+	desc.SumType = "New"
+	desc.New.SeqNo = 5
+	desc.New.NextValidatorShard = int64(-1 << 63)
+	leaf := boc.NewCell()
+	if err := leaf.WriteBit(false); err != nil {
+		t.Fatalf("WriteBit() failed: %v", err)
+	}
+	if err := tlb.Marshal(leaf, desc); err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	extra := testutil.MustMarshal(t, testMcStateExtra{
+		ShardHashes: tlb.NewHashmapE([]tlb.Uint32{0}, []tlb.Ref[boc.Cell]{{Value: *leaf}}),
+		Config:      tlb.NewHashmap([]tlb.Uint32{0, 34}, []tlb.Ref[boc.Cell]{{Value: *testutil.CellWithUint(t, 1)}, {Value: *testutil.CellWithUint(t, 2)}}),
+		Other:       *testutil.CellWithUint(t, 3),
+	})
+	mcOther := testutil.MustMarshal(t, testStateOther{
+		Libraries: tlb.NewHashmapE([]tlb.Bits256{tlb.Bits256(libHash)}, []tlb.LibDescr{{
+			Lib:        *lib,
+			Publishers: tlb.NewHashmap([]tlb.Bits256{tlb.Bits256(states.mcAccount.Address)}, []struct{}{{}}),
+		}}),
+	})
+	mcAccounts := map[ton.AccountID]testAccountItem{
+		states.mcAccount: activeAccount(t, states.mcAccount, 500, testutil.CellWithUint(t, 5), testutil.CellWithUint(t, 6)),
+	}
+	states.masterchainState = testutil.MustBoc(t, stateCell(t, -1, 100, mcAccounts, mcOther, extra))
+	shardAccounts := map[ton.AccountID]testAccountItem{
+		states.shardAccount: activeAccount(t, states.shardAccount, 1000, code, testutil.CellWithUint(t, 7)),
+	}
+	states.shardState = testutil.MustBoc(t, stateCell(t, 0, 5, shardAccounts, testutil.MustMarshal(t, testStateOther{}), nil))
+	return states
+}
+
+// fakeMethodEmulator returns the seqno 7 and remembers the environment of the last call.
+type fakeMethodEmulator struct {
+	env ton.MethodEnv
+}
+
+func (e *fakeMethodEmulator) RunGetMethod(ctx context.Context, env ton.MethodEnv, methodID int, params tlb.VmStack) (uint32, *boc.Cell, error) {
+	e.env = env
+	stack := boc.NewCell()
+	err := tlb.Marshal(stack, tlb.VmStack{{SumType: "VmStkTinyInt", VmStkTinyInt: 7}})
+	return 0, stack, err
+}
+
+func TestSnapshot_GetAccountState(t *testing.T) {
+	states := newTestStates(t)
+	s, err := New(states.masterchainState, [][]byte{states.shardState})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if want := (ton.BlockID{Workchain: -1, Shard: 1 << 63, Seqno: 100}); s.BlockID() != want {
+		t.Fatalf("want block %v, got: %v", want, s.BlockID())
+	}
+	tests := []struct {
+		name        string
+		accountID   ton.AccountID
+		wantBalance uint64
+		wantNone    bool
+		wantErr     error
+	}{
+		{
+			name:        "masterchain account",
+			accountID:   states.mcAccount,
+			wantBalance: 500,
+		},
+		{
+			name:        "basechain account",
+			accountID:   states.shardAccount,
+			wantBalance: 1000,
+		},
+		{
+			name:      "nonexistent account",
+			accountID: ton.AccountID{Workchain: 0, Address: ton.Bits256{0x45}},
+			wantNone:  true,
+		},
+		{
+			name:      "unknown workchain",
+			accountID: ton.AccountID{Workchain: 1, Address: ton.Bits256{0x44}},
+			wantErr:   ErrShardNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := s.GetAccountState(context.Background(), tt.accountID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAccountState() failed: %v", err)
+			}
+			if tt.wantNone {
+				if state.Account.SumType != "AccountNone" {
+					t.Fatalf("want AccountNone, got: %v", state.Account.SumType)
+				}
+				return
+			}
+			if state.Account.SumType != "Account" || uint64(state.Account.Account.Storage.Balance.Grams) != tt.wantBalance {
+				t.Fatalf("want balance %v, got: %+v", tt.wantBalance, state.Account)
+			}
+			if state.LastTransLt != 100 {
+				t.Fatalf("want last transaction lt 100, got: %v", state.LastTransLt)
+			}
+		})
+	}
+}
+
+func TestSnapshot_GetLibrariesAndConfig(t *testing.T) {
+	states := newTestStates(t)
+	s, err := New(states.masterchainState, nil)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	libHash, _ := states.lib.Hash256()
+	libs, err := s.GetLibraries(context.Background(), []ton.Bits256{libHash, {1}})
+	if err != nil {
+		t.Fatalf("GetLibraries() failed: %v", err)
+	}
+	if len(libs) != 1 {
+		t.Fatalf("want 1 library, got: %v", len(libs))
+	}
+	if hash, _ := libs[libHash].Hash256(); hash != libHash {
+		t.Fatalf("want library %x, got: %x", libHash, hash)
+	}
+	config, err := s.GetConfigAll(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("GetConfigAll() failed: %v", err)
+	}
+	if keys := config.Config.Keys(); len(keys) != 2 || keys[0] != 0 || keys[1] != 34 {
+		t.Fatalf("want config params 0 and 34, got: %v", keys)
+	}
+	if _, err := s.GetAccountState(context.Background(), states.shardAccount); !errors.Is(err, ErrShardNotFound) {
+		t.Fatalf("want ErrShardNotFound without shard states, got: %v", err)
+	}
+}
+
+func TestSnapshot_RunSmcMethod(t *testing.T) {
+	states := newTestStates(t)
+	ctx := context.Background()
+	emulator := &fakeMethodEmulator{}
+	s, err := New(states.masterchainState, [][]byte{states.shardState}, WithMethodEmulator(emulator))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	seqno, err := s.GetSeqno(ctx, states.shardAccount)
+	if err != nil {
+		t.Fatalf("GetSeqno() failed: %v", err)
+	}
+	if seqno != 7 {
+		t.Fatalf("want seqno 7, got: %v", seqno)
+	}
+	env := emulator.env
+	codeHash, _ := states.code.Hash256()
+	if hash, _ := env.Code.Hash256(); hash != codeHash {
+		t.Fatalf("method is executed with another code")
+	}
+	libHash, _ := states.lib.Hash256()
+	if env.AccountID != states.shardAccount || env.Balance != 1000 || env.UnixTime != 1700000100 || env.Config == nil {
+		t.Fatalf("unexpected environment: %+v", env)
+	}
+	if _, ok := env.Libraries[libHash]; !ok || len(env.Libraries) != 1 {
+		t.Fatalf("want the code library in the environment, got: %v", env.Libraries)
+	}
+
+	exitCode, _, err := s.RunSmcMethod(ctx, ton.AccountID{Workchain: 0, Address: ton.Bits256{0x45}}, "seqno", tlb.VmStack{})
+	if err != nil {
+		t.Fatalf("RunSmcMethod() failed: %v", err)
+	}
+	if exitCode != accountNotFoundExitCode {
+		t.Fatalf("want exit code %x for nonexistent account, got: %x", accountNotFoundExitCode, exitCode)
+	}
+	if seqno, err := s.GetSeqno(ctx, ton.AccountID{Workchain: 0, Address: ton.Bits256{0x45}}); err != nil || seqno != 0 {
+		t.Fatalf("want seqno 0 of nonexistent wallet, got: %v, %v", seqno, err)
+	}
+
+	withoutEmulator, err := New(states.masterchainState, [][]byte{states.shardState})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := withoutEmulator.GetSeqno(ctx, states.shardAccount); !errors.Is(err, ErrNoMethodEmulator) {
+		t.Fatalf("want ErrNoMethodEmulator, got: %v", err)
+	}
+	if _, err := s.SendMessage(ctx, nil); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("want ErrReadOnly, got: %v", err)
+	}
+}
+
+func TestNew_inconsistentStates(t *testing.T) {
+	states := newTestStates(t)
+	if _, err := New(states.shardState, nil); err == nil {
+		t.Fatalf("want error for a shard state instead of a masterchain one")
+	}
+	if _, err := New(states.masterchainState, [][]byte{states.masterchainState}); err == nil {
+		t.Fatalf("want error for a state the masterchain block doesn't refer to")
+	}
+}
+
+type fakeStateSource struct {
+	states map[ton.BlockIDExt][]byte
+}
+
+func (s *fakeStateSource) GetState(ctx context.Context, blockID ton.BlockIDExt) ([]byte, ton.Bits256, ton.Bits256, error) {
+	data, ok := s.states[blockID]
+	if !ok {
+		return nil, ton.Bits256{}, ton.Bits256{}, errors.New("state not found")
+	}
+	return data, ton.Bits256{}, ton.Bits256{}, nil
+}
+
+func (s *fakeStateSource) GetAllShardsInfo(ctx context.Context, blockID ton.BlockIDExt) ([]ton.BlockIDExt, error) {
+	return []ton.BlockIDExt{{BlockID: ton.BlockID{Workchain: 0, Shard: 1 << 63, Seqno: 5}}}, nil
+}
+
+func TestDownload(t *testing.T) {
+	states := newTestStates(t)
+	mcBlock := ton.BlockIDExt{BlockID: ton.BlockID{Workchain: -1, Shard: 1 << 63, Seqno: 100}}
+	shardBlock := ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 1 << 63, Seqno: 5}}
+	source := &fakeStateSource{states: map[ton.BlockIDExt][]byte{
+		mcBlock:    states.masterchainState,
+		shardBlock: states.shardState,
+	}}
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	if err := download(context.Background(), source, mcBlock, dir); err != nil {
+		t.Fatalf("download() failed: %v", err)
+	}
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer s.Close()
+	state, err := s.GetAccountState(context.Background(), states.shardAccount)
+	if err != nil {
+		t.Fatalf("GetAccountState() failed: %v", err)
+	}
+	if state.Account.SumType != "Account" {
+		t.Fatalf("want existing account, got: %v", state.Account.SumType)
+	}
+
+	// a lite server returns a state of another block.
+	source.states[shardBlock] = states.masterchainState
+	if err := download(context.Background(), source, mcBlock, t.TempDir()); err == nil {
+		t.Fatalf("want error for a state of another block")
+	}
+	if _, err := os.Stat(filepath.Join(dir, masterchainStateFile)); err != nil {
+		t.Fatalf("masterchain state is not stored: %v", err)
+	}
+}
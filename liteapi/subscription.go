@@ -0,0 +1,356 @@
+package liteapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// ErrTruncatedHistory is returned by TransactionSubscriber
+// when lite servers don't keep transactions following a subscription cursor.
+var ErrTruncatedHistory = errors.New("transaction history is truncated")
+
+// AccountCursor points to the last transaction of an account delivered to a subscriber.
+// The zero cursor means that the account has no transactions yet,
+// so all its transactions are delivered.
+type AccountCursor struct {
+	LastTransLt   uint64
+	LastTransHash ton.Bits256
+}
+
+// TransactionCursor returns a cursor pointing to the given transaction.
+// A subscription restarted with this cursor continues with the next transaction of the account.
+func TransactionCursor(tx ton.Transaction) AccountCursor {
+	return AccountCursor{LastTransLt: tx.Lt, LastTransHash: ton.Bits256(tx.Hash())}
+}
+
+// SubscriberOptions holds parameters to configure a TransactionSubscriber.
+type SubscriberOptions struct {
+	// SkipTruncatedHistory makes the subscriber continue from the oldest available transaction
+	// if lite servers don't keep transactions following a cursor.
+	// Otherwise, the subscriber stops with ErrTruncatedHistory.
+	SkipTruncatedHistory bool
+	// RetryDelay is a pause before restarting the block stream after a failure.
+	RetryDelay time.Duration
+}
+
+type SubscriberOption func(o *SubscriberOptions)
+
+// WithSkipTruncatedHistory makes the subscriber skip transactions lite servers don't keep anymore.
+func WithSkipTruncatedHistory() SubscriberOption {
+	return func(o *SubscriberOptions) {
+		o.SkipTruncatedHistory = true
+	}
+}
+
+// transactionSource is a part of Client used by TransactionSubscriber.
+type transactionSource interface {
+	blockSource
+	// lastTransaction returns a cursor pointing to the last transaction of the account in the given block.
+	lastTransaction(ctx context.Context, block ton.BlockIDExt, accountID ton.AccountID) (AccountCursor, error)
+	GetTransactions(ctx context.Context, count uint32, accountID ton.AccountID, lt uint64, hash ton.Bits256) ([]ton.Transaction, error)
+}
+
+func (s clientBlockSource) lastTransaction(ctx context.Context, block ton.BlockIDExt, accountID ton.AccountID) (AccountCursor, error) {
+	state, err := s.Client.WithBlock(block).GetAccountState(ctx, accountID)
+	if err != nil {
+		return AccountCursor{}, err
+	}
+	return AccountCursor{LastTransLt: state.LastTransLt, LastTransHash: ton.Bits256(state.LastTransHash)}, nil
+}
+
+type subscription struct {
+	cursor AccountCursor
+	// pending is set if the account's state has to be polled to find out its last transaction.
+	pending bool
+}
+
+// TransactionSubscriber delivers new transactions of subscribed accounts.
+//
+// Each transaction is delivered exactly once, transactions of an account are delivered in lt order.
+// The subscriber follows the blockchain with BlockStreamer and
+// takes transactions of subscribed accounts from new blocks.
+// Transactions made before the stream started are requested with GetTransactions
+// starting from the last transaction of an account in a masterchain block,
+// so the subscriber polls states of accounts when they are subscribed and when the stream fails.
+// With WithDetectArchiveNodes, old transactions are requested from archive nodes.
+type TransactionSubscriber struct {
+	source  transactionSource
+	options SubscriberOptions
+
+	mu            sync.Mutex
+	subscriptions map[ton.AccountID]*subscription
+}
+
+// NewTransactionSubscriber returns a subscriber getting transactions with the given client.
+func NewTransactionSubscriber(client *Client, opts ...SubscriberOption) *TransactionSubscriber {
+	return newTransactionSubscriber(clientBlockSource{Client: client}, opts...)
+}
+
+func newTransactionSubscriber(source transactionSource, opts ...SubscriberOption) *TransactionSubscriber {
+	options := SubscriberOptions{
+		RetryDelay: defaultStreamerRetryDelay,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return &TransactionSubscriber{
+		source:        source,
+		options:       options,
+		subscriptions: map[ton.AccountID]*subscription{},
+	}
+}
+
+// Subscribe starts delivering transactions of the account following the given cursor.
+// Subscribing an already subscribed account resets its cursor.
+// It is safe to call Subscribe while the subscriber is running.
+func (s *TransactionSubscriber) Subscribe(accountID ton.AccountID, cursor AccountCursor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[accountID] = &subscription{cursor: cursor, pending: true}
+}
+
+// Unsubscribe stops delivering transactions of the account.
+func (s *TransactionSubscriber) Unsubscribe(accountID ton.AccountID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, accountID)
+}
+
+// Cursor returns a cursor pointing to the last delivered transaction of the account.
+func (s *TransactionSubscriber) Cursor(accountID ton.AccountID) (AccountCursor, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subscriptions[accountID]
+	if !ok {
+		return AccountCursor{}, false
+	}
+	return sub.cursor, true
+}
+
+// streamError is an error of BlockStreamer that makes TransactionSubscriber restart the stream.
+type streamError struct {
+	err error
+}
+
+func (e streamError) Error() string {
+	return fmt.Sprintf("block stream: %v", e.err)
+}
+
+func (e streamError) Unwrap() error {
+	return e.err
+}
+
+// Run delivers transactions to the given channel until the context is canceled or an error occurs.
+// If the block stream fails, the subscriber polls states of all accounts and restarts the stream.
+// Run closes the channel before returning.
+func (s *TransactionSubscriber) Run(ctx context.Context, out chan<- ton.Transaction) error {
+	defer close(out)
+	for {
+		err := s.follow(ctx, out)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var streamErr streamError
+		if !errors.As(err, &streamErr) {
+			return err
+		}
+		select {
+		case <-time.After(s.options.RetryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// follow polls states of all accounts at the masterchain head and
+// then delivers transactions from blocks following the head.
+func (s *TransactionSubscriber) follow(ctx context.Context, out chan<- ton.Transaction) error {
+	head, err := retry(ctx, s.options.RetryDelay, func() (ton.BlockIDExt, error) {
+		return s.source.GetMasterchainInfo(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for _, sub := range s.subscriptions {
+		sub.pending = true
+	}
+	s.mu.Unlock()
+	if err := s.process(ctx, head, nil, out); err != nil {
+		return err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	streamer := newBlockStreamer(s.source, WithStreamCursor(head), WithStreamTransactionsOnly())
+	streamer.options.RetryDelay = s.options.RetryDelay
+	blocks := make(chan StreamedMasterchainBlock)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- streamer.Run(streamCtx, blocks)
+	}()
+	for block := range blocks {
+		txs := block.Transactions
+		for _, shardBlock := range block.ShardBlocks {
+			txs = append(txs, shardBlock.Transactions...)
+		}
+		if err := s.process(ctx, block.ID, txs, out); err != nil {
+			return err
+		}
+	}
+	return streamError{err: <-errCh}
+}
+
+// accountUpdate describes transactions of an account found in a masterchain block.
+type accountUpdate struct {
+	accountID ton.AccountID
+	cursor    AccountCursor
+	pending   bool
+	// last is the last transaction of the account in the masterchain block.
+	last AccountCursor
+	// known are transactions of the account taken from new blocks.
+	known map[ton.Bits256]ton.Transaction
+}
+
+// process delivers transactions of subscribed accounts made up to the given masterchain block.
+// txs are transactions from blocks committed by the masterchain block.
+func (s *TransactionSubscriber) process(ctx context.Context, mcBlock ton.BlockIDExt, txs []ton.Transaction, out chan<- ton.Transaction) error {
+	s.mu.Lock()
+	updates := map[ton.AccountID]*accountUpdate{}
+	for accountID, sub := range s.subscriptions {
+		if sub.pending {
+			updates[accountID] = &accountUpdate{accountID: accountID, cursor: sub.cursor, pending: true}
+		}
+	}
+	for _, tx := range txs {
+		accountID := ton.AccountID{Workchain: tx.BlockID.Workchain, Address: ton.Bits256(tx.AccountAddr)}
+		sub, ok := s.subscriptions[accountID]
+		if !ok || tx.Lt <= sub.cursor.LastTransLt {
+			continue
+		}
+		update, ok := updates[accountID]
+		if !ok {
+			update = &accountUpdate{accountID: accountID, cursor: sub.cursor}
+			updates[accountID] = update
+		}
+		if update.known == nil {
+			update.known = map[ton.Bits256]ton.Transaction{}
+		}
+		update.known[ton.Bits256(tx.Hash())] = tx
+		if tx.Lt > update.last.LastTransLt {
+			update.last = TransactionCursor(tx)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, update := range updates {
+		if !update.pending {
+			continue
+		}
+		last, err := retry(ctx, s.options.RetryDelay, func() (AccountCursor, error) {
+			return s.source.lastTransaction(ctx, mcBlock, update.accountID)
+		})
+		if err != nil {
+			return err
+		}
+		update.last = last
+	}
+	sorted := make([]*accountUpdate, 0, len(updates))
+	for _, update := range updates {
+		sorted = append(sorted, update)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].last.LastTransLt != sorted[j].last.LastTransLt {
+			return sorted[i].last.LastTransLt < sorted[j].last.LastTransLt
+		}
+		return sorted[i].accountID.String() < sorted[j].accountID.String()
+	})
+	for _, update := range sorted {
+		chain, err := s.transactionsSince(ctx, update)
+		if err != nil {
+			return err
+		}
+		cursor := update.cursor
+		for _, tx := range chain {
+			select {
+			case out <- tx:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			next := TransactionCursor(tx)
+			if !s.advance(update.accountID, cursor, next) {
+				break
+			}
+			cursor = next
+		}
+		if update.pending {
+			s.mu.Lock()
+			if sub, ok := s.subscriptions[update.accountID]; ok && sub.cursor == cursor {
+				sub.pending = false
+			}
+			s.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// advance moves the account's cursor unless the account was resubscribed or unsubscribed.
+func (s *TransactionSubscriber) advance(accountID ton.AccountID, from, to AccountCursor) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subscriptions[accountID]
+	if !ok || sub.cursor != from {
+		return false
+	}
+	sub.cursor = to
+	return true
+}
+
+// transactionsSince returns transactions of an account following its cursor up to its last transaction
+// ordered by lt.
+func (s *TransactionSubscriber) transactionsSince(ctx context.Context, update *accountUpdate) ([]ton.Transaction, error) {
+	var chain []ton.Transaction
+	lt, hash := update.last.LastTransLt, update.last.LastTransHash
+	for lt > update.cursor.LastTransLt {
+		if tx, ok := update.known[hash]; ok {
+			chain = append(chain, tx)
+			lt, hash = tx.PrevTransLt, ton.Bits256(tx.PrevTransHash)
+			continue
+		}
+		txs, err := retry(ctx, s.options.RetryDelay, func() ([]ton.Transaction, error) {
+			return s.source.GetTransactions(ctx, maxTransactionCount, update.accountID, lt, hash)
+		})
+		if truncatedHistory(err) {
+			if !s.options.SkipTruncatedHistory {
+				return nil, fmt.Errorf("%w: account %v, lt %v", ErrTruncatedHistory, update.accountID, lt)
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(txs) == 0 {
+			return nil, fmt.Errorf("account %v has no transaction %v:%x", update.accountID, lt, hash)
+		}
+		for _, tx := range txs {
+			if tx.Lt <= update.cursor.LastTransLt {
+				break
+			}
+			chain = append(chain, tx)
+			lt, hash = tx.PrevTransLt, ton.Bits256(tx.PrevTransHash)
+		}
+	}
+	if lt < update.cursor.LastTransLt || (lt == update.cursor.LastTransLt && hash != update.cursor.LastTransHash) {
+		return nil, fmt.Errorf("cursor %v:%x is not in the history of account %v", update.cursor.LastTransLt, update.cursor.LastTransHash, update.accountID)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
@@ -0,0 +1,237 @@
+package liteapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+type fakeTransactionSource struct {
+	*fakeBlockSource
+
+	mu    sync.Mutex
+	state map[ton.AccountID]AccountCursor
+	// history contains transactions of accounts, the latest one goes first.
+	history map[ton.AccountID][]ton.Transaction
+	// truncatedBefore makes GetTransactions fail for transactions with smaller lt.
+	truncatedBefore uint64
+	calls           int
+}
+
+func (s *fakeTransactionSource) lastTransaction(ctx context.Context, block ton.BlockIDExt, accountID ton.AccountID) (AccountCursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state[accountID], nil
+}
+
+func (s *fakeTransactionSource) GetTransactions(ctx context.Context, count uint32, accountID ton.AccountID, lt uint64, hash ton.Bits256) ([]ton.Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls += 1
+	if lt < s.truncatedBefore {
+		return nil, liteclient.LiteServerErrorC{Code: 0xfffffe70, Message: "truncated"} // -400
+	}
+	history := s.history[accountID]
+	for i, tx := range history {
+		if tx.Lt == lt && ton.Bits256(tx.Hash()) == hash {
+			var res []ton.Transaction
+			for _, tx := range history[i:min(i+int(count), len(history))] {
+				if tx.Lt < s.truncatedBefore {
+					break
+				}
+				res = append(res, tx)
+			}
+			return res, nil
+		}
+	}
+	return nil, nil
+}
+
+func txLts(txs []ton.Transaction) []uint64 {
+	var lts []uint64
+	for _, tx := range txs {
+		lts = append(lts, tx.Lt)
+	}
+	return lts
+}
+
+func TestTransactionSubscriber_process(t *testing.T) {
+	txs := decodeTestTransactions(t)
+	accountID := ton.AccountID{Workchain: -1, Address: ton.Bits256(txs[0].AccountAddr)}
+	another := ton.AccountID{Workchain: -1, Address: ton.Bits256{1}}
+	mcBlock := testBlockID(-1, testShardFull, 100)
+	for i := range txs {
+		txs[i].BlockID = mcBlock
+	}
+	// the oldest transaction goes last.
+	beforeAll := AccountCursor{LastTransLt: txs[2].PrevTransLt, LastTransHash: ton.Bits256(txs[2].PrevTransHash)}
+
+	tests := []struct {
+		name            string
+		opts            []SubscriberOption
+		cursor          AccountCursor
+		pending         bool
+		blockTxs        []ton.Transaction
+		truncatedBefore uint64
+		wantLts         []uint64
+		wantCalls       int
+		wantErr         error
+	}{
+		{
+			name:      "poll account state",
+			cursor:    beforeAll,
+			pending:   true,
+			wantLts:   []uint64{txs[2].Lt, txs[1].Lt, txs[0].Lt},
+			wantCalls: 1,
+		},
+		{
+			name:    "poll account without new transactions",
+			cursor:  TransactionCursor(txs[0]),
+			pending: true,
+		},
+		{
+			name:     "transactions from blocks",
+			cursor:   TransactionCursor(txs[2]),
+			blockTxs: []ton.Transaction{txs[0], txs[1]},
+			wantLts:  []uint64{txs[1].Lt, txs[0].Lt},
+		},
+		{
+			name:      "missing transactions are requested",
+			cursor:    beforeAll,
+			blockTxs:  []ton.Transaction{txs[0]},
+			wantLts:   []uint64{txs[2].Lt, txs[1].Lt, txs[0].Lt},
+			wantCalls: 1,
+		},
+		{
+			name:     "already delivered transactions are skipped",
+			cursor:   TransactionCursor(txs[1]),
+			blockTxs: []ton.Transaction{txs[0], txs[1], txs[2]},
+			wantLts:  []uint64{txs[0].Lt},
+		},
+		{
+			name:            "truncated history",
+			cursor:          beforeAll,
+			pending:         true,
+			truncatedBefore: txs[1].Lt,
+			wantCalls:       2,
+			wantErr:         ErrTruncatedHistory,
+		},
+		{
+			name:            "skip truncated history",
+			opts:            []SubscriberOption{WithSkipTruncatedHistory()},
+			cursor:          beforeAll,
+			pending:         true,
+			truncatedBefore: txs[1].Lt,
+			wantLts:         []uint64{txs[1].Lt, txs[0].Lt},
+			wantCalls:       2,
+		},
+		{
+			name:      "cursor is not in history",
+			cursor:    AccountCursor{LastTransLt: txs[1].Lt, LastTransHash: ton.Bits256{1}},
+			pending:   true,
+			wantCalls: 1,
+			wantErr:   errors.New("any"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeTransactionSource{
+				fakeBlockSource: newFakeBlockSource(),
+				state:           map[ton.AccountID]AccountCursor{accountID: TransactionCursor(txs[0])},
+				history:         map[ton.AccountID][]ton.Transaction{accountID: txs},
+				truncatedBefore: tt.truncatedBefore,
+			}
+			subscriber := newTransactionSubscriber(source, tt.opts...)
+			subscriber.Subscribe(accountID, tt.cursor)
+			subscriber.subscriptions[accountID].pending = tt.pending
+			subscriber.Subscribe(another, AccountCursor{})
+			subscriber.subscriptions[another].pending = false
+
+			out := make(chan ton.Transaction, 10)
+			err := subscriber.process(context.Background(), mcBlock, tt.blockTxs, out)
+			close(out)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				if errors.Is(tt.wantErr, ErrTruncatedHistory) && !errors.Is(err, ErrTruncatedHistory) {
+					t.Fatalf("want ErrTruncatedHistory, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("process() failed: %v", err)
+			}
+			var delivered []ton.Transaction
+			for tx := range out {
+				delivered = append(delivered, tx)
+			}
+			if lts := txLts(delivered); !reflect.DeepEqual(lts, tt.wantLts) {
+				t.Fatalf("want transactions: %v, got: %v", tt.wantLts, lts)
+			}
+			if source.calls != tt.wantCalls {
+				t.Fatalf("want %v GetTransactions calls, got: %v", tt.wantCalls, source.calls)
+			}
+			cursor, _ := subscriber.Cursor(accountID)
+			if len(delivered) > 0 && cursor != TransactionCursor(delivered[len(delivered)-1]) {
+				t.Fatalf("cursor is not advanced")
+			}
+			if subscriber.subscriptions[accountID].pending {
+				t.Fatalf("account is still pending")
+			}
+		})
+	}
+}
+
+func TestTransactionSubscriber_Run(t *testing.T) {
+	txs := decodeTestTransactions(t)
+	accountID := ton.AccountID{Workchain: -1, Address: ton.Bits256(txs[0].AccountAddr)}
+	mc := func(seqno uint32) ton.BlockIDExt { return testBlockID(-1, testShardFull, seqno) }
+
+	source := &fakeTransactionSource{
+		fakeBlockSource: newFakeBlockSource(),
+		state:           map[ton.AccountID]AccountCursor{accountID: TransactionCursor(txs[1])},
+		history:         map[ton.AccountID][]ton.Transaction{accountID: txs},
+	}
+	source.addMasterchainBlock(mc(100), mc(99))
+	source.addMasterchainBlock(mc(101), mc(100))
+	source.head = mc(100)
+
+	subscriber := newTransactionSubscriber(source)
+	subscriber.options.RetryDelay = time.Millisecond
+	subscriber.Subscribe(accountID, AccountCursor{LastTransLt: txs[2].PrevTransLt, LastTransHash: ton.Bits256(txs[2].PrevTransHash)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan ton.Transaction)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- subscriber.Run(ctx, out)
+	}()
+	var delivered []ton.Transaction
+	delivered = append(delivered, <-out, <-out)
+	// the block stream ends after block 101 and the subscriber restarts it polling the account again,
+	// the new transaction is delivered after the restart.
+	source.mu.Lock()
+	source.state[accountID] = TransactionCursor(txs[0])
+	source.mu.Unlock()
+	delivered = append(delivered, <-out)
+	select {
+	case tx := <-out:
+		t.Fatalf("unexpected transaction: %v", tx.Lt)
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if lts, want := txLts(delivered), []uint64{txs[2].Lt, txs[1].Lt, txs[0].Lt}; !reflect.DeepEqual(lts, want) {
+		t.Fatalf("want transactions: %v, got: %v", want, lts)
+	}
+}
@@ -0,0 +1,199 @@
+package liteapi
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteclient"
+	"github.com/caigou-xyz/tongo/tl"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// verifyTransactionChain checks that the given transactions are consecutive transactions of the account
+// going back in time starting from the transaction with the given lt and hash.
+// Each transaction must be the one referenced by the previous transaction's PrevTransLt and PrevTransHash.
+func verifyTransactionChain(accountID ton.AccountID, lt uint64, hash ton.Bits256, txs []ton.Transaction) error {
+	for i, tx := range txs {
+		if ton.Bits256(tx.AccountAddr) != accountID.Address {
+			return fmt.Errorf("%w: transaction %v belongs to another account", ErrInvalidProof, i)
+		}
+		if tx.Lt != lt || ton.Bits256(tx.Hash()) != hash {
+			return fmt.Errorf("%w: transaction %v is %v:%x instead of %v:%x", ErrInvalidProof, i, tx.Lt, tx.Hash(), lt, hash)
+		}
+		lt, hash = tx.PrevTransLt, ton.Bits256(tx.PrevTransHash)
+	}
+	return nil
+}
+
+// checkTransactionProof checks that the transaction with the given lt and hash
+// is included into the given block as a transaction of the account.
+// The proof is a merkle proof of the block returned by liteServer.getOneTransaction
+// or liteServer.listBlockTransactionsExt.
+func checkTransactionProof(blockID ton.BlockIDExt, accountID ton.AccountID, lt uint64, hash ton.Bits256, proof *boc.Cell) error {
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		return fmt.Errorf("block %v: %w", blockID, err)
+	}
+	return checkBlockTransaction(blockID, root, accountID, lt, hash)
+}
+
+// checkBlockTransaction checks that the transaction with the given lt and hash
+// is included into the block as a transaction of the account.
+// root is the root of the block's tree returned by boc.VerifyMerkleProof,
+// it can be used to check any number of transactions.
+func checkBlockTransaction(blockID ton.BlockIDExt, root *boc.Cell, accountID ton.AccountID, lt uint64, hash ton.Bits256) error {
+	shard, err := ton.ParseShardID(int64(blockID.Shard))
+	if err != nil {
+		return err
+	}
+	if blockID.Workchain != accountID.Workchain || !shard.MatchAccountID(accountID) {
+		return fmt.Errorf("block %v can't contain account %v", blockID, accountID)
+	}
+	// block#11ef55aa global_id:int32
+	// info:^BlockInfo value_flow:^ValueFlow
+	// state_update:^(MERKLE_UPDATE ShardState)
+	// extra:^BlockExtra = Block;
+	refs := root.Refs()
+	if len(refs) != 4 {
+		return fmt.Errorf("block %v: invalid block header", blockID)
+	}
+	// block_extra in_msg_descr:^InMsgDescr out_msg_descr:^OutMsgDescr account_blocks:^ShardAccountBlocks ... = BlockExtra;
+	extra := refs[3]
+	if extra.CellType() == boc.PrunedBranchCell {
+		return fmt.Errorf("block %v: block extra is pruned", blockID)
+	}
+	extra.ResetCounters()
+	if magic, err := extra.ReadUint(32); err != nil || magic != blockExtraMagic {
+		return fmt.Errorf("block %v: invalid block extra", blockID)
+	}
+	extraRefs := extra.Refs()
+	if len(extraRefs) < 3 {
+		return fmt.Errorf("block %v: invalid block extra", blockID)
+	}
+	accountBlocks := extraRefs[2]
+	if accountBlocks.CellType() == boc.PrunedBranchCell {
+		return fmt.Errorf("block %v: account blocks are pruned", blockID)
+	}
+	accountBlocks.ResetCounters()
+	// _ (HashmapAugE 256 AccountBlock CurrencyCollection) = ShardAccountBlocks;
+	notEmpty, err := accountBlocks.ReadBit()
+	if err != nil {
+		return err
+	}
+	if !notEmpty {
+		return fmt.Errorf("block %v has no transactions", blockID)
+	}
+	accountsRoot, err := accountBlocks.NextRef()
+	if err != nil {
+		return err
+	}
+	key := boc.NewBitString(256)
+	if err := key.WriteBytes(accountID.Address[:]); err != nil {
+		return err
+	}
+	accountBlock, err := tlb.FindHashmapValue(accountsRoot, key)
+	if err != nil {
+		return err
+	}
+	if accountBlock == nil {
+		return fmt.Errorf("block %v has no transactions of account %v", blockID, accountID)
+	}
+	// acc_trans#5 account_addr:bits256
+	// transactions:(HashmapAug 64 ^Transaction CurrencyCollection)
+	// state_update:^(HASH_UPDATE Account) = AccountBlock;
+	if err := skipCurrencyCollection(accountBlock); err != nil {
+		return err
+	}
+	var header struct {
+		Magic       tlb.Magic `tlb:"acc_trans#5"`
+		AccountAddr tlb.Bits256
+	}
+	if err := tlb.Unmarshal(accountBlock, &header); err != nil {
+		return err
+	}
+	if ton.Bits256(header.AccountAddr) != accountID.Address {
+		return fmt.Errorf("block %v: invalid account block", blockID)
+	}
+	ltKey := boc.NewBitString(64)
+	if err := ltKey.WriteUint(lt, 64); err != nil {
+		return err
+	}
+	// the hashmap of transactions starts right in the account block's cell.
+	value, err := tlb.FindHashmapValue(accountBlock.CopyRemaining(), ltKey)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("block %v has no transaction with lt %v", blockID, lt)
+	}
+	if err := skipCurrencyCollection(value); err != nil {
+		return err
+	}
+	txCell, err := value.NextRef()
+	if err != nil {
+		return err
+	}
+	txHash, err := txCell.HashAtLevel(0)
+	if err != nil {
+		return err
+	}
+	if ton.Bits256(txHash) != hash {
+		return fmt.Errorf("block %v contains another transaction with lt %v", blockID, lt)
+	}
+	return nil
+}
+
+// verifyBlockTransactions checks that transactions returned by liteServer.listBlockTransactionsExt
+// are included into the given block and go in the requested order after the given transaction.
+// Transactions are ordered by account and lt, the reverse order is requested with mode 64.
+func verifyBlockTransactions(blockID ton.BlockIDExt, mode uint32, after *liteclient.LiteServerTransactionId3C, txs []ton.Transaction, res liteclient.LiteServerBlockTransactionsExtC) error {
+	if res.Id.ToBlockIdExt() != blockID {
+		return fmt.Errorf("%w: transactions are from block %v instead of %v", ErrInvalidProof, res.Id.ToBlockIdExt(), blockID)
+	}
+	if uint32(len(txs)) > res.ReqCount {
+		return fmt.Errorf("%w: %v transactions instead of at most %v", ErrInvalidProof, len(txs), res.ReqCount)
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+	proof, err := boc.DeserializeSingleRootBoc(res.Proof)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		return fmt.Errorf("%w: block %v: %v", ErrInvalidProof, blockID, err)
+	}
+	reverse := mode&64 != 0
+	prev := after
+	for i, tx := range txs {
+		current := liteclient.LiteServerTransactionId3C{Account: tl.Int256(tx.AccountAddr), Lt: tx.Lt}
+		if prev != nil {
+			cmp := compareTransactionIDs(*prev, current)
+			if (!reverse && cmp >= 0) || (reverse && cmp <= 0) {
+				return fmt.Errorf("%w: transaction %v is out of order", ErrInvalidProof, i)
+			}
+		}
+		prev = &current
+		accountID := ton.AccountID{Workchain: blockID.Workchain, Address: ton.Bits256(tx.AccountAddr)}
+		if err := checkBlockTransaction(blockID, root, accountID, tx.Lt, ton.Bits256(tx.Hash())); err != nil {
+			return fmt.Errorf("%w: transaction %v: %v", ErrInvalidProof, i, err)
+		}
+	}
+	return nil
+}
+
+func compareTransactionIDs(a, b liteclient.LiteServerTransactionId3C) int {
+	if cmp := bytes.Compare(a.Account[:], b.Account[:]); cmp != 0 {
+		return cmp
+	}
+	switch {
+	case a.Lt < b.Lt:
+		return -1
+	case a.Lt > b.Lt:
+		return 1
+	}
+	return 0
+}
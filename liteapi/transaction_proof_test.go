@@ -0,0 +1,268 @@
+package liteapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/liteapi/internal/testutil"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// testTransactions are three consecutive transactions of the same account, the latest one goes first.
+var testTransactions = []string{
+	"B5EE9C724102070100019E0003B57E2D41ED396A9F1BA03839D63C5650FAFC6FCFB574FD03F2E67D6555B61A3ACD9000019FA692BDA4ABF09A2C354F195AE4CCC0E0823A64BE24A61AC5DE7B117144D0A25CF0B7284CA000019FA692BDA41629F85660001461E3E3080102030101A004008272351FDFE28574AE29FC95C113A819FD8D4609355948EFD527F8A97C4F1F883F9AB9FB184CCAC0504709880BEF9DF17E51F44D62273B1886FE1F45D4268A31439F02150C090E8BFC2B5861E3E311050600C948008DBE435819EC7BFAE0721AA85A4D01BC6414619B03A6FAEC7AF93C2FE48234030038B507B4E5AA7C6E80E0E758F15943EBF1BF3ED5D3F40FCB99F59556D868EB3650E8BFC2B406145860000033F4D257B492C53F0ACC6A993B6D800000000000000040009E407BEC3B957000000000000000001D00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000005BC00000000000000000000000012D452DA449E50B8CF7DD27861F146122AFE1B546BB8B70FC8216F0C614139F8E0426B8C2D6",
+	"B5EE9C7241020A0100024A0003B57E2D41ED396A9F1BA03839D63C5650FAFC6FCFB574FD03F2E67D6555B61A3ACD9000019FA692BDA41C0C3B53376473958A45DB5C122EE5EC7B5DBD6AFEA1B51BE8D894E85377C79CE000019FA6621A78A629F85660003469DA27880102030201E004050082728734D5189F3298FD505CEC1E2ECE027B7361F9A47A3D0221C0D556E023DC4D5F351FDFE28574AE29FC95C113A819FD8D4609355948EFD527F8A97C4F1F883F9A020F0C4B061993CF0440080901E18801C5A83DA72D53E37407073AC78ACA1F5F8DF9F6AE9FA07E5CCFACAAB6C34759B2047002A1C28BCD96496121965C341237AFD99C4EC31F5098D0EDEDA5D307B2EBA42DC340251441D16C3A069BFE20751C08B7DDC83067DAECB7D9071A260CEE68314D4D18BB14FC2CF800000020001C060101DF07006A6200574BA8A53890BF135A88D761900EE44F40247050298824D3617C3FFFB89E46EAA812A05F20000000000000000000000000000000B36801C5A83DA72D53E37407073AC78ACA1F5F8DF9F6AE9FA07E5CCFACAAB6C34759B3002BA5D4529C485F89AD446BB0C8077227A012382814C41269B0BE1FFFDC4F23755409502F900006145860000033F4D257B484C53F0ACC40009D419D8313880000000000000000110000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000020006FC987A1204C14584000000000000200000000000357D3046A89BB7B0F886158490114F4AC5E3B1FF7FB5F6BE1CDE59B14D6C616044050164C765B14EE",
+	"B5EE9C724102070100019E0003B57E2D41ED396A9F1BA03839D63C5650FAFC6FCFB574FD03F2E67D6555B61A3ACD9000019FA6621A78A8ECF33C9DA783435971609355882C0A5C41F801BF4077AE8435C65217171707C000019FA6621A781629F84BB0001461E3E3080102030101A0040082720968F8B82A52012B3804582229A4F4DB6C5F15F51D2C1CDF315BFA27A5817DDF8734D5189F3298FD505CEC1E2ECE027B7361F9A47A3D0221C0D556E023DC4D5F02150C090E8BFC159861E3E311050600C948014CB767011CEA403ACBAC14157827A428B9352A5441592BCAB8AE43F17808DE550038B507B4E5AA7C6E80E0E758F15943EBF1BF3ED5D3F40FCB99F59556D868EB3650E8BFC15806145860000033F4CC434F12C53F09766A993B6D800000000000000040009E407BEC3B957000000000000000001D00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000005BC00000000000000000000000012D452DA449E50B8CF7DD27861F146122AFE1B546BB8B70FC8216F0C614139F8E0428010D9C",
+}
+
+func decodeTestTransactions(t *testing.T) []ton.Transaction {
+	t.Helper()
+	var txs []ton.Transaction
+	for _, raw := range testTransactions {
+		data, err := hex.DecodeString(raw)
+		if err != nil {
+			t.Fatalf("DecodeString() failed: %v", err)
+		}
+		cell, err := boc.DeserializeSingleRootBoc(data)
+		if err != nil {
+			t.Fatalf("DeserializeSingleRootBoc() failed: %v", err)
+		}
+		var tx tlb.Transaction
+		if err := tlb.Unmarshal(cell, &tx); err != nil {
+			t.Fatalf("Unmarshal() failed: %v", err)
+		}
+		txs = append(txs, ton.Transaction{Transaction: tx})
+	}
+	return txs
+}
+
+func Test_verifyTransactionChain(t *testing.T) {
+	txs := decodeTestTransactions(t)
+	accountID := ton.AccountID{Workchain: -1, Address: ton.Bits256(txs[0].AccountAddr)}
+	lt, hash := txs[0].Lt, ton.Bits256(txs[0].Hash())
+
+	tests := []struct {
+		name      string
+		accountID ton.AccountID
+		lt        uint64
+		hash      ton.Bits256
+		txs       []ton.Transaction
+		wantErr   bool
+	}{
+		{
+			name:      "full chain",
+			accountID: accountID,
+			lt:        lt,
+			hash:      hash,
+			txs:       txs,
+		},
+		{
+			name:      "chain starting from the second transaction",
+			accountID: accountID,
+			lt:        txs[1].Lt,
+			hash:      ton.Bits256(txs[1].Hash()),
+			txs:       txs[1:],
+		},
+		{
+			name:      "no transactions",
+			accountID: accountID,
+			lt:        lt,
+			hash:      hash,
+		},
+		{
+			name:      "missing transaction",
+			accountID: accountID,
+			lt:        lt,
+			hash:      hash,
+			txs:       []ton.Transaction{txs[0], txs[2]},
+			wantErr:   true,
+		},
+		{
+			name:      "another starting transaction",
+			accountID: accountID,
+			lt:        lt,
+			hash:      ton.Bits256{1},
+			txs:       txs,
+			wantErr:   true,
+		},
+		{
+			name:      "another account",
+			accountID: ton.AccountID{Workchain: -1, Address: ton.Bits256{1}},
+			lt:        lt,
+			hash:      hash,
+			txs:       txs,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyTransactionChain(tt.accountID, tt.lt, tt.hash, tt.txs)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidProof) {
+					t.Fatalf("want ErrInvalidProof, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyTransactionChain() failed: %v", err)
+			}
+		})
+	}
+}
+
+type testTransactionItem struct {
+	Extra       tlb.CurrencyCollection
+	Transaction boc.Cell `tlb:"^"`
+}
+
+// testAccountBlock is a leaf of ShardAccountBlocks: CurrencyCollection extra followed by AccountBlock.
+type testAccountBlock struct {
+	Extra        tlb.CurrencyCollection
+	Magic        tlb.Magic `tlb:"acc_trans#5"`
+	AccountAddr  tlb.Bits256
+	Transactions tlb.Hashmap[tlb.Uint64, testTransactionItem]
+	StateUpdate  boc.Cell `tlb:"^"`
+}
+
+type testShardAccountBlocks struct {
+	Accounts tlb.HashmapE[tlb.Bits256, testAccountBlock]
+	Extra    tlb.CurrencyCollection
+}
+
+func Test_checkTransactionProof(t *testing.T) {
+	left := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x02}}
+	right := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x81, 0x02}}
+	absent := ton.AccountID{Workchain: 0, Address: ton.Bits256{0x01, 0x03}}
+
+	tx1, tx2, tx3 := testutil.CellWithUint(t, 1), testutil.CellWithUint(t, 2), testutil.CellWithUint(t, 3)
+	accountBlocks := testutil.MustMarshal(t, testShardAccountBlocks{
+		Accounts: tlb.NewHashmapE(
+			[]tlb.Bits256{tlb.Bits256(left.Address), tlb.Bits256(right.Address)},
+			[]testAccountBlock{
+				{
+					AccountAddr: tlb.Bits256(left.Address),
+					Transactions: tlb.NewHashmap(
+						[]tlb.Uint64{100, 200},
+						[]testTransactionItem{{Transaction: *tx1}, {Transaction: *tx2}}),
+					StateUpdate: *testutil.CellWithUint(t, 4),
+				},
+				{
+					AccountAddr: tlb.Bits256(right.Address),
+					Transactions: tlb.NewHashmap(
+						[]tlb.Uint64{300},
+						[]testTransactionItem{{Transaction: *tx3}}),
+					StateUpdate: *testutil.CellWithUint(t, 5),
+				},
+			}),
+	})
+	extra := testutil.MustMarshal(t, testBlockExtra{
+		InMsgDescr:    *testutil.CellWithUint(t, 6),
+		OutMsgDescr:   *testutil.CellWithUint(t, 7),
+		AccountBlocks: *accountBlocks,
+	})
+	block := testBlockCell(t, testutil.CellWithUint(t, 8), testutil.CellWithUint(t, 9), extra)
+	blockID := ton.BlockIDExt{
+		BlockID:  ton.BlockID{Workchain: 0, Shard: 0x8000000000000000, Seqno: 10},
+		RootHash: testutil.MustHash(t, block),
+	}
+	proof := merkleProof(t, block)
+
+	tests := []struct {
+		name      string
+		blockID   ton.BlockIDExt
+		accountID ton.AccountID
+		lt        uint64
+		hash      ton.Bits256
+		wantErr   bool
+	}{
+		{
+			name:      "first transaction",
+			blockID:   blockID,
+			accountID: left,
+			lt:        100,
+			hash:      testutil.MustHash(t, tx1),
+		},
+		{
+			name:      "second transaction",
+			blockID:   blockID,
+			accountID: left,
+			lt:        200,
+			hash:      testutil.MustHash(t, tx2),
+		},
+		{
+			name:      "single transaction",
+			blockID:   blockID,
+			accountID: right,
+			lt:        300,
+			hash:      testutil.MustHash(t, tx3),
+		},
+		{
+			name:      "another transaction",
+			blockID:   blockID,
+			accountID: left,
+			lt:        100,
+			hash:      testutil.MustHash(t, tx2),
+			wantErr:   true,
+		},
+		{
+			name:      "unknown lt",
+			blockID:   blockID,
+			accountID: left,
+			lt:        300,
+			hash:      testutil.MustHash(t, tx3),
+			wantErr:   true,
+		},
+		{
+			name:      "unknown account",
+			blockID:   blockID,
+			accountID: absent,
+			lt:        100,
+			hash:      testutil.MustHash(t, tx1),
+			wantErr:   true,
+		},
+		{
+			name:      "another shard",
+			blockID:   ton.BlockIDExt{BlockID: ton.BlockID{Workchain: 0, Shard: 0xc000000000000000, Seqno: 10}, RootHash: blockID.RootHash},
+			accountID: left,
+			lt:        100,
+			hash:      testutil.MustHash(t, tx1),
+			wantErr:   true,
+		},
+		{
+			name:      "another block",
+			blockID:   ton.BlockIDExt{BlockID: blockID.BlockID, RootHash: ton.Bits256{1}},
+			accountID: left,
+			lt:        100,
+			hash:      testutil.MustHash(t, tx1),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTransactionProof(tt.blockID, tt.accountID, tt.lt, tt.hash, proof)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkTransactionProof() failed: %v", err)
+			}
+		})
+	}
+
+	// a verified root is reused to check all transactions of the block.
+	root, err := boc.VerifyMerkleProof(proof, blockID.RootHash)
+	if err != nil {
+		t.Fatalf("VerifyMerkleProof() failed: %v", err)
+	}
+	for _, tt := range tests[:3] {
+		if err := checkBlockTransaction(blockID, root, tt.accountID, tt.lt, tt.hash); err != nil {
+			t.Fatalf("checkBlockTransaction() failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package liteclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ErrNotRecorded is returned by a replay client when a cassette doesn't contain an answer to a query.
+var ErrNotRecorded = newClientError("query is not recorded in the cassette")
+
+// Interaction is a single query to a lite server and its answer.
+type Interaction struct {
+	// Method is a name of the lite server method.
+	// It is informational and is ignored when a cassette is replayed.
+	Method string `json:"method"`
+	// Query is a TL-encoded query as it is passed to Client.Request.
+	Query []byte `json:"query"`
+	// Answer is a TL-encoded answer of the lite server.
+	Answer []byte `json:"answer"`
+	// Background is set for a query made with a context returned by WithBackground.
+	Background bool `json:"background,omitempty"`
+}
+
+type backgroundKey struct{}
+
+// WithBackground marks requests made with the returned context as background ones,
+// like requests a connections pool sends to track the state of lite servers.
+// Timing of such requests depends on goroutine scheduling,
+// so they don't take part in the ordered replay of a cassette:
+// a cassette keeps only the latest answer to each background query,
+// and a replay client answers background queries without moving to the next recorded answer of the same query.
+func WithBackground(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundKey{}, true)
+}
+
+func isBackground(ctx context.Context) bool {
+	return ctx.Value(backgroundKey{}) != nil
+}
+
+// Cassette keeps queries sent to lite servers along with their answers.
+// It is filled by a client configured with OptionRecorder
+// and serves answers to a client created with NewReplayClient,
+// so a recorded session can be replayed later without network access.
+//
+// Queries are matched byte by byte.
+// If the same query has been recorded several times,
+// its answers are replayed in the recorded order and the last one is repeated after that.
+// Background queries are handled separately, see WithBackground.
+type Cassette struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	answers      map[string][][]byte
+	served       map[string]int
+	background   map[string][]byte
+}
+
+// NewCassette returns an empty cassette.
+func NewCassette() *Cassette {
+	return &Cassette{
+		answers:    map[string][][]byte{},
+		served:     map[string]int{},
+		background: map[string][]byte{},
+	}
+}
+
+// LoadCassette reads a cassette from a file written by Cassette.Save.
+func LoadCassette(filename string) (*Cassette, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCassette(f)
+}
+
+// ReadCassette reads a cassette in JSON format.
+func ReadCassette(r io.Reader) (*Cassette, error) {
+	var interactions []Interaction
+	if err := json.NewDecoder(r).Decode(&interactions); err != nil {
+		return nil, err
+	}
+	c := NewCassette()
+	for _, i := range interactions {
+		if i.Background {
+			c.recordBackground(i.Query, i.Answer)
+			continue
+		}
+		c.Record(i.Query, i.Answer)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to a file in JSON format.
+func (c *Cassette) Save(filename string) error {
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+// WriteTo writes the cassette to w in JSON format.
+func (c *Cassette) WriteTo(w io.Writer) (int64, error) {
+	bs, err := json.MarshalIndent(c.Interactions(), "", " ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(bs)
+	return int64(n), err
+}
+
+// Record adds a query and its answer to the cassette.
+func (c *Cassette) Record(query, answer []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := string(query)
+	c.answers[key] = append(c.answers[key], bytes.Clone(answer))
+	c.interactions = append(c.interactions, Interaction{
+		Method: QueryMethod(query),
+		Query:  bytes.Clone(query),
+		Answer: bytes.Clone(answer),
+	})
+}
+
+func (c *Cassette) recordBackground(query, answer []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.background[string(query)] = bytes.Clone(answer)
+}
+
+// Interactions returns all recorded interactions in the recorded order
+// followed by the latest answers to background queries.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interactions := append([]Interaction{}, c.interactions...)
+	queries := make([]string, 0, len(c.background))
+	for query := range c.background {
+		queries = append(queries, query)
+	}
+	sort.Strings(queries)
+	for _, query := range queries {
+		interactions = append(interactions, Interaction{
+			Method:     QueryMethod([]byte(query)),
+			Query:      []byte(query),
+			Answer:     bytes.Clone(c.background[query]),
+			Background: true,
+		})
+	}
+	return interactions
+}
+
+// Len returns a number of recorded interactions, background queries are not counted.
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.interactions)
+}
+
+// backgroundAnswer returns the latest answer to a background query.
+// If the query has been recorded as a regular one, the answer is taken from there
+// without moving to the next recorded answer.
+func (c *Cassette) backgroundAnswer(query []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := string(query)
+	if answer, ok := c.background[key]; ok {
+		return bytes.Clone(answer), nil
+	}
+	answers := c.answers[key]
+	if len(answers) == 0 {
+		return nil, ErrNotRecorded
+	}
+	return bytes.Clone(answers[c.served[key]]), nil
+}
+
+func (c *Cassette) answer(query []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := string(query)
+	answers := c.answers[key]
+	if len(answers) == 0 {
+		return nil, ErrNotRecorded
+	}
+	idx := c.served[key]
+	if idx < len(answers)-1 {
+		c.served[key] = idx + 1
+	}
+	return bytes.Clone(answers[idx]), nil
+}
+
+// OptionRecorder configures a client to record all successful requests to the given cassette.
+func OptionRecorder(cassette *Cassette) Options {
+	return func(c *Client) {
+		c.recorder = cassette
+	}
+}
+
+// NewReplayClient returns a client that doesn't connect to any lite server
+// and answers requests from the given cassette.
+// Requests missing in the cassette fail with ErrNotRecorded.
+func NewReplayClient(cassette *Cassette, opts ...Options) *Client {
+	c := &Client{
+		timeout: defaultTimeout,
+		queries: make(map[queryID]chan []byte),
+		done:    make(chan struct{}),
+		replay:  cassette,
+	}
+	for _, f := range opts {
+		f(c)
+	}
+	return c
+}
+
+func (c *Client) replayRequest(ctx context.Context, q []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, newClientError("request timeout: %v", err)
+	}
+	if isBackground(ctx) {
+		return c.replay.backgroundAnswer(q)
+	}
+	return c.replay.answer(q)
+}
@@ -0,0 +1,107 @@
+package liteclient
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	handler := &testHandler{
+		info: LiteServerMasterchainInfoC{
+			Last: TonNodeBlockIdExtC{Workchain: 0xffffffff, Shard: 0x8000000000000000, Seqno: 100500},
+		},
+	}
+	recorder := NewCassette()
+	server, client := startTestServer(t, handler, OptionRecorder(recorder))
+	ctx := context.Background()
+
+	if _, err := client.LiteServerGetMasterchainInfo(ctx); err != nil {
+		t.Fatalf("LiteServerGetMasterchainInfo() failed: %v", err)
+	}
+	handler.info.Last.Seqno = 100501
+	if _, err := client.LiteServerGetMasterchainInfo(ctx); err != nil {
+		t.Fatalf("LiteServerGetMasterchainInfo() failed: %v", err)
+	}
+	// background queries are kept apart from the recorded session.
+	handler.info.Last.Seqno = 100502
+	for i := 0; i < 3; i++ {
+		if _, err := client.LiteServerGetMasterchainInfo(WithBackground(ctx)); err != nil {
+			t.Fatalf("LiteServerGetMasterchainInfo() failed: %v", err)
+		}
+	}
+	request := LiteServerListBlockTransactionsRequest{Id: handler.info.Last, Count: 42}
+	if _, err := client.LiteServerListBlockTransactions(ctx, request); err != nil {
+		t.Fatalf("LiteServerListBlockTransactions() failed: %v", err)
+	}
+	if _, err := client.LiteServerGetTime(ctx); err == nil {
+		t.Fatalf("LiteServerGetTime() must fail")
+	}
+	client.Close(context.Background())
+	server.Close()
+
+	if recorder.Len() != 4 {
+		t.Fatalf("want 4 interactions, got: %v", recorder.Len())
+	}
+	interactions := recorder.Interactions()
+	if len(interactions) != 5 || !interactions[4].Background {
+		t.Fatalf("want 4 interactions and 1 background query, got: %v", len(interactions))
+	}
+	if interactions[0].Method != LiteServerGetMasterchainInfoRequestName {
+		t.Fatalf("want %v, got: %v", LiteServerGetMasterchainInfoRequestName, interactions[0].Method)
+	}
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recorder.Save(filename); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	cassette, err := LoadCassette(filename)
+	if err != nil {
+		t.Fatalf("LoadCassette() failed: %v", err)
+	}
+
+	replay := NewReplayClient(cassette)
+	if !replay.IsOK() {
+		t.Fatalf("replay client must be OK")
+	}
+	// answers to the same query are replayed in order, the last one is repeated.
+	// background queries get the latest background answer and don't affect the order.
+	for _, step := range []struct {
+		ctx  context.Context
+		want uint32
+	}{
+		{WithBackground(ctx), 100502},
+		{ctx, 100500},
+		{WithBackground(ctx), 100502},
+		{ctx, 100501},
+		{ctx, 100501},
+	} {
+		ctx, want := step.ctx, step.want
+		info, err := replay.LiteServerGetMasterchainInfo(ctx)
+		if err != nil {
+			t.Fatalf("LiteServerGetMasterchainInfo() failed: %v", err)
+		}
+		if info.Last.Seqno != want {
+			t.Fatalf("want seqno %v, got: %v", want, info.Last.Seqno)
+		}
+	}
+	txs, err := replay.LiteServerListBlockTransactions(ctx, request)
+	if err != nil {
+		t.Fatalf("LiteServerListBlockTransactions() failed: %v", err)
+	}
+	if txs.ReqCount != 42 {
+		t.Fatalf("want 42, got: %v", txs.ReqCount)
+	}
+	var liteServerErr LiteServerErrorC
+	if _, err := replay.LiteServerGetTime(ctx); !errors.As(err, &liteServerErr) || liteServerErr.Message != "clock is broken" {
+		t.Fatalf("want recorded lite server error, got: %v", err)
+	}
+	request.Count = 43
+	if _, err := replay.LiteServerListBlockTransactions(ctx, request); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("want ErrNotRecorded, got: %v", err)
+	}
+	replay.Close(context.Background())
+	if _, err := replay.LiteServerGetMasterchainInfo(ctx); !errors.Is(err, ErrClosed) {
+		t.Fatalf("want ErrClosed, got: %v", err)
+	}
+}
@@ -36,6 +36,22 @@ type Client struct {
 	connMutex    sync.Mutex
 	queries      map[queryID]chan []byte
 	queriesMutex sync.Mutex
+
+	// recorder, if set, records all successful requests.
+	recorder *Cassette
+	// replay, if set, answers requests instead of lite servers.
+	replay *Cassette
+
+	// connID is reported to hooks, see OptionConnID.
+	connID      int
+	hooks       []RequestHook
+	interceptor RequestInterceptor
+
+	// done is closed when the client is closed.
+	done      chan struct{}
+	closeOnce sync.Once
+	// readers tracks goroutines reading answers from the connections.
+	readers sync.WaitGroup
 }
 
 type Options func(connection *Client)
@@ -51,11 +67,14 @@ func OptionWorkersPerConnection(n int) Options {
 		if n < 1 {
 			n = 1
 		}
+		if len(c.connections) == 0 {
+			return
+		}
 		connFirst := c.connections[0]
 		for i := 0; i < n-1; i++ {
 			conn, err := NewConnection(context.Background(), connFirst.peerPublicKey, connFirst.host)
 			if err != nil {
-				slog.Warn("liteclient clone connection error", err)
+				slog.Warn("liteclient clone connection error", "err", err)
 				continue
 			}
 			c.connections = append(c.connections, conn)
@@ -68,12 +87,14 @@ func NewClient(c *Connection, opts ...Options) *Client {
 		timeout:     defaultTimeout,
 		connections: []*Connection{c},
 		queries:     make(map[queryID]chan []byte),
+		done:        make(chan struct{}),
 	}
 	for _, f := range opts {
 		f(c2)
 	}
 
 	for _, conn := range c2.connections {
+		c2.readers.Add(1)
 		go c2.reader(conn)
 	}
 	return c2
@@ -81,6 +102,14 @@ func NewClient(c *Connection, opts ...Options) *Client {
 
 // IsOK returns true if there is no problems with this client and its underlying connection to a lite server.
 func (c *Client) IsOK() bool {
+	if c.replay != nil {
+		select {
+		case <-c.done:
+			return false
+		default:
+			return true
+		}
+	}
 	for _, conn := range c.connections {
 		if conn.Status() == Connected {
 			return true
@@ -89,12 +118,86 @@ func (c *Client) IsOK() bool {
 	return false
 }
 
+// Close closes all connections of the client and waits for their background goroutines to exit.
+// In-flight and subsequent requests fail with ErrClosed.
+// If ctx is done before the goroutines exit, Close returns the context error,
+// the client is closed anyway.
+func (c *Client) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	var err error
+	for _, conn := range c.connections {
+		// every connection is closed even if ctx is already done.
+		if closeErr := conn.Close(ctx); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return waitGroupWithContext(ctx, &c.readers)
+}
+
 // Request sends q as query in adnl.message.query and receives answer from adnl.message.answer
 // adnl.message.query query_id:int256 query:bytes = adnl.Message
 // adnl.message.answer query_id:int256 answer:bytes = adnl.Message
 func (c *Client) Request(ctx context.Context, q []byte) ([]byte, error) {
+	if c.interceptor != nil {
+		return c.interceptor(ctx, q, c.request)
+	}
+	return c.request(ctx, q)
+}
+
+func (c *Client) request(ctx context.Context, q []byte) ([]byte, error) {
+	select {
+	case <-c.done:
+		return nil, ErrClosed
+	default:
+	}
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
+	var conn *Connection
+	if c.replay == nil {
+		c.connMutex.Lock()
+		conn = c.connections[c.nextConn]
+		c.nextConn = (c.nextConn + 1) % len(c.connections)
+		c.connMutex.Unlock()
+	}
+	if len(c.hooks) == 0 {
+		return c.send(ctx, conn, q)
+	}
+	info := RequestInfo{
+		Method:      QueryMethod(q),
+		ConnID:      c.connID,
+		RequestSize: len(q),
+	}
+	if conn != nil {
+		info.ServerHost = conn.host
+	}
+	for _, h := range c.hooks {
+		ctx = h.BeforeRequest(ctx, info)
+	}
+	start := time.Now()
+	resp, err := c.send(ctx, conn, q)
+	result := RequestResult{
+		Latency:         time.Since(start),
+		ResponseSize:    len(resp),
+		LiteServerError: LiteServerErrorFromAnswer(resp),
+		Err:             err,
+	}
+	for _, h := range c.hooks {
+		h.AfterRequest(ctx, info, result)
+	}
+	return resp, err
+}
+
+// send sends q to the given connection and waits for an answer.
+// conn is nil for a replay client.
+func (c *Client) send(ctx context.Context, conn *Connection, q []byte) ([]byte, error) {
+	if c.replay != nil {
+		return c.replayRequest(ctx, q)
+	}
 	var id queryID
 	rand.Read(id[:])
 	data := make([]byte, 4, 44+len(q)) //create with small overhead for reducing garbage collector calls
@@ -110,11 +213,6 @@ func (c *Client) Request(ctx context.Context, q []byte) ([]byte, error) {
 	resp := c.registerCallback(id)
 	defer c.unregisterCallback(id)
 
-	c.connMutex.Lock()
-	conn := c.connections[c.nextConn]
-	c.nextConn = (c.nextConn + 1) % len(c.connections)
-	c.connMutex.Unlock()
-
 	err = conn.Send(p)
 	if err != nil {
 		return nil, err
@@ -122,7 +220,16 @@ func (c *Client) Request(ctx context.Context, q []byte) ([]byte, error) {
 	select {
 	case <-ctx.Done():
 		return nil, newClientError("request timeout: %v", ctx.Err())
+	case <-c.done:
+		return nil, ErrClosed
 	case b := <-resp:
+		if c.recorder != nil {
+			if isBackground(ctx) {
+				c.recorder.recordBackground(q, b)
+			} else {
+				c.recorder.Record(q, b)
+			}
+		}
 		return b, nil
 	}
 }
@@ -175,7 +282,14 @@ func decodeLength(b []byte) (int, []byte, error) {
 }
 
 func (c *Client) reader(conn *Connection) {
-	for p := range conn.Responses() {
+	defer c.readers.Done()
+	for {
+		var p Packet
+		select {
+		case <-conn.Done():
+			return
+		case p = <-conn.Responses():
+		}
 		if p.MagicType() != magicADNLAnswer {
 			continue
 		}
@@ -229,7 +343,7 @@ func alignBytes(data []byte) []byte {
 }
 
 // WaitMasterchainSeqno waits for the given block to become committed.
-// If timeout happens, it returns an error.
+// If timeout happens, it returns ErrWaitTimeout.
 func (c *Client) WaitMasterchainSeqno(ctx context.Context, seqno uint32, timeout uint32) error {
 	data := make([]byte, 0, 12)
 	data = binary.LittleEndian.AppendUint32(data, magicLiteServerWaitMasterchainSeqno)
@@ -243,13 +357,17 @@ func (c *Client) WaitMasterchainSeqno(ctx context.Context, seqno uint32, timeout
 		return fmt.Errorf("not enough bytes for tag")
 	}
 	tag := binary.LittleEndian.Uint32(resp[:4])
-	if tag == 0xbba9e148 {
+	if tag == magicLiteServerError {
 		var errRes LiteServerErrorC
 		if err = tl.Unmarshal(bytes.NewReader(resp[4:]), &errRes); err != nil {
 			return err
 		}
-		if errRes.Code == 0 {
+		switch errRes.Code {
+		case 0:
+			// a bare prefix is answered with liteServer.error with code 0 when the block appears.
 			return nil
+		case errorCodeTimeout:
+			return fmt.Errorf("%w: %v", ErrWaitTimeout, errRes.Message)
 		}
 		return errRes
 	}
@@ -257,6 +375,9 @@ func (c *Client) WaitMasterchainSeqno(ctx context.Context, seqno uint32, timeout
 }
 
 func (c *Client) AverageRoundTrip() time.Duration {
+	if len(c.connections) == 0 {
+		return 0
+	}
 	var total time.Duration
 	for _, conn := range c.connections {
 		total += conn.AverageRoundTrip()
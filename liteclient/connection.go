@@ -18,9 +18,14 @@ type ConnectionStatus int
 const (
 	Connecting ConnectionStatus = iota
 	Connected
+	Closed
 )
 const (
 	reconnectTimeout = 10 * time.Second
+
+	// minReconnectDelay and maxReconnectDelay bound an exponential backoff between reconnect attempts.
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
 )
 
 type Connection struct {
@@ -28,6 +33,14 @@ type Connection struct {
 	host          string
 	resp          chan Packet
 
+	// ctx is canceled when the connection is closed,
+	// all background goroutines of the connection exit once it is done.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// wg tracks background goroutines of the connection.
+	// New goroutines are added only under mu while the connection is not closed.
+	wg sync.WaitGroup
+
 	// mu protects all fields below.
 	mu           sync.Mutex
 	status       ConnectionStatus
@@ -36,16 +49,24 @@ type Connection struct {
 	avgRoundTrip time.Duration
 }
 
+// NewConnection establishes a connection to a lite server.
+// The given context is used only to dial the server,
+// the connection lives until Close is called.
 func NewConnection(ctx context.Context, peerPublicKey []byte, host string) (*Connection, error) {
+	lifetimeCtx, cancel := context.WithCancel(context.Background())
 	c := Connection{
 		host:          host,
 		peerPublicKey: peerPublicKey,
 		resp:          make(chan Packet),
 		status:        Connecting,
+		ctx:           lifetimeCtx,
+		cancel:        cancel,
 	}
 	if err := c.setupEncryptedConnection(ctx); err != nil {
+		cancel()
 		return nil, err
 	}
+	c.wg.Add(1)
 	go c.ping()
 	return &c, nil
 }
@@ -58,16 +79,21 @@ func (c *Connection) setupEncryptedConnection(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.status == Closed {
+		econn.close()
+		return ErrClosed
+	}
 	c.econn = econn
 	c.status = Connected
 	c.pings = make(map[uint64]time.Time, 5)
-	go c.reader(econn.handleIncomingPackets())
+	c.wg.Add(1)
+	go c.reader(econn.handleIncomingPackets(c.ctx.Done()))
 	return nil
 }
 
 func (c *Connection) reconnect() {
 	c.mu.Lock()
-	if c.status == Connecting {
+	if c.status != Connected {
 		c.mu.Unlock()
 		return
 	}
@@ -75,15 +101,58 @@ func (c *Connection) reconnect() {
 	c.econn.close()
 	c.mu.Unlock()
 
+	delay := minReconnectDelay
 	for {
-		if err := c.setupEncryptedConnection(context.Background()); err != nil {
-			time.Sleep(1 * time.Second)
-			continue
+		err := c.setupEncryptedConnection(c.ctx)
+		if err == nil || c.ctx.Err() != nil {
+			return
 		}
-		break
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxReconnectDelay)
+	}
+}
+
+// Close closes the connection and waits for all its background goroutines to exit.
+// Subsequent calls to Send return ErrClosed.
+// If ctx is done before the goroutines exit, Close returns the context error,
+// the connection is closed anyway and the goroutines exit eventually.
+func (c *Connection) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if c.status != Closed {
+		c.status = Closed
+		c.cancel()
+		if c.econn != nil {
+			c.econn.close()
+		}
+	}
+	c.mu.Unlock()
+	return waitGroupWithContext(ctx, &c.wg)
+}
+
+// waitGroupWithContext waits for the wait group or for ctx to be done.
+func waitGroupWithContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
+// Done returns a channel that is closed when the connection is closed.
+func (c *Connection) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
 func averageRoundTrip(roundTrips []time.Duration) time.Duration {
 	total := time.Duration(0)
 	for _, rt := range roundTrips {
@@ -93,6 +162,13 @@ func averageRoundTrip(roundTrips []time.Duration) time.Duration {
 }
 
 func (c *Connection) reader(packetCh chan Packet) {
+	defer c.wg.Done()
+	defer func() {
+		// the underlying connection is closed at this point,
+		// wait for the goroutine reading from it to exit.
+		for range packetCh {
+		}
+	}()
 	var roundTrips []time.Duration
 	for {
 		select {
@@ -116,8 +192,13 @@ func (c *Connection) reader(packetCh chan Packet) {
 				// the next iteration of this for loop will restart reconnect timeout.
 				continue
 			}
-			c.resp <- p
-
+			select {
+			case c.resp <- p:
+			case <-c.ctx.Done():
+				return
+			}
+		case <-c.ctx.Done():
+			return
 		case <-time.After(reconnectTimeout):
 			c.reconnect()
 			// setupEncryptedConnection will run another reader.
@@ -131,11 +212,18 @@ func (c *Connection) Send(p Packet) error {
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.status == Closed {
+		return ErrClosed
+	}
 	if c.status != Connected {
 		return newClientError("not connected yet")
 	}
 	if err := c.econn.send(b); err != nil {
-		go c.reconnect()
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.reconnect()
+		}()
 		return newClientError("net.Conn.send() failed: %v", err)
 	}
 	return nil
@@ -146,10 +234,17 @@ func (c *Connection) Responses() chan Packet {
 }
 
 func (c *Connection) ping() {
+	defer c.wg.Done()
 	ping := make([]byte, 12)
 	binary.LittleEndian.PutUint32(ping[:4], magicTCPPing)
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
 	for {
-		time.Sleep(time.Second * 3)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
 		mrand.Read(ping[4:])
 		p, err := NewPacket(ping)
 		if err != nil {
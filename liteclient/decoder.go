@@ -55,3 +55,26 @@ func LiteapiRequestDecoder(b []byte) (uint32, *RequestName, any, error) {
 	}
 	return tag, pointer(UnknownRequest), nil, nil
 }
+
+// QueryMethod returns a name of the lite server method called by the given liteServer.query.
+func QueryMethod(query []byte) RequestName {
+	if len(query) < 4 || binary.LittleEndian.Uint32(query) != magicLiteServerQuery {
+		return UnknownRequest
+	}
+	var payload []byte
+	if err := tl.Unmarshal(bytes.NewReader(query[4:]), &payload); err != nil {
+		return UnknownRequest
+	}
+	if len(payload) >= 4 && binary.LittleEndian.Uint32(payload) == magicLiteServerWaitMasterchainSeqno {
+		// liteServer.waitMasterchainSeqno seqno:int timeout_ms:int prefixes the actual request.
+		if len(payload) < 12 {
+			return UnknownRequest
+		}
+		payload = payload[12:]
+	}
+	_, name, _, _ := LiteapiRequestDecoder(payload)
+	if name == nil {
+		return UnknownRequest
+	}
+	return *name
+}
@@ -2,10 +2,13 @@ package liteclient
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ed25519"
 	"fmt"
+	"io"
 	"net"
 )
 
@@ -54,17 +57,26 @@ func newEncryptedConnection(ctx context.Context, peerPublicKey []byte, host stri
 	return c, nil
 }
 
-func (econn *encryptedConn) handshake(address Address, params params, keys x25519Keys) error {
-	key := append([]byte{}, keys.shared[:16]...)
-	key = append(key, params.hash()[16:32]...)
-	nonce := append([]byte{}, params.hash()[0:4]...)
-	nonce = append(nonce, keys.shared[20:32]...)
+// handshakeCipher returns a cipher used to encrypt session parameters during the handshake.
+func handshakeCipher(shared []byte, paramsHash []byte) (cipher.Stream, error) {
+	key := append([]byte{}, shared[:16]...)
+	key = append(key, paramsHash[16:32]...)
+	nonce := append([]byte{}, paramsHash[0:4]...)
+	nonce = append(nonce, shared[20:32]...)
 	cipherKey, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCTR(cipherKey, nonce), nil
+}
+
+func (econn *encryptedConn) handshake(address Address, params params, keys x25519Keys) error {
+	stream, err := handshakeCipher(keys.shared, params.hash())
 	if err != nil {
 		return err
 	}
 	data := append([]byte{}, params[:]...)
-	cipher.NewCTR(cipherKey, nonce).XORKeyStream(data, data)
+	stream.XORKeyStream(data, data)
 	req := make([]byte, 256)
 	copy(req[:32], address.hash())
 	copy(req[32:64], keys.public)
@@ -81,9 +93,64 @@ func (econn *encryptedConn) handshake(address Address, params params, keys x2551
 	return nil
 }
 
-func (econn *encryptedConn) handleIncomingPackets() chan Packet {
+// acceptEncryptedConnection runs a server side of the handshake on the given tcp connection.
+// The client has to address the handshake to the public key of the given private key.
+func acceptEncryptedConnection(conn net.Conn, key ed25519.PrivateKey) (*encryptedConn, error) {
+	a, err := NewAddress(key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	req := make([]byte, 256)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(req[:32], a.hash()) {
+		return nil, fmt.Errorf("handshake is addressed to unknown key %x", req[:32])
+	}
+	shared, err := sharedKey(key, req[32:64])
+	if err != nil {
+		return nil, err
+	}
+	paramsHash := req[64:96]
+	stream, err := handshakeCipher(shared, paramsHash)
+	if err != nil {
+		return nil, err
+	}
+	var p params
+	stream.XORKeyStream(p[:], req[96:256])
+	if !bytes.Equal(p.hash(), paramsHash) {
+		return nil, fmt.Errorf("handshake parameters checksum error")
+	}
+	// our tx is the client's rx and vice versa.
+	ci, err := aes.NewCipher(p.rxKey())
+	if err != nil {
+		return nil, err
+	}
+	dci, err := aes.NewCipher(p.txKey())
+	if err != nil {
+		return nil, err
+	}
+	c := &encryptedConn{
+		cipher:   cipher.NewCTR(ci, p.rxNonce()),
+		decipher: cipher.NewCTR(dci, p.txNonce()),
+		conn:     conn,
+	}
+	// an empty packet confirms the handshake.
+	confirmation, err := NewPacket(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.send(confirmation.marshal()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// handleIncomingPackets reads packets from the connection until it fails or done is closed.
+func (econn *encryptedConn) handleIncomingPackets(done <-chan struct{}) chan Packet {
 	ch := make(chan Packet, 0)
 	go func() {
+		defer close(ch)
 		ioReader := bufio.NewReader(econn.conn)
 		for {
 			p, err := ParsePacket(ioReader, econn.decipher)
@@ -92,10 +159,13 @@ func (econn *encryptedConn) handleIncomingPackets() chan Packet {
 				//
 				// if somebody calls encryptedConn.close(),
 				// ParsePacket() will return an error, and we will close the channel.
-				close(ch)
 				return
 			}
-			ch <- p
+			select {
+			case ch <- p:
+			case <-done:
+				return
+			}
 		}
 	}()
 	return ch
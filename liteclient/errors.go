@@ -1,9 +1,17 @@
 package liteclient
 
 import (
+	"errors"
 	"fmt"
 )
 
+var (
+	// ErrClosed is returned by requests to a closed client or connection.
+	ErrClosed = newClientError("client is closed")
+	// ErrWaitTimeout is returned by WaitMasterchainSeqno when a lite server doesn't get the block in time.
+	ErrWaitTimeout = errors.New("timeout waiting for masterchain block")
+)
+
 type clientError string
 
 func (e clientError) Error() string {
@@ -2854,6 +2854,251 @@ func (t *LiteServerDispatchQueueInfoC) UnmarshalTL(r io.Reader) error {
 	return nil
 }
 
+type LiteServerTransactionMetadataC struct {
+	Mode        uint32
+	Depth       uint32
+	Initiator   LiteServerAccountIdC
+	InitiatorLt uint64
+}
+
+func (t LiteServerTransactionMetadataC) MarshalTL() ([]byte, error) {
+	var (
+		err error
+		b   []byte
+	)
+	buf := new(bytes.Buffer)
+	b, err = tl.Marshal(t.Mode)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Depth)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Initiator)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.InitiatorLt)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *LiteServerTransactionMetadataC) UnmarshalTL(r io.Reader) error {
+	var err error
+	err = tl.Unmarshal(r, &t.Mode)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Depth)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Initiator)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.InitiatorLt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type LiteServerDispatchQueueMessageC struct {
+	Addr     tl.Int256
+	Lt       uint64
+	Hash     tl.Int256
+	Metadata LiteServerTransactionMetadataC
+}
+
+func (t LiteServerDispatchQueueMessageC) MarshalTL() ([]byte, error) {
+	var (
+		err error
+		b   []byte
+	)
+	buf := new(bytes.Buffer)
+	b, err = tl.Marshal(t.Addr)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Lt)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Hash)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *LiteServerDispatchQueueMessageC) UnmarshalTL(r io.Reader) error {
+	var err error
+	err = tl.Unmarshal(r, &t.Addr)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Lt)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Hash)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Metadata)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type LiteServerDispatchQueueMessagesC struct {
+	Mode        uint32
+	Id          TonNodeBlockIdExtC
+	Messages    []LiteServerDispatchQueueMessageC
+	Complete    bool
+	Proof       []byte
+	MessagesBoc []byte
+}
+
+func (t LiteServerDispatchQueueMessagesC) MarshalTL() ([]byte, error) {
+	var (
+		err error
+		b   []byte
+	)
+	buf := new(bytes.Buffer)
+	b, err = tl.Marshal(t.Mode)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Messages)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Complete)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	if (t.Mode>>0)&1 == 1 {
+		b, err = tl.Marshal(t.Proof)
+		if err != nil {
+			return nil, err
+		}
+		_, err = buf.Write(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if (t.Mode>>2)&1 == 1 {
+		b, err = tl.Marshal(t.MessagesBoc)
+		if err != nil {
+			return nil, err
+		}
+		_, err = buf.Write(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *LiteServerDispatchQueueMessagesC) UnmarshalTL(r io.Reader) error {
+	var err error
+	err = tl.Unmarshal(r, &t.Mode)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Id)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Messages)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Complete)
+	if err != nil {
+		return err
+	}
+	if (t.Mode>>0)&1 == 1 {
+		var tempProof []byte
+		err = tl.Unmarshal(r, &tempProof)
+		if err != nil {
+			return err
+		}
+		t.Proof = tempProof
+	}
+	if (t.Mode>>2)&1 == 1 {
+		var tempMessagesBoc []byte
+		err = tl.Unmarshal(r, &tempMessagesBoc)
+		if err != nil {
+			return err
+		}
+		t.MessagesBoc = tempMessagesBoc
+	}
+	return nil
+}
+
 type LiteProxyRequestRateLimitC struct {
 	Limit   uint32
 	PerTime uint32
@@ -4259,26 +4504,6 @@ func (t *LiteServerListBlockTransactionsRequest) UnmarshalTL(r io.Reader) error
 		}
 		t.After = &tempAfter
 	}
-	if (t.Mode>>6)&1 == 1 {
-		var tTrue bool
-		err = tl.Unmarshal(r, &tTrue)
-		if err != nil {
-			return err
-		}
-		if tTrue != true {
-			return fmt.Errorf("not a True type")
-		}
-	}
-	if (t.Mode>>5)&1 == 1 {
-		var tTrue bool
-		err = tl.Unmarshal(r, &tTrue)
-		if err != nil {
-			return err
-		}
-		if tTrue != true {
-			return fmt.Errorf("not a True type")
-		}
-	}
 	return nil
 }
 
@@ -4385,26 +4610,6 @@ func (t *LiteServerListBlockTransactionsExtRequest) UnmarshalTL(r io.Reader) err
 		}
 		t.After = &tempAfter
 	}
-	if (t.Mode>>6)&1 == 1 {
-		var tTrue bool
-		err = tl.Unmarshal(r, &tTrue)
-		if err != nil {
-			return err
-		}
-		if tTrue != true {
-			return fmt.Errorf("not a True type")
-		}
-	}
-	if (t.Mode>>5)&1 == 1 {
-		var tTrue bool
-		err = tl.Unmarshal(r, &tTrue)
-		if err != nil {
-			return err
-		}
-		if tTrue != true {
-			return fmt.Errorf("not a True type")
-		}
-	}
 	return nil
 }
 
@@ -5198,16 +5403,6 @@ func (t *LiteServerGetDispatchQueueInfoRequest) UnmarshalTL(r io.Reader) error {
 	if err != nil {
 		return err
 	}
-	if (t.Mode>>0)&1 == 1 {
-		var tTrue bool
-		err = tl.Unmarshal(r, &tTrue)
-		if err != nil {
-			return err
-		}
-		if tTrue != true {
-			return fmt.Errorf("not a True type")
-		}
-	}
 	return nil
 }
 
@@ -5242,6 +5437,119 @@ func (c *Client) LiteServerGetDispatchQueueInfo(ctx context.Context, request Lit
 	return res, fmt.Errorf("invalid tag")
 }
 
+type LiteServerGetDispatchQueueMessagesRequest struct {
+	Mode        uint32
+	Id          TonNodeBlockIdExtC
+	Addr        tl.Int256
+	AfterLt     uint64
+	MaxMessages uint32
+}
+
+func (t LiteServerGetDispatchQueueMessagesRequest) MarshalTL() ([]byte, error) {
+	var (
+		err error
+		b   []byte
+	)
+	buf := new(bytes.Buffer)
+	b, err = tl.Marshal(t.Mode)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Id)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.Addr)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.AfterLt)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	b, err = tl.Marshal(t.MaxMessages)
+	if err != nil {
+		return nil, err
+	}
+	_, err = buf.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *LiteServerGetDispatchQueueMessagesRequest) UnmarshalTL(r io.Reader) error {
+	var err error
+	err = tl.Unmarshal(r, &t.Mode)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Id)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.Addr)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.AfterLt)
+	if err != nil {
+		return err
+	}
+	err = tl.Unmarshal(r, &t.MaxMessages)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) LiteServerGetDispatchQueueMessages(ctx context.Context, request LiteServerGetDispatchQueueMessagesRequest) (res LiteServerDispatchQueueMessagesC, err error) {
+	payload, err := tl.Marshal(struct {
+		tl.SumType
+		Req LiteServerGetDispatchQueueMessagesRequest `tlSumType:"bbfd6439"`
+	}{SumType: "Req", Req: request})
+	if err != nil {
+		return res, err
+	}
+	resp, err := c.liteServerRequest(ctx, payload)
+	if err != nil {
+		return res, err
+	}
+	if len(resp) < 4 {
+		return res, fmt.Errorf("not enough bytes for tag")
+	}
+	tag := binary.LittleEndian.Uint32(resp[:4])
+	if tag == 0xbba9e148 {
+		var errRes LiteServerErrorC
+		err = tl.Unmarshal(bytes.NewReader(resp[4:]), &errRes)
+		if err != nil {
+			return res, err
+		}
+		return res, errRes
+	}
+	if tag == 0x4b407931 {
+		err = tl.Unmarshal(bytes.NewReader(resp[4:]), &res)
+		return res, err
+	}
+	return res, fmt.Errorf("invalid tag")
+}
+
 type LiteProxyGetRequestRateLimitRequest struct{}
 
 func (t *LiteProxyGetRequestRateLimitRequest) UnmarshalTL(r io.Reader) error {
@@ -5295,6 +5603,8 @@ var (
 	decodeFuncLiteServerGetConfigParamsRequest = decodeRequest(0x2a111c19, LiteServerGetConfigParamsRequestName, LiteServerGetConfigParamsRequest{})
 	// 0x01e66bf3
 	decodeFuncLiteServerGetDispatchQueueInfoRequest = decodeRequest(0x01e66bf3, LiteServerGetDispatchQueueInfoRequestName, LiteServerGetDispatchQueueInfoRequest{})
+	// 0xbbfd6439
+	decodeFuncLiteServerGetDispatchQueueMessagesRequest = decodeRequest(0xbbfd6439, LiteServerGetDispatchQueueMessagesRequestName, LiteServerGetDispatchQueueMessagesRequest{})
 	// 0xd122b662
 	decodeFuncLiteServerGetLibrariesRequest = decodeRequest(0xd122b662, LiteServerGetLibrariesRequestName, LiteServerGetLibrariesRequest{})
 	// 0x8c026c31
@@ -5346,6 +5656,7 @@ var taggedRequestDecodeFunctions = map[uint32]reqDecoderFunc{
 	0x911b26b7: decodeFuncLiteServerGetConfigAllRequest,
 	0x2a111c19: decodeFuncLiteServerGetConfigParamsRequest,
 	0x01e66bf3: decodeFuncLiteServerGetDispatchQueueInfoRequest,
+	0xbbfd6439: decodeFuncLiteServerGetDispatchQueueMessagesRequest,
 	0xd122b662: decodeFuncLiteServerGetLibrariesRequest,
 	0x8c026c31: decodeFuncLiteServerGetLibrariesWithProofRequest,
 	0x70a671df: decodeFuncLiteServerGetMasterchainInfoExtRequest,
@@ -5378,6 +5689,7 @@ const (
 	LiteServerGetConfigAllRequestName             RequestName = "liteServer.getConfigAll"
 	LiteServerGetConfigParamsRequestName          RequestName = "liteServer.getConfigParams"
 	LiteServerGetDispatchQueueInfoRequestName     RequestName = "liteServer.getDispatchQueueInfo"
+	LiteServerGetDispatchQueueMessagesRequestName RequestName = "liteServer.getDispatchQueueMessages"
 	LiteServerGetLibrariesRequestName             RequestName = "liteServer.getLibraries"
 	LiteServerGetLibrariesWithProofRequestName    RequestName = "liteServer.getLibrariesWithProof"
 	LiteServerGetMasterchainInfoExtRequestName    RequestName = "liteServer.getMasterchainInfoExt"
@@ -5398,3 +5710,344 @@ const (
 	LiteServerRunSmcMethodRequestName             RequestName = "liteServer.runSmcMethod"
 	LiteServerSendMessageRequestName              RequestName = "liteServer.sendMessage"
 )
+
+type LiteServerHandler interface {
+	LiteServerGetMasterchainInfo(ctx context.Context) (LiteServerMasterchainInfoC, error)
+	LiteServerGetMasterchainInfoExt(ctx context.Context, request LiteServerGetMasterchainInfoExtRequest) (LiteServerMasterchainInfoExtC, error)
+	LiteServerGetTime(ctx context.Context) (LiteServerCurrentTimeC, error)
+	LiteServerGetVersion(ctx context.Context) (LiteServerVersionC, error)
+	LiteServerGetBlock(ctx context.Context, request LiteServerGetBlockRequest) (LiteServerBlockDataC, error)
+	LiteServerGetState(ctx context.Context, request LiteServerGetStateRequest) (LiteServerBlockStateC, error)
+	LiteServerGetBlockHeader(ctx context.Context, request LiteServerGetBlockHeaderRequest) (LiteServerBlockHeaderC, error)
+	LiteServerSendMessage(ctx context.Context, request LiteServerSendMessageRequest) (LiteServerSendMsgStatusC, error)
+	LiteServerGetAccountState(ctx context.Context, request LiteServerGetAccountStateRequest) (LiteServerAccountStateC, error)
+	LiteServerGetAccountStatePrunned(ctx context.Context, request LiteServerGetAccountStatePrunnedRequest) (LiteServerAccountStateC, error)
+	LiteServerRunSmcMethod(ctx context.Context, request LiteServerRunSmcMethodRequest) (LiteServerRunMethodResultC, error)
+	LiteServerGetShardInfo(ctx context.Context, request LiteServerGetShardInfoRequest) (LiteServerShardInfoC, error)
+	LiteServerGetAllShardsInfo(ctx context.Context, request LiteServerGetAllShardsInfoRequest) (LiteServerAllShardsInfoC, error)
+	LiteServerGetOneTransaction(ctx context.Context, request LiteServerGetOneTransactionRequest) (LiteServerTransactionInfoC, error)
+	LiteServerGetTransactions(ctx context.Context, request LiteServerGetTransactionsRequest) (LiteServerTransactionListC, error)
+	LiteServerLookupBlock(ctx context.Context, request LiteServerLookupBlockRequest) (LiteServerBlockHeaderC, error)
+	LiteServerLookupBlockWithProof(ctx context.Context, request LiteServerLookupBlockWithProofRequest) (LiteServerLookupBlockResultC, error)
+	LiteServerListBlockTransactions(ctx context.Context, request LiteServerListBlockTransactionsRequest) (LiteServerBlockTransactionsC, error)
+	LiteServerListBlockTransactionsExt(ctx context.Context, request LiteServerListBlockTransactionsExtRequest) (LiteServerBlockTransactionsExtC, error)
+	LiteServerGetBlockProof(ctx context.Context, request LiteServerGetBlockProofRequest) (LiteServerPartialBlockProofC, error)
+	LiteServerGetConfigAll(ctx context.Context, request LiteServerGetConfigAllRequest) (LiteServerConfigInfoC, error)
+	LiteServerGetConfigParams(ctx context.Context, request LiteServerGetConfigParamsRequest) (LiteServerConfigInfoC, error)
+	LiteServerGetValidatorStats(ctx context.Context, request LiteServerGetValidatorStatsRequest) (LiteServerValidatorStatsC, error)
+	LiteServerGetLibraries(ctx context.Context, request LiteServerGetLibrariesRequest) (LiteServerLibraryResultC, error)
+	LiteServerGetLibrariesWithProof(ctx context.Context, request LiteServerGetLibrariesWithProofRequest) (LiteServerLibraryResultWithProofC, error)
+	LiteServerGetShardBlockProof(ctx context.Context, request LiteServerGetShardBlockProofRequest) (LiteServerShardBlockProofC, error)
+	LiteServerGetOutMsgQueueSizes(ctx context.Context, request LiteServerGetOutMsgQueueSizesRequest) (LiteServerOutMsgQueueSizesC, error)
+	LiteServerGetDispatchQueueInfo(ctx context.Context, request LiteServerGetDispatchQueueInfoRequest) (LiteServerDispatchQueueInfoC, error)
+	LiteServerGetDispatchQueueMessages(ctx context.Context, request LiteServerGetDispatchQueueMessagesRequest) (LiteServerDispatchQueueMessagesC, error)
+	LiteProxyGetRequestRateLimit(ctx context.Context) (LiteProxyRequestRateLimitC, error)
+}
+
+type UnimplementedLiteServerHandler struct{}
+
+func (UnimplementedLiteServerHandler) LiteServerGetMasterchainInfo(ctx context.Context) (res LiteServerMasterchainInfoC, err error) {
+	return res, notImplemented(LiteServerGetMasterchainInfoRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetMasterchainInfoExt(ctx context.Context, request LiteServerGetMasterchainInfoExtRequest) (res LiteServerMasterchainInfoExtC, err error) {
+	return res, notImplemented(LiteServerGetMasterchainInfoExtRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetTime(ctx context.Context) (res LiteServerCurrentTimeC, err error) {
+	return res, notImplemented(LiteServerGetTimeRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetVersion(ctx context.Context) (res LiteServerVersionC, err error) {
+	return res, notImplemented(LiteServerGetVersionRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetBlock(ctx context.Context, request LiteServerGetBlockRequest) (res LiteServerBlockDataC, err error) {
+	return res, notImplemented(LiteServerGetBlockRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetState(ctx context.Context, request LiteServerGetStateRequest) (res LiteServerBlockStateC, err error) {
+	return res, notImplemented(LiteServerGetStateRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetBlockHeader(ctx context.Context, request LiteServerGetBlockHeaderRequest) (res LiteServerBlockHeaderC, err error) {
+	return res, notImplemented(LiteServerGetBlockHeaderRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerSendMessage(ctx context.Context, request LiteServerSendMessageRequest) (res LiteServerSendMsgStatusC, err error) {
+	return res, notImplemented(LiteServerSendMessageRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetAccountState(ctx context.Context, request LiteServerGetAccountStateRequest) (res LiteServerAccountStateC, err error) {
+	return res, notImplemented(LiteServerGetAccountStateRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetAccountStatePrunned(ctx context.Context, request LiteServerGetAccountStatePrunnedRequest) (res LiteServerAccountStateC, err error) {
+	return res, notImplemented(LiteServerGetAccountStatePrunnedRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerRunSmcMethod(ctx context.Context, request LiteServerRunSmcMethodRequest) (res LiteServerRunMethodResultC, err error) {
+	return res, notImplemented(LiteServerRunSmcMethodRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetShardInfo(ctx context.Context, request LiteServerGetShardInfoRequest) (res LiteServerShardInfoC, err error) {
+	return res, notImplemented(LiteServerGetShardInfoRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetAllShardsInfo(ctx context.Context, request LiteServerGetAllShardsInfoRequest) (res LiteServerAllShardsInfoC, err error) {
+	return res, notImplemented(LiteServerGetAllShardsInfoRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetOneTransaction(ctx context.Context, request LiteServerGetOneTransactionRequest) (res LiteServerTransactionInfoC, err error) {
+	return res, notImplemented(LiteServerGetOneTransactionRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetTransactions(ctx context.Context, request LiteServerGetTransactionsRequest) (res LiteServerTransactionListC, err error) {
+	return res, notImplemented(LiteServerGetTransactionsRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerLookupBlock(ctx context.Context, request LiteServerLookupBlockRequest) (res LiteServerBlockHeaderC, err error) {
+	return res, notImplemented(LiteServerLookupBlockRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerLookupBlockWithProof(ctx context.Context, request LiteServerLookupBlockWithProofRequest) (res LiteServerLookupBlockResultC, err error) {
+	return res, notImplemented(LiteServerLookupBlockWithProofRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerListBlockTransactions(ctx context.Context, request LiteServerListBlockTransactionsRequest) (res LiteServerBlockTransactionsC, err error) {
+	return res, notImplemented(LiteServerListBlockTransactionsRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerListBlockTransactionsExt(ctx context.Context, request LiteServerListBlockTransactionsExtRequest) (res LiteServerBlockTransactionsExtC, err error) {
+	return res, notImplemented(LiteServerListBlockTransactionsExtRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetBlockProof(ctx context.Context, request LiteServerGetBlockProofRequest) (res LiteServerPartialBlockProofC, err error) {
+	return res, notImplemented(LiteServerGetBlockProofRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetConfigAll(ctx context.Context, request LiteServerGetConfigAllRequest) (res LiteServerConfigInfoC, err error) {
+	return res, notImplemented(LiteServerGetConfigAllRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetConfigParams(ctx context.Context, request LiteServerGetConfigParamsRequest) (res LiteServerConfigInfoC, err error) {
+	return res, notImplemented(LiteServerGetConfigParamsRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetValidatorStats(ctx context.Context, request LiteServerGetValidatorStatsRequest) (res LiteServerValidatorStatsC, err error) {
+	return res, notImplemented(LiteServerGetValidatorStatsRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetLibraries(ctx context.Context, request LiteServerGetLibrariesRequest) (res LiteServerLibraryResultC, err error) {
+	return res, notImplemented(LiteServerGetLibrariesRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetLibrariesWithProof(ctx context.Context, request LiteServerGetLibrariesWithProofRequest) (res LiteServerLibraryResultWithProofC, err error) {
+	return res, notImplemented(LiteServerGetLibrariesWithProofRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetShardBlockProof(ctx context.Context, request LiteServerGetShardBlockProofRequest) (res LiteServerShardBlockProofC, err error) {
+	return res, notImplemented(LiteServerGetShardBlockProofRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetOutMsgQueueSizes(ctx context.Context, request LiteServerGetOutMsgQueueSizesRequest) (res LiteServerOutMsgQueueSizesC, err error) {
+	return res, notImplemented(LiteServerGetOutMsgQueueSizesRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetDispatchQueueInfo(ctx context.Context, request LiteServerGetDispatchQueueInfoRequest) (res LiteServerDispatchQueueInfoC, err error) {
+	return res, notImplemented(LiteServerGetDispatchQueueInfoRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteServerGetDispatchQueueMessages(ctx context.Context, request LiteServerGetDispatchQueueMessagesRequest) (res LiteServerDispatchQueueMessagesC, err error) {
+	return res, notImplemented(LiteServerGetDispatchQueueMessagesRequestName)
+}
+
+func (UnimplementedLiteServerHandler) LiteProxyGetRequestRateLimit(ctx context.Context) (res LiteProxyRequestRateLimitC, err error) {
+	return res, notImplemented(LiteProxyGetRequestRateLimitRequestName)
+}
+
+func dispatchRequest(ctx context.Context, h LiteServerHandler, request any) ([]byte, error) {
+	switch r := request.(type) {
+	case LiteServerGetMasterchainInfoRequest:
+		res, err := h.LiteServerGetMasterchainInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x85832881, res)
+	case LiteServerGetMasterchainInfoExtRequest:
+		res, err := h.LiteServerGetMasterchainInfoExt(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xa8cce0f5, res)
+	case LiteServerGetTimeRequest:
+		res, err := h.LiteServerGetTime(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xe953000d, res)
+	case LiteServerGetVersionRequest:
+		res, err := h.LiteServerGetVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x5a0491e5, res)
+	case LiteServerGetBlockRequest:
+		res, err := h.LiteServerGetBlock(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xa574ed6c, res)
+	case LiteServerGetStateRequest:
+		res, err := h.LiteServerGetState(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xabaddc0c, res)
+	case LiteServerGetBlockHeaderRequest:
+		res, err := h.LiteServerGetBlockHeader(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x752d8219, res)
+	case LiteServerSendMessageRequest:
+		res, err := h.LiteServerSendMessage(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x3950e597, res)
+	case LiteServerGetAccountStateRequest:
+		res, err := h.LiteServerGetAccountState(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x7079c751, res)
+	case LiteServerGetAccountStatePrunnedRequest:
+		res, err := h.LiteServerGetAccountStatePrunned(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x7079c751, res)
+	case LiteServerRunSmcMethodRequest:
+		res, err := h.LiteServerRunSmcMethod(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xa39a616b, res)
+	case LiteServerGetShardInfoRequest:
+		res, err := h.LiteServerGetShardInfo(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x9fe6cd84, res)
+	case LiteServerGetAllShardsInfoRequest:
+		res, err := h.LiteServerGetAllShardsInfo(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x98fe72d, res)
+	case LiteServerGetOneTransactionRequest:
+		res, err := h.LiteServerGetOneTransaction(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xedeed47, res)
+	case LiteServerGetTransactionsRequest:
+		res, err := h.LiteServerGetTransactions(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x6f26c60b, res)
+	case LiteServerLookupBlockRequest:
+		res, err := h.LiteServerLookupBlock(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x752d8219, res)
+	case LiteServerLookupBlockWithProofRequest:
+		res, err := h.LiteServerLookupBlockWithProof(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x57c7ccc5, res)
+	case LiteServerListBlockTransactionsRequest:
+		res, err := h.LiteServerListBlockTransactions(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xbd8cad2b, res)
+	case LiteServerListBlockTransactionsExtRequest:
+		res, err := h.LiteServerListBlockTransactionsExt(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xfb8ffce4, res)
+	case LiteServerGetBlockProofRequest:
+		res, err := h.LiteServerGetBlockProof(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x8ed0d2c1, res)
+	case LiteServerGetConfigAllRequest:
+		res, err := h.LiteServerGetConfigAll(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xae7b272f, res)
+	case LiteServerGetConfigParamsRequest:
+		res, err := h.LiteServerGetConfigParams(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xae7b272f, res)
+	case LiteServerGetValidatorStatsRequest:
+		res, err := h.LiteServerGetValidatorStats(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xb9f796d8, res)
+	case LiteServerGetLibrariesRequest:
+		res, err := h.LiteServerGetLibraries(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x117ab96b, res)
+	case LiteServerGetLibrariesWithProofRequest:
+		res, err := h.LiteServerGetLibrariesWithProof(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x99370a1f, res)
+	case LiteServerGetShardBlockProofRequest:
+		res, err := h.LiteServerGetShardBlockProof(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x1d62a07a, res)
+	case LiteServerGetOutMsgQueueSizesRequest:
+		res, err := h.LiteServerGetOutMsgQueueSizes(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0xf8504a03, res)
+	case LiteServerGetDispatchQueueInfoRequest:
+		res, err := h.LiteServerGetDispatchQueueInfo(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x5d1132d0, res)
+	case LiteServerGetDispatchQueueMessagesRequest:
+		res, err := h.LiteServerGetDispatchQueueMessages(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x4b407931, res)
+	case LiteProxyGetRequestRateLimitRequest:
+		res, err := h.LiteProxyGetRequestRateLimit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return marshalResponse(0x14cb3f0c, res)
+	}
+	return nil, fmt.Errorf("unsupported request %T", request)
+}
@@ -31,6 +31,11 @@ func main() {
 		panic(err)
 	}
 
+	handler, err := g.LoadServerHandler("LiteServerHandler", parsed.Functions)
+	if err != nil {
+		panic(err)
+	}
+
 	f, err := os.Create("generated.go")
 	if err != nil {
 		panic(err)
@@ -58,4 +63,8 @@ import (
 	if err != nil {
 		panic(err)
 	}
+	_, err = f.WriteString(handler)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,87 @@
+package liteclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/caigou-xyz/tongo/tl"
+)
+
+// RequestInfo describes a request sent by Client.Request.
+type RequestInfo struct {
+	// Method is a name of the lite server method decoded from the TL tag of the query.
+	Method RequestName
+	// ServerHost is an address of the lite server the request is sent to.
+	// It is empty for a replay client.
+	ServerHost string
+	// ConnID is an ID of the client configured with OptionConnID.
+	// Connection pools set it to IDs of their connections.
+	ConnID int
+	// RequestSize is a size of the TL-encoded query in bytes.
+	RequestSize int
+}
+
+// RequestResult describes an outcome of a request sent by Client.Request.
+type RequestResult struct {
+	Latency time.Duration
+	// ResponseSize is a size of the TL-encoded answer in bytes.
+	ResponseSize int
+	// LiteServerError is set if the lite server answered with liteServer.error.
+	LiteServerError *LiteServerErrorC
+	// Err is an error that prevented getting an answer, like a timeout or a closed connection.
+	Err error
+}
+
+// RequestHook observes requests sent by a client to lite servers.
+// Hooks are called synchronously, so they must not block.
+type RequestHook interface {
+	// BeforeRequest is called before a request is sent.
+	// The returned context is used for the request and passed to AfterRequest,
+	// so a hook can start a tracing span here.
+	BeforeRequest(ctx context.Context, info RequestInfo) context.Context
+	// AfterRequest is called when a request is done.
+	AfterRequest(ctx context.Context, info RequestInfo, result RequestResult)
+}
+
+// OptionRequestHook adds a hook to be notified about every request of a client.
+func OptionRequestHook(hook RequestHook) Options {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hook)
+	}
+}
+
+// RequestInvoker sends a query to a lite server and returns its answer.
+type RequestInvoker func(ctx context.Context, q []byte) ([]byte, error)
+
+// RequestInterceptor wraps Client.Request.
+// invoker sends the query using the client's own connections,
+// an interceptor is free to call it several times or to send the query somewhere else.
+type RequestInterceptor func(ctx context.Context, q []byte, invoker RequestInvoker) ([]byte, error)
+
+// OptionRequestInterceptor sets an interceptor of all requests of a client.
+func OptionRequestInterceptor(interceptor RequestInterceptor) Options {
+	return func(c *Client) {
+		c.interceptor = interceptor
+	}
+}
+
+// OptionConnID sets an ID reported to request hooks in RequestInfo.ConnID.
+func OptionConnID(id int) Options {
+	return func(c *Client) {
+		c.connID = id
+	}
+}
+
+// LiteServerErrorFromAnswer returns liteServer.error if the given answer contains one.
+func LiteServerErrorFromAnswer(answer []byte) *LiteServerErrorC {
+	if len(answer) < 4 || binary.LittleEndian.Uint32(answer) != magicLiteServerError {
+		return nil
+	}
+	var errRes LiteServerErrorC
+	if err := tl.Unmarshal(bytes.NewReader(answer[4:]), &errRes); err != nil {
+		return nil
+	}
+	return &errRes
+}
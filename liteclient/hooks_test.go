@@ -0,0 +1,69 @@
+package liteclient
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type recordingHook struct {
+	mu      sync.Mutex
+	infos   []RequestInfo
+	results []RequestResult
+}
+
+type hookCtxKey struct{}
+
+func (h *recordingHook) BeforeRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, hookCtxKey{}, info.Method)
+}
+
+func (h *recordingHook) AfterRequest(ctx context.Context, info RequestInfo, result RequestResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ctx.Value(hookCtxKey{}) != info.Method {
+		panic("context from BeforeRequest is lost")
+	}
+	h.infos = append(h.infos, info)
+	h.results = append(h.results, result)
+}
+
+func TestClient_RequestHook(t *testing.T) {
+	hook := &recordingHook{}
+	server, client := startTestServer(t, &testHandler{}, OptionRequestHook(hook), OptionConnID(7))
+	ctx := context.Background()
+	if _, err := client.LiteServerGetMasterchainInfo(ctx); err != nil {
+		t.Fatalf("LiteServerGetMasterchainInfo() failed: %v", err)
+	}
+	if _, err := client.LiteServerGetTime(ctx); err == nil {
+		t.Fatalf("LiteServerGetTime() must fail")
+	}
+	client.Close(context.Background())
+	if _, err := client.LiteServerGetTime(ctx); err == nil {
+		t.Fatalf("LiteServerGetTime() must fail")
+	}
+	server.Close()
+
+	tests := []struct {
+		method     RequestName
+		wantLSCode bool
+	}{
+		{method: LiteServerGetMasterchainInfoRequestName},
+		{method: LiteServerGetTimeRequestName, wantLSCode: true},
+	}
+	if len(hook.infos) != len(tests) {
+		t.Fatalf("want %v requests, got: %v", len(tests), len(hook.infos))
+	}
+	for i, tt := range tests {
+		info, result := hook.infos[i], hook.results[i]
+		if info.Method != tt.method || info.ConnID != 7 || info.ServerHost == "" || info.RequestSize == 0 {
+			t.Fatalf("unexpected request info: %+v", info)
+		}
+		if result.Err != nil || result.ResponseSize == 0 || result.Latency == 0 {
+			t.Fatalf("unexpected request result: %+v", result)
+		}
+		if (result.LiteServerError != nil) != tt.wantLSCode {
+			t.Fatalf("want lite server error: %v, got: %v", tt.wantLSCode, result.LiteServerError)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package liteclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/caigou-xyz/tongo/tl"
+)
+
+const (
+	magicLiteServerError = 0xbba9e148 // crc32("liteServer.error code:int message:string = liteServer.Error")
+
+	// errorCodeFailure is a generic error code of a lite server,
+	// it is used when a handler returns an error that is not LiteServerErrorC.
+	errorCodeFailure = 601
+	// errorCodeProtoViolation is returned when a query can't be decoded.
+	errorCodeProtoViolation = 621
+	// errorCodeTimeout is returned when a block doesn't appear in time.
+	errorCodeTimeout = 652
+)
+
+var (
+	ErrServerClosed = errors.New("server closed")
+)
+
+// MasterchainSeqnoWaiter can be implemented by a LiteServerHandler to support
+// liteServer.waitMasterchainSeqno query prefix.
+// If a handler doesn't implement it, the prefix is ignored.
+type MasterchainSeqnoWaiter interface {
+	WaitMasterchainSeqno(ctx context.Context, seqno uint32, timeout uint32) error
+}
+
+// Server accepts ADNL connections over TCP and serves lite server queries.
+// Each decoded liteServer.* query is routed to the corresponding method of LiteServerHandler.
+//
+// Client implements LiteServerHandler, so a Server in front of a Client works as a proxy.
+type Server struct {
+	privateKey ed25519.PrivateKey
+	handler    LiteServerHandler
+
+	// mu protects all fields below.
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+}
+
+// NewServer returns a new lite server with the given identity.
+func NewServer(privateKey ed25519.PrivateKey, handler LiteServerHandler) *Server {
+	return &Server{
+		privateKey: privateKey,
+		handler:    handler,
+		listeners:  map[net.Listener]struct{}{},
+		conns:      map[net.Conn]struct{}{},
+	}
+}
+
+// PublicKey returns a public key clients use to connect to this server.
+func (s *Server) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+// ListenAndServe listens on the TCP network address addr and serves incoming connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts incoming connections on the listener l until the server is closed.
+// Serve always returns a non-nil error, after Close it returns ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.untrackListener(l)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close closes all listeners and active connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+	return nil
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *Server) trackListener(l net.Listener) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackListener(l net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.listeners, l)
+}
+
+func (s *Server) trackConn(c net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[c] = struct{}{}
+	return true
+}
+
+func (s *Server) untrackConn(c net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, c)
+}
+
+// serverConn serializes writes to an encrypted connection.
+type serverConn struct {
+	mu    sync.Mutex
+	econn *encryptedConn
+}
+
+func (c *serverConn) send(payload []byte) error {
+	p, err := NewPacket(payload)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.econn.send(p.marshal())
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	if !s.trackConn(conn) {
+		return
+	}
+	defer s.untrackConn(conn)
+
+	econn, err := acceptEncryptedConnection(conn, s.privateKey)
+	if err != nil {
+		slog.Debug("liteclient.Server handshake failed", "remote", conn.RemoteAddr().String(), "err", err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sc := &serverConn{econn: econn}
+	for p := range econn.handleIncomingPackets(ctx.Done()) {
+		switch p.MagicType() {
+		case magicTCPPing:
+			if len(p.Payload) != 12 {
+				continue
+			}
+			pong := make([]byte, 12)
+			binary.LittleEndian.PutUint32(pong[:4], magicTCPPong)
+			copy(pong[4:], p.Payload[4:])
+			if err := sc.send(pong); err != nil {
+				return
+			}
+		case magicADNLQuery:
+			go s.processQuery(ctx, sc, p)
+		}
+	}
+}
+
+func (s *Server) processQuery(ctx context.Context, sc *serverConn, p Packet) {
+	var msg AdnlMessage
+	if err := tl.Unmarshal(bytes.NewReader(p.Payload), &msg); err != nil {
+		slog.Debug("liteclient.Server failed to decode adnl.message.query", "err", err)
+		return
+	}
+	query := msg.AdnlMessageQuery
+	answer, err := s.handleQuery(ctx, query.Query)
+	if err != nil {
+		answer, err = marshalError(err)
+		if err != nil {
+			slog.Info("liteclient.Server failed to encode error", "err", err)
+			return
+		}
+	}
+	payload, err := tl.Marshal(AdnlMessage{
+		SumType: "AdnlMessageAnswer",
+		AdnlMessageAnswer: struct {
+			QueryId tl.Int256
+			Answer  []byte
+		}{QueryId: query.QueryId, Answer: answer},
+	})
+	if err != nil {
+		slog.Info("liteclient.Server failed to encode adnl.message.answer", "err", err)
+		return
+	}
+	if err := sc.send(payload); err != nil {
+		slog.Debug("liteclient.Server failed to send answer", "err", err)
+	}
+}
+
+// handleQuery decodes liteServer.query, calls a handler and returns a TL-encoded answer.
+func (s *Server) handleQuery(ctx context.Context, q []byte) ([]byte, error) {
+	if len(q) < 4 || binary.LittleEndian.Uint32(q[:4]) != magicLiteServerQuery {
+		return nil, LiteServerErrorC{Code: errorCodeProtoViolation, Message: "unknown query"}
+	}
+	var data []byte
+	if err := tl.Unmarshal(bytes.NewReader(q[4:]), &data); err != nil {
+		return nil, LiteServerErrorC{Code: errorCodeProtoViolation, Message: fmt.Sprintf("failed to decode liteServer.query: %v", err)}
+	}
+	if len(data) >= 12 && binary.LittleEndian.Uint32(data[:4]) == magicLiteServerWaitMasterchainSeqno {
+		seqno := binary.LittleEndian.Uint32(data[4:8])
+		timeout := binary.LittleEndian.Uint32(data[8:12])
+		data = data[12:]
+		if err := s.waitMasterchainSeqno(ctx, seqno, timeout); err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			// a bare prefix, nothing to do after waiting.
+			// Client.WaitMasterchainSeqno treats liteServer.error with code 0 as success.
+			return marshalResponse(magicLiteServerError, LiteServerErrorC{})
+		}
+	}
+	_, name, request, err := LiteapiRequestDecoder(data)
+	if err != nil {
+		return nil, LiteServerErrorC{Code: errorCodeProtoViolation, Message: err.Error()}
+	}
+	if request == nil {
+		return nil, LiteServerErrorC{Code: errorCodeProtoViolation, Message: fmt.Sprintf("unsupported query %v", *name)}
+	}
+	return dispatchRequest(ctx, s.handler, request)
+}
+
+// waitMasterchainSeqno calls MasterchainSeqnoWaiter of the handler limiting it with the timeout in milliseconds.
+// If the timeout expires, it returns a lite server error with errorCodeTimeout.
+// If ctx is done, it returns the context error.
+func (s *Server) waitMasterchainSeqno(ctx context.Context, seqno uint32, timeout uint32) error {
+	waiter, ok := s.handler.(MasterchainSeqnoWaiter)
+	if !ok {
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+	err := waiter.WaitMasterchainSeqno(waitCtx, seqno, timeout)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if waitCtx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+		return LiteServerErrorC{Code: errorCodeTimeout, Message: fmt.Sprintf("timeout waiting for masterchain block %v", seqno)}
+	}
+	return err
+}
+
+func marshalResponse(tag uint32, res any) ([]byte, error) {
+	b, err := tl.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	return append(binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(b)), tag), b...), nil
+}
+
+func marshalError(err error) ([]byte, error) {
+	var liteServerErr LiteServerErrorC
+	if !errors.As(err, &liteServerErr) {
+		liteServerErr = LiteServerErrorC{Code: errorCodeFailure, Message: err.Error()}
+	}
+	return marshalResponse(magicLiteServerError, liteServerErr)
+}
+
+func notImplemented(name RequestName) error {
+	return LiteServerErrorC{Code: errorCodeFailure, Message: fmt.Sprintf("%v is not implemented", name)}
+}
@@ -0,0 +1,182 @@
+package liteclient
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+type testHandler struct {
+	UnimplementedLiteServerHandler
+
+	info LiteServerMasterchainInfoC
+}
+
+func (h *testHandler) LiteServerGetMasterchainInfo(ctx context.Context) (LiteServerMasterchainInfoC, error) {
+	return h.info, nil
+}
+
+func (h *testHandler) LiteServerListBlockTransactions(ctx context.Context, request LiteServerListBlockTransactionsRequest) (LiteServerBlockTransactionsC, error) {
+	return LiteServerBlockTransactionsC{
+		Id:       request.Id,
+		ReqCount: request.Count,
+		Ids:      []LiteServerTransactionIdC{},
+		Proof:    []byte{},
+	}, nil
+}
+
+func (h *testHandler) WaitMasterchainSeqno(ctx context.Context, seqno uint32, timeout uint32) error {
+	if seqno <= h.info.Last.Seqno {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (h *testHandler) LiteServerGetTime(ctx context.Context) (LiteServerCurrentTimeC, error) {
+	return LiteServerCurrentTimeC{}, errors.New("clock is broken")
+}
+
+func startTestServer(t *testing.T, handler LiteServerHandler, opts ...Options) (*Server, *Client) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	server := NewServer(key, handler)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	go server.Serve(l)
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := NewConnection(context.Background(), server.PublicKey(), l.Addr().String())
+	if err != nil {
+		t.Fatalf("NewConnection() failed: %v", err)
+	}
+	return server, NewClient(conn, append([]Options{OptionTimeout(5 * time.Second)}, opts...)...)
+}
+
+func TestServer(t *testing.T) {
+	handler := &testHandler{
+		info: LiteServerMasterchainInfoC{
+			Last: TonNodeBlockIdExtC{Workchain: 0xffffffff, Shard: 0x8000000000000000, Seqno: 100500},
+		},
+	}
+	_, client := startTestServer(t, handler)
+	ctx := context.Background()
+
+	info, err := client.LiteServerGetMasterchainInfo(ctx)
+	if err != nil {
+		t.Fatalf("LiteServerGetMasterchainInfo() failed: %v", err)
+	}
+	if info.Last.Seqno != 100500 {
+		t.Fatalf("want seqno 100500, got: %v", info.Last.Seqno)
+	}
+
+	// want_proof and reverse_order are "true" flags that don't occupy any bytes on the wire.
+	txs, err := client.LiteServerListBlockTransactions(ctx, LiteServerListBlockTransactionsRequest{
+		Id:    info.Last,
+		Mode:  1<<5 | 1<<6 | 1<<7,
+		Count: 42,
+		After: &LiteServerTransactionId3C{Lt: 1},
+	})
+	if err != nil {
+		t.Fatalf("LiteServerListBlockTransactions() failed: %v", err)
+	}
+	if txs.ReqCount != 42 || txs.Id.Seqno != 100500 {
+		t.Fatalf("unexpected response: %v", txs)
+	}
+
+	_, err = client.LiteServerGetTime(ctx)
+	var liteServerErr LiteServerErrorC
+	if !errors.As(err, &liteServerErr) || liteServerErr.Message != "clock is broken" {
+		t.Fatalf("want lite server error, got: %v", err)
+	}
+
+	_, err = client.LiteServerGetVersion(ctx)
+	if !errors.As(err, &liteServerErr) || liteServerErr.Code != errorCodeFailure {
+		t.Fatalf("want not implemented error, got: %v", err)
+	}
+
+	if err := client.WaitMasterchainSeqno(ctx, 100500, 1000); err != nil {
+		t.Fatalf("WaitMasterchainSeqno() failed: %v", err)
+	}
+	if err := client.WaitMasterchainSeqno(ctx, 100501, 50); !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("want ErrWaitTimeout, got: %v", err)
+	}
+}
+
+func TestServer_Close(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	server := NewServer(key, UnimplementedLiteServerHandler{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(l)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	server.Close()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrServerClosed) {
+			t.Fatalf("want ErrServerClosed, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Serve() didn't return after Close()")
+	}
+}
+
+type blockingHandler struct {
+	UnimplementedLiteServerHandler
+}
+
+func (h *blockingHandler) LiteServerGetTime(ctx context.Context) (LiteServerCurrentTimeC, error) {
+	<-ctx.Done()
+	return LiteServerCurrentTimeC{}, ctx.Err()
+}
+
+func TestClient_Close(t *testing.T) {
+	_, client := startTestServer(t, &blockingHandler{})
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.LiteServerGetTime(context.Background())
+		errCh <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrClosed) {
+			t.Fatalf("want ErrClosed for in-flight request, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("in-flight request didn't fail after Close()")
+	}
+	if _, err := client.LiteServerGetTime(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("want ErrClosed, got: %v", err)
+	}
+	if client.IsOK() {
+		t.Fatalf("closed client must not be OK")
+	}
+	// Close is idempotent and all background goroutines have exited.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Close(ctx); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+}
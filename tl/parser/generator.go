@@ -351,17 +351,13 @@ func (g *Generator) generateUnmarshalerCode(declaration CombinatorDeclaration, r
 				return "", err
 			}
 			typeString := gt.String()
-			builder.WriteString(fmt.Sprintf("if (t.%s>>%s)&1 == 1{\n",
-				utils.ToCamelCase(field.Modificator.Name), field.Modificator.Bit))
 			if typeString == "True" {
-				// TODO: add field?
-				builder.WriteString("var tTrue bool\n")
-				builder.WriteString("err = tl.Unmarshal(r, &tTrue)\n")
-				builder.WriteString(unmarshalerReturnErr)
-				builder.WriteString("if tTrue != true {return fmt.Errorf(\"not a True type\")}\n")
-				builder.WriteString("}\n")
+				// true is a bare type without any data on the wire,
+				// its presence is fully described by the mode bit.
 				continue
 			}
+			builder.WriteString(fmt.Sprintf("if (t.%s>>%s)&1 == 1{\n",
+				utils.ToCamelCase(field.Modificator.Name), field.Modificator.Bit))
 			builder.WriteString(fmt.Sprintf("var temp%s %s\n", name, typeString))
 			builder.WriteString("err = tl.Unmarshal(r, &temp" + name + ")\n")
 			builder.WriteString(unmarshalerReturnErr)
@@ -527,18 +523,9 @@ func (g *Generator) generateGolangMethod(typeName string, c CombinatorDeclaratio
 		return "", fmt.Errorf("invalid error tag")
 	}
 
-	var respType tlType
-	ok = false
-	for k, v := range g.newTlTypes {
-		// TODO: valid if only one constructor OR type
-		if strings.ToLower(c.Combinator) == strings.ToLower(k) {
-			respType = v
-			ok = true
-			break
-		}
-	}
-	if !ok {
-		return "", fmt.Errorf("response type %s not parsed", responseName)
+	respType, err := g.responseType(c)
+	if err != nil {
+		return "", err
 	}
 
 	builder := strings.Builder{}
@@ -592,6 +579,71 @@ func (g *Generator) generateGolangMethod(typeName string, c CombinatorDeclaratio
 	return builder.String(), nil
 }
 
+func (g *Generator) responseType(c CombinatorDeclaration) (tlType, error) {
+	for k, v := range g.newTlTypes {
+		// TODO: valid if only one constructor OR type
+		if strings.ToLower(c.Combinator) == strings.ToLower(k) {
+			return v, nil
+		}
+	}
+	return tlType{}, fmt.Errorf("response type %s not parsed", utils.ToCamelCase(c.Combinator))
+}
+
+// LoadServerHandler generates a handler interface with a method per function,
+// a stub implementation of the interface and a dispatcher
+// that routes a decoded request to the corresponding handler method.
+// Types and functions must be loaded first with LoadTypes and LoadFunctions.
+func (g *Generator) LoadServerHandler(interfaceName string, functions []CombinatorDeclaration) (string, error) {
+	iface := strings.Builder{}
+	stub := strings.Builder{}
+	dispatcher := strings.Builder{}
+
+	iface.WriteString(fmt.Sprintf("type %s interface {\n", interfaceName))
+	dispatcher.WriteString(fmt.Sprintf("func dispatchRequest(ctx context.Context, h %s, request any) ([]byte, error) {\n", interfaceName))
+	dispatcher.WriteString("switch r := request.(type) {\n")
+	for _, c := range functions {
+		methodName := utils.ToCamelCase(c.Constructor)
+		requestName := methodName + "Request"
+		respType, err := g.responseType(c)
+		if err != nil {
+			return "", err
+		}
+		params := "ctx context.Context"
+		args := "ctx"
+		if len(c.FieldDefinitions) > 0 {
+			params += ", request " + requestName
+			args += ", r"
+		}
+		iface.WriteString(fmt.Sprintf("%s(%s) (%s, error)\n", methodName, params, respType.name))
+
+		stub.WriteString(fmt.Sprintf("func (Unimplemented%s) %s(%s) (res %s, err error) {\n", interfaceName, methodName, params, respType.name))
+		stub.WriteString(fmt.Sprintf("return res, notImplemented(%sName)\n}\n\n", requestName))
+
+		dispatcher.WriteString(fmt.Sprintf("case %s:\n", requestName))
+		dispatcher.WriteString(fmt.Sprintf("res, err := h.%s(%s)\n", methodName, args))
+		dispatcher.WriteString("if err != nil {return nil, err}\n")
+		if len(respType.tags) == 1 {
+			dispatcher.WriteString(fmt.Sprintf("return marshalResponse(%#x, res)\n", respType.tags[0]))
+		} else {
+			dispatcher.WriteString("return tl.Marshal(res)\n")
+		}
+	}
+	iface.WriteString("}\n")
+	dispatcher.WriteString("}\n")
+	dispatcher.WriteString("return nil, fmt.Errorf(\"unsupported request %T\", request)\n}\n")
+
+	s := iface.String() + "\n"
+	s += fmt.Sprintf("type Unimplemented%s struct{}\n\n", interfaceName)
+	s += stub.String()
+	s += dispatcher.String()
+
+	b, err := format.Source([]byte(s))
+	if err != nil {
+		return s, err
+	}
+	return string(b), nil
+}
+
 func (g *Generator) generateGolangMethodRequestType(c CombinatorDeclaration) (tlType, error) {
 	name := utils.ToCamelCase(c.Constructor) + "Request"
 	s, err := g.generateGolangStruct(c)
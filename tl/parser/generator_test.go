@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -112,6 +113,35 @@ func TestGenerateGolangMethods(t *testing.T) {
 	fmt.Printf("%s", s)
 }
 
+func TestGenerateServerHandler(t *testing.T) {
+	parsed, err := Parse(SOURCE)
+	if err != nil {
+		panic(err)
+	}
+	g := NewGenerator(nil, "*Client")
+
+	if _, err := g.LoadTypes(parsed.Declarations); err != nil {
+		panic(err)
+	}
+	if _, err := g.LoadFunctions(parsed.Functions); err != nil {
+		panic(err)
+	}
+	s, err := g.LoadServerHandler("LiteServerHandler", parsed.Functions)
+	if err != nil {
+		t.Fatalf("LoadServerHandler() failed: %v", err)
+	}
+	for _, want := range []string{
+		"type LiteServerHandler interface",
+		"LiteServerGetBlock(ctx context.Context, request LiteServerGetBlockRequest) (LiteServerBlockDataC, error)",
+		"func (UnimplementedLiteServerHandler) LiteServerGetTime(ctx context.Context)",
+		"case LiteServerGetMasterchainInfoRequest:",
+	} {
+		if !strings.Contains(s, want) {
+			t.Fatalf("generated handler doesn't contain %q", want)
+		}
+	}
+}
+
 func TestCheckBits(t *testing.T) {
 	mode := 53
 	fmt.Printf("Mode: %b\n", mode)
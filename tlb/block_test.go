@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"reflect"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/caigou-xyz/tongo/boc"
@@ -154,3 +158,134 @@ func libraries(s *ShardState) map[string]map[string]struct{} {
 	}
 	return libs
 }
+
+func Test_tlb_UnmarshalLazyBoc(t *testing.T) {
+	data, err := os.ReadFile("testdata/block-1/block.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	cells, err := boc.DeserializeBoc(data)
+	if err != nil {
+		t.Fatalf("boc.DeserializeBoc() failed: %v", err)
+	}
+	var want Block
+	if err := Unmarshal(cells[0], &want); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	bag, err := boc.NewLazyBoc(bytes.NewReader(data), int64(len(data)), boc.WithCacheSize(16))
+	if err != nil {
+		t.Fatalf("boc.NewLazyBoc() failed: %v", err)
+	}
+
+	var header BlockHeader
+	if err := Unmarshal(bag.Roots()[0], &header); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	if !reflect.DeepEqual(header.Info, want.Info) {
+		t.Fatalf("block info mismatch")
+	}
+
+	bag.Roots()[0].ResetCounters()
+	var block Block
+	if err := Unmarshal(bag.Roots()[0], &block); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	var wantHashes, hashes []Bits256
+	for _, tx := range want.AllTransactions() {
+		wantHashes = append(wantHashes, tx.Hash())
+	}
+	for _, tx := range block.AllTransactions() {
+		hashes = append(hashes, tx.Hash())
+	}
+	if len(hashes) == 0 || !reflect.DeepEqual(hashes, wantHashes) {
+		t.Fatalf("want transactions %v, got: %v", wantHashes, hashes)
+	}
+	if err := bag.Err(); err != nil {
+		t.Fatalf("Err() returned: %v", err)
+	}
+}
+
+// failingReader fails to read once fail is set.
+type failingReader struct {
+	r    io.ReaderAt
+	fail bool
+}
+
+func (r *failingReader) ReadAt(p []byte, off int64) (int, error) {
+	if r.fail {
+		return 0, errors.New("read failed")
+	}
+	return r.r.ReadAt(p, off)
+}
+
+func Test_tlb_UnmarshalLazyBocReadError(t *testing.T) {
+	data, err := os.ReadFile("testdata/block-1/block.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	r := &failingReader{r: bytes.NewReader(data)}
+	bag, err := boc.NewLazyBoc(r, int64(len(data)), boc.WithCacheSize(16))
+	if err != nil {
+		t.Fatalf("boc.NewLazyBoc() failed: %v", err)
+	}
+	r.fail = true
+	var block Block
+	if err := Unmarshal(bag.Roots()[0], &block); err == nil {
+		t.Fatalf("want error")
+	}
+	if bag.Err() == nil {
+		t.Fatalf("want error")
+	}
+}
+
+func Test_tlb_UnmarshalSliceConcurrently(t *testing.T) {
+	data, err := os.ReadFile("testdata/block-1/block.bin")
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	cells, err := boc.DeserializeBoc(data)
+	if err != nil {
+		t.Fatalf("boc.DeserializeBoc() failed: %v", err)
+	}
+	root := cells[0]
+	var want Block
+	if err := Unmarshal(root, &want); err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+	root.ResetCounters()
+	var wantHashes []Bits256
+	for _, tx := range want.AllTransactions() {
+		wantHashes = append(wantHashes, tx.Hash())
+	}
+
+	const workers = 4
+	results := make([][]Bits256, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var block Block
+			if err := NewDecoder().UnmarshalSlice(root.BeginParse(), &block); err != nil {
+				errs[i] = err
+				return
+			}
+			for _, tx := range block.AllTransactions() {
+				results[i] = append(results[i], tx.Hash())
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("UnmarshalSlice() failed: %v", errs[i])
+		}
+		if len(results[i]) == 0 || !reflect.DeepEqual(results[i], wantHashes) {
+			t.Fatalf("want transactions %v, got: %v", wantHashes, results[i])
+		}
+	}
+	if root.BitsAvailableForRead() != root.BitSize() || root.RefsAvailableForRead() != root.RefsSize() {
+		t.Fatalf("the root cursors must not be changed")
+	}
+}
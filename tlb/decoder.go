@@ -37,7 +37,25 @@ func NewDecoder() *Decoder {
 
 // Unmarshal decodes the give cell using TL-B schema and stores the result in the value pointed to by o.
 func (dec *Decoder) Unmarshal(c *boc.Cell, o any) error {
-	return decode(c, "", reflect.ValueOf(o), dec)
+	err := c.ReadSlice(func(s *boc.Slice) error {
+		return decode(s, "", reflect.ValueOf(o), dec)
+	})
+	if err != nil {
+		return err
+	}
+	// methods like Cell.Refs() can't return an error when a lazy bag of cells fails to load a cell.
+	return c.LazyErr()
+}
+
+// UnmarshalSlice decodes the given slice using TL-B schema and stores the result in the value pointed to by o.
+// Unlike Unmarshal, it doesn't change the cells being decoded,
+// so the same cell tree can be decoded by several goroutines at once,
+// each goroutine must use its own Decoder though.
+func (dec *Decoder) UnmarshalSlice(s *boc.Slice, o any) error {
+	if err := decode(s, "", reflect.ValueOf(o), dec); err != nil {
+		return err
+	}
+	return s.LazyErr()
 }
 
 // UnmarshalerTLB contains method UnmarshalTLB that must be implemented by a struct
@@ -46,13 +64,38 @@ type UnmarshalerTLB interface {
 	UnmarshalTLB(c *boc.Cell, decoder *Decoder) error
 }
 
+// SliceUnmarshalerTLB contains method UnmarshalTLBSlice that can be implemented by a struct
+// to decode its specific representation from a slice.
+// Decoder prefers it to UnmarshalerTLB.
+type SliceUnmarshalerTLB interface {
+	UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error
+}
+
 type tagValidator interface {
 	ValidateTag(c *boc.Cell, tag string) error
 }
 
+type sliceTagValidator interface {
+	validateTagSlice(s *boc.Slice, tag string) error
+}
+
+// bitsReader is implemented by both boc.Cell and boc.Slice.
+type bitsReader interface {
+	ReadBit() (bool, error)
+	ReadUint(bitLen int) (uint64, error)
+	ReadUnary() (uint, error)
+	ReadLimUint(n int) (uint, error)
+}
+
 func Unmarshal(c *boc.Cell, o any) error {
 	dec := Decoder{}
-	return decode(c, "", reflect.ValueOf(o), &dec)
+	return dec.Unmarshal(c, o)
+}
+
+// UnmarshalSlice decodes the given slice using TL-B schema and stores the result in the value pointed to by o.
+func UnmarshalSlice(s *boc.Slice, o any) error {
+	dec := Decoder{}
+	return dec.UnmarshalSlice(s, o)
 }
 
 var bocCellType = reflect.TypeOf(boc.Cell{})
@@ -60,23 +103,23 @@ var bocCellPointerType = reflect.TypeOf(&boc.Cell{})
 var bocTlbANyPointerType = reflect.TypeOf(&Any{})
 var bitStringType = reflect.TypeOf(boc.BitString{})
 
-func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error {
+func decode(s *boc.Slice, tag string, val reflect.Value, decoder *Decoder) error {
 	if decoder.withDebug {
 		decoder.debugPath = append(decoder.debugPath, fmt.Sprintf("%v#%v", val.Type().Name(), tag))
 		defer func() {
 			decoder.debugPath = decoder.debugPath[:len(decoder.debugPath)-1]
 		}()
 	}
-	if c.IsLibrary() {
+	if s.IsLibrary() {
 		if val.Kind() == reflect.Ptr && val.Type() == bocCellPointerType {
 			// this is a library cell, and we unmarshal it to a cell.
 			// let's not resolve it and keep it as is
-			val.Elem().Set(reflect.ValueOf(c).Elem())
+			val.Elem().Set(reflect.ValueOf(s.ToCell()).Elem())
 			return nil
 		}
 		if val.Kind() == reflect.Ptr && val.Type() == bocTlbANyPointerType {
 			//todo: remove
-			a := Any(*c)
+			a := Any(*s.ToCell())
 			val.Elem().Set(reflect.ValueOf(a))
 			return nil
 		}
@@ -84,7 +127,7 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 			// use WithLibraryResolver to provide a library resolver
 			return fmt.Errorf("library cell decoding is not configured properly")
 		}
-		hash, err := c.Hash256()
+		hash, err := s.Cell().Hash256()
 		if err != nil {
 			return err
 		}
@@ -92,7 +135,7 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 		if err != nil {
 			return err
 		}
-		c = cell
+		s = cell.BeginParse()
 	}
 	t, err := parseTag(tag)
 	if err != nil {
@@ -101,26 +144,26 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 	switch {
 	case t.IsMaybeRef:
 		tag = ""
-		exist, err := c.ReadBit()
+		exist, err := s.ReadBit()
 		if err != nil {
 			return err
 		}
 		if !exist {
 			return nil
 		}
-		c, err = c.NextRef()
+		s, err = s.NextRef()
 		if err != nil {
 			return err
 		}
-		if c.IsLibrary() {
+		if s.IsLibrary() {
 			return fmt.Errorf("library cell as a ref is not implemented")
 		}
-		if c.CellType() == boc.PrunedBranchCell {
+		if s.CellType() == boc.PrunedBranchCell {
 			return nil
 		}
 	case t.IsMaybe:
 		tag = ""
-		exist, err := c.ReadBit()
+		exist, err := s.ReadBit()
 		if err != nil {
 			return err
 		}
@@ -129,40 +172,42 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 		}
 	case t.IsRef:
 		tag = ""
-		c, err = c.NextRef()
+		s, err = s.NextRef()
 		if err != nil {
 			return err
 		}
-		if c.IsLibrary() {
+		if s.IsLibrary() {
 			if val.Kind() == reflect.Struct && val.Type() == bocCellType {
 				// this is a library cell, and we unmarshal it to a cell.
 				// let's not resolve it and keep it as is
-				val.Set(reflect.ValueOf(c).Elem())
+				val.Set(reflect.ValueOf(s.ToCell()).Elem())
 				return nil
 			}
 			return fmt.Errorf("library cell as a ref is not implemented")
 		}
-		if c.CellType() == boc.PrunedBranchCell {
+		if s.CellType() == boc.PrunedBranchCell {
 			return nil
 		}
 	}
-	i, ok := val.Interface().(UnmarshalerTLB)
-	if ok {
+	if hasUnmarshaler(val.Interface()) {
 		if val.IsNil() {
 			p := reflect.New(val.Type().Elem())
 			val.Set(p)
-			i = p.Interface().(UnmarshalerTLB)
 		}
-		return i.UnmarshalTLB(c, decoder)
+		return callUnmarshaler(val.Interface(), s, decoder)
 	}
 	if val.CanAddr() {
-		i, ok := val.Addr().Interface().(UnmarshalerTLB)
-		if ok {
-			return i.UnmarshalTLB(c, decoder)
+		i := val.Addr().Interface()
+		if hasUnmarshaler(i) {
+			return callUnmarshaler(i, s, decoder)
 		}
-		v, ok := val.Addr().Interface().(tagValidator)
-		if ok {
-			return v.ValidateTag(c, tag)
+		if v, ok := i.(sliceTagValidator); ok {
+			return v.validateTagSlice(s, tag)
+		}
+		if v, ok := i.(tagValidator); ok {
+			return s.ReadCell(func(c *boc.Cell) error {
+				return v.ValidateTag(c, tag)
+			})
 		}
 	}
 	if !val.CanSet() && val.Kind() != reflect.Pointer {
@@ -179,7 +224,7 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 		case reflect.Int64:
 			l = 64
 		}
-		v, err := c.ReadInt(l)
+		v, err := s.ReadInt(l)
 		if err != nil {
 			return err
 		}
@@ -195,14 +240,14 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 		case reflect.Uint64:
 			l = 64
 		}
-		v, err := c.ReadUint(l)
+		v, err := s.ReadUint(l)
 		if err != nil {
 			return err
 		}
 		val.SetUint(v)
 		return nil
 	case reflect.Bool:
-		b, err := c.ReadBit()
+		b, err := s.ReadBit()
 		if err != nil {
 			return err
 		}
@@ -211,14 +256,14 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 	case reflect.Struct:
 		switch val.Type() {
 		case bocCellType:
-			return decodeCell(c, val)
+			return decodeCell(s, val)
 		case bitStringType:
-			return decodeBitString(c, val)
+			return decodeBitString(s, val)
 		default:
-			return decodeStruct(c, val, decoder)
+			return decodeStruct(s, val, decoder)
 		}
 	case reflect.String:
-		bs := c.ReadRemainingBits()
+		bs := s.ReadRemainingBits()
 		bytes, err := bs.GetTopUppedArray()
 		if err != nil {
 			return err
@@ -227,10 +272,10 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 		return nil
 	case reflect.Pointer:
 		if val.Kind() == reflect.Pointer && !val.IsNil() {
-			return decode(c, tag, val.Elem(), decoder)
+			return decode(s, tag, val.Elem(), decoder)
 		}
 		a := reflect.New(val.Type().Elem())
-		err = decode(c, tag, a, decoder)
+		err = decode(s, tag, a, decoder)
 		if err != nil {
 			return err
 		}
@@ -240,7 +285,7 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 		if val.Type().Elem().Kind() != reflect.Uint8 {
 			return fmt.Errorf("decoding array of %v not supported", val.Type().Elem().Kind())
 		}
-		v, err := c.ReadBytes(val.Len())
+		v, err := s.ReadBytes(val.Len())
 		if err != nil {
 			return err
 		}
@@ -251,15 +296,15 @@ func decode(c *boc.Cell, tag string, val reflect.Value, decoder *Decoder) error
 	}
 }
 
-func decodeStruct(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
+func decodeStruct(s *boc.Slice, val reflect.Value, decoder *Decoder) error {
 	if _, ok := val.Type().FieldByName("SumType"); ok {
-		return decodeSumType(c, val, decoder)
+		return decodeSumType(s, val, decoder)
 	} else {
-		return decodeBasicStruct(c, val, decoder)
+		return decodeBasicStruct(s, val, decoder)
 	}
 }
 
-func decodeBasicStruct(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
+func decodeBasicStruct(s *boc.Slice, val reflect.Value, decoder *Decoder) error {
 	for i := 0; i < val.NumField(); i++ {
 		if decoder.withDebug {
 			decoder.debugPath = append(decoder.debugPath, val.Type().Field(i).Name)
@@ -268,7 +313,7 @@ func decodeBasicStruct(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
 			return fmt.Errorf("can't set field %v", i)
 		}
 		tag := val.Type().Field(i).Tag.Get("tlb")
-		err := decode(c, tag, val.Field(i), decoder)
+		err := decode(s, tag, val.Field(i), decoder)
 		if err != nil {
 			return err
 		}
@@ -279,7 +324,7 @@ func decodeBasicStruct(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
 	return nil
 }
 
-func decodeSumType(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
+func decodeSumType(s *boc.Slice, val reflect.Value, decoder *Decoder) error {
 	for i := 0; i < val.NumField(); i++ {
 		if !val.Field(i).CanSet() {
 			return fmt.Errorf("can't set field %v", i)
@@ -288,7 +333,7 @@ func decodeSumType(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
 			continue
 		}
 		tag := val.Type().Field(i).Tag.Get("tlbSumType")
-		ok, err := compareWithSumTag(c, tag)
+		ok, err := compareWithSumTag(s, tag)
 		if err != nil {
 			return err
 		}
@@ -300,7 +345,7 @@ func decodeSumType(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
 				}()
 			}
 			val.FieldByName("SumType").SetString(val.Type().Field(i).Name)
-			err := decode(c, "", val.Field(i), decoder)
+			err := decode(s, "", val.Field(i), decoder)
 			if err != nil {
 				return err
 			}
@@ -310,37 +355,57 @@ func decodeSumType(c *boc.Cell, val reflect.Value, decoder *Decoder) error {
 	return fmt.Errorf("can not decode sumtype %v", val.Type().Name())
 }
 
-func compareWithSumTag(c *boc.Cell, tag string) (bool, error) {
+func compareWithSumTag(s *boc.Slice, tag string) (bool, error) {
 	t, err := ParseTag(tag)
 	if err != nil {
 		return false, err
 	}
-	if c.BitsAvailableForRead() < t.Len {
+	if s.BitsAvailableForRead() < t.Len {
 		return false, nil
 	}
-	y, err := c.PickUint(t.Len)
+	y, err := s.PickUint(t.Len)
 	if err != nil {
 		return false, err
 	}
 	if t.Val == y {
-		_ = c.Skip(t.Len) // already checked
+		_ = s.Skip(t.Len) // already checked
 		return true, nil
 	}
 	return false, nil
 }
 
-func decodeCell(c *boc.Cell, val reflect.Value) error {
+func decodeCell(s *boc.Slice, val reflect.Value) error {
 	if !val.CanSet() {
 		return fmt.Errorf("value can't be changed")
 	}
-	val.Set(reflect.ValueOf(c).Elem())
+	val.Set(reflect.ValueOf(s.ToCell()).Elem())
 	return nil
 }
 
-func decodeBitString(c *boc.Cell, val reflect.Value) error {
+func decodeBitString(s *boc.Slice, val reflect.Value) error {
 	return fmt.Errorf("bigString decoding not supported")
 }
 
+func hasUnmarshaler(v any) bool {
+	switch v.(type) {
+	case SliceUnmarshalerTLB, UnmarshalerTLB:
+		return true
+	}
+	return false
+}
+
+// callUnmarshaler calls the specific unmarshalling code of the value,
+// a cell for UnmarshalerTLB is created by boc.Slice.ReadCell.
+func callUnmarshaler(v any, s *boc.Slice, decoder *Decoder) error {
+	if u, ok := v.(SliceUnmarshalerTLB); ok {
+		return u.UnmarshalTLBSlice(s, decoder)
+	}
+	u := v.(UnmarshalerTLB)
+	return s.ReadCell(func(c *boc.Cell) error {
+		return u.UnmarshalTLB(c, decoder)
+	})
+}
+
 // Hasher returns boc.Hasher that is used to calculate hashes when decoding.
 func (dec *Decoder) Hasher() *boc.Hasher {
 	return dec.hasher
@@ -18,13 +18,31 @@ type tagEncoder interface {
 	EncodeTag(c *boc.Cell, tag string) error
 }
 
+// bitsWriter is implemented by both boc.Cell and boc.Builder.
+type bitsWriter interface {
+	WriteUint(val uint64, bitLen int) error
+}
+
 func Marshal(c *boc.Cell, o any) error {
 	encoder := Encoder{}
-	return encode(c, "", o, &encoder)
+	return encoder.Marshal(c, o)
+}
+
+// MarshalBuilder encodes the given value using TL-B schema and appends the result to the builder.
+func MarshalBuilder(b *boc.Builder, o any) error {
+	encoder := Encoder{}
+	return encode(b, "", o, &encoder)
 }
 
 func (enc *Encoder) Marshal(c *boc.Cell, o any) error {
-	return encode(c, "", o, enc)
+	return c.WriteBuilder(func(b *boc.Builder) error {
+		return encode(b, "", o, enc)
+	})
+}
+
+// MarshalBuilder encodes the given value using TL-B schema and appends the result to the builder.
+func (enc *Encoder) MarshalBuilder(b *boc.Builder, o any) error {
+	return encode(b, "", o, enc)
 }
 
 func isNil(o any) bool {
@@ -36,66 +54,74 @@ func isNil(o any) bool {
 
 }
 
-func encode(c *boc.Cell, tag string, o any, encoder *Encoder) error {
+func encode(b *boc.Builder, tag string, o any, encoder *Encoder) error {
 	if m, ok := o.(tagEncoder); ok {
-		return m.EncodeTag(c, tag)
+		return b.WriteCell(func(c *boc.Cell) error {
+			return m.EncodeTag(c, tag)
+		})
 	}
 	t, err := parseTag(tag)
 	if err != nil {
 		return err
 	}
-	tag = ""
 	switch {
 	case t.IsMaybeRef:
 		if isNil(o) {
-			err := c.WriteBit(false)
+			err := b.WriteBit(false)
 			return err
 		}
-		if err := c.WriteBit(true); err != nil {
+		if err := b.WriteBit(true); err != nil {
 			return err
 		}
-		c, err = c.NewRef()
-		if err != nil {
-			return err
-		}
-
+		return encodeRef(b, t, o, encoder)
 	case t.IsMaybe:
 		if isNil(o) {
-			err := c.WriteBit(false)
+			err := b.WriteBit(false)
 			return err
 		}
-		if err := c.WriteBit(true); err != nil {
+		if err := b.WriteBit(true); err != nil {
 			return err
 		}
 	case t.IsRef:
-		c, err = c.NewRef()
-		if err != nil {
-			return err
-		}
+		return encodeRef(b, t, o, encoder)
 	}
+	return encodeValue(b, t, o, encoder)
+}
+
+func encodeRef(b *boc.Builder, t tag, o any, encoder *Encoder) error {
+	ref := boc.NewBuilder()
+	if err := encodeValue(ref, t, o, encoder); err != nil {
+		return err
+	}
+	return b.AddRef(ref.EndCell())
+}
+
+func encodeValue(b *boc.Builder, t tag, o any, encoder *Encoder) error {
 	if m, ok := o.(MarshalerTLB); ok {
-		return m.MarshalTLB(c, encoder)
+		return b.WriteCell(func(c *boc.Cell) error {
+			return m.MarshalTLB(c, encoder)
+		})
 	}
 	val := reflect.ValueOf(o)
 	switch val.Kind() {
 	case reflect.Uint8:
-		return c.WriteUint(val.Uint(), 8)
+		return b.WriteUint(val.Uint(), 8)
 	case reflect.Uint16:
-		return c.WriteUint(val.Uint(), 16)
+		return b.WriteUint(val.Uint(), 16)
 	case reflect.Uint32:
-		return c.WriteUint(val.Uint(), 32)
+		return b.WriteUint(val.Uint(), 32)
 	case reflect.Uint64:
-		return c.WriteUint(val.Uint(), 64)
+		return b.WriteUint(val.Uint(), 64)
 	case reflect.Int8:
-		return c.WriteInt(val.Int(), 8)
+		return b.WriteInt(val.Int(), 8)
 	case reflect.Int16:
-		return c.WriteInt(val.Int(), 16)
+		return b.WriteInt(val.Int(), 16)
 	case reflect.Int32:
-		return c.WriteInt(val.Int(), 32)
+		return b.WriteInt(val.Int(), 32)
 	case reflect.Int64:
-		return c.WriteInt(val.Int(), 64)
+		return b.WriteInt(val.Int(), 64)
 	case reflect.Bool:
-		err := c.WriteBit(val.Bool())
+		err := b.WriteBit(val.Bool())
 		if err != nil {
 			return err
 		}
@@ -103,58 +129,58 @@ func encode(c *boc.Cell, tag string, o any, encoder *Encoder) error {
 	case reflect.Struct:
 		switch o.(type) {
 		case boc.Cell:
-			return encodeCell(c, o)
+			return encodeCell(b, o)
 		case boc.BitString:
-			return encodeBitString(c, o)
+			return encodeBitString(b, o)
 		default:
-			return encodeStruct(c, o, encoder)
+			return encodeStruct(b, o, encoder)
 		}
 	case reflect.Pointer:
 		if val.IsNil() && !t.IsOptional {
 			return fmt.Errorf("can't encode empty pointer %v if tlb scheme is not optional", val.Type())
 		}
-		return encode(c, tag, val.Elem().Interface(), encoder)
+		return encode(b, "", val.Elem().Interface(), encoder)
 	case reflect.Array:
 		if val.Type().Elem().Kind() != reflect.Uint8 {
 			return fmt.Errorf("encoding array of %v not supported", val.Type().Elem().Kind())
 		}
 		// TODO: optimize
-		b := make([]byte, 0, val.Len())
+		bytes := make([]byte, 0, val.Len())
 		for i := 0; i < val.Len(); i++ {
-			b = append(b, uint8(val.Index(i).Uint()))
+			bytes = append(bytes, uint8(val.Index(i).Uint()))
 		}
-		return c.WriteBytes(b)
+		return b.WriteBytes(bytes)
 	case reflect.Slice:
 		if val.Type().Elem().Kind() != reflect.Uint8 {
 			return fmt.Errorf("encoding slice of %v not supported", val.Type().Elem().Kind())
 		}
-		return c.WriteBytes(val.Bytes())
+		return b.WriteBytes(val.Bytes())
 	default:
 		return fmt.Errorf("type %v not implemented", val.Kind())
 	}
 }
 
-func encodeStruct(c *boc.Cell, o any, encoder *Encoder) error {
+func encodeStruct(b *boc.Builder, o any, encoder *Encoder) error {
 	val := reflect.ValueOf(o)
 	if _, ok := val.Type().FieldByName("SumType"); ok {
-		return encodeSumType(c, o, encoder)
+		return encodeSumType(b, o, encoder)
 	} else {
-		return encodeBasicStruct(c, o, encoder)
+		return encodeBasicStruct(b, o, encoder)
 	}
 }
 
-func encodeBasicStruct(c *boc.Cell, o any, encoder *Encoder) error {
+func encodeBasicStruct(b *boc.Builder, o any, encoder *Encoder) error {
 	val := reflect.ValueOf(o)
 	for i := 0; i < val.NumField(); i++ {
 		tag := val.Type().Field(i).Tag.Get("tlb")
-		if err := encode(c, tag, val.Field(i).Interface(), encoder); err != nil {
+		if err := encode(b, tag, val.Field(i).Interface(), encoder); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func encodeSumType(c *boc.Cell, o any, encoder *Encoder) error {
+func encodeSumType(b *boc.Builder, o any, encoder *Encoder) error {
 	val := reflect.ValueOf(o)
 	name := val.FieldByName("SumType").String()
 	for i := 0; i < val.NumField(); i++ {
@@ -165,11 +191,11 @@ func encodeSumType(c *boc.Cell, o any, encoder *Encoder) error {
 		if name != val.Type().Field(i).Name {
 			continue
 		}
-		err := encodeSumTag(c, tag)
+		err := encodeSumTag(b, tag)
 		if err != nil {
 			return err
 		}
-		err = encode(c, "", val.Field(i).Interface(), encoder)
+		err = encode(b, "", val.Field(i).Interface(), encoder)
 		if err != nil {
 			return err
 		}
@@ -178,13 +204,15 @@ func encodeSumType(c *boc.Cell, o any, encoder *Encoder) error {
 	return nil
 }
 
-func encodeCell(c *boc.Cell, o any) error {
-	*c = o.(boc.Cell)
-	return nil
+func encodeCell(b *boc.Builder, o any) error {
+	return b.WriteCell(func(c *boc.Cell) error {
+		*c = o.(boc.Cell)
+		return nil
+	})
 }
 
-func encodeBitString(c *boc.Cell, o any) error {
-	err := c.WriteBitString(o.(boc.BitString))
+func encodeBitString(b *boc.Builder, o any) error {
+	err := b.WriteBitString(o.(boc.BitString))
 	if err != nil {
 		return err
 	}
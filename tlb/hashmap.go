@@ -114,10 +114,16 @@ func (h Hashmap[keyT, T]) encodeMap(c *boc.Cell, keys []boc.BitString, values []
 }
 
 func (h *Hashmap[keyT, T]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	var s keyT
-	keySize := s.FixedSize()
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return h.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (h *Hashmap[keyT, T]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	var k keyT
+	keySize := k.FixedSize()
 	keyPrefix := boc.NewBitString(keySize)
-	err := h.mapInner(keySize, keySize, c, &keyPrefix, decoder)
+	err := h.mapInner(keySize, keySize, s, &keyPrefix, decoder)
 	if err != nil {
 		return err
 	}
@@ -238,7 +244,7 @@ func ProveKeyInHashmap[T any](prover *boc.MerkleProver, cell *boc.Cell, key boc.
 
 }
 
-func (h *Hashmap[keyT, T]) mapInner(keySize, leftKeySize int, c *boc.Cell, keyPrefix *boc.BitString, decoder *Decoder) error {
+func (h *Hashmap[keyT, T]) mapInner(keySize, leftKeySize int, c *boc.Slice, keyPrefix *boc.BitString, decoder *Decoder) error {
 	var err error
 	var size int
 	if c.CellType() == boc.PrunedBranchCell {
@@ -282,7 +288,7 @@ func (h *Hashmap[keyT, T]) mapInner(keySize, leftKeySize int, c *boc.Cell, keyPr
 	}
 	// add node to map
 	var value T
-	err = decoder.Unmarshal(c, &value)
+	err = decoder.UnmarshalSlice(c, &value)
 	if err != nil {
 		return err
 	}
@@ -293,8 +299,7 @@ func (h *Hashmap[keyT, T]) mapInner(keySize, leftKeySize int, c *boc.Cell, keyPr
 	}
 
 	var k keyT
-	cell := boc.NewCellWithBits(key)
-	err = decoder.Unmarshal(cell, &k)
+	err = decoder.UnmarshalSlice(boc.NewCellWithBits(key).BeginParse(), &k)
 	if err != nil {
 		return err
 	}
@@ -367,8 +372,14 @@ func (h HashmapE[keyT, T]) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 }
 
 func (h *HashmapE[keyT, T]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return h.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (h *HashmapE[keyT, T]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
 	var temp Maybe[Ref[Hashmap[keyT, T]]]
-	err := decoder.Unmarshal(c, &temp)
+	err := decoder.UnmarshalSlice(s, &temp)
 	h.m = temp.Value.Value
 	return err
 }
@@ -464,17 +475,23 @@ func (h HashmapAug[keyT, T1, T2]) MarshalTLB(c *boc.Cell, encoder *Encoder) erro
 }
 
 func (h *HashmapAug[keyT, T1, T2]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return h.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (h *HashmapAug[keyT, T1, T2]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
 	var t keyT
 	keySize := t.FixedSize()
 	keyPrefix := boc.NewBitString(keySize)
-	err := h.mapInner(keySize, keySize, c, &keyPrefix, &h.extra, decoder)
+	err := h.mapInner(keySize, keySize, s, &keyPrefix, &h.extra, decoder)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (h *HashmapAug[keyT, T1, T2]) mapInner(keySize, leftKeySize int, c *boc.Cell, keyPrefix *boc.BitString, extras *HashMapAugExtraList[T2], decoder *Decoder) error {
+func (h *HashmapAug[keyT, T1, T2]) mapInner(keySize, leftKeySize int, c *boc.Slice, keyPrefix *boc.BitString, extras *HashMapAugExtraList[T2], decoder *Decoder) error {
 	var err error
 	var size int
 	if c.CellType() == boc.PrunedBranchCell {
@@ -519,21 +536,21 @@ func (h *HashmapAug[keyT, T1, T2]) mapInner(keySize, leftKeySize int, c *boc.Cel
 		}
 		extras.Left = &extraLeft
 		extras.Right = &extraRight
-		err = decoder.Unmarshal(c, &extra)
+		err = decoder.UnmarshalSlice(c, &extra)
 		if err != nil {
 			return err
 		}
 		extras.Data = extra
 		return nil
 	}
-	err = decoder.Unmarshal(c, &extra)
+	err = decoder.UnmarshalSlice(c, &extra)
 	if err != nil {
 		return err
 	}
 	extras.Data = extra
 	// add node to map
 	var value T1
-	err = decoder.Unmarshal(c, &value)
+	err = decoder.UnmarshalSlice(c, &value)
 	if err != nil {
 		return err
 	}
@@ -544,8 +561,7 @@ func (h *HashmapAug[keyT, T1, T2]) mapInner(keySize, leftKeySize int, c *boc.Cel
 	}
 
 	var k keyT
-	cell := boc.NewCellWithBits(key)
-	err = decoder.Unmarshal(cell, &k)
+	err = decoder.UnmarshalSlice(boc.NewCellWithBits(key).BeginParse(), &k)
 	if err != nil {
 		return err
 	}
@@ -559,11 +575,17 @@ type HashmapAugE[keyT fixedSize, T1, T2 any] struct {
 }
 
 func (h *HashmapAugE[keyT, T1, T2]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return h.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (h *HashmapAugE[keyT, T1, T2]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
 	var temp struct {
 		M     Maybe[Ref[HashmapAug[keyT, T1, T2]]]
 		Extra T2
 	}
-	err := decoder.Unmarshal(c, &temp)
+	err := decoder.UnmarshalSlice(s, &temp)
 	h.m = temp.M.Value.Value
 	h.extra = temp.Extra
 	return err
@@ -588,7 +610,64 @@ func (h HashmapAugE[keyT, T1, T2]) Keys() []keyT {
 	return h.m.keys
 }
 
-func loadLabelSize(size int, c *boc.Cell) (int, error) {
+// FindHashmapValue walks down a hashmap from its root cell along the given key
+// and returns a cell containing the key's value with the read cursor positioned at the value.
+// For an augmented hashmap, the value is prefixed with its extra.
+// It returns nil if the hashmap doesn't contain the key.
+// It fails if the path to the key goes through a pruned branch cell,
+// so when the hashmap is a part of a merkle proof, the result proves presence or absence of the key.
+func FindHashmapValue(root *boc.Cell, key boc.BitString) (*boc.Cell, error) {
+	keySize := key.BitsAvailableForRead()
+	cell := root
+	for {
+		if cell.CellType() == boc.PrunedBranchCell {
+			return nil, errors.New("can't find key in hashmap with pruned branch cell")
+		}
+		cell.ResetCounters()
+		labelBits := boc.NewBitString(keySize)
+		size, label, err := loadLabel(keySize, cell, &labelBits)
+		if err != nil {
+			return nil, err
+		}
+		if size > keySize {
+			return nil, errors.New("hashmap label is longer than key")
+		}
+		expected, err := key.ReadBits(size)
+		if err != nil {
+			return nil, err
+		}
+		got, err := label.ReadBits(size)
+		if err != nil {
+			return nil, err
+		}
+		if got.ToFiftHex() != expected.ToFiftHex() {
+			return nil, nil
+		}
+		keySize -= size
+		if keySize == 0 {
+			return cell, nil
+		}
+		isRight, err := key.ReadBit()
+		if err != nil {
+			return nil, err
+		}
+		keySize--
+		idx := 0
+		if isRight {
+			idx = 1
+		}
+		refs, err := cell.LoadRefs()
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) < 2 {
+			return nil, boc.ErrNotEnoughRefs
+		}
+		cell = refs[idx]
+	}
+}
+
+func loadLabelSize(size int, c bitsReader) (int, error) {
 	first, err := c.ReadBit()
 	if err != nil {
 		return 0, err
@@ -625,7 +704,7 @@ func loadLabelSize(size int, c *boc.Cell) (int, error) {
 	}
 	return int(ln), nil
 }
-func loadLabel(size int, c *boc.Cell, key *boc.BitString) (int, *boc.BitString, error) {
+func loadLabel(size int, c bitsReader, key *boc.BitString) (int, *boc.BitString, error) {
 	first, err := c.ReadBit()
 	if err != nil {
 		return 0, nil, err
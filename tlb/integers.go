@@ -38,6 +38,19 @@ func (u *VarUInteger1) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger1) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(0)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger1(*val)
+	return nil
+}
+
 func (u VarUInteger1) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -78,6 +91,19 @@ func (u *VarUInteger2) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger2) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(1)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger2(*val)
+	return nil
+}
+
 func (u VarUInteger2) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -118,6 +144,19 @@ func (u *VarUInteger3) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger3) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(2)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger3(*val)
+	return nil
+}
+
 func (u VarUInteger3) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -158,6 +197,19 @@ func (u *VarUInteger4) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger4) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(3)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger4(*val)
+	return nil
+}
+
 func (u VarUInteger4) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -198,6 +250,19 @@ func (u *VarUInteger5) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger5) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(4)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger5(*val)
+	return nil
+}
+
 func (u VarUInteger5) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -238,6 +303,19 @@ func (u *VarUInteger6) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger6) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(5)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger6(*val)
+	return nil
+}
+
 func (u VarUInteger6) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -278,6 +356,19 @@ func (u *VarUInteger7) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger7) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(6)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger7(*val)
+	return nil
+}
+
 func (u VarUInteger7) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -318,6 +409,19 @@ func (u *VarUInteger8) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger8) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(7)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger8(*val)
+	return nil
+}
+
 func (u VarUInteger8) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -358,6 +462,19 @@ func (u *VarUInteger9) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger9) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(8)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger9(*val)
+	return nil
+}
+
 func (u VarUInteger9) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -398,6 +515,19 @@ func (u *VarUInteger10) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger10) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(9)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger10(*val)
+	return nil
+}
+
 func (u VarUInteger10) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -438,6 +568,19 @@ func (u *VarUInteger11) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger11) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(10)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger11(*val)
+	return nil
+}
+
 func (u VarUInteger11) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -478,6 +621,19 @@ func (u *VarUInteger12) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger12) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(11)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger12(*val)
+	return nil
+}
+
 func (u VarUInteger12) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -518,6 +674,19 @@ func (u *VarUInteger13) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger13) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(12)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger13(*val)
+	return nil
+}
+
 func (u VarUInteger13) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -558,6 +727,19 @@ func (u *VarUInteger14) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger14) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(13)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger14(*val)
+	return nil
+}
+
 func (u VarUInteger14) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -598,6 +780,19 @@ func (u *VarUInteger15) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger15) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(14)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger15(*val)
+	return nil
+}
+
 func (u VarUInteger15) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -638,6 +833,19 @@ func (u *VarUInteger16) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger16) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(15)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger16(*val)
+	return nil
+}
+
 func (u VarUInteger16) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -678,6 +886,19 @@ func (u *VarUInteger17) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger17) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(16)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger17(*val)
+	return nil
+}
+
 func (u VarUInteger17) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -718,6 +939,19 @@ func (u *VarUInteger18) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger18) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(17)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger18(*val)
+	return nil
+}
+
 func (u VarUInteger18) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -758,6 +992,19 @@ func (u *VarUInteger19) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger19) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(18)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger19(*val)
+	return nil
+}
+
 func (u VarUInteger19) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -798,6 +1045,19 @@ func (u *VarUInteger20) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger20) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(19)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger20(*val)
+	return nil
+}
+
 func (u VarUInteger20) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -838,6 +1098,19 @@ func (u *VarUInteger21) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger21) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(20)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger21(*val)
+	return nil
+}
+
 func (u VarUInteger21) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -878,6 +1151,19 @@ func (u *VarUInteger22) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger22) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(21)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger22(*val)
+	return nil
+}
+
 func (u VarUInteger22) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -918,6 +1204,19 @@ func (u *VarUInteger23) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger23) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(22)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger23(*val)
+	return nil
+}
+
 func (u VarUInteger23) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -958,6 +1257,19 @@ func (u *VarUInteger24) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger24) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(23)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger24(*val)
+	return nil
+}
+
 func (u VarUInteger24) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -998,6 +1310,19 @@ func (u *VarUInteger25) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger25) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(24)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger25(*val)
+	return nil
+}
+
 func (u VarUInteger25) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1038,6 +1363,19 @@ func (u *VarUInteger26) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger26) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(25)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger26(*val)
+	return nil
+}
+
 func (u VarUInteger26) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1078,6 +1416,19 @@ func (u *VarUInteger27) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger27) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(26)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger27(*val)
+	return nil
+}
+
 func (u VarUInteger27) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1118,6 +1469,19 @@ func (u *VarUInteger28) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger28) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(27)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger28(*val)
+	return nil
+}
+
 func (u VarUInteger28) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1158,6 +1522,19 @@ func (u *VarUInteger29) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger29) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(28)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger29(*val)
+	return nil
+}
+
 func (u VarUInteger29) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1198,6 +1575,19 @@ func (u *VarUInteger30) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger30) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(29)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger30(*val)
+	return nil
+}
+
 func (u VarUInteger30) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1238,6 +1628,19 @@ func (u *VarUInteger31) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger31) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(30)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger31(*val)
+	return nil
+}
+
 func (u VarUInteger31) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1278,6 +1681,19 @@ func (u *VarUInteger32) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (u *VarUInteger32) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint(31)
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger32(*val)
+	return nil
+}
+
 func (u VarUInteger32) MarshalJSON() ([]byte, error) {
 	i := big.Int(u)
 	return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -1305,6 +1721,12 @@ func (u *Uint1) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint1) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(1)
+	*u = Uint1(v)
+	return err
+}
+
 func (u Uint1) FixedSize() int {
 	return 1
 }
@@ -1355,6 +1777,12 @@ func (u *Int1) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int1) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(1)
+	*u = Int1(v)
+	return err
+}
+
 func (u Int1) FixedSize() int {
 	return 1
 }
@@ -1405,6 +1833,12 @@ func (u *Uint2) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint2) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(2)
+	*u = Uint2(v)
+	return err
+}
+
 func (u Uint2) FixedSize() int {
 	return 2
 }
@@ -1455,6 +1889,12 @@ func (u *Int2) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int2) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(2)
+	*u = Int2(v)
+	return err
+}
+
 func (u Int2) FixedSize() int {
 	return 2
 }
@@ -1505,6 +1945,12 @@ func (u *Uint3) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint3) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(3)
+	*u = Uint3(v)
+	return err
+}
+
 func (u Uint3) FixedSize() int {
 	return 3
 }
@@ -1555,6 +2001,12 @@ func (u *Int3) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int3) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(3)
+	*u = Int3(v)
+	return err
+}
+
 func (u Int3) FixedSize() int {
 	return 3
 }
@@ -1605,6 +2057,12 @@ func (u *Uint4) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint4) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(4)
+	*u = Uint4(v)
+	return err
+}
+
 func (u Uint4) FixedSize() int {
 	return 4
 }
@@ -1655,6 +2113,12 @@ func (u *Int4) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int4) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(4)
+	*u = Int4(v)
+	return err
+}
+
 func (u Int4) FixedSize() int {
 	return 4
 }
@@ -1705,6 +2169,12 @@ func (u *Uint5) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint5) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(5)
+	*u = Uint5(v)
+	return err
+}
+
 func (u Uint5) FixedSize() int {
 	return 5
 }
@@ -1755,6 +2225,12 @@ func (u *Int5) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int5) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(5)
+	*u = Int5(v)
+	return err
+}
+
 func (u Int5) FixedSize() int {
 	return 5
 }
@@ -1805,6 +2281,12 @@ func (u *Uint6) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint6) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(6)
+	*u = Uint6(v)
+	return err
+}
+
 func (u Uint6) FixedSize() int {
 	return 6
 }
@@ -1849,8 +2331,14 @@ func (u Int6) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 	return c.WriteInt(int64(u), 6)
 }
 
-func (u *Int6) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	v, err := c.ReadInt(6)
+func (u *Int6) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	v, err := c.ReadInt(6)
+	*u = Int6(v)
+	return err
+}
+
+func (u *Int6) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(6)
 	*u = Int6(v)
 	return err
 }
@@ -1905,6 +2393,12 @@ func (u *Uint7) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint7) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(7)
+	*u = Uint7(v)
+	return err
+}
+
 func (u Uint7) FixedSize() int {
 	return 7
 }
@@ -1955,6 +2449,12 @@ func (u *Int7) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int7) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(7)
+	*u = Int7(v)
+	return err
+}
+
 func (u Int7) FixedSize() int {
 	return 7
 }
@@ -2005,6 +2505,12 @@ func (u *Uint8) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint8) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(8)
+	*u = Uint8(v)
+	return err
+}
+
 func (u Uint8) FixedSize() int {
 	return 8
 }
@@ -2055,6 +2561,12 @@ func (u *Int8) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int8) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(8)
+	*u = Int8(v)
+	return err
+}
+
 func (u Int8) FixedSize() int {
 	return 8
 }
@@ -2105,6 +2617,12 @@ func (u *Uint9) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint9) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(9)
+	*u = Uint9(v)
+	return err
+}
+
 func (u Uint9) FixedSize() int {
 	return 9
 }
@@ -2155,6 +2673,12 @@ func (u *Int9) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int9) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(9)
+	*u = Int9(v)
+	return err
+}
+
 func (u Int9) FixedSize() int {
 	return 9
 }
@@ -2205,6 +2729,12 @@ func (u *Uint10) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint10) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(10)
+	*u = Uint10(v)
+	return err
+}
+
 func (u Uint10) FixedSize() int {
 	return 10
 }
@@ -2255,6 +2785,12 @@ func (u *Int10) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int10) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(10)
+	*u = Int10(v)
+	return err
+}
+
 func (u Int10) FixedSize() int {
 	return 10
 }
@@ -2305,6 +2841,12 @@ func (u *Uint11) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint11) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(11)
+	*u = Uint11(v)
+	return err
+}
+
 func (u Uint11) FixedSize() int {
 	return 11
 }
@@ -2355,6 +2897,12 @@ func (u *Int11) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int11) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(11)
+	*u = Int11(v)
+	return err
+}
+
 func (u Int11) FixedSize() int {
 	return 11
 }
@@ -2405,6 +2953,12 @@ func (u *Uint12) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint12) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(12)
+	*u = Uint12(v)
+	return err
+}
+
 func (u Uint12) FixedSize() int {
 	return 12
 }
@@ -2455,6 +3009,12 @@ func (u *Int12) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int12) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(12)
+	*u = Int12(v)
+	return err
+}
+
 func (u Int12) FixedSize() int {
 	return 12
 }
@@ -2505,6 +3065,12 @@ func (u *Uint13) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint13) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(13)
+	*u = Uint13(v)
+	return err
+}
+
 func (u Uint13) FixedSize() int {
 	return 13
 }
@@ -2555,6 +3121,12 @@ func (u *Int13) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int13) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(13)
+	*u = Int13(v)
+	return err
+}
+
 func (u Int13) FixedSize() int {
 	return 13
 }
@@ -2605,6 +3177,12 @@ func (u *Uint14) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint14) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(14)
+	*u = Uint14(v)
+	return err
+}
+
 func (u Uint14) FixedSize() int {
 	return 14
 }
@@ -2655,6 +3233,12 @@ func (u *Int14) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int14) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(14)
+	*u = Int14(v)
+	return err
+}
+
 func (u Int14) FixedSize() int {
 	return 14
 }
@@ -2705,6 +3289,12 @@ func (u *Uint15) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint15) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(15)
+	*u = Uint15(v)
+	return err
+}
+
 func (u Uint15) FixedSize() int {
 	return 15
 }
@@ -2755,6 +3345,12 @@ func (u *Int15) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int15) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(15)
+	*u = Int15(v)
+	return err
+}
+
 func (u Int15) FixedSize() int {
 	return 15
 }
@@ -2805,6 +3401,12 @@ func (u *Uint16) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint16) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(16)
+	*u = Uint16(v)
+	return err
+}
+
 func (u Uint16) FixedSize() int {
 	return 16
 }
@@ -2855,6 +3457,12 @@ func (u *Int16) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int16) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(16)
+	*u = Int16(v)
+	return err
+}
+
 func (u Int16) FixedSize() int {
 	return 16
 }
@@ -2905,6 +3513,12 @@ func (u *Uint17) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint17) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(17)
+	*u = Uint17(v)
+	return err
+}
+
 func (u Uint17) FixedSize() int {
 	return 17
 }
@@ -2955,6 +3569,12 @@ func (u *Int17) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int17) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(17)
+	*u = Int17(v)
+	return err
+}
+
 func (u Int17) FixedSize() int {
 	return 17
 }
@@ -3005,6 +3625,12 @@ func (u *Uint18) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint18) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(18)
+	*u = Uint18(v)
+	return err
+}
+
 func (u Uint18) FixedSize() int {
 	return 18
 }
@@ -3055,6 +3681,12 @@ func (u *Int18) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int18) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(18)
+	*u = Int18(v)
+	return err
+}
+
 func (u Int18) FixedSize() int {
 	return 18
 }
@@ -3105,6 +3737,12 @@ func (u *Uint19) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint19) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(19)
+	*u = Uint19(v)
+	return err
+}
+
 func (u Uint19) FixedSize() int {
 	return 19
 }
@@ -3155,6 +3793,12 @@ func (u *Int19) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int19) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(19)
+	*u = Int19(v)
+	return err
+}
+
 func (u Int19) FixedSize() int {
 	return 19
 }
@@ -3205,6 +3849,12 @@ func (u *Uint20) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint20) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(20)
+	*u = Uint20(v)
+	return err
+}
+
 func (u Uint20) FixedSize() int {
 	return 20
 }
@@ -3255,6 +3905,12 @@ func (u *Int20) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int20) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(20)
+	*u = Int20(v)
+	return err
+}
+
 func (u Int20) FixedSize() int {
 	return 20
 }
@@ -3305,6 +3961,12 @@ func (u *Uint21) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint21) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(21)
+	*u = Uint21(v)
+	return err
+}
+
 func (u Uint21) FixedSize() int {
 	return 21
 }
@@ -3355,6 +4017,12 @@ func (u *Int21) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int21) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(21)
+	*u = Int21(v)
+	return err
+}
+
 func (u Int21) FixedSize() int {
 	return 21
 }
@@ -3405,6 +4073,12 @@ func (u *Uint22) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint22) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(22)
+	*u = Uint22(v)
+	return err
+}
+
 func (u Uint22) FixedSize() int {
 	return 22
 }
@@ -3455,6 +4129,12 @@ func (u *Int22) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int22) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(22)
+	*u = Int22(v)
+	return err
+}
+
 func (u Int22) FixedSize() int {
 	return 22
 }
@@ -3505,6 +4185,12 @@ func (u *Uint23) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint23) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(23)
+	*u = Uint23(v)
+	return err
+}
+
 func (u Uint23) FixedSize() int {
 	return 23
 }
@@ -3555,6 +4241,12 @@ func (u *Int23) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int23) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(23)
+	*u = Int23(v)
+	return err
+}
+
 func (u Int23) FixedSize() int {
 	return 23
 }
@@ -3605,6 +4297,12 @@ func (u *Uint24) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint24) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(24)
+	*u = Uint24(v)
+	return err
+}
+
 func (u Uint24) FixedSize() int {
 	return 24
 }
@@ -3655,6 +4353,12 @@ func (u *Int24) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int24) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(24)
+	*u = Int24(v)
+	return err
+}
+
 func (u Int24) FixedSize() int {
 	return 24
 }
@@ -3705,6 +4409,12 @@ func (u *Uint25) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint25) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(25)
+	*u = Uint25(v)
+	return err
+}
+
 func (u Uint25) FixedSize() int {
 	return 25
 }
@@ -3755,6 +4465,12 @@ func (u *Int25) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int25) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(25)
+	*u = Int25(v)
+	return err
+}
+
 func (u Int25) FixedSize() int {
 	return 25
 }
@@ -3805,6 +4521,12 @@ func (u *Uint26) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint26) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(26)
+	*u = Uint26(v)
+	return err
+}
+
 func (u Uint26) FixedSize() int {
 	return 26
 }
@@ -3855,6 +4577,12 @@ func (u *Int26) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int26) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(26)
+	*u = Int26(v)
+	return err
+}
+
 func (u Int26) FixedSize() int {
 	return 26
 }
@@ -3905,6 +4633,12 @@ func (u *Uint27) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint27) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(27)
+	*u = Uint27(v)
+	return err
+}
+
 func (u Uint27) FixedSize() int {
 	return 27
 }
@@ -3955,6 +4689,12 @@ func (u *Int27) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int27) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(27)
+	*u = Int27(v)
+	return err
+}
+
 func (u Int27) FixedSize() int {
 	return 27
 }
@@ -4005,6 +4745,12 @@ func (u *Uint28) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint28) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(28)
+	*u = Uint28(v)
+	return err
+}
+
 func (u Uint28) FixedSize() int {
 	return 28
 }
@@ -4055,6 +4801,12 @@ func (u *Int28) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int28) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(28)
+	*u = Int28(v)
+	return err
+}
+
 func (u Int28) FixedSize() int {
 	return 28
 }
@@ -4105,6 +4857,12 @@ func (u *Uint29) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint29) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(29)
+	*u = Uint29(v)
+	return err
+}
+
 func (u Uint29) FixedSize() int {
 	return 29
 }
@@ -4155,6 +4913,12 @@ func (u *Int29) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int29) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(29)
+	*u = Int29(v)
+	return err
+}
+
 func (u Int29) FixedSize() int {
 	return 29
 }
@@ -4205,6 +4969,12 @@ func (u *Uint30) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint30) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(30)
+	*u = Uint30(v)
+	return err
+}
+
 func (u Uint30) FixedSize() int {
 	return 30
 }
@@ -4255,6 +5025,12 @@ func (u *Int30) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int30) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(30)
+	*u = Int30(v)
+	return err
+}
+
 func (u Int30) FixedSize() int {
 	return 30
 }
@@ -4305,6 +5081,12 @@ func (u *Uint31) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint31) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(31)
+	*u = Uint31(v)
+	return err
+}
+
 func (u Uint31) FixedSize() int {
 	return 31
 }
@@ -4355,6 +5137,12 @@ func (u *Int31) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int31) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(31)
+	*u = Int31(v)
+	return err
+}
+
 func (u Int31) FixedSize() int {
 	return 31
 }
@@ -4405,6 +5193,12 @@ func (u *Uint32) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint32) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(32)
+	*u = Uint32(v)
+	return err
+}
+
 func (u Uint32) FixedSize() int {
 	return 32
 }
@@ -4455,6 +5249,12 @@ func (u *Int32) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int32) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(32)
+	*u = Int32(v)
+	return err
+}
+
 func (u Int32) FixedSize() int {
 	return 32
 }
@@ -4505,6 +5305,12 @@ func (u *Uint33) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint33) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(33)
+	*u = Uint33(v)
+	return err
+}
+
 func (u Uint33) FixedSize() int {
 	return 33
 }
@@ -4555,6 +5361,12 @@ func (u *Int33) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int33) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(33)
+	*u = Int33(v)
+	return err
+}
+
 func (u Int33) FixedSize() int {
 	return 33
 }
@@ -4605,6 +5417,12 @@ func (u *Uint34) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint34) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(34)
+	*u = Uint34(v)
+	return err
+}
+
 func (u Uint34) FixedSize() int {
 	return 34
 }
@@ -4655,6 +5473,12 @@ func (u *Int34) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int34) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(34)
+	*u = Int34(v)
+	return err
+}
+
 func (u Int34) FixedSize() int {
 	return 34
 }
@@ -4705,6 +5529,12 @@ func (u *Uint35) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint35) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(35)
+	*u = Uint35(v)
+	return err
+}
+
 func (u Uint35) FixedSize() int {
 	return 35
 }
@@ -4755,6 +5585,12 @@ func (u *Int35) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int35) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(35)
+	*u = Int35(v)
+	return err
+}
+
 func (u Int35) FixedSize() int {
 	return 35
 }
@@ -4805,6 +5641,12 @@ func (u *Uint36) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint36) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(36)
+	*u = Uint36(v)
+	return err
+}
+
 func (u Uint36) FixedSize() int {
 	return 36
 }
@@ -4855,6 +5697,12 @@ func (u *Int36) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int36) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(36)
+	*u = Int36(v)
+	return err
+}
+
 func (u Int36) FixedSize() int {
 	return 36
 }
@@ -4905,6 +5753,12 @@ func (u *Uint37) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint37) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(37)
+	*u = Uint37(v)
+	return err
+}
+
 func (u Uint37) FixedSize() int {
 	return 37
 }
@@ -4955,6 +5809,12 @@ func (u *Int37) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int37) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(37)
+	*u = Int37(v)
+	return err
+}
+
 func (u Int37) FixedSize() int {
 	return 37
 }
@@ -4999,8 +5859,14 @@ func (u Uint38) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 	return c.WriteUint(uint64(u), 38)
 }
 
-func (u *Uint38) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	v, err := c.ReadUint(38)
+func (u *Uint38) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	v, err := c.ReadUint(38)
+	*u = Uint38(v)
+	return err
+}
+
+func (u *Uint38) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(38)
 	*u = Uint38(v)
 	return err
 }
@@ -5055,6 +5921,12 @@ func (u *Int38) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int38) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(38)
+	*u = Int38(v)
+	return err
+}
+
 func (u Int38) FixedSize() int {
 	return 38
 }
@@ -5105,6 +5977,12 @@ func (u *Uint39) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint39) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(39)
+	*u = Uint39(v)
+	return err
+}
+
 func (u Uint39) FixedSize() int {
 	return 39
 }
@@ -5155,6 +6033,12 @@ func (u *Int39) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int39) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(39)
+	*u = Int39(v)
+	return err
+}
+
 func (u Int39) FixedSize() int {
 	return 39
 }
@@ -5205,6 +6089,12 @@ func (u *Uint40) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint40) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(40)
+	*u = Uint40(v)
+	return err
+}
+
 func (u Uint40) FixedSize() int {
 	return 40
 }
@@ -5255,6 +6145,12 @@ func (u *Int40) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int40) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(40)
+	*u = Int40(v)
+	return err
+}
+
 func (u Int40) FixedSize() int {
 	return 40
 }
@@ -5305,6 +6201,12 @@ func (u *Uint41) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint41) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(41)
+	*u = Uint41(v)
+	return err
+}
+
 func (u Uint41) FixedSize() int {
 	return 41
 }
@@ -5355,6 +6257,12 @@ func (u *Int41) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int41) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(41)
+	*u = Int41(v)
+	return err
+}
+
 func (u Int41) FixedSize() int {
 	return 41
 }
@@ -5405,6 +6313,12 @@ func (u *Uint42) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint42) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(42)
+	*u = Uint42(v)
+	return err
+}
+
 func (u Uint42) FixedSize() int {
 	return 42
 }
@@ -5455,6 +6369,12 @@ func (u *Int42) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int42) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(42)
+	*u = Int42(v)
+	return err
+}
+
 func (u Int42) FixedSize() int {
 	return 42
 }
@@ -5505,6 +6425,12 @@ func (u *Uint43) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint43) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(43)
+	*u = Uint43(v)
+	return err
+}
+
 func (u Uint43) FixedSize() int {
 	return 43
 }
@@ -5555,6 +6481,12 @@ func (u *Int43) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int43) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(43)
+	*u = Int43(v)
+	return err
+}
+
 func (u Int43) FixedSize() int {
 	return 43
 }
@@ -5605,6 +6537,12 @@ func (u *Uint44) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint44) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(44)
+	*u = Uint44(v)
+	return err
+}
+
 func (u Uint44) FixedSize() int {
 	return 44
 }
@@ -5655,6 +6593,12 @@ func (u *Int44) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int44) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(44)
+	*u = Int44(v)
+	return err
+}
+
 func (u Int44) FixedSize() int {
 	return 44
 }
@@ -5705,6 +6649,12 @@ func (u *Uint45) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint45) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(45)
+	*u = Uint45(v)
+	return err
+}
+
 func (u Uint45) FixedSize() int {
 	return 45
 }
@@ -5755,6 +6705,12 @@ func (u *Int45) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int45) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(45)
+	*u = Int45(v)
+	return err
+}
+
 func (u Int45) FixedSize() int {
 	return 45
 }
@@ -5805,6 +6761,12 @@ func (u *Uint46) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint46) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(46)
+	*u = Uint46(v)
+	return err
+}
+
 func (u Uint46) FixedSize() int {
 	return 46
 }
@@ -5855,6 +6817,12 @@ func (u *Int46) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int46) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(46)
+	*u = Int46(v)
+	return err
+}
+
 func (u Int46) FixedSize() int {
 	return 46
 }
@@ -5905,6 +6873,12 @@ func (u *Uint47) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint47) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(47)
+	*u = Uint47(v)
+	return err
+}
+
 func (u Uint47) FixedSize() int {
 	return 47
 }
@@ -5955,6 +6929,12 @@ func (u *Int47) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int47) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(47)
+	*u = Int47(v)
+	return err
+}
+
 func (u Int47) FixedSize() int {
 	return 47
 }
@@ -6005,6 +6985,12 @@ func (u *Uint48) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint48) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(48)
+	*u = Uint48(v)
+	return err
+}
+
 func (u Uint48) FixedSize() int {
 	return 48
 }
@@ -6055,6 +7041,12 @@ func (u *Int48) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int48) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(48)
+	*u = Int48(v)
+	return err
+}
+
 func (u Int48) FixedSize() int {
 	return 48
 }
@@ -6105,6 +7097,12 @@ func (u *Uint49) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint49) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(49)
+	*u = Uint49(v)
+	return err
+}
+
 func (u Uint49) FixedSize() int {
 	return 49
 }
@@ -6155,6 +7153,12 @@ func (u *Int49) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int49) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(49)
+	*u = Int49(v)
+	return err
+}
+
 func (u Int49) FixedSize() int {
 	return 49
 }
@@ -6205,6 +7209,12 @@ func (u *Uint50) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint50) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(50)
+	*u = Uint50(v)
+	return err
+}
+
 func (u Uint50) FixedSize() int {
 	return 50
 }
@@ -6255,6 +7265,12 @@ func (u *Int50) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int50) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(50)
+	*u = Int50(v)
+	return err
+}
+
 func (u Int50) FixedSize() int {
 	return 50
 }
@@ -6305,6 +7321,12 @@ func (u *Uint51) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint51) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(51)
+	*u = Uint51(v)
+	return err
+}
+
 func (u Uint51) FixedSize() int {
 	return 51
 }
@@ -6355,6 +7377,12 @@ func (u *Int51) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int51) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(51)
+	*u = Int51(v)
+	return err
+}
+
 func (u Int51) FixedSize() int {
 	return 51
 }
@@ -6405,6 +7433,12 @@ func (u *Uint52) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint52) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(52)
+	*u = Uint52(v)
+	return err
+}
+
 func (u Uint52) FixedSize() int {
 	return 52
 }
@@ -6455,6 +7489,12 @@ func (u *Int52) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int52) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(52)
+	*u = Int52(v)
+	return err
+}
+
 func (u Int52) FixedSize() int {
 	return 52
 }
@@ -6505,6 +7545,12 @@ func (u *Uint53) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint53) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(53)
+	*u = Uint53(v)
+	return err
+}
+
 func (u Uint53) FixedSize() int {
 	return 53
 }
@@ -6555,6 +7601,12 @@ func (u *Int53) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int53) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(53)
+	*u = Int53(v)
+	return err
+}
+
 func (u Int53) FixedSize() int {
 	return 53
 }
@@ -6605,6 +7657,12 @@ func (u *Uint54) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint54) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(54)
+	*u = Uint54(v)
+	return err
+}
+
 func (u Uint54) FixedSize() int {
 	return 54
 }
@@ -6655,6 +7713,12 @@ func (u *Int54) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int54) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(54)
+	*u = Int54(v)
+	return err
+}
+
 func (u Int54) FixedSize() int {
 	return 54
 }
@@ -6705,6 +7769,12 @@ func (u *Uint55) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint55) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(55)
+	*u = Uint55(v)
+	return err
+}
+
 func (u Uint55) FixedSize() int {
 	return 55
 }
@@ -6755,6 +7825,12 @@ func (u *Int55) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int55) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(55)
+	*u = Int55(v)
+	return err
+}
+
 func (u Int55) FixedSize() int {
 	return 55
 }
@@ -6805,6 +7881,12 @@ func (u *Uint56) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint56) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(56)
+	*u = Uint56(v)
+	return err
+}
+
 func (u Uint56) FixedSize() int {
 	return 56
 }
@@ -6855,6 +7937,12 @@ func (u *Int56) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int56) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(56)
+	*u = Int56(v)
+	return err
+}
+
 func (u Int56) FixedSize() int {
 	return 56
 }
@@ -6905,6 +7993,12 @@ func (u *Uint57) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint57) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(57)
+	*u = Uint57(v)
+	return err
+}
+
 func (u Uint57) FixedSize() int {
 	return 57
 }
@@ -6955,6 +8049,12 @@ func (u *Int57) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int57) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(57)
+	*u = Int57(v)
+	return err
+}
+
 func (u Int57) FixedSize() int {
 	return 57
 }
@@ -7005,6 +8105,12 @@ func (u *Uint58) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint58) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(58)
+	*u = Uint58(v)
+	return err
+}
+
 func (u Uint58) FixedSize() int {
 	return 58
 }
@@ -7055,6 +8161,12 @@ func (u *Int58) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int58) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(58)
+	*u = Int58(v)
+	return err
+}
+
 func (u Int58) FixedSize() int {
 	return 58
 }
@@ -7105,6 +8217,12 @@ func (u *Uint59) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint59) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(59)
+	*u = Uint59(v)
+	return err
+}
+
 func (u Uint59) FixedSize() int {
 	return 59
 }
@@ -7155,6 +8273,12 @@ func (u *Int59) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int59) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(59)
+	*u = Int59(v)
+	return err
+}
+
 func (u Int59) FixedSize() int {
 	return 59
 }
@@ -7205,6 +8329,12 @@ func (u *Uint60) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint60) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(60)
+	*u = Uint60(v)
+	return err
+}
+
 func (u Uint60) FixedSize() int {
 	return 60
 }
@@ -7255,6 +8385,12 @@ func (u *Int60) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int60) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(60)
+	*u = Int60(v)
+	return err
+}
+
 func (u Int60) FixedSize() int {
 	return 60
 }
@@ -7305,6 +8441,12 @@ func (u *Uint61) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint61) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(61)
+	*u = Uint61(v)
+	return err
+}
+
 func (u Uint61) FixedSize() int {
 	return 61
 }
@@ -7355,6 +8497,12 @@ func (u *Int61) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int61) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(61)
+	*u = Int61(v)
+	return err
+}
+
 func (u Int61) FixedSize() int {
 	return 61
 }
@@ -7405,6 +8553,12 @@ func (u *Uint62) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint62) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(62)
+	*u = Uint62(v)
+	return err
+}
+
 func (u Uint62) FixedSize() int {
 	return 62
 }
@@ -7455,6 +8609,12 @@ func (u *Int62) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int62) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(62)
+	*u = Int62(v)
+	return err
+}
+
 func (u Int62) FixedSize() int {
 	return 62
 }
@@ -7505,6 +8665,12 @@ func (u *Uint63) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint63) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(63)
+	*u = Uint63(v)
+	return err
+}
+
 func (u Uint63) FixedSize() int {
 	return 63
 }
@@ -7555,6 +8721,12 @@ func (u *Int63) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int63) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(63)
+	*u = Int63(v)
+	return err
+}
+
 func (u Int63) FixedSize() int {
 	return 63
 }
@@ -7605,6 +8777,12 @@ func (u *Uint64) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint64) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint(64)
+	*u = Uint64(v)
+	return err
+}
+
 func (u Uint64) FixedSize() int {
 	return 64
 }
@@ -7655,6 +8833,12 @@ func (u *Int64) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int64) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt(64)
+	*u = Int64(v)
+	return err
+}
+
 func (u Int64) FixedSize() int {
 	return 64
 }
@@ -7709,6 +8893,15 @@ func (u *Uint128) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint128) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigUint(128)
+	if err != nil {
+		return err
+	}
+	*u = Uint128(*v)
+	return err
+}
+
 func (u Uint128) FixedSize() int {
 	return 128
 }
@@ -7744,6 +8937,15 @@ func (u *Int128) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int128) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigInt(128)
+	if err != nil {
+		return err
+	}
+	*u = Int128(*v)
+	return err
+}
+
 func (u Int128) FixedSize() int {
 	return 128
 }
@@ -7779,6 +8981,15 @@ func (u *Uint256) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint256) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigUint(256)
+	if err != nil {
+		return err
+	}
+	*u = Uint256(*v)
+	return err
+}
+
 func (u Uint256) FixedSize() int {
 	return 256
 }
@@ -7814,6 +9025,15 @@ func (u *Int256) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int256) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigInt(256)
+	if err != nil {
+		return err
+	}
+	*u = Int256(*v)
+	return err
+}
+
 func (u Int256) FixedSize() int {
 	return 256
 }
@@ -7849,6 +9069,15 @@ func (u *Uint257) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint257) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigUint(257)
+	if err != nil {
+		return err
+	}
+	*u = Uint257(*v)
+	return err
+}
+
 func (u Uint257) FixedSize() int {
 	return 257
 }
@@ -7884,6 +9113,15 @@ func (u *Int257) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int257) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigInt(257)
+	if err != nil {
+		return err
+	}
+	*u = Int257(*v)
+	return err
+}
+
 func (u Int257) FixedSize() int {
 	return 257
 }
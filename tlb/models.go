@@ -26,11 +26,18 @@ type SignedCoins int64
 func (g Grams) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 	var amount VarUInteger16
 	amount = VarUInteger16(*big.NewInt(int64(g)))
-	err := encode(c, "", amount, encoder)
-	return err
+	return encoder.Marshal(c, amount)
 }
 
 func (g *Grams) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	return g.read(c)
+}
+
+func (g *Grams) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	return g.read(s)
+}
+
+func (g *Grams) read(c bitsReader) error {
 	ln, err := c.ReadLimUint(15)
 	if err != nil {
 		return err
@@ -59,10 +66,18 @@ func (g SignedCoins) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 		g = -g
 	}
 	amount := VarUInteger16(*big.NewInt(int64(g)))
-	return encode(c, "", amount, encoder)
+	return encoder.Marshal(c, amount)
 }
 
 func (g *SignedCoins) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
+	return g.read(c)
+}
+
+func (g *SignedCoins) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	return g.read(s)
+}
+
+func (g *SignedCoins) read(c bitsReader) error {
 	negative, err := c.ReadBit()
 	if err != nil {
 		return err
@@ -35,6 +35,19 @@ func (u *VarUInteger{{.NameIndex}}) UnmarshalTLB(c *boc.Cell, decoder *Decoder)
 	return nil
 }
 
+func (u *VarUInteger{{.NameIndex}}) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	ln, err := s.ReadLimUint({{.BitsLimit}})
+	if err != nil {
+		return err
+	}
+	val, err := s.ReadBigUint(int(ln) * 8)
+	if err != nil {
+		return err
+	}
+	*u = VarUInteger{{.NameIndex}}(*val)
+	return nil
+}
+
 func (u VarUInteger{{.NameIndex}}) MarshalJSON() ([]byte, error) {
     i := big.Int(u)
     return []byte(fmt.Sprintf("\"%s\"", i.String())), nil
@@ -86,6 +99,12 @@ func (u *Uint{{.NameIndex}}) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint{{.NameIndex}}) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadUint({{.NameIndex}})
+	*u = Uint{{.NameIndex}}(v)
+	return err
+}
+
 func (u Uint{{.NameIndex}}) FixedSize() int {
 	return {{.NameIndex}}
 }
@@ -143,6 +162,12 @@ func (u *Int{{.NameIndex}}) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int{{.NameIndex}}) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadInt({{.NameIndex}})
+	*u = Int{{.NameIndex}}(v)
+	return err
+}
+
 func (u Int{{.NameIndex}}) FixedSize() int {
 	return {{.NameIndex}}
 }
@@ -230,6 +255,15 @@ func (u *Uint{{.NameIndex}}) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Uint{{.NameIndex}}) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigUint({{.NameIndex}})
+	if err != nil {
+		return err
+	}
+	*u = Uint{{.NameIndex}}(*v)
+	return err
+}
+
 func (u Uint{{.NameIndex}}) FixedSize() int {
 	return {{.NameIndex}}
 }
@@ -265,6 +299,15 @@ func (u *Int{{.NameIndex}}) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Int{{.NameIndex}}) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBigInt({{.NameIndex}})
+	if err != nil {
+		return err
+	}
+	*u = Int{{.NameIndex}}(*v)
+	return err
+}
+
 func (u Int{{.NameIndex}}) FixedSize() int {
 	return {{.NameIndex}}
 }
@@ -361,11 +404,17 @@ func (u *Bits%v) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (u *Bits%v) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	v, err := s.ReadBits(%v)
+	*u = Bits%v(v)
+	return err
+}
+
 func (u Bits%v) FixedSize() int {
 	return %v
 }
 
-	`, i, i, i, i, i, i, i)
+	`, i, i, i, i, i, i, i, i, i, i)
 		}
 	}
 	bytes, err := format.Source(b.Bytes())
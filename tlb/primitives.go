@@ -47,6 +47,14 @@ func (m Maybe[T]) Pointer() *T {
 }
 
 func (m *Magic) ValidateTag(c *boc.Cell, tag string) error {
+	return m.validateTag(c, tag)
+}
+
+func (m *Magic) validateTagSlice(s *boc.Slice, tag string) error {
+	return m.validateTag(s, tag)
+}
+
+func (m *Magic) validateTag(c bitsReader, tag string) error {
 	a := strings.Split(tag, "$")
 	if len(a) == 2 {
 		x, err := strconv.ParseUint(a[1], 2, 32)
@@ -112,13 +120,19 @@ func (m Maybe[_]) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 }
 
 func (m *Maybe[_]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	exist, err := c.ReadBit()
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return m.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (m *Maybe[_]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	exist, err := s.ReadBit()
 	if err != nil {
 		return err
 	}
 	m.Exists = exist
 	if exist {
-		err = decoder.Unmarshal(c, &m.Value)
+		err = decoder.UnmarshalSlice(s, &m.Value)
 		if err != nil {
 			return err
 		}
@@ -164,18 +178,24 @@ func (m Either[_, _]) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 }
 
 func (m *Either[_, _]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	isRight, err := c.ReadBit()
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return m.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (m *Either[_, _]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	isRight, err := s.ReadBit()
 	if err != nil {
 		return err
 	}
 	m.IsRight = isRight
 	if isRight {
-		err = decoder.Unmarshal(c, &m.Right)
+		err = decoder.UnmarshalSlice(s, &m.Right)
 		if err != nil {
 			return err
 		}
 	} else {
-		err = decoder.Unmarshal(c, &m.Left)
+		err = decoder.UnmarshalSlice(s, &m.Left)
 		if err != nil {
 			return err
 		}
@@ -198,18 +218,24 @@ func (m EitherRef[_]) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 }
 
 func (m *EitherRef[_]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	isRight, err := c.ReadBit()
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return m.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (m *EitherRef[_]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	isRight, err := s.ReadBit()
 	if err != nil {
 		return err
 	}
 	m.IsRight = isRight
 	if isRight {
-		c, err = c.NextRef()
+		s, err = s.NextRef()
 		if err != nil {
 			return err
 		}
 	}
-	return decoder.Unmarshal(c, &m.Value)
+	return decoder.UnmarshalSlice(s, &m.Value)
 }
 
 func (m Ref[_]) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
@@ -223,7 +249,13 @@ func (m Ref[_]) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 }
 
 func (m *Ref[T]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
-	r, err := c.NextRef()
+	return c.ReadSlice(func(s *boc.Slice) error {
+		return m.UnmarshalTLBSlice(s, decoder)
+	})
+}
+
+func (m *Ref[T]) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	r, err := s.NextRef()
 	if err != nil {
 		return err
 	}
@@ -232,7 +264,7 @@ func (m *Ref[T]) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 		m.Value = value
 		return nil
 	}
-	err = decoder.Unmarshal(r, &m.Value)
+	err = decoder.UnmarshalSlice(r, &m.Value)
 	if err != nil {
 		return err
 	}
@@ -249,6 +281,12 @@ func (n *Unary) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return err
 }
 
+func (n *Unary) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	a, err := s.ReadUnary()
+	*n = Unary(a)
+	return err
+}
+
 func (a Any) MarshalTLB(c *boc.Cell, encoder *Encoder) error {
 	x := boc.Cell(a)
 	y := &x
@@ -275,6 +313,12 @@ func (a *Any) UnmarshalTLB(c *boc.Cell, decoder *Decoder) error {
 	return nil
 }
 
+func (a *Any) UnmarshalTLBSlice(s *boc.Slice, decoder *Decoder) error {
+	x := s.CopyRemaining()
+	*a = Any(*x)
+	return nil
+}
+
 func (a Any) MarshalJSON() ([]byte, error) {
 	return boc.Cell(a).MarshalJSON()
 }
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-
-	"github.com/caigou-xyz/tongo/boc"
 )
 
 var ErrInvalidTag = errors.New("invalid tag")
@@ -57,7 +55,7 @@ func parseTag(s string) (tag, error) {
 	return t, nil
 }
 
-func encodeSumTag(c *boc.Cell, tag string) error {
+func encodeSumTag(c bitsWriter, tag string) error {
 	t, err := ParseTag(tag)
 	if err != nil {
 		return err
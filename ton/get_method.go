@@ -0,0 +1,29 @@
+package ton
+
+import (
+	"github.com/caigou-xyz/tongo/boc"
+)
+
+// MethodEnv describes an environment a lite server executed a get method in.
+// liteapi builds it from proofs of a get method result and passes it to a method emulator,
+// tvm.MethodEmulator runs get methods in it.
+type MethodEnv struct {
+	AccountID AccountID
+	// Code and Data are the account's code and data, proven against the target block.
+	// Cells not used by the lite server during execution are pruned.
+	Code *boc.Cell
+	Data *boc.Cell
+	// UnixTime and RandSeed are taken from the initial c7 register as is.
+	// Nothing in a proof binds them, so a get method that depends on them
+	// is checked only against the values chosen by the lite server.
+	UnixTime uint32
+	RandSeed Bits256
+	// Balance is taken from the initial c7 register and checked against the proven account.
+	Balance uint64
+	// Config is the root cell of the blockchain configuration taken from the initial c7 register, it can be nil.
+	// When checking a get method result, it is compared with the configuration of the proven masterchain state.
+	Config *boc.Cell
+	// Libraries are library cells the account's code refers to, it can be nil.
+	// When checking a get method result, they are requested with GetLibrariesWithProof.
+	Libraries map[Bits256]*boc.Cell
+}
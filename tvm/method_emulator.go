@@ -0,0 +1,63 @@
+package tvm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/caigou-xyz/tongo/boc"
+	"github.com/caigou-xyz/tongo/code"
+	"github.com/caigou-xyz/tongo/tlb"
+	"github.com/caigou-xyz/tongo/ton"
+)
+
+// MethodEmulator runs get methods with the TVM emulator
+// in the same environment a lite server executed them in.
+// It can be used with liteapi.WithMethodEmulator
+// to check results of get methods returned by lite servers.
+type MethodEmulator struct {
+	options []Option
+}
+
+// NewMethodEmulator returns a new MethodEmulator.
+// The options are applied to every emulator instance,
+// the balance, the config, libraries and c7 are taken from the environment.
+func NewMethodEmulator(opts ...Option) *MethodEmulator {
+	return &MethodEmulator{options: opts}
+}
+
+func (m *MethodEmulator) RunGetMethod(ctx context.Context, env ton.MethodEnv, methodID int, params tlb.VmStack) (uint32, *boc.Cell, error) {
+	e, err := NewEmulator(env.Code, env.Data, env.Config, m.options...)
+	if err != nil {
+		return 0, nil, err
+	}
+	e.balance = env.Balance
+	if len(env.Libraries) > 0 {
+		libs, err := code.LibrariesToBase64(env.Libraries)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := e.setLibs(libs); err != nil {
+			return 0, nil, err
+		}
+	}
+	if err := e.setC7WithSeed(env.AccountID.ToRaw(), env.UnixTime, env.RandSeed); err != nil {
+		return 0, nil, err
+	}
+	res, err := e.runGetMethod(methodID, params)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !res.Success {
+		return 0, nil, fmt.Errorf("TVM emulation error: %v", res.Error)
+	}
+	b, err := base64.StdEncoding.DecodeString(res.Stack)
+	if err != nil {
+		return 0, nil, err
+	}
+	stack, err := boc.DeserializeSingleRootBoc(b)
+	if err != nil {
+		return 0, nil, err
+	}
+	return uint32(res.VmExitCode), stack, nil
+}
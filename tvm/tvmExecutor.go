@@ -259,6 +259,10 @@ func (e *Emulator) setC7(address string, unixTime uint32) error {
 	if err != nil {
 		return err
 	}
+	return e.setC7WithSeed(address, unixTime, seed)
+}
+
+func (e *Emulator) setC7WithSeed(address string, unixTime uint32, seed [32]byte) error {
 	cConfigStr := C.CString(e.config)
 	defer C.free(unsafe.Pointer(cConfigStr))
 	if e.config == "" {
@@ -140,6 +140,12 @@ type blockchain interface {
 	GetAccountState(ctx context.Context, accountID ton.AccountID) (tlb.ShardAccount, error)
 }
 
+// messageWaiter is implemented by blockchain interfaces able to wait for a message to be processed,
+// like liteapi.Client.
+type messageWaiter interface {
+	SendMessageAndWait(ctx context.Context, payload []byte, validUntil time.Time) (ton.Transaction, error)
+}
+
 func GetCodeByVer(ver Version) *boc.Cell {
 	c, err := boc.DeserializeBocBase64(codes[ver])
 	if err != nil {
@@ -174,19 +174,15 @@ func GenerateStateInit(
 	return *state, nil
 }
 
-func (w *Wallet) RawSendV2(
-	ctx context.Context,
+// createExternalMessage returns a serialized signed external message and its hash.
+func (w *Wallet) createExternalMessage(
 	seqno uint32,
 	validUntil time.Time,
 	internalMessages []RawMessage,
 	init *tlb.StateInit,
-	waitingConfirmation time.Duration,
-) (ton.Bits256, error) {
-	if w.blockchain == nil {
-		return ton.Bits256{}, errors.New("blockchain interface is nil")
-	}
+) ([]byte, ton.Bits256, error) {
 	if len(internalMessages) > w.intWallet.maxMessageNumber() {
-		return ton.Bits256{}, fmt.Errorf("%v wallet support up to %v internal messages", w.ver, w.intWallet.maxMessageNumber())
+		return nil, ton.Bits256{}, fmt.Errorf("%v wallet support up to %v internal messages", w.ver, w.intWallet.maxMessageNumber())
 	}
 	msgConfig := MessageConfig{
 		Seqno:      seqno,
@@ -195,24 +191,42 @@ func (w *Wallet) RawSendV2(
 	}
 	signedBodyCell, err := w.intWallet.createSignedMsgBodyCell(w.key, internalMessages, msgConfig)
 	if err != nil {
-		return ton.Bits256{}, fmt.Errorf("can not marshal wallet message body: %v", err)
+		return nil, ton.Bits256{}, fmt.Errorf("can not marshal wallet message body: %v", err)
 	}
 	extMsg, err := ton.CreateExternalMessage(w.address, signedBodyCell, init, tlb.VarUInteger16{})
 	if err != nil {
-		return ton.Bits256{}, fmt.Errorf("can not create external message: %v", err)
+		return nil, ton.Bits256{}, fmt.Errorf("can not create external message: %v", err)
 	}
 	extMsgCell := boc.NewCell()
 	err = tlb.Marshal(extMsgCell, extMsg)
 	if err != nil {
-		return ton.Bits256{}, fmt.Errorf("can not marshal wallet external message: %v", err)
+		return nil, ton.Bits256{}, fmt.Errorf("can not marshal wallet external message: %v", err)
 	}
 	msgHash, err := extMsgCell.Hash256()
 	if err != nil {
-		return ton.Bits256{}, fmt.Errorf("can not create external message: %v", err)
+		return nil, ton.Bits256{}, fmt.Errorf("can not create external message: %v", err)
 	}
 	payload, err := extMsgCell.ToBocCustom(false, false, false, 0)
 	if err != nil {
-		return ton.Bits256{}, fmt.Errorf("can not serialize external message cell: %v", err)
+		return nil, ton.Bits256{}, fmt.Errorf("can not serialize external message cell: %v", err)
+	}
+	return payload, msgHash, nil
+}
+
+func (w *Wallet) RawSendV2(
+	ctx context.Context,
+	seqno uint32,
+	validUntil time.Time,
+	internalMessages []RawMessage,
+	init *tlb.StateInit,
+	waitingConfirmation time.Duration,
+) (ton.Bits256, error) {
+	if w.blockchain == nil {
+		return ton.Bits256{}, errors.New("blockchain interface is nil")
+	}
+	payload, msgHash, err := w.createExternalMessage(seqno, validUntil, internalMessages, init)
+	if err != nil {
+		return ton.Bits256{}, err
 	}
 	t := time.Now()
 	_, err = w.blockchain.SendMessage(ctx, payload) // TODO: add result code check
@@ -256,28 +270,79 @@ func (w *Wallet) SendV2(ctx context.Context, waitingConfirmation time.Duration,
 	if w.blockchain == nil {
 		return ton.Bits256{}, errors.New("blockchain interface is nil")
 	}
+	params, msgArray, err := w.prepareMessages(ctx, messages)
+	if err != nil {
+		return ton.Bits256{}, err
+	}
+	validUntil := time.Now().Add(w.msgDefaultLifetime)
+	return w.RawSendV2(ctx, params.Seqno, validUntil, msgArray, params.Init, waitingConfirmation)
+}
+
+// prepareMessages returns parameters of the next wallet message and the given messages converted to internal ones.
+func (w *Wallet) prepareMessages(ctx context.Context, messages []Sendable) (NextMsgParams, []RawMessage, error) {
 	state, err := w.blockchain.GetAccountState(ctx, w.GetAddress())
 	if err != nil {
-		return ton.Bits256{}, fmt.Errorf("get account state failed: %v", err)
+		return NextMsgParams{}, nil, fmt.Errorf("get account state failed: %v", err)
 	}
 	params, err := w.intWallet.NextMessageParams(state)
 	if err != nil {
-		return ton.Bits256{}, err
+		return NextMsgParams{}, nil, err
 	}
 	msgArray := make([]RawMessage, 0, len(messages))
 	for _, m := range messages {
 		intMsg, mode, err := m.ToInternal()
 		if err != nil {
-			return ton.Bits256{}, err
+			return NextMsgParams{}, nil, err
 		}
 		cell := boc.NewCell()
 		if err := tlb.Marshal(cell, intMsg); err != nil {
-			return ton.Bits256{}, err
+			return NextMsgParams{}, nil, err
 		}
 		msgArray = append(msgArray, RawMessage{Message: cell, Mode: mode})
 	}
+	return params, msgArray, nil
+}
+
+// RawSendAndWait
+// Generates a signed external message for wallet with custom internal messages, seqno, TTL and init,
+// sends it and waits for the wallet's transaction processing the message.
+// The blockchain interface must implement SendMessageAndWait like liteapi.Client does.
+// If the message isn't processed before validUntil, the blockchain's expiry error is returned.
+func (w *Wallet) RawSendAndWait(
+	ctx context.Context,
+	seqno uint32,
+	validUntil time.Time,
+	internalMessages []RawMessage,
+	init *tlb.StateInit,
+) (ton.Transaction, error) {
+	if w.blockchain == nil {
+		return ton.Transaction{}, errors.New("blockchain interface is nil")
+	}
+	waiter, ok := w.blockchain.(messageWaiter)
+	if !ok {
+		return ton.Transaction{}, errors.New("blockchain interface doesn't support waiting for messages")
+	}
+	payload, _, err := w.createExternalMessage(seqno, validUntil, internalMessages, init)
+	if err != nil {
+		return ton.Transaction{}, err
+	}
+	return waiter.SendMessageAndWait(ctx, payload, validUntil)
+}
+
+// SendAndWait
+// Generates a signed external message for wallet with custom internal messages and default TTL,
+// sends it and waits for the wallet's transaction processing the message.
+// Take a look at RawSendAndWait.
+func (w *Wallet) SendAndWait(ctx context.Context, messages ...Sendable) (ton.Transaction, error) {
+	if w.blockchain == nil {
+		return ton.Transaction{}, errors.New("blockchain interface is nil")
+	}
+	params, msgArray, err := w.prepareMessages(ctx, messages)
+	if err != nil {
+		return ton.Transaction{}, err
+	}
 	validUntil := time.Now().Add(w.msgDefaultLifetime)
-	return w.RawSendV2(ctx, params.Seqno, validUntil, msgArray, params.Init, waitingConfirmation)
+	return w.RawSendAndWait(ctx, params.Seqno, validUntil, msgArray, params.Init)
 }
 
 // Send